@@ -0,0 +1,12 @@
+// Command go-metric scrapes Prometheus targets, diffs snapshots, estimates cardinality and lints metric names,
+// see the metrictool package for the subcommands.
+package main
+
+import (
+	"github.com/liangweijiang/go-metric/pkg/metrictool"
+	"os"
+)
+
+func main() {
+	os.Exit(metrictool.Main(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
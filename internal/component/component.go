@@ -0,0 +1,177 @@
+// Package component implements the component instruments of pkg/interfaces, which record the metrics of the
+// middlewares under the naming policy of their interfaces.ComponentSpec: a fixed metric name and unit, and the values
+// of the predefined tags given at creation, without any other dimension.
+package component
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/liangweijiang/go-metric/internal/metrics/nop"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MissingTagValue is the value of the predefined tags given no or an empty value, so that all the series of a
+// component have the same tag keys.
+const MissingTagValue = "unknown"
+
+// ErrInvalidSpec is returned by Validate for the specs breaking the naming policy.
+var ErrInvalidSpec = errors.New("invalid component spec")
+
+var (
+	// metricNamePattern is the pattern of the metric names of the components.
+	metricNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	// tagKeyPattern is the pattern of the tag keys of the components.
+	tagKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	// reservedSuffixes are the suffixes added to the metric names by the exposition.
+	reservedSuffixes = []string{"_total", "_bucket", "_count", "_sum"}
+)
+
+// Validate checks that spec follows the naming policy of the components: a lower snake case metric name without a
+// suffix added by the exposition, lower snake case tag keys without duplicates, and increasing histogram buckets.
+func Validate(spec interfaces.ComponentSpec) error {
+	if !metricNamePattern.MatchString(spec.Name) {
+		return fmt.Errorf("%w: metric name %q must be lower snake case", ErrInvalidSpec, spec.Name)
+	}
+	for _, suffix := range reservedSuffixes {
+		if strings.HasSuffix(spec.Name, suffix) {
+			return fmt.Errorf("%w: metric name %q must not end with %s", ErrInvalidSpec, spec.Name, suffix)
+		}
+	}
+	seen := make(map[string]bool, len(spec.Tags))
+	for _, key := range spec.Tags {
+		if !tagKeyPattern.MatchString(key) {
+			return fmt.Errorf("%w: %s: tag key %q must be lower snake case", ErrInvalidSpec, spec.Name, key)
+		}
+		if seen[key] {
+			return fmt.Errorf("%w: %s: duplicate tag key %q", ErrInvalidSpec, spec.Name, key)
+		}
+		seen[key] = true
+	}
+	for i := 1; i < len(spec.Buckets); i++ {
+		if spec.Buckets[i] <= spec.Buckets[i-1] {
+			return fmt.Errorf("%w: %s: buckets must be increasing", ErrInvalidSpec, spec.Name)
+		}
+	}
+	return nil
+}
+
+// instrument holds the spec and the tags of a component instrument, and the meter its measurements are recorded to,
+// resolved at every record so that the instrument follows the meter replaced at runtime.
+type instrument struct {
+	meter func() interfaces.BaseMeter
+	spec  interfaces.ComponentSpec
+	tags  map[string]string
+	start time.Time
+}
+
+// newInstrument pairs the values with the predefined tags of spec in order, the values beyond the predefined tags
+// are ignored and the missing ones set to MissingTagValue. The instrument is created now.
+func newInstrument(meter func() interfaces.BaseMeter, spec interfaces.ComponentSpec, values []string) instrument {
+	tags := make(map[string]string, len(spec.Tags))
+	for i, key := range spec.Tags {
+		tags[key] = MissingTagValue
+		if i < len(values) && values[i] != "" {
+			tags[key] = values[i]
+		}
+	}
+	return instrument{
+		meter: meter,
+		spec:  spec,
+		tags:  tags,
+		start: time.Now(),
+	}
+}
+
+// histogram is the interfaces.ComponentHistogram recording to a histogram of the meter.
+type histogram struct {
+	instrument
+}
+
+// NewHistogram creates the histogram of spec with the values of its predefined tags, recorded to the meter returned
+// by meter. An invalid spec, see Validate, yields a no-op histogram.
+func NewHistogram(meter func() interfaces.BaseMeter, spec interfaces.ComponentSpec, values ...string) interfaces.ComponentHistogram {
+	if Validate(spec) != nil {
+		return nop.ComponentHistogram
+	}
+	return &histogram{instrument: newInstrument(meter, spec, values)}
+}
+
+// Update records the duration d.
+func (h *histogram) Update(ctx context.Context, d time.Duration) {
+	h.meter().NewHistogramWithBuckets(h.spec.Name, h.spec.Desc, h.spec.Unit, h.spec.Buckets).
+		WithTags(h.tags).Update(ctx, d)
+}
+
+// UpdateInSeconds records the time elapsed since the creation of the histogram, in seconds.
+func (h *histogram) UpdateInSeconds(ctx context.Context) {
+	h.UpdateSine(ctx, h.start)
+}
+
+// UpdateInMilliseconds records the time elapsed since the creation of the histogram, converted to seconds like every
+// duration.
+func (h *histogram) UpdateInMilliseconds(ctx context.Context) {
+	h.UpdateSine(ctx, h.start)
+}
+
+// UpdateSine records the time elapsed since start.
+func (h *histogram) UpdateSine(ctx context.Context, start time.Time) {
+	h.meter().NewHistogramWithBuckets(h.spec.Name, h.spec.Desc, h.spec.Unit, h.spec.Buckets).
+		WithTags(h.tags).UpdateSine(ctx, start)
+}
+
+// counter is the interfaces.ComponentCounter recording to a counter of the meter.
+type counter struct {
+	instrument
+}
+
+// NewCounter creates the counter of spec with the values of its predefined tags, recorded to the meter returned by
+// meter. An invalid spec, see Validate, yields a no-op counter.
+func NewCounter(meter func() interfaces.BaseMeter, spec interfaces.ComponentSpec, values ...string) interfaces.ComponentCounter {
+	if Validate(spec) != nil {
+		return nop.Counter
+	}
+	return &counter{instrument: newInstrument(meter, spec, values)}
+}
+
+// Incr increments the counter by delta.
+func (c *counter) Incr(ctx context.Context, delta float64) {
+	c.meter().NewCounter(c.spec.Name, c.spec.Desc, c.spec.Unit).WithTags(c.tags).Incr(ctx, delta)
+}
+
+// IncrOne increments the counter by one.
+func (c *counter) IncrOne(ctx context.Context) {
+	c.Incr(ctx, 1)
+}
+
+// upDownCounter is the interfaces.ComponentUpDownCounter recording to an up-down counter of the meter.
+type upDownCounter struct {
+	instrument
+}
+
+// NewUpDownCounter creates the up-down counter of spec with the values of its predefined tags, recorded to the meter
+// returned by meter. An invalid spec, see Validate, yields a no-op up-down counter.
+func NewUpDownCounter(meter func() interfaces.BaseMeter, spec interfaces.ComponentSpec, values ...string) interfaces.ComponentUpDownCounter {
+	if Validate(spec) != nil {
+		return nop.UpDownCounter
+	}
+	return &upDownCounter{instrument: newInstrument(meter, spec, values)}
+}
+
+// Update adds delta to the counter.
+func (c *upDownCounter) Update(ctx context.Context, delta float64) {
+	c.meter().NewUpDownCounter(c.spec.Name, c.spec.Desc, c.spec.Unit).WithTags(c.tags).Update(ctx, delta)
+}
+
+// IncrOne increments the counter by one.
+func (c *upDownCounter) IncrOne(ctx context.Context) {
+	c.Update(ctx, 1)
+}
+
+// DecrOne decrements the counter by one.
+func (c *upDownCounter) DecrOne(ctx context.Context) {
+	c.Update(ctx, -1)
+}
@@ -0,0 +1,69 @@
+package component_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/internal/component"
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var messagesSpec = interfaces.ComponentSpec{
+	Name: "mq_messages",
+	Desc: "messages consumed",
+	Tags: []string{"topic", "outcome"},
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, component.Validate(messagesSpec))
+	assert.ErrorIs(t, component.Validate(interfaces.ComponentSpec{Name: "mq_messages_total"}), component.ErrInvalidSpec)
+	assert.ErrorIs(t, component.Validate(interfaces.ComponentSpec{Name: "mq", Tags: []string{"topic", "topic"}}), component.ErrInvalidSpec)
+	assert.ErrorIs(t, component.Validate(interfaces.ComponentSpec{Name: "mq", Buckets: []float64{2, 1}}), component.ErrInvalidSpec)
+}
+
+func TestCounter(t *testing.T) {
+	m, err := meter.NewMeter(meter.WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	base := func() interfaces.BaseMeter { return m }
+
+	component.NewCounter(base, messagesSpec, "orders", "success").IncrOne(context.Background())
+	component.NewCounter(base, messagesSpec, "orders").Incr(context.Background(), 2)
+	component.NewCounter(base, messagesSpec, "orders", "error", "ignored").IncrOne(context.Background())
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`mq_messages_total{topic="orders",outcome="success"} 1`,
+		`mq_messages_total{topic="orders",outcome="unknown"} 2`,
+		`mq_messages_total{topic="orders",outcome="error"} 1`,
+	)
+}
+
+func TestComponents(t *testing.T) {
+	for _, spec := range []interfaces.ComponentSpec{
+		component.HTTPServerRequests, component.HTTPServerDuration, component.HTTPServerInFlight,
+		component.HTTPClientRequests, component.HTTPClientDuration, component.DBQueries, component.DBDuration,
+		component.CacheRequests, component.CacheDuration, component.CacheEvictions, component.CacheEntries,
+		component.MQPublished, component.MQConsumed,
+		component.MQProcessDuration,
+	} {
+		assert.NoError(t, component.Validate(spec))
+	}
+
+	m, err := meter.NewMeter(meter.WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	c := m.Components()
+	c.HTTPServer.Requests("GET", "/pay", "200").IncrOne(context.Background())
+	c.DB.Duration("mysql", "select", "success").Update(context.Background(), 20*time.Millisecond)
+	c.Cache.Duration("sessions", "get").UpdateInSeconds(context.Background())
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`http_server_requests_total{method="GET",route="/pay",status="200"} 1`,
+		`db_query_duration_seconds_sum{db_system="mysql",operation="select",outcome="success"} 0.02`,
+		`cache_duration_seconds_count{cache="sessions",operation="get"} 1`,
+	)
+}
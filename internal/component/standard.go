@@ -0,0 +1,235 @@
+package component
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/semconv"
+)
+
+// Tag keys of the standard components which are not defined by semconv.
+const (
+	TagDBSystem = "db_system"
+	TagCache    = "cache"
+	TagResult   = "result"
+	TagReason   = "reason"
+	TagTopic    = "topic"
+)
+
+// Values of the result tag of the cache requests.
+const (
+	CacheResultHit  = "hit"
+	CacheResultMiss = "miss"
+)
+
+// Specs of the standard components returned by Meter.Components.
+var (
+	HTTPServerRequests = interfaces.ComponentSpec{
+		Name: "http_server_requests",
+		Desc: "number of HTTP requests served",
+		Tags: []string{semconv.Method, semconv.Route, semconv.Status},
+	}
+	HTTPServerDuration = interfaces.ComponentSpec{
+		Name:    "http_server_duration",
+		Desc:    "duration of the HTTP requests served",
+		Unit:    "s",
+		Buckets: config.BucketsHTTPServer,
+		Tags:    []string{semconv.Method, semconv.Route, semconv.Status},
+	}
+	HTTPServerInFlight = interfaces.ComponentSpec{
+		Name: "http_server_in_flight",
+		Desc: "number of HTTP requests being served",
+		Tags: []string{semconv.Method, semconv.Route},
+	}
+	HTTPClientRequests = interfaces.ComponentSpec{
+		Name: "http_client_requests",
+		Desc: "number of HTTP requests sent",
+		Tags: []string{semconv.PeerService, semconv.Method, semconv.Status},
+	}
+	HTTPClientDuration = interfaces.ComponentSpec{
+		Name:    "http_client_duration",
+		Desc:    "duration of the HTTP requests sent",
+		Unit:    "s",
+		Buckets: config.BucketsHTTPServer,
+		Tags:    []string{semconv.PeerService, semconv.Method, semconv.Status},
+	}
+	DBQueries = interfaces.ComponentSpec{
+		Name: "db_queries",
+		Desc: "number of database queries",
+		Tags: []string{TagDBSystem, semconv.Operation, semconv.Outcome},
+	}
+	DBDuration = interfaces.ComponentSpec{
+		Name:    "db_query_duration",
+		Desc:    "duration of the database queries",
+		Unit:    "s",
+		Buckets: config.BucketsDB,
+		Tags:    []string{TagDBSystem, semconv.Operation, semconv.Outcome},
+	}
+	CacheRequests = interfaces.ComponentSpec{
+		Name: "cache_requests",
+		Desc: "number of cache lookups by result",
+		Tags: []string{TagCache, TagResult},
+	}
+	CacheDuration = interfaces.ComponentSpec{
+		Name:    "cache_duration",
+		Desc:    "duration of the cache operations",
+		Unit:    "s",
+		Buckets: config.BucketsCacheFast,
+		Tags:    []string{TagCache, semconv.Operation},
+	}
+	CacheEvictions = interfaces.ComponentSpec{
+		Name: "cache_evictions",
+		Desc: "number of cache entries evicted by reason",
+		Tags: []string{TagCache, TagReason},
+	}
+	CacheEntries = interfaces.ComponentSpec{
+		Name: "cache_entries",
+		Desc: "number of entries in the cache",
+		Tags: []string{TagCache},
+	}
+	MQPublished = interfaces.ComponentSpec{
+		Name: "mq_published_messages",
+		Desc: "number of messages published",
+		Tags: []string{TagTopic, semconv.Outcome},
+	}
+	MQConsumed = interfaces.ComponentSpec{
+		Name: "mq_consumed_messages",
+		Desc: "number of messages consumed",
+		Tags: []string{TagTopic, semconv.Outcome},
+	}
+	MQProcessDuration = interfaces.ComponentSpec{
+		Name:    "mq_process_duration",
+		Desc:    "duration of the processing of the consumed messages",
+		Unit:    "s",
+		Buckets: config.BucketsDB,
+		Tags:    []string{TagTopic, semconv.Outcome},
+	}
+)
+
+// meterFunc returns the meter of the components.
+type meterFunc func() interfaces.BaseMeter
+
+// NewComponents creates the standard components recording to the meter returned by meter.
+func NewComponents(meter func() interfaces.BaseMeter) interfaces.Components {
+	m := meterFunc(meter)
+	return interfaces.Components{
+		HTTPServer: httpServer{m},
+		HTTPClient: httpClient{m},
+		DB:         db{m},
+		Cache:      cache{m},
+		MQ:         mq{m},
+	}
+}
+
+// httpServer is the interfaces.HTTPServerComponent of the standard components.
+type httpServer struct {
+	meter meterFunc
+}
+
+// Requests returns the counter of the requests served.
+func (c httpServer) Requests(method, route, status string) interfaces.ComponentCounter {
+	return NewCounter(c.meter, HTTPServerRequests, method, route, status)
+}
+
+// Duration returns the histogram of the duration of the requests served.
+func (c httpServer) Duration(method, route, status string) interfaces.ComponentHistogram {
+	return NewHistogram(c.meter, HTTPServerDuration, method, route, status)
+}
+
+// InFlight returns the up-down counter of the requests being served.
+func (c httpServer) InFlight(method, route string) interfaces.ComponentUpDownCounter {
+	return NewUpDownCounter(c.meter, HTTPServerInFlight, method, route)
+}
+
+// httpClient is the interfaces.HTTPClientComponent of the standard components.
+type httpClient struct {
+	meter meterFunc
+}
+
+// Requests returns the counter of the requests sent.
+func (c httpClient) Requests(peerService, method, status string) interfaces.ComponentCounter {
+	return NewCounter(c.meter, HTTPClientRequests, peerService, method, status)
+}
+
+// Duration returns the histogram of the duration of the requests sent.
+func (c httpClient) Duration(peerService, method, status string) interfaces.ComponentHistogram {
+	return NewHistogram(c.meter, HTTPClientDuration, peerService, method, status)
+}
+
+// db is the interfaces.DBComponent of the standard components.
+type db struct {
+	meter meterFunc
+}
+
+// Queries returns the counter of the queries.
+func (c db) Queries(system, operation, outcome string) interfaces.ComponentCounter {
+	return NewCounter(c.meter, DBQueries, system, operation, outcome)
+}
+
+// Duration returns the histogram of the duration of the queries.
+func (c db) Duration(system, operation, outcome string) interfaces.ComponentHistogram {
+	return NewHistogram(c.meter, DBDuration, system, operation, outcome)
+}
+
+// cache is the interfaces.CacheComponent of the standard components.
+type cache struct {
+	meter meterFunc
+}
+
+// Requests returns the counter of the lookups with the given result.
+func (c cache) Requests(name, result string) interfaces.ComponentCounter {
+	return NewCounter(c.meter, CacheRequests, name, result)
+}
+
+// Duration returns the histogram of the duration of the operations.
+func (c cache) Duration(name, operation string) interfaces.ComponentHistogram {
+	return NewHistogram(c.meter, CacheDuration, name, operation)
+}
+
+// RecordHit counts a lookup finding its entry.
+func (c cache) RecordHit(ctx context.Context, name string) {
+	c.Requests(name, CacheResultHit).IncrOne(ctx)
+}
+
+// RecordMiss counts a lookup not finding its entry.
+func (c cache) RecordMiss(ctx context.Context, name string) {
+	c.Requests(name, CacheResultMiss).IncrOne(ctx)
+}
+
+// RecordEviction counts an entry evicted for the given reason.
+func (c cache) RecordEviction(ctx context.Context, name, reason string) {
+	NewCounter(c.meter, CacheEvictions, name, reason).IncrOne(ctx)
+}
+
+// ObserveSize registers the gauge of the number of entries of the cache, reported by size at every collection.
+func (c cache) ObserveSize(name string, size func() float64) interfaces.Registration {
+	if name == "" {
+		name = MissingTagValue
+	}
+	tags := map[string]string{TagCache: name}
+	return c.meter().NewObservableGauge(CacheEntries.Name, CacheEntries.Desc, CacheEntries.Unit,
+		func(_ context.Context, o interfaces.Observer) error {
+			o.Observe(size(), tags)
+			return nil
+		})
+}
+
+// mq is the interfaces.MQComponent of the standard components.
+type mq struct {
+	meter meterFunc
+}
+
+// Published returns the counter of the messages published.
+func (c mq) Published(topic, outcome string) interfaces.ComponentCounter {
+	return NewCounter(c.meter, MQPublished, topic, outcome)
+}
+
+// Consumed returns the counter of the messages consumed.
+func (c mq) Consumed(topic, outcome string) interfaces.ComponentCounter {
+	return NewCounter(c.meter, MQConsumed, topic, outcome)
+}
+
+// ProcessDuration returns the histogram of the processing duration of the messages consumed.
+func (c mq) ProcessDuration(topic, outcome string) interfaces.ComponentHistogram {
+	return NewHistogram(c.meter, MQProcessDuration, topic, outcome)
+}
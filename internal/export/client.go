@@ -0,0 +1,132 @@
+// Package export holds the pipeline shared by the push exporters: the compression of the bodies, the retries of the
+// failed requests and the batching of the metric families, configured by config.ExportCfg.
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	dto "github.com/prometheus/client_model/go"
+	"io"
+	"net/http"
+)
+
+// HTTPDoer sends HTTP requests, e.g. *http.Client, as push.HTTPDoer.
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client is a HTTPDoer compressing the bodies of the requests and retrying those failing with a network error, a 429
+// or a 5xx status according to the export configuration, waiting with the clock of the meter.
+type Client struct {
+	cfg  *config.Config
+	doer HTTPDoer
+}
+
+// NewClient creates a client sending the requests with doer, http.DefaultClient if nil.
+func NewClient(cfg *config.Config, doer HTTPDoer) *Client {
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	return &Client{
+		cfg:  cfg,
+		doer: doer,
+	}
+}
+
+// Do sends the request, its body compressed, until it succeeds or the attempts are exhausted, returning the last
+// response or error. The backoffs are cut short when the context of the request is done.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+	compression := config.CompressionNone
+	if c.cfg.Export != nil {
+		compression = c.cfg.Export.Compression
+	}
+	if compression != config.CompressionNone && len(body) > 0 {
+		compressed, err := Compress(compression, body)
+		if err != nil {
+			return nil, err
+		}
+		body = compressed
+	}
+
+	attempts := c.cfg.Export.GetMaxAttempts()
+	for attempt := 1; ; attempt++ {
+		r := req.Clone(req.Context())
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+		if compression != config.CompressionNone && body != nil {
+			r.Header.Set("Content-Encoding", string(compression))
+		}
+		resp, err := c.doer.Do(r)
+		if attempt >= attempts || !retryable(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+			c.cfg.WriteDebugOrNot(fmt.Sprintf("export to %s returned status %d, retry %d", req.URL.Host, resp.StatusCode, attempt))
+		} else {
+			c.cfg.WriteDebugOrNot(fmt.Sprintf("export to %s failed: %v, retry %d", req.URL.Host, err, attempt))
+		}
+		timer := c.cfg.GetClock().NewTimer(c.cfg.Export.Backoff(attempt))
+		select {
+		case <-timer.C():
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// retryable reports whether a request failing with resp or err may succeed when sent again.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// Compress encodes body with the compression.
+func Compress(compression config.Compression, body []byte) ([]byte, error) {
+	switch compression {
+	case config.CompressionNone:
+		return body, nil
+	case config.CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported compression %q", config.ErrInvalidExport, compression)
+	}
+}
+
+// Batches splits the metric families in batches of size families, a single batch if size is not positive.
+func Batches(mfs []*dto.MetricFamily, size int) [][]*dto.MetricFamily {
+	if size <= 0 || len(mfs) <= size {
+		return [][]*dto.MetricFamily{mfs}
+	}
+	batches := make([][]*dto.MetricFamily, 0, (len(mfs)+size-1)/size)
+	for len(mfs) > size {
+		batches = append(batches, mfs[:size])
+		mfs = mfs[size:]
+	}
+	return append(batches, mfs)
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	body := []byte(strings.Repeat("orders_total 1\n", 10))
+	decoders := map[config.Compression]func(b []byte) ([]byte, error){
+		config.CompressionGzip: func(b []byte) ([]byte, error) {
+			r, err := gzip.NewReader(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			return io.ReadAll(r)
+		},
+		config.CompressionNone: func(b []byte) ([]byte, error) {
+			return b, nil
+		},
+	}
+	for compression, decode := range decoders {
+		compressed, err := Compress(compression, body)
+		require.NoError(t, err, compression)
+		decoded, err := decode(compressed)
+		require.NoError(t, err, compression)
+		assert.Equal(t, body, decoded, compression)
+	}
+	_, err := Compress("zstd", body)
+	assert.ErrorIs(t, err, config.ErrInvalidExport)
+}
+
+func TestClientRetry(t *testing.T) {
+	var attempts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		attempts = append(attempts, r.Header.Get("Content-Encoding")+" "+string(body))
+		if len(attempts) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Export: &config.ExportCfg{
+		Retry: config.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}}
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("orders_total 1"))
+	require.NoError(t, err)
+	resp, err := NewClient(cfg, nil).Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{" orders_total 1", " orders_total 1", " orders_total 1"}, attempts)
+
+	attempts = nil
+	cfg.Export.Retry.MaxAttempts = 2
+	req, err = http.NewRequest(http.MethodPut, server.URL, strings.NewReader("orders_total 1"))
+	require.NoError(t, err)
+	resp, err = NewClient(cfg, nil).Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "the last response is returned")
+	assert.Len(t, attempts, 2)
+}
+
+func TestBatches(t *testing.T) {
+	mfs := make([]*dto.MetricFamily, 5)
+	var sizes []int
+	for _, batch := range Batches(mfs, 2) {
+		sizes = append(sizes, len(batch))
+	}
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+	assert.Len(t, Batches(mfs, 0), 1)
+}
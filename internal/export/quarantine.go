@@ -0,0 +1,86 @@
+package export
+
+import (
+	dto "github.com/prometheus/client_model/go"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// rejectedMetric matches the metric names quoted in the errors of the backends rejecting series, e.g. the push
+// gateway answering `collected metric "orders_total" { ... } was collected before with the same name and label values`
+// or `metric name "1st" is invalid`.
+var rejectedMetric = regexp.MustCompile(`metric(?: name)? \\?"([^"\\]+)\\?"`)
+
+// Quarantine holds the metrics rejected by a backend, which are excluded from the following exports so that the
+// others keep being exported.
+type Quarantine struct {
+	mu    sync.Mutex
+	names map[string]struct{}
+}
+
+// NewQuarantine creates an empty quarantine.
+func NewQuarantine() *Quarantine {
+	return &Quarantine{
+		names: make(map[string]struct{}),
+	}
+}
+
+// Add quarantines the metrics named in the rejection message which belong to the families exported, and returns
+// those quarantined by this call.
+func (q *Quarantine) Add(mfs []*dto.MetricFamily, message string) []string {
+	exported := make(map[string]struct{}, len(mfs))
+	for _, mf := range mfs {
+		exported[mf.GetName()] = struct{}{}
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var added []string
+	for _, match := range rejectedMetric.FindAllStringSubmatch(message, -1) {
+		name := match[1]
+		if _, ok := exported[name]; !ok {
+			continue
+		}
+		if _, ok := q.names[name]; ok {
+			continue
+		}
+		q.names[name] = struct{}{}
+		added = append(added, name)
+	}
+	return added
+}
+
+// Filter returns the families which are not quarantined.
+func (q *Quarantine) Filter(mfs []*dto.MetricFamily) []*dto.MetricFamily {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.names) == 0 {
+		return mfs
+	}
+	kept := make([]*dto.MetricFamily, 0, len(mfs))
+	for _, mf := range mfs {
+		if _, ok := q.names[mf.GetName()]; !ok {
+			kept = append(kept, mf)
+		}
+	}
+	return kept
+}
+
+// Names returns the sorted names of the quarantined metrics.
+func (q *Quarantine) Names() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	names := make([]string, 0, len(q.names))
+	for name := range q.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsRejection reports whether the export error is a rejection of some of the series, a 400 status, rather than a
+// failure of the backend.
+func IsRejection(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "status code 400")
+}
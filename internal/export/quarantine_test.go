@@ -0,0 +1,33 @@
+package export
+
+import (
+	"errors"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuarantine(t *testing.T) {
+	family := func(name string) *dto.MetricFamily {
+		return &dto.MetricFamily{Name: &name}
+	}
+	mfs := []*dto.MetricFamily{family("orders_total"), family("refunds_total"), family("latency_seconds")}
+	q := NewQuarantine()
+	message := `unexpected status code 400 while pushing to http://gateway/metrics/job/10.0.0.7: ` +
+		`pushed metrics are invalid or inconsistent with existing metrics: collected metric "refunds_total" ` +
+		`{ label:{name:"route" value:"/a"} counter:{value:1} } was collected before with the same name and label values` +
+		` and metric "unknown_total" is invalid`
+
+	assert.Equal(t, []string{"refunds_total"}, q.Add(mfs, message), "only the metrics exported are quarantined")
+	assert.Empty(t, q.Add(mfs, message), "a metric is quarantined once")
+	var kept []string
+	for _, mf := range q.Filter(mfs) {
+		kept = append(kept, mf.GetName())
+	}
+	assert.Equal(t, []string{"orders_total", "latency_seconds"}, kept)
+	assert.Equal(t, []string{"refunds_total"}, q.Names())
+
+	assert.True(t, IsRejection(errors.New(message)))
+	assert.False(t, IsRejection(errors.New("unexpected status code 503 while pushing")))
+}
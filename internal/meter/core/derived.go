@@ -0,0 +1,60 @@
+package core
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/derive"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"strings"
+)
+
+// derivedGroup holds the sums of the metrics of an expression over the series with the same values of the By tags.
+type derivedGroup struct {
+	tags map[string]string
+	sums map[string]float64
+}
+
+// registerDerived registers the observable gauge of a derived metric, whose expression is evaluated at every
+// collection from the values tracked by the registry. The expression was parsed by config.Validate.
+func (m *Meter) registerDerived(d config.DerivedMetric) {
+	expr, err := derive.Parse(d.Expr)
+	if err != nil {
+		m.cfg.WriteErrorOrNot("failed to parse derived metric " + d.Name + ": " + err.Error())
+		return
+	}
+	m.NewObservableGauge(d.Name, d.Desc, d.Unit, func(_ context.Context, o interfaces.Observer) error {
+		for _, g := range m.derivedGroups(expr, d.By) {
+			if v, ok := expr.Eval(func(metric string) float64 { return g.sums[metric] }); ok {
+				o.Observe(v, g.tags)
+			}
+		}
+		return nil
+	})
+	m.registry.Untrack(d.Name)
+}
+
+// derivedGroups sums the series of the metrics of expr by the values of the by tags, a metric without series in a
+// group counting as zero.
+func (m *Meter) derivedGroups(expr *derive.Expr, by []string) map[string]*derivedGroup {
+	groups := make(map[string]*derivedGroup)
+	for _, metric := range expr.Metrics() {
+		for _, s := range m.registry.Series(metric) {
+			tags := make(map[string]string, len(by))
+			values := make([]string, 0, len(by))
+			for _, key := range by {
+				v, _ := s.Attrs.Value(attribute.Key(key))
+				tags[key] = v.Emit()
+				values = append(values, v.Emit())
+			}
+			key := strings.Join(values, "\xff")
+			g, ok := groups[key]
+			if !ok {
+				g = &derivedGroup{tags: tags, sums: make(map[string]float64)}
+				groups[key] = g
+			}
+			g.sums[metric] += s.Value
+		}
+	}
+	return groups
+}
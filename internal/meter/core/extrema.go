@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/semconv"
+	api "go.opentelemetry.io/otel/metric"
+	"strings"
+)
+
+// Suffixes of the names of the gauges exporting the extrema of a histogram.
+const (
+	ExtremaMinSuffix = "_min"
+	ExtremaMaxSuffix = "_max"
+)
+
+// registerExtrema registers, on the first creation of the histogram, the gauges exporting the smallest and the
+// largest value recorded to each of its series since the previous collection. The series not recorded to during an
+// interval are not exported. With the unit suffixes appended to the names, the suffixes of the extrema go before the
+// unit suffix, e.g. latency_min_seconds.
+func (m *Meter) registerExtrema(metricName, unit string) {
+	if _, loaded := m.extrema.LoadOrStore(metricName, struct{}{}); loaded {
+		return
+	}
+	minName, maxName := metricName+ExtremaMinSuffix, metricName+ExtremaMaxSuffix
+	if suffix, ok := semconv.UnitSuffix(unit); ok && m.cfg.UnitSuffixes && strings.HasSuffix(metricName, suffix) {
+		base := strings.TrimSuffix(metricName, suffix)
+		minName, maxName = base+ExtremaMinSuffix+suffix, base+ExtremaMaxSuffix+suffix
+	}
+	minGauge, err := m.meter.Float64ObservableGauge(minName,
+		api.WithDescription("smallest value of "+metricName+" since the previous collection"),
+		api.WithUnit(unit))
+	if err != nil {
+		m.cfg.WriteErrorOrNot("failed to create " + m.name + " min gauge of " + metricName + ": " + err.Error())
+		return
+	}
+	maxGauge, err := m.meter.Float64ObservableGauge(maxName,
+		api.WithDescription("largest value of "+metricName+" since the previous collection"),
+		api.WithUnit(unit))
+	if err != nil {
+		m.cfg.WriteErrorOrNot("failed to create " + m.name + " max gauge of " + metricName + ": " + err.Error())
+		return
+	}
+	_, err = m.meter.RegisterCallback(func(_ context.Context, o api.Observer) error {
+		for _, e := range m.registry.CollectExtrema(metricName) {
+			set := api.WithAttributeSet(e.Attrs)
+			o.ObserveFloat64(minGauge, e.Min, set)
+			o.ObserveFloat64(maxGauge, e.Max, set)
+		}
+		return nil
+	}, minGauge, maxGauge)
+	if err != nil {
+		m.cfg.WriteErrorOrNot("failed to register " + m.name + " extrema callback of " + metricName + ": " + err.Error())
+	}
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/internal/process"
+	"github.com/liangweijiang/go-metric/internal/registry"
+	"github.com/liangweijiang/go-metric/internal/runtime"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"sync/atomic"
+)
+
+// _ is a blank identifier used for type assertion to ensure that *PushMeter implements the interfaces.Shutdowner interface.
+var _ interfaces.Shutdowner = (*PushMeter)(nil)
+
+// PushMeter embeds the core meter of the meters whose metrics are pushed by a PushReader, such as the OTLP and the
+// Graphite meters, and runs their lifecycle: the pushes, the runtime and process collectors and the drop auditor run
+// while the meter is switched on, and Shutdown closes the exporter. It exposes no endpoint.
+type PushMeter struct {
+	*Meter
+	cfg         *config.Config
+	reader      *PushReader
+	collectors  []interfaces.MetricCollector
+	dropAuditor *registry.DropAuditor
+	started     int32
+	closed      int32
+}
+
+// NewPushMeter creates the meter name creating the instruments of the meter meterName of provider, whose metrics
+// are pushed by reader. It is started right away unless cfg.DeferStart is set.
+func NewPushMeter(cfg *config.Config, name string, provider *metric.MeterProvider, meterName string, reader *PushReader) *PushMeter {
+	dropAuditor := registry.NewDropAuditor(cfg)
+	m := &PushMeter{
+		Meter:       NewMeter(cfg, name, provider, provider.Meter(meterName), NewRegistry(cfg, dropAuditor)),
+		cfg:         cfg,
+		reader:      reader,
+		dropAuditor: dropAuditor,
+	}
+	m.collectors = []interfaces.MetricCollector{
+		runtime.NewRuntimeCollector(cfg, m),
+		process.NewFDCollector(cfg, m),
+		process.NewDiskCollector(cfg, m),
+		process.NewUptimeCollector(cfg, m),
+	}
+	if !cfg.DeferStart {
+		m.start()
+	}
+	return m
+}
+
+// start switches the meter on and starts the pushes, the collectors and the drop auditor, once.
+func (m *PushMeter) start() {
+	if !atomic.CompareAndSwapInt32(&m.started, 0, 1) {
+		return
+	}
+	// the meter may have been switched off before its deferred start.
+	m.SetRunning(true)
+	m.run()
+}
+
+// run starts the pushes, the collectors and the drop auditor.
+func (m *PushMeter) run() {
+	m.reader.Start()
+	for _, collector := range m.collectors {
+		collector.Start()
+	}
+	m.dropAuditor.Start()
+}
+
+// halt stops the pushes, the collectors and the drop auditor.
+func (m *PushMeter) halt() {
+	m.reader.Stop()
+	for _, collector := range m.collectors {
+		collector.Stop()
+	}
+	m.dropAuditor.Stop()
+}
+
+// WithRunning switches the meter on or off, starting or stopping the pushes, the collectors and the drop auditor. The
+// metrics recorded so far are pushed when the meter is switched off. A meter whose start is deferred is started by the
+// first call with true, and only switched off by false before. It does nothing once the meter is shut down.
+func (m *PushMeter) WithRunning(on bool) {
+	if atomic.LoadInt32(&m.closed) == 1 {
+		return
+	}
+	if atomic.LoadInt32(&m.started) == 0 {
+		if on {
+			m.start()
+		} else {
+			m.SetRunning(false)
+		}
+		return
+	}
+	if !m.SetRunning(on) {
+		return
+	}
+	if on {
+		m.cfg.WriteInfoOrNot(m.name + " meter is started")
+		m.run()
+		return
+	}
+	m.cfg.WriteInfoOrNot(m.name + " meter is stopped")
+	m.halt()
+	ctx, cancel := context.WithTimeout(context.Background(), m.reader.timeout)
+	defer cancel()
+	_ = m.Flush(ctx)
+}
+
+// Shutdown switches the meter off for good: it stops the pushes, the collectors and the drop auditor, pushes the
+// metrics recorded so far a last time if the meter was started, and shuts the exporter down.
+func (m *PushMeter) Shutdown(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&m.closed, 0, 1) {
+		return nil
+	}
+	m.SetRunning(false)
+	m.halt()
+	m.cfg.WriteInfoOrNot(m.name + " meter is shut down")
+	return m.reader.Shutdown(ctx)
+}
@@ -0,0 +1,567 @@
+// Package core creates the instruments of the SDK on an OpenTelemetry meter. It is shared by the meters built on the
+// OpenTelemetry SDK, such as the Prometheus meter, and backs the meters wrapping a provider configured by the
+// application.
+package core
+
+import (
+	"context"
+	"fmt"
+	"github.com/liangweijiang/go-metric/internal/component"
+	"github.com/liangweijiang/go-metric/internal/metrics/dual"
+	"github.com/liangweijiang/go-metric/internal/metrics/nop"
+	"github.com/liangweijiang/go-metric/internal/metrics/prom"
+	"github.com/liangweijiang/go-metric/internal/registry"
+	"github.com/liangweijiang/go-metric/pkg/analyze"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/semconv"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// _ is a blank identifier used for type assertion to ensure that *Meter implements the interfaces.Meter interface.
+var _ interfaces.Meter = (*Meter)(nil)
+
+// _ is a blank identifier used for type assertion to ensure that *Meter implements the interfaces.Reloadable interface.
+var _ interfaces.Reloadable = (*Meter)(nil)
+
+// Self-metrics exposing the unused instruments, tagged with their metric name, and the metric names nearly
+// duplicating a metric created before, also tagged with the name of that metric.
+const (
+	UnusedMetric        = "go_metric_unused_instruments"
+	NameConflictsMetric = "go_metric_name_conflicts"
+	TagMetric           = "metric"
+	TagConflictsWith    = "conflicts_with"
+)
+
+// Self-metric exposing the number of instruments of every deprecated metric created by every package still using it,
+// tagged with the metric name, its replacement and the package.
+const (
+	DeprecatedUsesMetric = "go_metric_deprecated_uses"
+	TagReplacement       = "replacement"
+	TagConsumer          = "consumer"
+)
+
+// Self-metric counting the panics recovered from the observable callbacks and the tag providers, tagged with the kind,
+// callback or tag_provider, and the name of the code which panicked, the metric name of a callback or the type of a
+// tag provider.
+const (
+	PanicsMetric = "go_metric_panics"
+	TagKind      = "kind"
+	TagName      = "name"
+)
+
+// flusher is implemented by the meter providers of the OpenTelemetry SDK.
+type flusher interface {
+	ForceFlush(ctx context.Context) error
+}
+
+// Meter implements interfaces.Meter on an OpenTelemetry meter, the instruments it creates consult the registry before
+// every measurement. It exposes no handler and serves no endpoint: the meters embedding it add their own exporters.
+type Meter struct {
+	cfg       *config.Config
+	name      string
+	running   int32
+	meter     api.Meter
+	provider  api.MeterProvider
+	registry  *registry.Registry
+	extrema   sync.Map
+	scheduler *callbackScheduler
+}
+
+// NewMeter creates a running Meter named after its backend, e.g. "prometheus", creating its instruments on the meter
+// of provider. The registry is created with NewRegistry.
+// When the registry tracks the usage of the metrics, the unused ones are exposed by the UnusedMetric gauge, the names
+// nearly duplicating each other are exposed by the NameConflictsMetric gauge, the packages still using the deprecated
+// metrics by the DeprecatedUsesMetric gauge.
+// The configured derived metrics are registered as observable gauges.
+// The panics of the observable callbacks and the tag providers are recovered, see recoverPanics.
+// With callback workers configured, the callbacks of the observable gauges are run by a callbackScheduler.
+func NewMeter(cfg *config.Config, name string, provider api.MeterProvider, meter api.Meter, r *registry.Registry) *Meter {
+	m := &Meter{
+		cfg:      cfg,
+		name:     name,
+		running:  1,
+		meter:    meter,
+		provider: provider,
+		registry: r,
+	}
+	if cfg.CallbackWorkers > 0 {
+		m.scheduler = newCallbackScheduler(cfg, meter, r)
+	}
+	m.recoverPanics()
+	if r.UsageWindow() > 0 {
+		m.newObservableGauge(UnusedMetric, "instruments created but not recorded to during the usage window", "",
+			func(_ context.Context, o interfaces.Observer) error {
+				for _, name := range r.Unused() {
+					o.Observe(1, map[string]string{TagMetric: name})
+				}
+				return nil
+			})
+		r.Untrack(UnusedMetric)
+	}
+	m.newObservableGauge(NameConflictsMetric, "metric names nearly duplicating a metric created before", "",
+		func(_ context.Context, o interfaces.Observer) error {
+			for name, first := range r.NameConflicts() {
+				o.Observe(1, map[string]string{TagMetric: name, TagConflictsWith: first})
+			}
+			return nil
+		})
+	r.Untrack(NameConflictsMetric)
+	m.newObservableGauge(DeprecatedUsesMetric, "instruments of the deprecated metrics created by every package", "",
+		func(_ context.Context, o interfaces.Observer) error {
+			for _, use := range r.DeprecatedUses() {
+				o.Observe(float64(use.Count), map[string]string{
+					TagMetric:      use.Metric,
+					TagReplacement: use.Replacement,
+					TagConsumer:    use.Consumer,
+				})
+			}
+			return nil
+		})
+	r.Untrack(DeprecatedUsesMetric)
+	for _, d := range cfg.DerivedMetrics {
+		m.registerDerived(d)
+	}
+	return m
+}
+
+// recoverPanics sets the panic policy of the registry, logging the panics recovered and counting them by the
+// PanicsMetric counter. The counter is created upfront and recorded to without the tag providers, since a panic may be
+// recovered while the meter collects, or from a tag provider.
+func (m *Meter) recoverPanics() {
+	counter, err := m.meter.Float64Counter(PanicsMetric,
+		api.WithDescription("panics recovered from the observable callbacks and the tag providers"))
+	if err != nil {
+		m.cfg.WriteErrorOrNot("create " + PanicsMetric + " counter failed: " + err.Error())
+	}
+	m.registry.SetPanicPolicy(m.cfg.GetPanicLimit(), func(kind, name string, recovered any, disabled bool) {
+		message := fmt.Sprintf("%s %s panicked: %v", kind, name, recovered)
+		if disabled {
+			message += ", disabled"
+		}
+		m.cfg.WriteErrorOrNot(message)
+		if counter != nil {
+			counter.Add(context.Background(), 1, api.WithAttributes(
+				attribute.String(TagKind, kind), attribute.String(TagName, name)))
+		}
+	})
+}
+
+// NewRegistry creates the registry of a meter, carrying the tag providers and the clock of the configuration
+// and logging the near-duplicate tag keys and metric names, and the bucket recommendations when tuning the buckets.
+// Dropped measurements are accounted to dropAuditor, which may be nil.
+func NewRegistry(cfg *config.Config, dropAuditor *registry.DropAuditor) *registry.Registry {
+	r := registry.NewRegistry(dropAuditor)
+	r.SetTagProviders(cfg.TagProviders)
+	r.EnableKeyCheck(cfg.WriteErrorOrNot)
+	r.EnableNameCheck(cfg.WriteErrorOrNot)
+	r.SetClock(cfg.GetClock())
+	if cfg.FeatureGate != nil {
+		r.SetFeatureGate(cfg.FeatureGate, config.InstrumentInfo{
+			BaseTags: cfg.BaseTags,
+			Instance: cfg.LocalIP,
+		})
+	}
+	if len(cfg.MetricBudgets) > 0 {
+		r.SetBudgets(registry.NewBudgets(cfg.MetricBudgets, cfg.StrictBudgets, cfg.WriteErrorOrNot))
+	}
+	r.TrackUsage(cfg.UnusedWindow)
+	r.LimitCardinality(cfg.GetCardinalityLimit)
+	if cfg.ValueReadback || len(cfg.DerivedMetrics) > 0 {
+		r.TrackValues()
+	}
+	if cfg.HistogramExtrema {
+		r.TrackExtrema()
+	}
+	if cfg.CreationAudit {
+		r.TrackOrigins()
+	}
+	if cfg.Dictionary != nil {
+		r.SetDictionary(cfg.Dictionary, cfg.WriteErrorOrNot)
+	}
+	r.SetRenames(cfg.Renames, cfg.RenameUntil)
+	if cfg.BucketTuningWindow > 0 {
+		r.TuneBuckets(cfg.BucketTuningWindow, func(recommendations []analyze.BucketRecommendation) {
+			for _, recommendation := range recommendations {
+				cfg.WriteInfoOrNot("histogram buckets of " + recommendation.String())
+			}
+		})
+	}
+	r.SetNameCollisionPolicy(cfg.NameCollisionPolicy)
+	r.GuardUnits(cfg.UnitCoercion, cfg.WriteErrorOrNot)
+	return r
+}
+
+// Config returns the configuration of the meter.
+func (m *Meter) Config() *config.Config {
+	return m.cfg
+}
+
+// Registry returns the registry of the meter.
+func (m *Meter) Registry() *registry.Registry {
+	return m.registry
+}
+
+// Clock returns the clock of the meter, see clock.From.
+func (m *Meter) Clock() clock.Clock {
+	return m.cfg.GetClock()
+}
+
+// SetRunning switches the meter on or off and reports whether its state changed.
+// A meter switched off creates no-op instruments.
+func (m *Meter) SetRunning(on bool) bool {
+	if on {
+		return atomic.CompareAndSwapInt32(&m.running, 0, 1)
+	}
+	return atomic.CompareAndSwapInt32(&m.running, 1, 0)
+}
+
+// isRunning checks if the meter is currently running.
+func (m *Meter) isRunning() bool {
+	return atomic.LoadInt32(&m.running) == 1
+}
+
+// Running reports whether the meter is switched on.
+func (m *Meter) Running() bool {
+	return m.isRunning()
+}
+
+// WithRunning switches the meter on or off.
+func (m *Meter) WithRunning(on bool) {
+	if !m.SetRunning(on) {
+		return
+	}
+	if on {
+		m.cfg.WriteInfoOrNot(m.name + " meter is started")
+	} else {
+		m.cfg.WriteInfoOrNot(m.name + " meter is stopped")
+	}
+}
+
+// GetHandler returns a handler answering 404 Not Found, the meter exposing no metrics itself.
+func (m *Meter) GetHandler() http.Handler {
+	return http.NotFoundHandler()
+}
+
+// Flush forces the meter provider to flush when it supports it, e.g. the provider of the OpenTelemetry SDK.
+func (m *Meter) Flush(ctx context.Context) error {
+	if f, ok := m.provider.(flusher); ok {
+		if err := f.ForceFlush(ctx); err != nil {
+			m.cfg.WriteErrorOrNot("failed to flush " + m.name + " meter: " + err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+// DisableMetric mutes the metric with the given name at runtime.
+// Instruments created before and after the call drop their measurements until EnableMetric is called with the same name.
+func (m *Meter) DisableMetric(metricName string) {
+	m.cfg.WriteInfoOrNot("disable metric: " + metricName)
+	m.registry.Disable(metricName)
+}
+
+// EnableMetric restores the metric with the given name previously muted by DisableMetric.
+func (m *Meter) EnableMetric(metricName string) {
+	m.cfg.WriteInfoOrNot("enable metric: " + metricName)
+	m.registry.Enable(metricName)
+}
+
+// DeprecateMetric marks the metric with the given name as deprecated in favor of replacement, which may be empty.
+// Its series are tagged deprecated="true" from then on, and the packages still creating its instruments are logged
+// once each and exposed by the DeprecatedUsesMetric gauge.
+func (m *Meter) DeprecateMetric(metricName, replacement string) {
+	message := "deprecate metric: " + metricName
+	if replacement != "" {
+		message += ", replaced by " + replacement
+	}
+	m.cfg.WriteInfoOrNot(message)
+	m.registry.Deprecate(metricName, replacement)
+}
+
+// Use appends interceptor to the interceptors of the measurements, given every measurement of the instruments of the
+// meter, those created before and those of the standard components included, with its final tags.
+func (m *Meter) Use(interceptor interfaces.Interceptor) {
+	m.registry.Use(interceptor)
+}
+
+// SetLogLevel changes the level of the SDK logging at runtime.
+func (m *Meter) SetLogLevel(level config.LogLevel) {
+	m.cfg.SetLogLevel(level)
+}
+
+// SetPushPeriod changes the period of the push gateway, the next push is scheduled with it.
+func (m *Meter) SetPushPeriod(period time.Duration) {
+	m.cfg.SetPushPeriod(period)
+}
+
+// SetCardinalityLimit changes the number of distinct tag sets allowed per metric, the measurements of the new series
+// beyond it are dropped.
+func (m *Meter) SetCardinalityLimit(limit int) {
+	m.cfg.SetCardinalityLimit(limit)
+}
+
+// NewCounter creates a new Counter metric with the specified name, description, and unit.
+// It returns a no-op counter if the meter is not running or the metric is gated off by the configured feature gate
+// or refused by the instrument budget of its module.
+// This method uses the underlying meter to create a Float64Counter and wraps it with a custom Counter implementation.
+// In case of failure creating the counter, a log message is emitted and a no-op counter is returned.
+// During the transition of a renamed metric, the counter records to both its old and new names.
+func (m *Meter) NewCounter(metricName, desc, unit string) interfaces.Counter {
+	name, alias := m.registry.Rename(metricName)
+	if alias == "" {
+		return m.newCounter(name, desc, unit)
+	}
+	return dual.NewCounter(m.newCounter(name, desc, unit), m.newCounter(alias, desc, unit))
+}
+
+// newCounter creates the counter of the metric with the given name, see NewCounter.
+func (m *Meter) newCounter(metricName, desc, unit string) interfaces.Counter {
+	if !m.isRunning() {
+		m.registry.Drop(registry.DropReasonNopFallback, metricName)
+		return nop.Counter
+	}
+	metricName, desc, unit, ok := m.resolve("counter", metricName, desc, unit)
+	if !ok || m.registry.Gated("counter", metricName, unit) || !m.registry.AdmitBudget(metricName) {
+		return nop.Counter
+	}
+	counter, err := m.meter.Float64Counter(
+		metricName,
+		api.WithDescription(desc),
+		api.WithUnit(unit),
+	)
+	if err != nil {
+		m.cfg.WriteDebugOrNot("failed to create " + m.name + " counter: " + err.Error())
+		m.registry.Drop(registry.DropReasonNopFallback, metricName)
+		return nop.Counter
+	}
+	m.registry.Created(metricName)
+	return prom.NewCounter(metricName, counter, m.registry)
+}
+
+// NewUpDownCounter creates a new UpDownCounter metric within the meter.
+// It requires a metric name, description, and unit of measure.
+// If the meter is not running, it returns a no-op UpDownCounter.
+// Otherwise, it initializes a new UpDownCounter with the provided parameters and adds it to the meter.
+// Returns a no-op UpDownCounter if the creation fails within the underlying meter.
+// During the transition of a renamed metric, the counter records to both its old and new names.
+func (m *Meter) NewUpDownCounter(metricName, desc, unit string) interfaces.UpDownCounter {
+	name, alias := m.registry.Rename(metricName)
+	if alias == "" {
+		return m.newUpDownCounter(name, desc, unit)
+	}
+	return dual.NewUpDownCounter(m.newUpDownCounter(name, desc, unit), m.newUpDownCounter(alias, desc, unit))
+}
+
+// newUpDownCounter creates the up/down counter of the metric with the given name, see NewUpDownCounter.
+func (m *Meter) newUpDownCounter(metricName, desc, unit string) interfaces.UpDownCounter {
+	if !m.isRunning() {
+		m.registry.Drop(registry.DropReasonNopFallback, metricName)
+		return nop.UpDownCounter
+	}
+	metricName, desc, unit, ok := m.resolve("updowncounter", metricName, desc, unit)
+	if !ok || m.registry.Gated("updowncounter", metricName, unit) || !m.registry.AdmitBudget(metricName) {
+		return nop.UpDownCounter
+	}
+	udCounter, err := m.meter.Float64UpDownCounter(metricName,
+		api.WithDescription(desc),
+		api.WithUnit(unit),
+	)
+	if err != nil {
+		m.cfg.WriteDebugOrNot("failed to create " + m.name + " upDownCounter: " + err.Error())
+		m.registry.Drop(registry.DropReasonNopFallback, metricName)
+		return nop.UpDownCounter
+	}
+	m.registry.Created(metricName)
+	return prom.NewUpDownCounter(metricName, udCounter, m.registry)
+}
+
+// NewGauge creates a new Gauge metric with the specified name, description, and unit within the meter.
+// Returns a no-op Gauge if the meter is not currently running.
+// It uses the provided metricName, description, and unit to configure the gauge via the underlying meter.
+// In case of an error during gauge creation, a log is emitted and a no-op Gauge is returned.
+// During the transition of a renamed metric, the gauge records to both its old and new names.
+func (m *Meter) NewGauge(metricName, desc, unit string) interfaces.Gauge {
+	name, alias := m.registry.Rename(metricName)
+	if alias == "" {
+		return m.newGauge(name, desc, unit)
+	}
+	return dual.NewGauge(m.newGauge(name, desc, unit), m.newGauge(alias, desc, unit))
+}
+
+// newGauge creates the gauge of the metric with the given name, see NewGauge.
+func (m *Meter) newGauge(metricName, desc, unit string) interfaces.Gauge {
+	if !m.isRunning() {
+		m.registry.Drop(registry.DropReasonNopFallback, metricName)
+		return nop.Gauge
+	}
+	metricName, desc, unit, ok := m.resolve("gauge", metricName, desc, unit)
+	if !ok || m.registry.Gated("gauge", metricName, unit) || !m.registry.AdmitBudget(metricName) {
+		return nop.Gauge
+	}
+	gauge, err := m.meter.Float64Gauge(metricName,
+		api.WithDescription(desc),
+		api.WithUnit(unit))
+	if err != nil {
+		m.cfg.WriteDebugOrNot("failed to create " + m.name + " gauge: " + err.Error())
+		m.registry.Drop(registry.DropReasonNopFallback, metricName)
+		return nop.Gauge
+	}
+	m.registry.Created(metricName)
+	return prom.NewGauge(metricName, gauge, m.registry)
+}
+
+// NewObservableGauge creates a new asynchronous Gauge metric whose values are reported by callback at every collection.
+// If the meter is not running or the creation fails, a no-op Registration is returned and callback is never called.
+// The returned Registration stops the callback once unregistered. During the transition of a renamed metric, the
+// gauge is registered under both its old and new names, and callback is called once for each at every collection.
+func (m *Meter) NewObservableGauge(metricName, desc, unit string, callback interfaces.ObservableCallback) interfaces.Registration {
+	name, alias := m.registry.Rename(metricName)
+	if alias == "" {
+		return m.registerObservableGauge(name, desc, unit, callback)
+	}
+	return dual.NewRegistration(m.registerObservableGauge(name, desc, unit, callback),
+		m.registerObservableGauge(alias, desc, unit, callback))
+}
+
+// registerObservableGauge creates the observable gauge of the metric with the given name, see NewObservableGauge.
+func (m *Meter) registerObservableGauge(metricName, desc, unit string, callback interfaces.ObservableCallback) interfaces.Registration {
+	if !m.isRunning() {
+		m.registry.Drop(registry.DropReasonNopFallback, metricName)
+		return nop.Registration
+	}
+	metricName, desc, unit, ok := m.resolve("observablegauge", metricName, desc, unit)
+	if !ok || m.registry.Gated("gauge", metricName, unit) || !m.registry.AdmitBudget(metricName) {
+		return nop.Registration
+	}
+	return m.newObservableGauge(metricName, desc, unit, callback)
+}
+
+// resolve appends the unit suffixes to the name when configured, describes the instrument from the metrics
+// dictionary if it has no description, applies the name collision policy of the registry to the creation of an
+// instrument of the given kind, and returns the name, description and unit to create it with.
+// ok is false when the instrument must be refused.
+func (m *Meter) resolve(kind, metricName, desc, unit string) (string, string, string, bool) {
+	if m.cfg.UnitSuffixes {
+		metricName = semconv.AppendUnitSuffix(metricName, unit, kind == "counter")
+	}
+	desc = m.registry.Enrich(metricName, desc)
+	name, id, ok := m.registry.Resolve(metricName, registry.Identity{Kind: kind, Unit: unit, Desc: desc})
+	return name, id.Desc, id.Unit, ok
+}
+
+// newObservableGauge creates an observable gauge and registers its callback, without consulting the feature gate and
+// the budgets, e.g. for the self-metrics of the meter.
+func (m *Meter) newObservableGauge(metricName, desc, unit string, callback interfaces.ObservableCallback) interfaces.Registration {
+	gauge, err := m.meter.Float64ObservableGauge(metricName,
+		api.WithDescription(desc),
+		api.WithUnit(unit))
+	if err != nil {
+		m.cfg.WriteDebugOrNot("failed to create " + m.name + " observable gauge: " + err.Error())
+		m.registry.Drop(registry.DropReasonNopFallback, metricName)
+		return nop.Registration
+	}
+	m.registry.Created(metricName)
+	var registration api.Registration
+	if m.scheduler != nil {
+		registration, err = m.scheduler.add(metricName, gauge, prom.NewObservableCallback(metricName, gauge, callback, m.registry))
+	} else {
+		registration, err = m.meter.RegisterCallback(prom.NewObservableCallback(metricName, gauge, callback, m.registry), gauge)
+	}
+	if err != nil {
+		m.cfg.WriteErrorOrNot("failed to register " + m.name + " observable gauge callback: " + err.Error())
+		return nop.Registration
+	}
+	return registration
+}
+
+// NewHistogram creates a new Histogram metric with the specified name, description, and unit within the meter.
+// If the meter is not running, it returns a no-op Histogram.
+// The method configures the histogram using the underlying meter with the configured explicit bucket boundaries,
+// those of the histogram view of the provider, if any, when none is configured.
+// In case of an error during histogram creation, a log message is emitted, and a no-op Histogram is returned.
+func (m *Meter) NewHistogram(metricName, desc, unit string) interfaces.Histogram {
+	return m.newHistogram(metricName, desc, unit, m.cfg.HistogramBoundaries)
+}
+
+// NewHistogramWithBuckets creates a new Histogram metric using the given bucket boundaries instead of the configured ones,
+// e.g. one of the presets config.BucketsHTTPServer, config.BucketsDB or config.BucketsCacheFast.
+// Empty buckets fall back to the configured boundaries.
+func (m *Meter) NewHistogramWithBuckets(metricName, desc, unit string, buckets []float64) interfaces.Histogram {
+	if len(buckets) == 0 {
+		buckets = m.cfg.HistogramBoundaries
+	}
+	return m.newHistogram(metricName, desc, unit, buckets)
+}
+
+// NewSizeHistogram creates a new Histogram metric in bytes with the specified name and description,
+// using config.DefaultSizeBoundaries as bucket boundaries. Values are recorded with Histogram.Record.
+func (m *Meter) NewSizeHistogram(metricName, desc string) interfaces.Histogram {
+	return m.newHistogram(metricName, desc, config.UnitBytes, config.DefaultSizeBoundaries)
+}
+
+// NewCountHistogram creates a new Histogram metric of counts with the specified name and description,
+// using the powers of two of config.DefaultCountBoundaries as bucket boundaries. Values are recorded with Histogram.Record.
+func (m *Meter) NewCountHistogram(metricName, desc string) interfaces.Histogram {
+	return m.newHistogram(metricName, desc, config.UnitCount, config.DefaultCountBoundaries)
+}
+
+// newHistogram creates a new Histogram metric with the given explicit bucket boundaries.
+// If the meter is not running, the metric is gated off by the configured feature gate or refused by the instrument
+// budget of its module, or the histogram creation fails, a no-op Histogram is returned.
+// When the registry tracks the extrema of the histograms, their gauges are registered on the first creation.
+// When the registry tunes the buckets, the distribution of the values of the histogram is recorded. The unit of the
+// histograms in seconds is guarded against values in milliseconds. During the transition of a renamed metric, the
+// histogram records to both its old and new names.
+func (m *Meter) newHistogram(metricName, desc, unit string, boundaries []float64) interfaces.Histogram {
+	name, alias := m.registry.Rename(metricName)
+	if alias == "" {
+		return m.createHistogram(name, desc, unit, boundaries)
+	}
+	return dual.NewHistogram(m.createHistogram(name, desc, unit, boundaries),
+		m.createHistogram(alias, desc, unit, boundaries))
+}
+
+// createHistogram creates the histogram of the metric with the given name, see newHistogram.
+func (m *Meter) createHistogram(metricName, desc, unit string, boundaries []float64) interfaces.Histogram {
+	if !m.isRunning() {
+		m.registry.Drop(registry.DropReasonNopFallback, metricName)
+		return nop.Histogram
+	}
+	metricName, desc, unit, ok := m.resolve("histogram", metricName, desc, unit)
+	if !ok || m.registry.Gated("histogram", metricName, unit) || !m.registry.AdmitBudget(metricName) {
+		return nop.Histogram
+	}
+	if len(boundaries) == 0 {
+		m.registry.MarkUnbounded(metricName)
+	}
+	histogram, err := m.meter.Float64Histogram(metricName,
+		api.WithDescription(desc),
+		api.WithUnit(unit),
+		api.WithExplicitBucketBoundaries(boundaries...))
+	if err != nil {
+		m.cfg.WriteDebugOrNot("failed to create " + m.name + " histogram: " + err.Error())
+		m.registry.Drop(registry.DropReasonNopFallback, metricName)
+		return nop.Histogram
+	}
+	m.registry.Created(metricName)
+	if m.registry.TracksExtrema() {
+		m.registerExtrema(metricName, unit)
+	}
+	m.registry.WatchBuckets(metricName, boundaries)
+	m.registry.WatchUnit(metricName, unit, boundaries)
+	return prom.NewHistogram(metricName, histogram, m.registry)
+}
+
+// Components returns the standard components recording to the meter.
+func (m *Meter) Components() interfaces.Components {
+	return component.NewComponents(func() interfaces.BaseMeter {
+		return m
+	})
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"sync"
+	"time"
+)
+
+// PushReader is the reader of the meters pushing their metrics, exporting them every interval as a periodic reader
+// does, but only once started: a meter whose start is deferred runs no export goroutine and pushes nothing before.
+// ForceFlush exports the metrics right away, started or not.
+type PushReader struct {
+	*metric.ManualReader
+	cfg      *config.Config
+	name     string
+	exporter metric.Exporter
+	interval time.Duration
+	timeout  time.Duration
+
+	mu      sync.Mutex
+	started bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewPushReader creates the reader exporting the metrics of the meter name with exporter every interval, each export
+// bounded by timeout. The failed exports are logged through cfg.
+func NewPushReader(cfg *config.Config, name string, exporter metric.Exporter, interval, timeout time.Duration) *PushReader {
+	return &PushReader{
+		ManualReader: metric.NewManualReader(
+			metric.WithTemporalitySelector(exporter.Temporality),
+			metric.WithAggregationSelector(exporter.Aggregation)),
+		cfg:      cfg,
+		name:     name,
+		exporter: exporter,
+		interval: interval,
+		timeout:  timeout,
+	}
+}
+
+// Start starts the goroutine exporting the metrics every interval, it does nothing if it is running.
+func (r *PushReader) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		return
+	}
+	r.started = true
+	r.stop, r.done = make(chan struct{}), make(chan struct{})
+	go r.loop(r.stop, r.done)
+}
+
+// Stop stops the export goroutine and waits for it to return, it does nothing if it is not running.
+func (r *PushReader) Stop() {
+	r.mu.Lock()
+	stop, done := r.stop, r.done
+	r.stop, r.done = nil, nil
+	r.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// loop exports the metrics every interval until stop is closed, then closes done.
+func (r *PushReader) loop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.export(context.Background()); err != nil {
+				r.cfg.WriteErrorOrNot("failed to push " + r.name + " metrics: " + err.Error())
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// export collects the metrics and exports them within the timeout.
+func (r *PushReader) export(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	var rm metricdata.ResourceMetrics
+	if err := r.Collect(ctx, &rm); err != nil {
+		return err
+	}
+	return r.exporter.Export(ctx, &rm)
+}
+
+// ForceFlush exports the metrics collected now and flushes the exporter.
+func (r *PushReader) ForceFlush(ctx context.Context) error {
+	if err := r.export(ctx); err != nil {
+		return err
+	}
+	return r.exporter.ForceFlush(ctx)
+}
+
+// Shutdown stops the exports, exports the metrics a last time if the reader was ever started, and shuts the exporter
+// down.
+func (r *PushReader) Shutdown(ctx context.Context) error {
+	r.Stop()
+	r.mu.Lock()
+	started := r.started
+	r.mu.Unlock()
+	var err error
+	if started {
+		err = r.export(ctx)
+	}
+	return errors.Join(err, r.ManualReader.Shutdown(ctx), r.exporter.Shutdown(ctx))
+}
@@ -0,0 +1,211 @@
+package core
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/internal/registry"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	api "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/embedded"
+	"sync"
+	"sync/atomic"
+)
+
+// scheduledCallback is the callback of an observable gauge run by the scheduler.
+type scheduledCallback struct {
+	name     string
+	gauge    api.Float64Observable
+	callback api.Callback
+	running  int32
+}
+
+// callbackScheduler runs the callbacks of the observable gauges on a bounded pool of workers at every collection,
+// each within the configured timeout, so that a slow callback does not delay the whole collection. The values a
+// callback observes are buffered and reported once it returned, those of the callbacks not done in time are dropped,
+// and a callback still running from a previous collection is skipped.
+// The callbacks are guarded by their own lock, never held while registering on the meter, which collects holding its
+// own lock.
+type callbackScheduler struct {
+	cfg          *config.Config
+	meter        api.Meter
+	registry     *registry.Registry
+	mu           sync.Mutex
+	registration api.Registration
+	callbacksMu  sync.RWMutex
+	callbacks    map[*scheduledCallback]struct{}
+}
+
+// newCallbackScheduler creates a scheduler registering its callbacks on meter.
+func newCallbackScheduler(cfg *config.Config, meter api.Meter, r *registry.Registry) *callbackScheduler {
+	return &callbackScheduler{
+		cfg:       cfg,
+		meter:     meter,
+		registry:  r,
+		callbacks: make(map[*scheduledCallback]struct{}),
+	}
+}
+
+// add schedules the callback of the gauge, and returns the registration unscheduling it.
+func (s *callbackScheduler) add(name string, gauge api.Float64Observable, callback api.Callback) (api.Registration, error) {
+	c := &scheduledCallback{
+		name:     name,
+		gauge:    gauge,
+		callback: callback,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callbacksMu.Lock()
+	s.callbacks[c] = struct{}{}
+	s.callbacksMu.Unlock()
+	if err := s.register(); err != nil {
+		s.callbacksMu.Lock()
+		delete(s.callbacks, c)
+		s.callbacksMu.Unlock()
+		return nil, err
+	}
+	return &scheduledRegistration{scheduler: s, callback: c}, nil
+}
+
+// remove unschedules the callback.
+func (s *callbackScheduler) remove(c *scheduledCallback) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callbacksMu.Lock()
+	_, ok := s.callbacks[c]
+	delete(s.callbacks, c)
+	s.callbacksMu.Unlock()
+	if !ok {
+		return nil
+	}
+	return s.register()
+}
+
+// register replaces the registration of the scheduler by one observing the gauges of the callbacks scheduled, the
+// meter requiring the instruments observed by a callback to be declared when it is registered. The new registration
+// is made before the previous one is removed, so that no collection misses the callbacks.
+func (s *callbackScheduler) register() error {
+	s.callbacksMu.RLock()
+	gauges := make([]api.Observable, 0, len(s.callbacks))
+	for c := range s.callbacks {
+		gauges = append(gauges, c.gauge)
+	}
+	s.callbacksMu.RUnlock()
+	var registration api.Registration
+	if len(gauges) > 0 {
+		var err error
+		if registration, err = s.meter.RegisterCallback(s.collect, gauges...); err != nil {
+			return err
+		}
+	}
+	if s.registration != nil {
+		if err := s.registration.Unregister(); err != nil {
+			return err
+		}
+	}
+	s.registration = registration
+	return nil
+}
+
+// collect runs the scheduled callbacks on the workers and reports the values observed by those done in time.
+func (s *callbackScheduler) collect(ctx context.Context, o api.Observer) error {
+	s.callbacksMu.RLock()
+	callbacks := make([]*scheduledCallback, 0, len(s.callbacks))
+	for c := range s.callbacks {
+		callbacks = append(callbacks, c)
+	}
+	s.callbacksMu.RUnlock()
+
+	workers := make(chan struct{}, s.cfg.CallbackWorkers)
+	results := make([]*bufferedObserver, len(callbacks))
+	var wg sync.WaitGroup
+	for i, c := range callbacks {
+		if !atomic.CompareAndSwapInt32(&c.running, 0, 1) {
+			s.cfg.WriteErrorOrNot("observable gauge " + c.name + " callback is still running, skip it")
+			s.registry.Drop(registry.DropReasonCallbackTimeout, c.name)
+			continue
+		}
+		workers <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.run(ctx, c)
+			<-workers
+		}()
+	}
+	wg.Wait()
+
+	for _, buffer := range results {
+		if buffer != nil {
+			buffer.replay(o)
+		}
+	}
+	return nil
+}
+
+// run runs the callback within the timeout, and returns the values it observed, even if it failed, nil if it was not
+// done in time. The callback keeps running in the background after the timeout, it is skipped until it returns.
+func (s *callbackScheduler) run(ctx context.Context, c *scheduledCallback) *bufferedObserver {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.GetCallbackTimeout())
+	defer cancel()
+	buffer := &bufferedObserver{}
+	doneCh := make(chan error, 1)
+	go func() {
+		defer atomic.StoreInt32(&c.running, 0)
+		doneCh <- c.callback(ctx, buffer)
+	}()
+	select {
+	case err := <-doneCh:
+		if err != nil {
+			s.cfg.WriteErrorOrNot("observable gauge " + c.name + " callback failed: " + err.Error())
+		}
+		return buffer
+	case <-ctx.Done():
+		s.cfg.WriteErrorOrNot("observable gauge " + c.name + " callback is not done within " + s.cfg.GetCallbackTimeout().String())
+		s.registry.Drop(registry.DropReasonCallbackTimeout, c.name)
+		return nil
+	}
+}
+
+// scheduledRegistration unschedules a callback when unregistered.
+type scheduledRegistration struct {
+	embedded.Registration
+	scheduler *callbackScheduler
+	callback  *scheduledCallback
+}
+
+// Unregister unschedules the callback.
+func (r *scheduledRegistration) Unregister() error {
+	return r.scheduler.remove(r.callback)
+}
+
+// observation is a value observed by a callback.
+type observation struct {
+	gauge   api.Float64Observable
+	value   float64
+	options []api.ObserveOption
+}
+
+// bufferedObserver buffers the values observed by a callback to be reported once it returned.
+type bufferedObserver struct {
+	embedded.Observer
+	mu           sync.Mutex
+	observations []observation
+}
+
+// ObserveFloat64 buffers the value.
+func (b *bufferedObserver) ObserveFloat64(obsrv api.Float64Observable, value float64, opts ...api.ObserveOption) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.observations = append(b.observations, observation{gauge: obsrv, value: value, options: opts})
+}
+
+// ObserveInt64 is not used by the observable gauges of the SDK, which are float gauges.
+func (b *bufferedObserver) ObserveInt64(api.Int64Observable, int64, ...api.ObserveOption) {}
+
+// replay reports the buffered values to o.
+func (b *bufferedObserver) replay(o api.Observer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, obs := range b.observations {
+		o.ObserveFloat64(obs.gauge, obs.value, obs.options...)
+	}
+}
@@ -0,0 +1,242 @@
+package graphite
+
+import (
+	"bytes"
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// _ ensures that *exporter implements the metric.Exporter interface.
+var _ metric.Exporter = (*exporter)(nil)
+
+// exporter writes the metrics collected by the periodic reader as Graphite plaintext lines, path value timestamp, to
+// a carbon TCP connection, established on the first export and re-established once when a write fails.
+type exporter struct {
+	address   string
+	prefix    string
+	format    config.GraphiteTagFormat
+	timeout   time.Duration
+	constTags []attribute.KeyValue
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// newExporter creates the exporter configured by cfg.Graphite, the defaults if nil, writing the base tags, and the
+// instance tags if they go to the resource, with every series.
+func newExporter(cfg *config.Config) *exporter {
+	e := &exporter{
+		address:   cfg.Graphite.GetAddress(),
+		format:    cfg.Graphite.GetTagFormat(),
+		timeout:   cfg.Graphite.GetTimeout(),
+		constTags: cfg.WithBaseTags(),
+	}
+	if cfg.Graphite != nil && cfg.Graphite.Prefix != "" {
+		e.prefix = sanitize(cfg.Graphite.Prefix, true) + "."
+	}
+	if cfg.InstanceTagsInResource() {
+		for key, value := range cfg.InstanceTags() {
+			e.constTags = append(e.constTags, attribute.String(key, value))
+		}
+	}
+	return e
+}
+
+// Temporality returns the cumulative temporality: the counters are written as totals, whose rate is computed by
+// Graphite, e.g. with nonNegativeDerivative.
+func (e *exporter) Temporality(kind metric.InstrumentKind) metricdata.Temporality {
+	return metric.DefaultTemporalitySelector(kind)
+}
+
+// Aggregation returns the default aggregation of the instrument kind.
+func (e *exporter) Aggregation(kind metric.InstrumentKind) metric.Aggregation {
+	return metric.DefaultAggregationSelector(kind)
+}
+
+// Export writes the lines of the data points of rm to carbon.
+func (e *exporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	var b bytes.Buffer
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			e.appendMetric(&b, m)
+		}
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	return e.send(ctx, b.Bytes())
+}
+
+// appendMetric writes the lines of the data points of m: one per series for the sums and gauges, and the count, sum,
+// min and max of the series of the histograms, under the name suffixed with .count, .sum, .min and .max.
+func (e *exporter) appendMetric(b *bytes.Buffer, m metricdata.Metrics) {
+	switch data := m.Data.(type) {
+	case metricdata.Sum[float64]:
+		appendPoints(e, b, m.Name, data.DataPoints)
+	case metricdata.Sum[int64]:
+		appendPoints(e, b, m.Name, data.DataPoints)
+	case metricdata.Gauge[float64]:
+		appendPoints(e, b, m.Name, data.DataPoints)
+	case metricdata.Gauge[int64]:
+		appendPoints(e, b, m.Name, data.DataPoints)
+	case metricdata.Histogram[float64]:
+		appendHistogram(e, b, m.Name, data.DataPoints)
+	case metricdata.Histogram[int64]:
+		appendHistogram(e, b, m.Name, data.DataPoints)
+	}
+}
+
+// appendPoints writes a line per data point.
+func appendPoints[N int64 | float64](e *exporter, b *bytes.Buffer, name string, points []metricdata.DataPoint[N]) {
+	for _, p := range points {
+		e.appendLine(b, name, p.Attributes, float64(p.Value), p.Time)
+	}
+}
+
+// appendHistogram writes the count, sum, min and max lines of every data point.
+func appendHistogram[N int64 | float64](e *exporter, b *bytes.Buffer, name string, points []metricdata.HistogramDataPoint[N]) {
+	for _, p := range points {
+		e.appendLine(b, name+".count", p.Attributes, float64(p.Count), p.Time)
+		e.appendLine(b, name+".sum", p.Attributes, float64(p.Sum), p.Time)
+		if v, ok := p.Min.Value(); ok {
+			e.appendLine(b, name+".min", p.Attributes, float64(v), p.Time)
+		}
+		if v, ok := p.Max.Value(); ok {
+			e.appendLine(b, name+".max", p.Attributes, float64(v), p.Time)
+		}
+	}
+}
+
+// appendLine writes the plaintext line of the value of the series of the metric with the given attributes at t. The
+// values which are not numbers are skipped, carbon refusing them.
+func (e *exporter) appendLine(b *bytes.Buffer, name string, attrs attribute.Set, value float64, t time.Time) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return
+	}
+	b.WriteString(e.path(name, attrs))
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatInt(t.Unix(), 10))
+	b.WriteByte('\n')
+}
+
+// path returns the metric path of the series, the prefix and the name followed by the constant tags and attrs sorted
+// by key, as key.value segments or in the tagged format.
+func (e *exporter) path(name string, attrs attribute.Set) string {
+	tags := append(e.constTags[:len(e.constTags):len(e.constTags)], attrs.ToSlice()...)
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].Key < tags[j].Key
+	})
+	var b strings.Builder
+	b.WriteString(e.prefix)
+	b.WriteString(sanitize(name, true))
+	for _, kv := range tags {
+		value := kv.Value.Emit()
+		if e.format == config.GraphiteTagsTagged {
+			if value == "" {
+				continue
+			}
+			b.WriteByte(';')
+			b.WriteString(sanitizeTag(string(kv.Key)))
+			b.WriteByte('=')
+			b.WriteString(sanitizeTag(value))
+			continue
+		}
+		b.WriteByte('.')
+		b.WriteString(sanitize(string(kv.Key), false))
+		b.WriteByte('.')
+		b.WriteString(sanitize(value, false))
+	}
+	return b.String()
+}
+
+// send writes data to carbon within the timeout, or the deadline of ctx if sooner, dialing if not connected. A failed
+// write closes the connection, and is retried once on a new one, carbon closing the idle connections.
+func (e *exporter) send(ctx context.Context, data []byte) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	deadline := time.Now().Add(e.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	for attempt := 0; ; attempt++ {
+		if e.conn == nil {
+			dialer := net.Dialer{Deadline: deadline}
+			conn, err := dialer.DialContext(ctx, "tcp", e.address)
+			if err != nil {
+				return err
+			}
+			e.conn = conn
+		}
+		_ = e.conn.SetWriteDeadline(deadline)
+		_, err := e.conn.Write(data)
+		if err == nil {
+			return nil
+		}
+		_ = e.conn.Close()
+		e.conn = nil
+		if attempt > 0 {
+			return err
+		}
+	}
+}
+
+// ForceFlush does nothing, the lines are written by Export.
+func (e *exporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+// Shutdown closes the connection to carbon.
+func (e *exporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// sanitize replaces the characters other than letters, digits, underscores, dashes and colons by underscores, and the
+// dots too unless dots is set, so that a tag makes a single path segment. An empty segment is written as an
+// underscore.
+func sanitize(s string, dots bool) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == ':':
+			return r
+		case r == '.' && dots:
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// sanitizeTag replaces the characters refused in the Graphite tags, the separators, the spaces and the line breaks,
+// by underscores.
+func sanitizeTag(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ';', '=', '!', '^', '~', ' ', '\t', '\n', '\r':
+			return '_'
+		default:
+			return r
+		}
+	}, s)
+}
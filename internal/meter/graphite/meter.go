@@ -0,0 +1,54 @@
+// Package graphite implements the meter pushing the metrics to Graphite as plaintext lines over TCP.
+package graphite
+
+import (
+	"github.com/liangweijiang/go-metric/internal/meter/core"
+	"github.com/liangweijiang/go-metric/internal/meter/prom"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+// _ is a blank identifier used for type assertion to ensure that *GraphiteMeter implements the interfaces.Shutdowner interface.
+var _ interfaces.Shutdowner = (*GraphiteMeter)(nil)
+
+// graphiteMeterName is the name of the meter creating the instruments exported to Graphite.
+const graphiteMeterName = "go-metrics/graphite-meter"
+
+// GraphiteMeter embeds the core meter creating the instruments on a meter provider whose push reader pushes the
+// metrics to carbon. It exposes no endpoint, and runs the runtime and process collectors and the drop auditor while
+// switched on.
+type GraphiteMeter struct {
+	*core.PushMeter
+}
+
+// NewGraphiteMeter creates the meter pushing the metrics to the carbon daemon configured by cfg.Graphite, the default
+// address if nil, every configured interval, and next to the configured readers. The connection to carbon is
+// established on the first push, carbon down at startup does not fail the creation.
+func NewGraphiteMeter(cfg *config.Config) (*GraphiteMeter, error) {
+	resourceAttrs := cfg.WithBaseTags()
+	if cfg.InstanceTagsInResource() {
+		for key, value := range cfg.InstanceTags() {
+			resourceAttrs = append(resourceAttrs, attribute.String(key, value))
+		}
+	}
+	resource, err := prom.ResourceWithAttr(cfg, resourceAttrs)
+	if err != nil {
+		cfg.WriteErrorOrNot("failed to create resource: " + err.Error())
+		return nil, err
+	}
+	reader := core.NewPushReader(cfg, config.MeterProviderTypeGraphite.String(), newExporter(cfg), cfg.Graphite.GetInterval(), cfg.Graphite.GetTimeout())
+	providerOpts := []metric.Option{
+		metric.WithResource(resource),
+		metric.WithReader(reader),
+	}
+	for _, reader := range cfg.Readers {
+		providerOpts = append(providerOpts, metric.WithReader(reader))
+	}
+	provider := metric.NewMeterProvider(providerOpts...)
+
+	return &GraphiteMeter{
+		PushMeter: core.NewPushMeter(cfg, config.MeterProviderTypeGraphite.String(), provider, graphiteMeterName, reader),
+	}, nil
+}
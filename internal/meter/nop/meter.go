@@ -1,7 +1,10 @@
 package nop
 
 import (
+	"context"
+	"github.com/liangweijiang/go-metric/internal/component"
 	"github.com/liangweijiang/go-metric/internal/metrics/nop"
+	"github.com/liangweijiang/go-metric/pkg/config"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
 	"net/http"
 )
@@ -22,6 +25,30 @@ func (n *Meter) WithRunning(_ bool) {
 
 }
 
+func (n *Meter) DisableMetric(_ string) {
+
+}
+
+func (n *Meter) EnableMetric(_ string) {
+
+}
+
+func (n *Meter) DeprecateMetric(_, _ string) {
+
+}
+
+func (n *Meter) Use(_ interfaces.Interceptor) {
+
+}
+
+func (n *Meter) SetLogLevel(_ config.LogLevel) {
+
+}
+
+func (n *Meter) Flush(_ context.Context) error {
+	return nil
+}
+
 func (n *Meter) NewCounter(_, _, _ string) interfaces.Counter {
 	return nop.Counter
 }
@@ -37,3 +64,25 @@ func (n *Meter) NewGauge(_, _, _ string) interfaces.Gauge {
 func (n *Meter) NewHistogram(_, _, _ string) interfaces.Histogram {
 	return nop.Histogram
 }
+
+func (n *Meter) NewHistogramWithBuckets(_, _, _ string, _ []float64) interfaces.Histogram {
+	return nop.Histogram
+}
+
+func (n *Meter) NewSizeHistogram(_, _ string) interfaces.Histogram {
+	return nop.Histogram
+}
+
+func (n *Meter) NewCountHistogram(_, _ string) interfaces.Histogram {
+	return nop.Histogram
+}
+
+func (n *Meter) NewObservableGauge(_, _, _ string, _ interfaces.ObservableCallback) interfaces.Registration {
+	return nop.Registration
+}
+
+func (n *Meter) Components() interfaces.Components {
+	return component.NewComponents(func() interfaces.BaseMeter {
+		return n
+	})
+}
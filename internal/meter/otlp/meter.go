@@ -0,0 +1,111 @@
+// Package otlp implements the meter pushing the metrics to an OpenTelemetry Collector with the OTLP gRPC exporter.
+package otlp
+
+import (
+	"fmt"
+	"github.com/liangweijiang/go-metric/internal/meter/core"
+	"github.com/liangweijiang/go-metric/internal/meter/prom"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/grpc/credentials"
+	"math"
+	"time"
+)
+
+// _ is a blank identifier used for type assertion to ensure that *OTLPMeter implements the interfaces.Shutdowner interface.
+var _ interfaces.Shutdowner = (*OTLPMeter)(nil)
+
+// otlpMeterName is the name of the meter creating the instruments exported with OTLP.
+const otlpMeterName = "go-metrics/otlp-meter"
+
+// OTLPMeter embeds the core meter creating the instruments on a meter provider whose push reader pushes the
+// metrics with the OTLP gRPC exporter. It exposes no endpoint, and runs the runtime and process collectors and the
+// drop auditor while switched on.
+type OTLPMeter struct {
+	*core.PushMeter
+}
+
+// NewOTLPMeter creates the meter pushing the metrics to the collector configured by cfg.OTLP, the default endpoint
+// if nil, every configured interval, and next to the configured readers. The connection to the collector is
+// established lazily, a collector down at startup does not fail the creation. The gzip export compression and the
+// export retry policy are applied, the configuration validation refusing the other export settings.
+func NewOTLPMeter(cfg *config.Config) (*OTLPMeter, error) {
+	resourceAttrs := cfg.WithBaseTags()
+	if cfg.InstanceTagsInResource() {
+		for key, value := range cfg.InstanceTags() {
+			resourceAttrs = append(resourceAttrs, attribute.String(key, value))
+		}
+	}
+	resource, err := prom.ResourceWithAttr(cfg, resourceAttrs)
+	if err != nil {
+		cfg.WriteErrorOrNot("failed to create resource: " + err.Error())
+		return nil, err
+	}
+	// the exporter is created last, nothing would close it if the creation of the meter failed after it.
+	exporter, err := otlpmetricgrpc.New(cfg.GetContext(), exporterOptions(cfg)...)
+	if err != nil {
+		cfg.WriteErrorOrNot("failed to create otlp exporter: " + err.Error())
+		return nil, fmt.Errorf("%w: %v", config.ErrExporterInit, err)
+	}
+	reader := core.NewPushReader(cfg, config.MeterProviderTypeOTLPGrpc.String(), exporter, cfg.OTLP.GetInterval(), cfg.OTLP.GetTimeout())
+	providerOpts := []metric.Option{
+		metric.WithResource(resource),
+		metric.WithReader(reader),
+	}
+	for _, reader := range cfg.Readers {
+		providerOpts = append(providerOpts, metric.WithReader(reader))
+	}
+	provider := metric.NewMeterProvider(providerOpts...)
+
+	return &OTLPMeter{
+		PushMeter: core.NewPushMeter(cfg, config.MeterProviderTypeOTLPGrpc.String(), provider, otlpMeterName, reader),
+	}, nil
+}
+
+// exporterOptions returns the options of the OTLP exporter configured by cfg.
+func exporterOptions(cfg *config.Config) []otlpmetricgrpc.Option {
+	o := cfg.OTLP
+	if o == nil {
+		o = &config.OTLPCfg{}
+	}
+	opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithTimeout(o.GetTimeout())}
+	if o.Endpoint != "" {
+		opts = append(opts, otlpmetricgrpc.WithEndpoint(o.Endpoint))
+	}
+	if o.Insecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	} else if o.TLS != nil {
+		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(o.TLS)))
+	}
+	if len(o.Headers) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(o.Headers))
+	}
+	if cfg.Export != nil {
+		if cfg.Export.Compression == config.CompressionGzip {
+			opts = append(opts, otlpmetricgrpc.WithCompressor(string(config.CompressionGzip)))
+		}
+		if cfg.Export.Retry != (config.RetryPolicy{}) {
+			opts = append(opts, otlpmetricgrpc.WithRetry(retryConfig(cfg.Export)))
+		}
+	}
+	return opts
+}
+
+// retryConfig returns the retry policy of the exports as the retry configuration of the exporter, which bounds the
+// retries by time: the total of the backoffs before the last attempt.
+func retryConfig(e *config.ExportCfg) otlpmetricgrpc.RetryConfig {
+	attempts := e.GetMaxAttempts()
+	var elapsed time.Duration
+	for retry := 1; retry < attempts; retry++ {
+		elapsed += e.Backoff(retry)
+	}
+	return otlpmetricgrpc.RetryConfig{
+		Enabled:         attempts > 1,
+		InitialInterval: e.Backoff(1),
+		MaxInterval:     e.Backoff(math.MaxInt),
+		MaxElapsedTime:  elapsed,
+	}
+}
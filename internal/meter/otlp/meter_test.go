@@ -0,0 +1,42 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+)
+
+func TestRetryConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   config.RetryPolicy
+		expected otlpmetricgrpc.RetryConfig
+	}{
+		{
+			name:   "SingleAttempt",
+			policy: config.RetryPolicy{MaxAttempts: 1, InitialBackoff: time.Second},
+			expected: otlpmetricgrpc.RetryConfig{
+				InitialInterval: time.Second,
+				MaxInterval:     config.DefaultRetryMaxBackoff,
+			},
+		},
+		{
+			name:   "Attempts",
+			policy: config.RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second},
+			expected: otlpmetricgrpc.RetryConfig{
+				Enabled:         true,
+				InitialInterval: time.Second,
+				MaxInterval:     3 * time.Second,
+				MaxElapsedTime:  6 * time.Second,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, retryConfig(&config.ExportCfg{Retry: tt.policy}))
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package prom
+
+import (
+	cliprom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"sort"
+	"strings"
+)
+
+// aggregateGatherer removes labels from the series gathered and sums the series left with the same labels, e.g. to
+// pre-aggregate the series pushed to a gateway. The counters, gauges and untyped metrics are summed, the classic
+// histograms merged bucket by bucket. The summaries and the native histograms cannot be merged, their families are
+// gathered unchanged.
+type aggregateGatherer struct {
+	cliprom.Gatherer
+	labels map[string]struct{}
+}
+
+// newAggregateGatherer wraps g, removing the labels and summing the series left identical.
+func newAggregateGatherer(g cliprom.Gatherer, labels []string) *aggregateGatherer {
+	a := &aggregateGatherer{
+		Gatherer: g,
+		labels:   make(map[string]struct{}, len(labels)),
+	}
+	for _, label := range labels {
+		a.labels[label] = struct{}{}
+	}
+	return a
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *aggregateGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	for _, mf := range mfs {
+		if mergeable(mf) {
+			mf.Metric = g.aggregate(mf)
+		}
+	}
+	return mfs, err
+}
+
+// mergeable reports whether the series of the family can be summed.
+func mergeable(mf *dto.MetricFamily) bool {
+	switch mf.GetType() {
+	case dto.MetricType_COUNTER, dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+		return true
+	case dto.MetricType_HISTOGRAM:
+		for _, m := range mf.Metric {
+			if h := m.GetHistogram(); h.Schema != nil || len(h.PositiveSpan) > 0 || len(h.NegativeSpan) > 0 {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// aggregate returns the series of the family without the labels, the series left identical being summed, in the
+// order of their first occurrence.
+func (g *aggregateGatherer) aggregate(mf *dto.MetricFamily) []*dto.Metric {
+	merged := make(map[string]*dto.Metric, len(mf.Metric))
+	var series []*dto.Metric
+	for _, m := range mf.Metric {
+		labels := make([]*dto.LabelPair, 0, len(m.Label))
+		var key strings.Builder
+		for _, l := range m.Label {
+			if _, drop := g.labels[l.GetName()]; drop {
+				continue
+			}
+			labels = append(labels, l)
+			key.WriteString(l.GetName() + "\xff" + l.GetValue() + "\xff")
+		}
+		if sum, ok := merged[key.String()]; ok {
+			mergeMetric(sum, m)
+			continue
+		}
+		m.Label = labels
+		// the samples summed from several series have no single timestamp, nor exemplar.
+		m.TimestampMs = nil
+		if m.Counter != nil {
+			m.Counter.Exemplar = nil
+		}
+		if m.Histogram != nil {
+			m.Histogram.Exemplars = nil
+			for _, b := range m.Histogram.Bucket {
+				b.Exemplar = nil
+			}
+		}
+		merged[key.String()] = m
+		series = append(series, m)
+	}
+	return series
+}
+
+// mergeMetric adds the value of m to sum.
+func mergeMetric(sum, m *dto.Metric) {
+	switch {
+	case sum.Counter != nil:
+		v := sum.Counter.GetValue() + m.Counter.GetValue()
+		sum.Counter.Value = &v
+		// the series summed began at the earliest one.
+		if c := m.Counter.CreatedTimestamp; c != nil && sum.Counter.CreatedTimestamp != nil && c.AsTime().Before(sum.Counter.CreatedTimestamp.AsTime()) {
+			sum.Counter.CreatedTimestamp = c
+		}
+	case sum.Gauge != nil:
+		v := sum.Gauge.GetValue() + m.Gauge.GetValue()
+		sum.Gauge.Value = &v
+	case sum.Untyped != nil:
+		v := sum.Untyped.GetValue() + m.Untyped.GetValue()
+		sum.Untyped.Value = &v
+	case sum.Histogram != nil:
+		mergeHistogram(sum.Histogram, m.Histogram)
+	}
+}
+
+// mergeHistogram adds the observations of h to sum. The buckets are merged by upper bound: a histogram without a
+// bound of the other counts the observations of its largest bound below it, which are less than or equal to it.
+func mergeHistogram(sum, h *dto.Histogram) {
+	count := sum.GetSampleCount() + h.GetSampleCount()
+	total := sum.GetSampleSum() + h.GetSampleSum()
+	sum.SampleCount, sum.SampleSum = &count, &total
+	if c := h.CreatedTimestamp; c != nil && sum.CreatedTimestamp != nil && c.AsTime().Before(sum.CreatedTimestamp.AsTime()) {
+		sum.CreatedTimestamp = c
+	}
+
+	bounds := make([]float64, 0, len(sum.Bucket)+len(h.Bucket))
+	seen := make(map[float64]struct{}, cap(bounds))
+	for _, buckets := range [][]*dto.Bucket{sum.Bucket, h.Bucket} {
+		for _, b := range buckets {
+			if _, ok := seen[b.GetUpperBound()]; !ok {
+				seen[b.GetUpperBound()] = struct{}{}
+				bounds = append(bounds, b.GetUpperBound())
+			}
+		}
+	}
+	sort.Float64s(bounds)
+	buckets := make([]*dto.Bucket, 0, len(bounds))
+	for _, bound := range bounds {
+		upperBound := bound
+		cumulative := cumulativeCount(sum.Bucket, bound) + cumulativeCount(h.Bucket, bound)
+		buckets = append(buckets, &dto.Bucket{UpperBound: &upperBound, CumulativeCount: &cumulative})
+	}
+	sum.Bucket = buckets
+}
+
+// cumulativeCount returns the cumulative count of the largest bucket bound less than or equal to bound, zero if none.
+func cumulativeCount(buckets []*dto.Bucket, bound float64) uint64 {
+	var count uint64
+	for _, b := range buckets {
+		if b.GetUpperBound() > bound {
+			break
+		}
+		count = b.GetCumulativeCount()
+	}
+	return count
+}
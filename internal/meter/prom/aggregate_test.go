@@ -0,0 +1,49 @@
+package prom
+
+import (
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	cliprom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateGatherer(t *testing.T) {
+	registry := cliprom.NewRegistry()
+	requests := cliprom.NewCounterVec(cliprom.CounterOpts{Name: "requests_total"}, []string{"route", "pod"})
+	latency := cliprom.NewHistogramVec(cliprom.HistogramOpts{Name: "latency_seconds", Buckets: []float64{1, 5}}, []string{"route", "pod"})
+	registry.MustRegister(requests, latency)
+	requests.WithLabelValues("/a", "a").Add(2)
+	requests.WithLabelValues("/a", "b").Add(3)
+	requests.WithLabelValues("/b", "a").Add(1)
+	latency.WithLabelValues("/a", "a").Observe(0.5)
+	latency.WithLabelValues("/a", "b").Observe(3)
+	latency.WithLabelValues("/a", "b").Observe(10)
+
+	handler := promhttp.HandlerFor(newAggregateGatherer(registry, []string{"pod"}), promhttp.HandlerOpts{})
+	metertest.ScrapeAndAssert(t, handler,
+		`requests_total{route="/a"} 5`,
+		`requests_total{route="/b"} 1`,
+		`latency_seconds_bucket{route="/a",le="1"} 1`,
+		`latency_seconds_bucket{route="/a",le="5"} 2`,
+		`latency_seconds_bucket{route="/a",le="+Inf"} 3`,
+		`latency_seconds_count{route="/a"} 3`,
+		`latency_seconds_sum{route="/a"} 13.5`,
+	)
+}
+
+func TestMergeHistogramBounds(t *testing.T) {
+	bucket := func(bound float64, count uint64) *dto.Bucket {
+		return &dto.Bucket{UpperBound: &bound, CumulativeCount: &count}
+	}
+	sum := &dto.Histogram{Bucket: []*dto.Bucket{bucket(1, 1), bucket(5, 2)}}
+	mergeHistogram(sum, &dto.Histogram{Bucket: []*dto.Bucket{bucket(2, 3)}})
+
+	var got [][2]float64
+	for _, b := range sum.Bucket {
+		got = append(got, [2]float64{b.GetUpperBound(), float64(b.GetCumulativeCount())})
+	}
+	assert.Equal(t, [][2]float64{{1, 1}, {2, 4}, {5, 5}}, got)
+}
@@ -0,0 +1,60 @@
+package prom
+
+import (
+	cliprom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sync"
+	"time"
+)
+
+// createdTimestampGatherer sets the OpenMetrics created timestamp of the counter and histogram series that lack one.
+// The exporter does not expose the start time of the OTel data points, so the created timestamp of a series is the
+// time of the gather preceding its first appearance, or the start of the meter for the series of the first gather.
+// This is a lower bound of the real creation time, which is what rate calculations across restarts require.
+type createdTimestampGatherer struct {
+	cliprom.Gatherer
+	mu         sync.Mutex
+	lastGather time.Time
+	created    map[string]*timestamppb.Timestamp
+}
+
+// newCreatedTimestampGatherer wraps g, series seen in the first gather are considered created at start.
+func newCreatedTimestampGatherer(g cliprom.Gatherer, start time.Time) *createdTimestampGatherer {
+	return &createdTimestampGatherer{
+		Gatherer:   g,
+		lastGather: start,
+		created:    make(map[string]*timestamppb.Timestamp),
+	}
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *createdTimestampGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	lowerBound := timestamppb.New(g.lastGather)
+	seen := make(map[string]*timestamppb.Timestamp, len(g.created))
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			key := mf.GetName() + "\xff" + labelsKey(m)
+			ct, ok := g.created[key]
+			if !ok {
+				ct = lowerBound
+			}
+			seen[key] = ct
+			switch {
+			case m.Counter != nil && m.Counter.CreatedTimestamp == nil:
+				m.Counter.CreatedTimestamp = ct
+			case m.Histogram != nil && m.Histogram.CreatedTimestamp == nil:
+				m.Histogram.CreatedTimestamp = ct
+			}
+		}
+	}
+	// series absent from this gather are forgotten, they get a new created timestamp if they come back.
+	g.created = seen
+	g.lastGather = now
+	return mfs, err
+}
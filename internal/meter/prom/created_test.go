@@ -0,0 +1,67 @@
+package prom
+
+import (
+	"testing"
+	"time"
+
+	cliprom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// createdOf returns the created timestamps of the series of the counter family name, by the value of their route label.
+func createdOf(t *testing.T, g cliprom.Gatherer, name string) map[string]time.Time {
+	t.Helper()
+	mfs, err := g.Gather()
+	require.NoError(t, err)
+	created := make(map[string]time.Time)
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.Metric {
+			created[routeOf(m)] = m.Counter.CreatedTimestamp.AsTime()
+		}
+	}
+	return created
+}
+
+// routeOf returns the value of the route label of m.
+func routeOf(m *dto.Metric) string {
+	for _, l := range m.Label {
+		if l.GetName() == "route" {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
+
+func TestCreatedTimestampGatherers(t *testing.T) {
+	// the exporter gathers the counters without created timestamps.
+	routes := []string{"/a"}
+	reg := cliprom.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		mf := &dto.MetricFamily{Name: proto.String("requests_total"), Type: dto.MetricType_COUNTER.Enum()}
+		for _, route := range routes {
+			mf.Metric = append(mf.Metric, &dto.Metric{
+				Label:   []*dto.LabelPair{{Name: proto.String("route"), Value: proto.String(route)}},
+				Counter: &dto.Counter{Value: proto.Float64(1)},
+			})
+		}
+		return []*dto.MetricFamily{mf}, nil
+	})
+	start := time.Unix(1000, 0).UTC()
+	scrapes, pushes := newCreatedTimestampGatherer(reg, start), newCreatedTimestampGatherer(reg, start)
+
+	assert.Equal(t, map[string]time.Time{"/a": start}, createdOf(t, scrapes, "requests_total"))
+
+	routes = append(routes, "/b")
+	scraped := createdOf(t, scrapes, "requests_total")
+	assert.Equal(t, start, scraped["/a"])
+	assert.True(t, scraped["/b"].After(start), "created after the previous scrape")
+
+	pushed := createdOf(t, pushes, "requests_total")
+	assert.Equal(t, map[string]time.Time{"/a": start, "/b": start}, pushed,
+		"the first push is not affected by the scrapes")
+}
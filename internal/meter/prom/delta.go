@@ -0,0 +1,102 @@
+package prom
+
+import (
+	cliprom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// deltaBucketSuffix is appended to the name of a histogram family to name the gauge family of its delta buckets.
+const deltaBucketSuffix = "_delta_bucket"
+
+// deltaBucketGatherer adds next to every histogram family a gauge family holding, for every bucket, the increment of
+// its cumulative count since the previous gather, labeled with the le of the bucket like the cumulative buckets.
+// Backends without rate-of-histogram support can render them as a heatmap directly. The increments are computed per
+// gather, so the meter should have a single scraper. A series seen for the first time, or whose counts were reset,
+// reports its whole counts.
+type deltaBucketGatherer struct {
+	cliprom.Gatherer
+	mu       sync.Mutex
+	previous map[string][]uint64
+}
+
+// newDeltaBucketGatherer wraps g.
+func newDeltaBucketGatherer(g cliprom.Gatherer) *deltaBucketGatherer {
+	return &deltaBucketGatherer{
+		Gatherer: g,
+		previous: make(map[string][]uint64),
+	}
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *deltaBucketGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	seen := make(map[string][]uint64, len(g.previous))
+	result := make([]*dto.MetricFamily, 0, len(mfs))
+	for _, mf := range mfs {
+		result = append(result, mf)
+		if mf.GetType() != dto.MetricType_HISTOGRAM {
+			continue
+		}
+		delta := &dto.MetricFamily{
+			Name: proto.String(mf.GetName() + deltaBucketSuffix),
+			Help: proto.String("Increments of the buckets of " + mf.GetName() + " since the previous scrape."),
+			Type: dto.MetricType_GAUGE.Enum(),
+		}
+		for _, m := range mf.Metric {
+			key := mf.GetName() + "\xff" + labelsKey(m)
+			counts := cumulativeCounts(m.GetHistogram())
+			seen[key] = counts
+			previous := g.previous[key]
+			if len(previous) != len(counts) || counts[len(counts)-1] < previous[len(previous)-1] {
+				previous = make([]uint64, len(counts))
+			}
+			for i, bound := range upperBounds(m.GetHistogram()) {
+				labels := append(append([]*dto.LabelPair{}, m.Label...), &dto.LabelPair{
+					Name:  proto.String("le"),
+					Value: proto.String(formatBound(bound)),
+				})
+				delta.Metric = append(delta.Metric, &dto.Metric{
+					Label: labels,
+					Gauge: &dto.Gauge{Value: proto.Float64(float64(counts[i] - previous[i]))},
+				})
+			}
+		}
+		result = append(result, delta)
+	}
+	// series absent from this gather are forgotten, they report their whole counts if they come back.
+	g.previous = seen
+	return result, err
+}
+
+// cumulativeCounts returns the cumulative counts of the buckets of h, followed by its count for the +Inf bucket.
+func cumulativeCounts(h *dto.Histogram) []uint64 {
+	counts := make([]uint64, 0, len(h.Bucket)+1)
+	for _, b := range h.Bucket {
+		counts = append(counts, b.GetCumulativeCount())
+	}
+	return append(counts, h.GetSampleCount())
+}
+
+// upperBounds returns the upper bounds of the buckets of h followed by +Inf.
+func upperBounds(h *dto.Histogram) []float64 {
+	bounds := make([]float64, 0, len(h.Bucket)+1)
+	for _, b := range h.Bucket {
+		bounds = append(bounds, b.GetUpperBound())
+	}
+	return append(bounds, math.Inf(1))
+}
+
+// formatBound formats an upper bound like the le label of the exposition formats.
+func formatBound(bound float64) string {
+	if math.IsInf(bound, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(bound, 'g', -1, 64)
+}
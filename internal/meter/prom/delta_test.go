@@ -0,0 +1,38 @@
+package prom
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeltaBuckets(t *testing.T) {
+	cfg := &config.Config{MeterProvider: config.MeterProviderTypePrometheus, DeltaBuckets: true}
+	m, err := NewPrometheusMeter(cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+	record := func(v float64) {
+		m.NewHistogramWithBuckets("latency", "", "s", []float64{1, 5}).AddTag("route", "/a").Record(ctx, v)
+	}
+
+	record(0.5)
+	record(3)
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`latency_seconds_bucket{route="/a",le="5"} 2`,
+		`latency_seconds_delta_bucket{route="/a",le="1"} 1`,
+		`latency_seconds_delta_bucket{route="/a",le="5"} 2`,
+		`latency_seconds_delta_bucket{route="/a",le="+Inf"} 2`,
+	)
+
+	record(3)
+	record(10)
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`latency_seconds_bucket{route="/a",le="+Inf"} 4`,
+		`latency_seconds_delta_bucket{route="/a",le="1"} 0`,
+		`latency_seconds_delta_bucket{route="/a",le="5"} 1`,
+		`latency_seconds_delta_bucket{route="/a",le="+Inf"} 2`,
+	)
+}
@@ -0,0 +1,65 @@
+package prom
+
+import (
+	cliprom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"sort"
+)
+
+// constLabelGatherer adds constant labels to every series gathered, e.g. the instance tags in push mode. A series
+// already labeled with one of the keys keeps its own value.
+type constLabelGatherer struct {
+	cliprom.Gatherer
+	labels []*dto.LabelPair
+}
+
+// newConstLabelGatherer wraps g, adding the labels to every series.
+func newConstLabelGatherer(g cliprom.Gatherer, labels map[string]string) *constLabelGatherer {
+	pairs := make([]*dto.LabelPair, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, &dto.LabelPair{Name: &name, Value: &value})
+	}
+	return &constLabelGatherer{
+		Gatherer: g,
+		labels:   pairs,
+	}
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *constLabelGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			m.Label = withLabels(m.Label, g.labels)
+		}
+	}
+	return mfs, err
+}
+
+// withLabels returns the labels of a series completed with the extra ones it lacks, sorted by name as in the
+// families gathered by a registry.
+func withLabels(labels, extra []*dto.LabelPair) []*dto.LabelPair {
+	added := false
+	for _, pair := range extra {
+		if !hasLabel(labels, pair.GetName()) {
+			labels = append(labels, pair)
+			added = true
+		}
+	}
+	if added {
+		sort.Slice(labels, func(i, j int) bool {
+			return labels[i].GetName() < labels[j].GetName()
+		})
+	}
+	return labels
+}
+
+// hasLabel reports whether labels contains a label with the given name.
+func hasLabel(labels []*dto.LabelPair, name string) bool {
+	for _, l := range labels {
+		if l.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
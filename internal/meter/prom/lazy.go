@@ -0,0 +1,276 @@
+package prom
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/internal/component"
+	"github.com/liangweijiang/go-metric/internal/meter/nop"
+	"github.com/liangweijiang/go-metric/internal/registry"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// _ is a blank identifier used for type assertion to ensure that *LazyMeter implements the interfaces.Meter interface.
+var _ interfaces.Meter = (*LazyMeter)(nil)
+
+// _ is a blank identifier used for type assertion to ensure that *LazyMeter implements the interfaces.Reloadable interface.
+var _ interfaces.Reloadable = (*LazyMeter)(nil)
+
+// LazyMeter is a Prometheus meter whose servers scraped from are started at once, while the exporter, the provider,
+// the resource detection and the collectors are initialized at the first scrape, or at the first use of the meter
+// needing them such as the creation of an instrument, cutting the startup latency of the processes that may never be
+// scraped, e.g. CLIs.
+type LazyMeter struct {
+	cfg      *config.Config
+	once     sync.Once
+	meter    atomic.Pointer[PrometheusMeter]
+	fallback interfaces.Meter
+	servers  []interfaces.MeterServer
+	running  int32
+	started  int32
+	handler  http.Handler
+}
+
+// NewLazyMeter starts the servers scraped from and returns a meter initializing the Prometheus meter on first use.
+// The initialization errors are logged and the meter falls back to a no-op meter.
+// When the start is deferred, the servers are started when the meter is switched on.
+func NewLazyMeter(cfg *config.Config) *LazyMeter {
+	l := &LazyMeter{
+		cfg:     cfg,
+		running: 1,
+	}
+	l.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.get().GetHandler().ServeHTTP(w, r)
+	})
+	l.servers = pullServers(cfg, l.handler, l.observeAccess)
+	if !cfg.DeferStart {
+		l.start()
+	}
+	return l
+}
+
+// start starts the servers scraped from and the Prometheus meter, if initialized, once.
+func (l *LazyMeter) start() {
+	if !atomic.CompareAndSwapInt32(&l.started, 0, 1) {
+		return
+	}
+	atomic.StoreInt32(&l.running, 1)
+	for _, meterServer := range l.servers {
+		meterServer.Start()
+	}
+	if promMeter := l.meter.Load(); promMeter != nil {
+		promMeter.start()
+	}
+}
+
+// get returns the Prometheus meter, initialized on the first call, or the no-op meter if its initialization failed.
+func (l *LazyMeter) get() interfaces.Meter {
+	l.once.Do(func() {
+		start := l.cfg.GetClock().Now()
+		promMeter, err := newPrometheusMeter(l.cfg, false)
+		if err != nil {
+			l.cfg.WriteErrorOrNot("failed to initialize lazy prometheus meter: " + err.Error())
+			l.fallback = nop.NewNopMeter()
+			return
+		}
+		if atomic.LoadInt32(&l.running) == 0 {
+			promMeter.WithRunning(false)
+		}
+		l.meter.Store(promMeter)
+		// the meter stored first is started either here or by a concurrent start.
+		if atomic.LoadInt32(&l.started) == 1 {
+			promMeter.start()
+		}
+		l.cfg.WriteInfoOrNot("lazy prometheus meter is initialized in " + l.cfg.GetClock().Since(start).String())
+	})
+	if promMeter := l.meter.Load(); promMeter != nil {
+		return promMeter
+	}
+	return l.fallback
+}
+
+// Initialized reports whether the Prometheus meter was initialized.
+func (l *LazyMeter) Initialized() bool {
+	return l.meter.Load() != nil
+}
+
+// observeAccess records the self-metrics of a request served by the servers, once the meter is initialized.
+func (l *LazyMeter) observeAccess(entry config.AccessLogEntry) {
+	if promMeter := l.meter.Load(); promMeter != nil {
+		promMeter.observeAccess(entry)
+	}
+}
+
+// Unwrap returns the Prometheus meter, initializing it.
+func (l *LazyMeter) Unwrap() interfaces.Meter {
+	return l.get()
+}
+
+// Registry returns the registry of the Prometheus meter, initializing it, or an empty registry if its initialization
+// failed.
+func (l *LazyMeter) Registry() *registry.Registry {
+	if promMeter, ok := l.get().(*PrometheusMeter); ok {
+		return promMeter.Registry()
+	}
+	return registry.NewRegistry(nil)
+}
+
+// Clock returns the clock of the meter, see clock.From.
+func (l *LazyMeter) Clock() clock.Clock {
+	return l.cfg.GetClock()
+}
+
+// ListenAddr returns the address the embedded server serves /metrics on, empty while it does not listen.
+func (l *LazyMeter) ListenAddr() string {
+	return l.cfg.ListenAddr()
+}
+
+// ServerInfo describes where the metrics are exposed, without initializing the Prometheus meter: the servers scraped
+// from are always listed, the exporters and the push gateway once initialized.
+func (l *LazyMeter) ServerInfo() config.ServerInfo {
+	info := config.ServerInfo{
+		Provider: l.cfg.MeterProvider.String(),
+		Running:  atomic.LoadInt32(&l.running) == 1,
+	}
+	if promMeter := l.meter.Load(); promMeter != nil {
+		info = promMeter.ServerInfo()
+	}
+	for _, meterServer := range l.servers {
+		info.Servers = append(info.Servers, meterServer.State())
+	}
+	return info
+}
+
+// Quarantined returns the metrics rejected by the push backends once the Prometheus meter is initialized, none before.
+func (l *LazyMeter) Quarantined() []string {
+	if promMeter := l.meter.Load(); promMeter != nil {
+		return promMeter.Quarantined()
+	}
+	return nil
+}
+
+// GetHandler returns the handler serving the metrics, which initializes the Prometheus meter at the first request.
+func (l *LazyMeter) GetHandler() http.Handler {
+	return l.handler
+}
+
+// WithRunning starts or stops the servers and the Prometheus meter, if initialized, otherwise it is initialized
+// in the given state. A meter whose start is deferred is started by the first call with true.
+func (l *LazyMeter) WithRunning(on bool) {
+	if on && atomic.LoadInt32(&l.started) == 0 {
+		l.start()
+		return
+	}
+	state := int32(0)
+	if on {
+		state = 1
+	}
+	if atomic.SwapInt32(&l.running, state) == state {
+		return
+	}
+	for _, meterServer := range l.servers {
+		if on {
+			meterServer.Start()
+		} else {
+			meterServer.Stop()
+		}
+	}
+	if promMeter := l.meter.Load(); promMeter != nil {
+		promMeter.WithRunning(on)
+	}
+}
+
+// DisableMetric mutes the metric, initializing the Prometheus meter.
+func (l *LazyMeter) DisableMetric(metricName string) {
+	l.get().DisableMetric(metricName)
+}
+
+// EnableMetric restores the metric, initializing the Prometheus meter.
+func (l *LazyMeter) EnableMetric(metricName string) {
+	l.get().EnableMetric(metricName)
+}
+
+// DeprecateMetric marks the metric as deprecated, initializing the Prometheus meter.
+func (l *LazyMeter) DeprecateMetric(metricName, replacement string) {
+	l.get().DeprecateMetric(metricName, replacement)
+}
+
+// Use appends interceptor to the interceptors of the measurements, initializing the Prometheus meter.
+func (l *LazyMeter) Use(interceptor interfaces.Interceptor) {
+	l.get().Use(interceptor)
+}
+
+// SetLogLevel changes the level of the SDK logging.
+func (l *LazyMeter) SetLogLevel(level config.LogLevel) {
+	l.cfg.SetLogLevel(level)
+}
+
+// SetPushPeriod changes the period of the push gateway, the next push is scheduled with it.
+func (l *LazyMeter) SetPushPeriod(period time.Duration) {
+	l.cfg.SetPushPeriod(period)
+}
+
+// SetCardinalityLimit changes the number of distinct tag sets allowed per metric, the measurements of the new series
+// beyond it are dropped.
+func (l *LazyMeter) SetCardinalityLimit(limit int) {
+	l.cfg.SetCardinalityLimit(limit)
+}
+
+// Flush flushes the Prometheus meter if it is initialized, there is nothing recorded to flush otherwise.
+func (l *LazyMeter) Flush(ctx context.Context) error {
+	if promMeter := l.meter.Load(); promMeter != nil {
+		return promMeter.Flush(ctx)
+	}
+	return nil
+}
+
+// NewCounter creates a counter, initializing the Prometheus meter.
+func (l *LazyMeter) NewCounter(metricName, desc, unit string) interfaces.Counter {
+	return l.get().NewCounter(metricName, desc, unit)
+}
+
+// NewUpDownCounter creates an up-down counter, initializing the Prometheus meter.
+func (l *LazyMeter) NewUpDownCounter(metricName, desc, unit string) interfaces.UpDownCounter {
+	return l.get().NewUpDownCounter(metricName, desc, unit)
+}
+
+// NewGauge creates a gauge, initializing the Prometheus meter.
+func (l *LazyMeter) NewGauge(metricName, desc, unit string) interfaces.Gauge {
+	return l.get().NewGauge(metricName, desc, unit)
+}
+
+// NewHistogram creates a histogram, initializing the Prometheus meter.
+func (l *LazyMeter) NewHistogram(metricName, desc, unit string) interfaces.Histogram {
+	return l.get().NewHistogram(metricName, desc, unit)
+}
+
+// NewObservableGauge creates an observable gauge, initializing the Prometheus meter.
+func (l *LazyMeter) NewObservableGauge(metricName, desc, unit string, callback interfaces.ObservableCallback) interfaces.Registration {
+	return l.get().NewObservableGauge(metricName, desc, unit, callback)
+}
+
+// NewHistogramWithBuckets creates a histogram with the given buckets, initializing the Prometheus meter.
+func (l *LazyMeter) NewHistogramWithBuckets(metricName, desc, unit string, buckets []float64) interfaces.Histogram {
+	return l.get().NewHistogramWithBuckets(metricName, desc, unit, buckets)
+}
+
+// NewSizeHistogram creates a size histogram, initializing the Prometheus meter.
+func (l *LazyMeter) NewSizeHistogram(metricName, desc string) interfaces.Histogram {
+	return l.get().NewSizeHistogram(metricName, desc)
+}
+
+// NewCountHistogram creates a count histogram, initializing the Prometheus meter.
+func (l *LazyMeter) NewCountHistogram(metricName, desc string) interfaces.Histogram {
+	return l.get().NewCountHistogram(metricName, desc)
+}
+
+// Components returns the standard components, which initialize the Prometheus meter when they record.
+func (l *LazyMeter) Components() interfaces.Components {
+	return component.NewComponents(func() interfaces.BaseMeter {
+		return l.get()
+	})
+}
@@ -1,19 +1,28 @@
 package prom
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/liangweijiang/go-metric/internal/meter/core"
 	"github.com/liangweijiang/go-metric/internal/meter/prom/server"
-	"github.com/liangweijiang/go-metric/internal/metrics/nop"
-	"github.com/liangweijiang/go-metric/internal/metrics/prom"
+	"github.com/liangweijiang/go-metric/internal/process"
+	"github.com/liangweijiang/go-metric/internal/registry"
 	"github.com/liangweijiang/go-metric/internal/runtime"
 	"github.com/liangweijiang/go-metric/pkg/config"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
 	cliprom "github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	api "go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
 	"sync/atomic"
+	"time"
 )
 
 // sdkVersion represents the current version of the SDK.
@@ -24,108 +33,232 @@ const (
 )
 
 // PrometheusMeter encapsulates the configuration and components necessary for managing Prometheus metrics.
-// It includes channels for controlling the meter's lifecycle, the primary meter instance,
-// a collection of meter servers, an HTTP handler for metrics exposure, and a runtime metric collector.
+// It embeds the core meter creating the instruments and holding the registry of the runtime switches of the metrics,
+// and adds channels for controlling the meter's lifecycle, a collection of meter servers, an HTTP handler for metrics
+// exposure and the runtime and process metric collectors.
 // This structure facilitates starting and stopping metric collection and export functionalities dynamically.
 type PrometheusMeter struct {
-	cfg              *config.Config
-	running          int32
-	onCh             chan struct{}
-	offCh            chan struct{}
-	meter            api.Meter
-	servers          []interfaces.MeterServer
-	handler          http.Handler
-	runtimeCollector interfaces.MetricCollector
+	*core.Meter
+	cfg         *config.Config
+	onCh        chan struct{}
+	offCh       chan struct{}
+	provider    *metric.MeterProvider
+	servers     []interfaces.MeterServer
+	handler     http.Handler
+	collectors  []interfaces.MetricCollector
+	dropAuditor *registry.DropAuditor
+	started     int32
 }
 
 // NewPrometheusMeter initializes and configures a Prometheus-based meter for metric collection.
 // It sets up a metric registry, exporter, resource, and meter provider based on the provided configuration.
-// Additionally, it configures a histogram view and starts a runtime collector.
-// If configured, it also sets up servers for pushing metrics to a gateway and serving HTTP requests for metrics.
+// Additionally, it configures the histogram view aggregating the histograms created without boundaries with the
+// configured ones, the native histogram views, the delta buckets and the instance tags when enabled, registers the configured readers next to the
+// exporter, and starts the runtime and process collectors.
+// If configured, it also sets up servers for pushing metrics to a gateway, serving HTTP requests for metrics and
+// announcing the scrape endpoint to a metadata service.
+// When the start is deferred, nothing is started and no goroutine is spawned until the meter is switched on.
 // Returns a PrometheusMeter instance and an error if any occur during setup.
 func NewPrometheusMeter(cfg *config.Config) (interfaces.Meter, error) {
-	registry := cliprom.NewRegistry()
+	promMeter, err := newPrometheusMeter(cfg, true)
+	if err != nil {
+		return nil, err
+	}
+	return promMeter, nil
+}
+
+// newPrometheusMeter builds the Prometheus meter, with the servers pulled from when pull is true, otherwise their
+// owner, such as the lazy meter, serves them.
+func newPrometheusMeter(cfg *config.Config, pull bool) (*PrometheusMeter, error) {
+	// the gateway is probed before anything is created, nothing would shut the provider down after a failed probe.
+	if err := server.ProbePushGateway(cfg); err != nil {
+		cfg.WriteErrorOrNot("failed to probe push gateway: " + err.Error())
+		return nil, err
+	}
+	promRegistry := cliprom.NewRegistry()
 	exporter, err := prometheus.New(
-		prometheus.WithRegisterer(registry),
+		prometheus.WithRegisterer(promRegistry),
 		prometheus.WithoutScopeInfo(),
 	)
 	if err != nil {
 		cfg.WriteErrorOrNot("failed to create prometheus exporter: " + err.Error())
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", config.ErrExporterInit, err)
 	}
 
-	resource, err := ResourceWithAttr(cfg.WithBaseTags())
+	resourceAttrs := cfg.WithBaseTags()
+	if cfg.InstanceTagsInResource() {
+		for key, value := range cfg.InstanceTags() {
+			resourceAttrs = append(resourceAttrs, attribute.String(key, value))
+		}
+	}
+	resource, err := ResourceWithAttr(cfg, resourceAttrs)
 	if err != nil {
 		cfg.WriteErrorOrNot("failed to create resource: " + err.Error())
 		return nil, err
 	}
-	provider := metric.NewMeterProvider(
+	dropAuditor := registry.NewDropAuditor(cfg)
+	r := core.NewRegistry(cfg, dropAuditor)
+	views := []metric.View{histogramView(r, cfg.HistogramBoundaries)}
+	var gatherer cliprom.Gatherer = promRegistry
+	if cfg.NativeHistograms {
+		views = append(views, nativeHistogramView)
+		gatherer = &nativeHistogramGatherer{Gatherer: gatherer}
+	}
+	if cfg.DeltaBuckets {
+		gatherer = newDeltaBucketGatherer(gatherer)
+	}
+	pullGatherer, pushGatherer := gatherer, gatherer
+	handlerOpts := promhttp.HandlerOpts{}
+	if cfg.CreatedTimestamps {
+		// the scrapes and the pushes gather at their own pace, each keeps the created timestamps of its own gathers.
+		start := time.Now()
+		pullGatherer = newCreatedTimestampGatherer(gatherer, start)
+		pushGatherer = newCreatedTimestampGatherer(gatherer, start)
+		handlerOpts.EnableOpenMetrics = true
+		handlerOpts.EnableOpenMetricsTextCreatedSamples = true
+	}
+	providerOpts := []metric.Option{
 		metric.WithResource(resource),
 		metric.WithReader(exporter),
-		metric.WithView(
-			metric.NewView(
-				metric.Instrument{
-					Kind: metric.InstrumentKindHistogram,
-				},
-				metric.Stream{
-					Aggregation: metric.AggregationExplicitBucketHistogram{
-						Boundaries: cfg.HistogramBoundaries,
-					},
-				},
-			),
-		),
-	)
+		metric.WithView(views...),
+	}
+	for _, reader := range cfg.Readers {
+		providerOpts = append(providerOpts, metric.WithReader(reader))
+	}
+	provider := metric.NewMeterProvider(providerOpts...)
 
 	meter := provider.Meter(prometheusMeterName, api.WithInstrumentationVersion(sdkVersion), api.WithInstrumentationAttributes())
-	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	if cfg.InstanceTagsPull == config.InstanceTagsLabels {
+		pullGatherer = newConstLabelGatherer(pullGatherer, cfg.InstanceTags())
+	}
+	if cfg.InstanceTagsPush == config.InstanceTagsLabels {
+		pushGatherer = newConstLabelGatherer(pushGatherer, cfg.InstanceTags())
+	}
+	if cfg.PushGateway.Enabled() && len(cfg.PushGateway.AggregateLabels) > 0 {
+		pushGatherer = newAggregateGatherer(pushGatherer, cfg.PushGateway.AggregateLabels)
+	}
+	var handler http.Handler
+	if cfg.ScrapeFilter != nil {
+		handler = newTenantHandler(cfg, pullGatherer, handlerOpts)
+	} else {
+		handler = promhttp.HandlerFor(pullGatherer, handlerOpts)
+	}
 	promMeter := &PrometheusMeter{
-		cfg:     cfg,
-		running: 1,
-		onCh:    make(chan struct{}),
-		offCh:   make(chan struct{}),
-		meter:   meter,
-		handler: handler,
+		Meter:       core.NewMeter(cfg, "prometheus", provider, meter, r),
+		cfg:         cfg,
+		onCh:        make(chan struct{}),
+		offCh:       make(chan struct{}),
+		provider:    provider,
+		handler:     handler,
+		dropAuditor: dropAuditor,
 	}
-	if cfg.PushGateway != nil {
-		promMeter.servers = append(promMeter.servers, server.NewPromPushGatewayServer(cfg, registry))
+	if cfg.PushGateway.Enabled() {
+		promMeter.servers = append(promMeter.servers, server.NewPromPushGatewayServer(cfg, pushGatherer, promMeter.observeExportDrop))
 	}
-	if cfg.PrometheusPort > 0 {
-		promMeter.servers = append(promMeter.servers, server.NewPromHttpServer(cfg, promMeter.GetHandler()))
+	if pull {
+		promMeter.servers = append(promMeter.servers, pullServers(cfg, promMeter.GetHandler(), promMeter.observeAccess)...)
+	}
+
+	promMeter.collectors = []interfaces.MetricCollector{
+		runtime.NewRuntimeCollector(cfg, promMeter),
+		process.NewFDCollector(cfg, promMeter),
+		process.NewDiskCollector(cfg, promMeter),
+		process.NewUptimeCollector(cfg, promMeter),
+	}
+	if !cfg.DeferStart {
+		promMeter.start()
 	}
+	return promMeter, nil
+}
+
+// histogramView aggregates the histograms created without bucket boundaries with the given boundaries, a single
+// bucket if there are none, as it always did; the boundaries given at the creation of the other histograms are kept.
+func histogramView(r *registry.Registry, boundaries []float64) metric.View {
+	return func(inst metric.Instrument) (metric.Stream, bool) {
+		if inst.Kind != metric.InstrumentKindHistogram || !r.Unbounded(inst.Name) {
+			return metric.Stream{}, false
+		}
+		return metric.Stream{
+			Name:        inst.Name,
+			Description: inst.Description,
+			Unit:        inst.Unit,
+			Aggregation: metric.AggregationExplicitBucketHistogram{
+				Boundaries: boundaries,
+			},
+		}, true
+	}
+}
 
-	promMeter.runtimeCollector = runtime.NewRuntimeCollector(cfg, promMeter)
-	promMeter.runtimeCollector.Start()
-	for _, meterServer := range promMeter.servers {
+// start starts the collectors, the drop auditor, the servers and the listener of the running state signals, once.
+func (p *PrometheusMeter) start() {
+	if !atomic.CompareAndSwapInt32(&p.started, 0, 1) {
+		return
+	}
+	// the meter may have been switched off before its deferred start.
+	p.SetRunning(true)
+	for _, collector := range p.collectors {
+		collector.Start()
+	}
+	p.dropAuditor.Start()
+	for _, meterServer := range p.servers {
 		meterServer.Start()
 	}
 
-	go promMeter.signalListener()
-	return promMeter, nil
+	go p.signalListener()
+}
+
+// pullServers returns the configured servers scraped from or announcing the scrape endpoint: the metrics server
+// serving handler, the management server, the report server, started after the metrics server whose bound address
+// it announces, and the server dumping the metrics served by handler on SIGUSR1. There is none in serverless mode.
+func pullServers(cfg *config.Config, handler http.Handler, observe func(entry config.AccessLogEntry)) []interfaces.MeterServer {
+	var servers []interfaces.MeterServer
+	if cfg.Serverless {
+		return servers
+	}
+	if cfg.PrometheusPort > 0 {
+		servers = append(servers, server.NewPromHttpServer(cfg, handler, observe))
+	}
+	if cfg.SeparateManagementPort() {
+		servers = append(servers, server.NewManagementServer(cfg, observe))
+	}
+	if cfg.ReportMetric.Enabled() {
+		servers = append(servers, server.NewReportServer(cfg))
+	}
+	if cfg.SignalDump {
+		servers = append(servers, server.NewSignalDumpServer(cfg, handler))
+	}
+	return servers
 }
 
 // signalListener monitors channels to start or stop the PrometheusMeter and its components.
-// It listens for signals on `onCh` to start and `offCh` to stop the meter, managing the runtime collector
+// It listens for signals on `onCh` to start and `offCh` to stop the meter, managing the metric collectors
 // and all meter servers accordingly. The method ensures the meter can only be started once and stopped once.
 func (p *PrometheusMeter) signalListener() {
 	for {
 		select {
 		case <-p.onCh:
-			if !atomic.CompareAndSwapInt32(&p.running, 0, 1) {
+			if !p.SetRunning(true) {
 				p.cfg.WriteInfoOrNot("prometheus meter is already running")
 				return
 			}
 			p.cfg.WriteInfoOrNot("prometheus meter is started")
-			p.runtimeCollector.Start()
+			for _, collector := range p.collectors {
+				collector.Start()
+			}
+			p.dropAuditor.Start()
 			for _, meterServer := range p.servers {
 				meterServer.Start()
 			}
 		case <-p.offCh:
-			if !atomic.CompareAndSwapInt32(&p.running, 1, 0) {
+			if !p.SetRunning(false) {
 				p.cfg.WriteInfoOrNot("prometheus meter is already stopped")
 				return
 			}
 			p.cfg.WriteInfoOrNot("prometheus meter is stopped")
-			p.runtimeCollector.Stop()
+			for _, collector := range p.collectors {
+				collector.Stop()
+			}
+			p.dropAuditor.Stop()
 			for _, meterServer := range p.servers {
 				meterServer.Stop()
 			}
@@ -133,6 +266,40 @@ func (p *PrometheusMeter) signalListener() {
 	}
 }
 
+// Self-metrics of the requests served by the embedded server when the access log is enabled.
+const (
+	accessRequestsMetric = "go_metric_http_requests"
+	accessDurationMetric = "go_metric_http_request_duration"
+	accessSizeMetric     = "go_metric_http_response_size"
+	// accessOtherRoute is the path tag of the requests no route matched, the raw paths would create a series per URL.
+	accessOtherRoute = "other"
+)
+
+// observeAccess records the self-metrics of a request served by the embedded server, after the collection of a scrape
+// completed so that the instruments are not created while the meter collects.
+func (p *PrometheusMeter) observeAccess(entry config.AccessLogEntry) {
+	ctx := context.Background()
+	route := entry.Route
+	if route == "" {
+		route = accessOtherRoute
+	}
+	tags := map[string]string{"path": route, "status": strconv.Itoa(entry.Status)}
+	p.NewCounter(accessRequestsMetric, "requests served by the embedded server", "").WithTags(tags).IncrOne(ctx)
+	p.NewHistogram(accessDurationMetric, "duration of the requests served by the embedded server", "s").
+		WithTags(tags).Update(ctx, entry.Duration)
+	p.NewSizeHistogram(accessSizeMetric, "size of the responses of the embedded server").
+		WithTags(tags).Record(ctx, float64(entry.Size))
+}
+
+// exportDroppedMetric counts the gatherings of the push gateway dropped because the export queue was full.
+const exportDroppedMetric = "go_metric_export_dropped"
+
+// observeExportDrop records a gathering dropped by the push gateway server.
+func (p *PrometheusMeter) observeExportDrop() {
+	p.NewCounter(exportDroppedMetric, "pushes dropped because the export queue was full", "").
+		AddTag("exporter", config.ServerKindPushGateway).IncrOne(context.Background())
+}
+
 // GetHandler returns the HTTP handler for exposing Prometheus metrics.
 // This handler can be used to integrate with HTTP servers to serve metrics data.
 // It retrieves the pre-configured http.Handler instance associated with the PrometheusMeter.
@@ -140,11 +307,59 @@ func (p *PrometheusMeter) GetHandler() http.Handler {
 	return p.handler
 }
 
+// ListenAddr returns the address the embedded server serves /metrics on, empty while it does not listen.
+func (p *PrometheusMeter) ListenAddr() string {
+	return p.cfg.ListenAddr()
+}
+
+// ServerInfo describes where the metrics are exposed: the state of every server, the address it is bound to or
+// pushes to, and the exporters, prometheus, push_gateway when enabled and the type of every configured reader.
+func (p *PrometheusMeter) ServerInfo() config.ServerInfo {
+	info := config.ServerInfo{
+		Provider:  p.cfg.MeterProvider.String(),
+		Running:   p.Running(),
+		Exporters: []string{"prometheus"},
+		Servers:   make([]config.ServerState, 0, len(p.servers)),
+	}
+	if p.cfg.PushGateway.Enabled() {
+		info.Exporters = append(info.Exporters, config.ServerKindPushGateway)
+	}
+	for _, reader := range p.cfg.Readers {
+		info.Exporters = append(info.Exporters, fmt.Sprintf("%T", reader))
+	}
+	for _, meterServer := range p.servers {
+		info.Servers = append(info.Servers, meterServer.State())
+	}
+	return info
+}
+
+// Quarantined returns the sorted names of the metrics rejected by the push backends, which are no longer exported
+// to them.
+func (p *PrometheusMeter) Quarantined() []string {
+	var names []string
+	for _, meterServer := range p.servers {
+		if q, ok := meterServer.(interface{ Quarantined() []string }); ok {
+			names = append(names, q.Quarantined()...)
+		}
+	}
+	sort.Strings(names)
+	return slices.Compact(names)
+}
+
 // WithRunning sets the running state of the PrometheusMeter to the specified boolean value.
 // When `on` is true, it attempts to send a signal on the `onCh` channel to start the meter.
 // When `on` is false, it tries to send a signal on the `offCh` channel to stop the meter.
 // Channels are used with a non-blocking send to avoid blocking the caller if the signals are not immediately processed.
+// A meter whose start is deferred is started by the first call with true, and only switched off by false before.
 func (p *PrometheusMeter) WithRunning(on bool) {
+	if atomic.LoadInt32(&p.started) == 0 {
+		if on {
+			p.start()
+		} else {
+			p.SetRunning(false)
+		}
+		return
+	}
 	if on {
 		select {
 		case p.onCh <- struct{}{}:
@@ -160,85 +375,16 @@ func (p *PrometheusMeter) WithRunning(on bool) {
 	}
 }
 
-// NewCounter creates a new Counter metric with the specified name, description, and unit.
-// It returns a no-op counter if the PrometheusMeter is not running.
-// This method uses the underlying meter to create a Float64Counter and wraps it with a custom Counter implementation.
-// In case of failure creating the counter, a log message is emitted and a no-op counter is returned.
-func (p *PrometheusMeter) NewCounter(metricName, desc, unit string) interfaces.Counter {
-	if !p.isRunning() {
-		return nop.Counter
-	}
-	counter, err := p.meter.Float64Counter(
-		metricName,
-		api.WithDescription(desc),
-		api.WithUnit(unit),
-	)
-	if err != nil {
-		p.cfg.WriteInfoOrNot("failed to create prometheus counter: " + err.Error())
-		return nop.Counter
+// Flush forces the meter provider to flush and every configured server to export the current metrics immediately,
+// e.g. pushing to the gateway before the process is stopped. Errors of all servers are joined together.
+func (p *PrometheusMeter) Flush(ctx context.Context) error {
+	errs := []error{p.provider.ForceFlush(ctx)}
+	for _, meterServer := range p.servers {
+		errs = append(errs, meterServer.Flush(ctx))
 	}
-	return prom.NewCounter(metricName, counter)
-}
-
-// NewUpDownCounter creates a new UpDownCounter metric within the PrometheusMeter.
-// It requires a metric name, description, and unit of measure.
-// If the PrometheusMeter is not running, it returns a no-op UpDownCounter.
-// Otherwise, it initializes a new UpDownCounter with the provided parameters and adds it to the meter.
-// Returns an error if the UpDownCounter creation fails within the underlying meter.
-func (p *PrometheusMeter) NewUpDownCounter(metricName, desc, unit string) interfaces.UpDownCounter {
-	if !p.isRunning() {
-		return nop.UpDownCounter
-	}
-	udCounter, err := p.meter.Float64UpDownCounter(metricName,
-		api.WithDescription(desc),
-		api.WithUnit(unit),
-	)
-	if err != nil {
-		p.cfg.WriteInfoOrNot("failed to create prometheus upDownCounter: " + err.Error())
-		return nop.UpDownCounter
+	if err := errors.Join(errs...); err != nil {
+		p.cfg.WriteErrorOrNot("failed to flush prometheus meter: " + err.Error())
+		return err
 	}
-	return prom.NewUpDownCounter(metricName, udCounter)
-}
-
-// NewGauge creates a new Gauge metric with the specified name, description, and unit within the PrometheusMeter.
-// Returns a no-op Gauge if the PrometheusMeter is not currently running.
-// It uses the provided metricName, description, and unit to configure the gauge via the underlying meter.
-// In case of an error during gauge creation, a log is emitted and a no-op Gauge is returned.
-func (p *PrometheusMeter) NewGauge(metricName, desc, unit string) interfaces.Gauge {
-	if !p.isRunning() {
-		return nop.Gauge
-	}
-	gauge, err := p.meter.Float64Gauge(metricName,
-		api.WithDescription(desc),
-		api.WithUnit(unit))
-	if err != nil {
-		p.cfg.WriteInfoOrNot("failed to create prometheus gauge: " + err.Error())
-		return nop.Gauge
-	}
-	return prom.NewGauge(metricName, gauge)
-}
-
-// NewHistogram creates a new Histogram metric with the specified name, description, and unit within the PrometheusMeter.
-// If the PrometheusMeter is not running, it returns a no-op Histogram.
-// The method configures the histogram using the underlying meter with explicit bucket boundaries.
-// In case of an error during histogram creation, a log message is emitted, and a no-op Histogram is returned.
-func (p *PrometheusMeter) NewHistogram(metricName, desc, unit string) interfaces.Histogram {
-	if !p.isRunning() {
-		return nop.Histogram
-	}
-	histogram, err := p.meter.Float64Histogram(metricName,
-		api.WithDescription(desc),
-		api.WithUnit(unit),
-		api.WithExplicitBucketBoundaries())
-	if err != nil {
-		p.cfg.WriteInfoOrNot("failed to create prometheus histogram: " + err.Error())
-		return nop.Histogram
-	}
-	return prom.NewHistogram(metricName, histogram)
-}
-
-// isRunning checks if the PrometheusMeter is currently running.
-// It returns true if the meter is running, false otherwise.
-func (p *PrometheusMeter) isRunning() bool {
-	return atomic.LoadInt32(&p.running) == 1
+	return nil
 }
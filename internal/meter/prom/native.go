@@ -0,0 +1,112 @@
+package prom
+
+import (
+	cliprom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"strings"
+)
+
+// nativeHistogramMarker is appended to the name of the exponential stream recorded next to every histogram
+// when native histograms are enabled, it never reaches the exposition since the streams are merged on gather.
+// nativeHistogramMaxSize and nativeHistogramMaxScale bound the exponential aggregation, 8 is the finest schema Prometheus accepts.
+const (
+	nativeHistogramMarker   = "__native"
+	nativeHistogramMaxSize  = 160
+	nativeHistogramMaxScale = 8
+)
+
+// nativeHistogramView records every histogram instrument a second time with an exponential aggregation,
+// under the instrument name followed by nativeHistogramMarker.
+func nativeHistogramView(inst metric.Instrument) (metric.Stream, bool) {
+	if inst.Kind != metric.InstrumentKindHistogram {
+		return metric.Stream{}, false
+	}
+	return metric.Stream{
+		Name:        inst.Name + nativeHistogramMarker,
+		Description: inst.Description,
+		Unit:        inst.Unit,
+		Aggregation: metric.AggregationBase2ExponentialHistogram{
+			MaxSize:  nativeHistogramMaxSize,
+			MaxScale: nativeHistogramMaxScale,
+		},
+	}, true
+}
+
+// nativeHistogramGatherer merges the exponential streams produced by nativeHistogramView into the explicit bucket
+// histograms of the same name, so a single family carries both the native (sparse) buckets and the classic ones.
+// Scrapers negotiating the protobuf format can use the native buckets while the text format falls back to the classic ones.
+type nativeHistogramGatherer struct {
+	cliprom.Gatherer
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *nativeHistogramGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	if len(mfs) == 0 {
+		return mfs, err
+	}
+
+	classics := make(map[string]*dto.MetricFamily, len(mfs))
+	for _, mf := range mfs {
+		if !strings.Contains(mf.GetName(), nativeHistogramMarker) {
+			classics[mf.GetName()] = mf
+		}
+	}
+
+	merged := make([]*dto.MetricFamily, 0, len(classics))
+	for _, mf := range mfs {
+		if !strings.Contains(mf.GetName(), nativeHistogramMarker) {
+			merged = append(merged, mf)
+			continue
+		}
+		name := strings.Replace(mf.GetName(), nativeHistogramMarker, "", 1)
+		classic, ok := classics[name]
+		if !ok {
+			mf.Name = &name
+			merged = append(merged, mf)
+			continue
+		}
+		mergeNativeHistograms(classic, mf)
+	}
+	return merged, err
+}
+
+// mergeNativeHistograms copies the native buckets of every metric of native into the metric of classic with the same labels.
+func mergeNativeHistograms(classic, native *dto.MetricFamily) {
+	byLabels := make(map[string]*dto.Histogram, len(classic.Metric))
+	for _, m := range classic.Metric {
+		if m.Histogram != nil {
+			byLabels[labelsKey(m)] = m.Histogram
+		}
+	}
+	for _, m := range native.Metric {
+		target, ok := byLabels[labelsKey(m)]
+		if !ok || m.Histogram == nil {
+			continue
+		}
+		source := m.Histogram
+		target.Schema = source.Schema
+		target.ZeroThreshold = source.ZeroThreshold
+		target.ZeroCount = source.ZeroCount
+		target.ZeroCountFloat = source.ZeroCountFloat
+		target.NegativeSpan = source.NegativeSpan
+		target.NegativeDelta = source.NegativeDelta
+		target.NegativeCount = source.NegativeCount
+		target.PositiveSpan = source.PositiveSpan
+		target.PositiveDelta = source.PositiveDelta
+		target.PositiveCount = source.PositiveCount
+	}
+}
+
+// labelsKey builds a key identifying the label set of a metric, labels are sorted by the registry on gather.
+func labelsKey(m *dto.Metric) string {
+	var sb strings.Builder
+	for _, label := range m.Label {
+		sb.WriteString(label.GetName())
+		sb.WriteByte(0xff)
+		sb.WriteString(label.GetValue())
+		sb.WriteByte(0xff)
+	}
+	return sb.String()
+}
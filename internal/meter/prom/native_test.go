@@ -0,0 +1,41 @@
+package prom
+
+import (
+	"context"
+	"testing"
+
+	cliprom "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	api "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestNativeHistogramGatherer(t *testing.T) {
+	registry := cliprom.NewRegistry()
+	exporter, err := prometheus.New(prometheus.WithRegisterer(registry), prometheus.WithoutScopeInfo(), prometheus.WithoutTargetInfo())
+	require.NoError(t, err)
+	provider := metric.NewMeterProvider(
+		metric.WithReader(exporter),
+		metric.WithView(
+			metric.NewView(
+				metric.Instrument{Kind: metric.InstrumentKindHistogram},
+				metric.Stream{Aggregation: metric.AggregationExplicitBucketHistogram{Boundaries: []float64{1, 2}}},
+			),
+			nativeHistogramView,
+		),
+	)
+	histogram, err := provider.Meter("test").Float64Histogram("req_duration", api.WithUnit("s"))
+	require.NoError(t, err)
+	histogram.Record(context.Background(), 1.5)
+
+	mfs, err := (&nativeHistogramGatherer{Gatherer: registry}).Gather()
+	require.NoError(t, err)
+	require.Len(t, mfs, 1)
+	assert.Equal(t, "req_duration_seconds", mfs[0].GetName())
+	h := mfs[0].Metric[0].GetHistogram()
+	assert.Len(t, h.GetBucket(), 2)
+	assert.Equal(t, int32(nativeHistogramMaxScale), h.GetSchema())
+	assert.NotEmpty(t, h.GetPositiveSpan())
+}
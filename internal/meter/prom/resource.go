@@ -2,35 +2,104 @@ package prom
 
 import (
 	"context"
+	"errors"
+	"github.com/liangweijiang/go-metric/pkg/config"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/resource"
 )
 
+// detector is a resource detector run concurrently with the others.
+type detector struct {
+	name   string
+	option resource.Option
+}
+
+// detectors are the resource detectors, in the order of their precedence: the attributes of a later detector
+// override those of an earlier one.
+var detectors = []detector{
+	{"env", resource.WithFromEnv()},                // Discover and provide attributes from OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME environment variables.
+	{"telemetry_sdk", resource.WithTelemetrySDK()}, // Discover and provide information about the OpenTelemetry SDK used.
+	{"process", resource.WithProcess()},            // Discover and provide process information.
+	{"os", resource.WithOS()},                      // Discover and provide OS information.
+	{"container", resource.WithContainer()},        // Discover and provide container information.
+	{"host", resource.WithHost()},                  // Discover and provide host information.
+}
+
+// detected is the resource found by the detector at index.
+type detected struct {
+	index int
+	res   *resource.Resource
+	err   error
+}
+
 // ResourceWithAttr creates a new OpenTelemetry resource with the provided custom attributes.
 // This function allows you to add additional resource attributes to the OpenTelemetry resource.
 //
-// The function takes a slice of attribute.KeyValue as input, where each KeyValue represents a custom attribute.
-// The function returns a pointer to the created resource.Resource and an error if any.
-//
 // The created resource includes attributes discovered from environment variables (OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME),
 // information about the OpenTelemetry SDK used, process information, OS information, container information, host information,
-// and the custom attributes provided as input.
+// and the custom attributes provided as input, which override the detected ones.
 //
-// Note: You can optionally add your own external Detector implementation by uncommenting the corresponding line in the function.
-func ResourceWithAttr(attributes []attribute.KeyValue) (*resource.Resource, error) {
-	res, err := resource.New(
-		context.Background(),
-		resource.WithFromEnv(),                 // Discover and provide attributes from OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME environment variables.
-		resource.WithTelemetrySDK(),            // Discover and provide information about the OpenTelemetry SDK used.
-		resource.WithProcess(),                 // Discover and provide process information.
-		resource.WithOS(),                      // Discover and provide OS information.
-		resource.WithContainer(),               // Discover and provide container information.
-		resource.WithHost(),                    // Discover and provide host information.
-		resource.WithAttributes(attributes...), // Add custom resource attributes.
-		// resource.WithDetectors(third_party.Detector{}),           // Bring your own external Detector implementation.
-	)
-	if err != nil {
-		return nil, err
+// The detectors run concurrently, bounded by the resource detection timeout of cfg, so that a detector stalling in a
+// restricted environment, e.g. on container metadata, does not stall the startup. The detectors failing or not done
+// in time are logged and skipped, the resource is built from the others: the error is always nil.
+// When the start of the meter is deferred, the detectors run one after the other in the calling goroutine instead.
+func ResourceWithAttr(cfg *config.Config, attributes []attribute.KeyValue) (*resource.Resource, error) {
+	ctx, cancel := context.WithTimeout(cfg.GetContext(), cfg.GetResourceDetectionTimeout())
+	defer cancel()
+
+	results := make(chan detected, len(detectors))
+	for i, d := range detectors {
+		detect := func() {
+			res, err := resource.New(ctx, d.option)
+			results <- detected{index: i, res: res, err: err}
+		}
+		if cfg.DeferStart {
+			detect()
+		} else {
+			go detect()
+		}
+	}
+	found := make([]*resource.Resource, len(detectors))
+	done := make([]bool, len(detectors))
+collect:
+	for range detectors {
+		select {
+		case r := <-results:
+			done[r.index] = true
+			if r.err != nil {
+				cfg.WriteErrorOrNot("resource detector " + detectors[r.index].name + " failed: " + r.err.Error())
+				// a partial resource still holds the attributes detected.
+				if !errors.Is(r.err, resource.ErrPartialResource) {
+					continue
+				}
+			}
+			found[r.index] = r.res
+		case <-ctx.Done():
+			for i := range detectors {
+				if !done[i] {
+					cfg.WriteErrorOrNot("resource detector " + detectors[i].name + " skipped: " + ctx.Err().Error())
+				}
+			}
+			break collect
+		}
+	}
+
+	res := resource.Empty()
+	for i, detectedRes := range append(found, resource.NewSchemaless(attributes...)) {
+		merged, err := resource.Merge(res, detectedRes)
+		if err != nil {
+			// the attributes are merged without schema URL.
+			cfg.WriteDebugOrNot("failed to merge resource " + resourceName(i) + ": " + err.Error())
+		}
+		res = merged
 	}
 	return res, nil
 }
+
+// resourceName returns the name of the detector at index, or attributes for the custom attributes.
+func resourceName(index int) string {
+	if index < len(detectors) {
+		return detectors[index].name
+	}
+	return "attributes"
+}
@@ -0,0 +1,42 @@
+package prom
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// stalledDetector blocks until released, like a detector waiting on unreachable container metadata.
+type stalledDetector chan struct{}
+
+func (d stalledDetector) Detect(context.Context) (*resource.Resource, error) {
+	<-d
+	return resource.NewSchemaless(attribute.String("stalled", "true")), nil
+}
+
+func TestResourceDetectionTimeout(t *testing.T) {
+	stalled := make(stalledDetector)
+	defer close(stalled)
+	saved := detectors
+	defer func() { detectors = saved }()
+	detectors = []detector{
+		{"stalled", resource.WithDetectors(stalled)},
+		{"static", resource.WithAttributes(attribute.String("region", "eu"))},
+	}
+
+	var logged []string
+	cfg := &config.Config{ResourceTimeout: 20 * time.Millisecond, ErrorLogWrite: func(s string) { logged = append(logged, s) }}
+	start := time.Now()
+	res, err := ResourceWithAttr(cfg, []attribute.KeyValue{attribute.String("service", "orders")})
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	assert.Equal(t, attribute.NewSet(attribute.String("region", "eu"), attribute.String("service", "orders")), *res.Set())
+	assert.Equal(t, []string{"[go-metrics] resource detector stalled skipped: context deadline exceeded"}, logged)
+}
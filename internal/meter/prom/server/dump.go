@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+)
+
+// signalDumpServer dumps the snapshot of the metrics served by the handler when the process receives SIGUSR1.
+type signalDumpServer struct {
+	cfg     *config.Config
+	handler http.Handler
+	running int32
+	sigCh   chan os.Signal
+	doneCh  chan struct{}
+}
+
+// NewSignalDumpServer creates a server dumping the snapshot of the metrics served by handler to the configured file
+// or stderr when the process receives SIGUSR1.
+func NewSignalDumpServer(cfg *config.Config, handler http.Handler) interfaces.MeterServer {
+	return &signalDumpServer{
+		cfg:     cfg,
+		handler: handler,
+	}
+}
+
+// Start installs the signal handler, there is none where SIGUSR1 does not exist.
+func (s *signalDumpServer) Start() {
+	if dumpSignal == nil {
+		s.cfg.WriteInfoOrNot("signal dump is not supported on this platform")
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+		return
+	}
+	s.sigCh = make(chan os.Signal, 1)
+	s.doneCh = make(chan struct{})
+	signal.Notify(s.sigCh, dumpSignal)
+	go s.listen(s.sigCh, s.doneCh)
+}
+
+// Stop uninstalls the signal handler, SIGUSR1 then terminates the process as by default.
+func (s *signalDumpServer) Stop() {
+	if !atomic.CompareAndSwapInt32(&s.running, 1, 0) {
+		return
+	}
+	signal.Stop(s.sigCh)
+	close(s.sigCh)
+	<-s.doneCh
+}
+
+// Flush returns nil, the server does not export metrics.
+func (s *signalDumpServer) Flush(context.Context) error {
+	return nil
+}
+
+// State returns the kind of the server, where the snapshot is dumped and whether the signal handler is installed.
+func (s *signalDumpServer) State() config.ServerState {
+	return config.ServerState{
+		Kind:    config.ServerKindSignalDump,
+		Addr:    s.cfg.SignalDumpTarget(),
+		Running: atomic.LoadInt32(&s.running) == 1,
+	}
+}
+
+// listen dumps the snapshot on every signal until sigCh is closed, then closes doneCh.
+func (s *signalDumpServer) listen(sigCh chan os.Signal, doneCh chan struct{}) {
+	defer close(doneCh)
+	for range sigCh {
+		if err := s.dump(); err != nil {
+			s.cfg.WriteErrorOrNot("failed to dump metrics to " + s.cfg.SignalDumpTarget() + ": " + err.Error())
+			continue
+		}
+		s.cfg.WriteInfoOrNot("dumped metrics to " + s.cfg.SignalDumpTarget())
+	}
+}
+
+// dump writes the snapshot served by the handler to the configured file, replacing its content, or to stderr.
+func (s *signalDumpServer) dump() error {
+	snapshot := &snapshotWriter{header: http.Header{}}
+	req, err := http.NewRequestWithContext(s.cfg.GetContext(), http.MethodGet, "/metrics", nil)
+	if err != nil {
+		return err
+	}
+	s.handler.ServeHTTP(snapshot, req)
+
+	var w io.Writer = os.Stderr
+	if s.cfg.SignalDumpPath != "" {
+		f, err := os.Create(s.cfg.SignalDumpPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	_, err = snapshot.body.WriteTo(w)
+	return err
+}
+
+// snapshotWriter is a http.ResponseWriter keeping the body served in memory.
+type snapshotWriter struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+// Header returns the header of the response, which is discarded.
+func (w *snapshotWriter) Header() http.Header {
+	return w.header
+}
+
+// Write appends b to the body.
+func (w *snapshotWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// WriteHeader discards the status of the response.
+func (w *snapshotWriter) WriteHeader(int) {}
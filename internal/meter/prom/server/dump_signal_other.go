@@ -0,0 +1,8 @@
+//go:build !unix
+
+package server
+
+import "os"
+
+// dumpSignal is nil where SIGUSR1 does not exist, the snapshot is never dumped.
+var dumpSignal os.Signal
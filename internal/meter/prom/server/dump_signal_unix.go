@@ -0,0 +1,11 @@
+//go:build unix
+
+package server
+
+import (
+	"os"
+	"syscall"
+)
+
+// dumpSignal is the signal dumping the snapshot of the metrics.
+var dumpSignal os.Signal = syscall.SIGUSR1
@@ -0,0 +1,34 @@
+//go:build unix
+
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalDumpServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.txt")
+	cfg := &config.Config{SignalDump: true, SignalDumpPath: path, InfoLogWrite: func(string) {}}
+	exporter := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("orders_total 1\n"))
+	})
+	dumpServer := NewSignalDumpServer(cfg, exporter)
+	dumpServer.Start()
+	defer dumpServer.Stop()
+	assert.Equal(t, config.ServerState{Kind: config.ServerKindSignalDump, Addr: path, Running: true}, dumpServer.State())
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool {
+		b, err := os.ReadFile(path)
+		return err == nil && string(b) == "orders_total 1\n"
+	}, 5*time.Second, 10*time.Millisecond)
+}
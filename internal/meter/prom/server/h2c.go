@@ -0,0 +1,20 @@
+//go:build go1.24
+
+package server
+
+import (
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"net/http"
+)
+
+// enableH2C makes the server accept HTTP/2 without TLS next to HTTP/1.1, bounding the streams of a connection by
+// the concurrent scrapes when they are bounded.
+func enableH2C(cfg *config.Config, server *http.Server) {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	server.Protocols = protocols
+	if limit := cfg.HTTPServer.MaxConcurrentScrapes; limit > 0 {
+		server.HTTP2 = &http.HTTP2Config{MaxConcurrentStreams: limit}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !go1.24
+
+package server
+
+import (
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"net/http"
+)
+
+// enableH2C requires the unencrypted HTTP/2 support of net/http added in go1.24, older toolchains serve HTTP/1.1 only.
+func enableH2C(cfg *config.Config, _ *http.Server) {
+	cfg.WriteErrorOrNot("h2c requires go1.24 or later, the embedded server serves HTTP/1.1 only")
+}
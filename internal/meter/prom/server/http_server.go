@@ -7,9 +7,10 @@ import (
 	"fmt"
 	"github.com/liangweijiang/go-metric/pkg/config"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"net"
 	"net/http"
 	"net/http/pprof"
-	"strconv"
+	"net/netip"
 	"sync/atomic"
 )
 
@@ -20,25 +21,67 @@ type promHttpServer struct {
 	exporterHandler http.Handler
 	server          *http.Server
 	cfg             *config.Config
+	port            int
+	management      bool
+	fallback        bool
+	allowlist       []netip.Prefix
+	scrapes         chan struct{}
+	observe         func(entry config.AccessLogEntry)
 	closeCh         chan struct{}
 	running         int32
+	addr            atomic.Value
 }
 
 // NewPromHttpServer initializes a new Prometheus HTTP server based on the provided configuration and exporter handler.
 // It sets up the necessary structures to start and stop the server, including configurations and channels for control.
 // Returns a MeterServer interface which can be used to manage the lifecycle of the HTTP server for metrics exposure.
-func NewPromHttpServer(cfg *config.Config, exporterHandler http.Handler) interfaces.MeterServer {
+// When a distinct management port is configured, the server only serves /metrics, the other endpoints being served
+// by the server of NewManagementServer. With the access log enabled, observe, which may be nil, is called with every
+// request served, e.g. to record self-metrics. When the port is taken, the server falls back to the configured port
+// range or to an ephemeral port, and records the address bound in the configuration.
+func NewPromHttpServer(cfg *config.Config, exporterHandler http.Handler, observe func(entry config.AccessLogEntry)) interfaces.MeterServer {
 
 	server := promHttpServer{
 		cfg:             cfg,
 		exporterHandler: exporterHandler,
+		port:            cfg.PrometheusPort,
+		management:      !cfg.SeparateManagementPort(),
+		fallback:        true,
+		allowlist:       parseAllowlist(cfg),
+		observe:         observe,
 		running:         0,
 		closeCh:         make(chan struct{}),
 	}
+	if limit := cfg.HTTPServer.MaxConcurrentScrapes; limit > 0 {
+		server.scrapes = make(chan struct{}, limit)
+	}
 
 	return &server
 }
 
+// parseAllowlist parses the scrape allowlist of the configuration, validated when the meter was created.
+func parseAllowlist(cfg *config.Config) []netip.Prefix {
+	allowlist, err := cfg.ParseScrapeAllowlist()
+	if err != nil {
+		cfg.WriteErrorOrNot("failed to parse scrape allowlist: " + err.Error())
+	}
+	return allowlist
+}
+
+// NewManagementServer initializes the HTTP server of the health check, configuration and profiling endpoints on the
+// configured management port, for deployments exposing them to other networks than the metrics.
+// observe is called with every request served like for NewPromHttpServer.
+func NewManagementServer(cfg *config.Config, observe func(entry config.AccessLogEntry)) interfaces.MeterServer {
+	return &promHttpServer{
+		cfg:        cfg,
+		port:       cfg.ManagementPort,
+		management: true,
+		allowlist:  parseAllowlist(cfg),
+		observe:    observe,
+		closeCh:    make(chan struct{}),
+	}
+}
+
 // Start initializes and begins listening for HTTP requests on the configured Prometheus port.
 // It sets up various endpoints like health check, metrics retrieval, and profiling routes.
 // If the server is already running, the method will not restart it.
@@ -48,28 +91,25 @@ func (s *promHttpServer) Start() {
 		s.cfg.WriteInfoOrNot("prom http server is already running")
 		return
 	}
-	s.cfg.WriteInfoOrNot(fmt.Sprintf("starting prom http server, port:%d", s.cfg.PrometheusPort))
-	mux := http.NewServeMux()
-	logRoute := func(route string) string {
-		s.cfg.WriteInfoOrNot(fmt.Sprintf("http handler, method:Get, uri:%s", route))
-		return route
+	s.cfg.WriteInfoOrNot(fmt.Sprintf("starting prom http server, port:%d", s.port))
+	listener, err := s.listen()
+	if err != nil {
+		s.cfg.WriteErrorOrNot(fmt.Sprintf("failed to start prom http server on port %d: %s", s.port, err.Error()))
+		atomic.StoreInt32(&s.running, 0)
+		return
 	}
-	mux.HandleFunc(logRoute("/actuator/health"), s.healthCheck)
-	mux.HandleFunc(logRoute("/metrics"), func(w http.ResponseWriter, r *http.Request) {
-		if s.exporterHandler != nil {
-			s.exporterHandler.ServeHTTP(w, r)
-		}
-	})
-	mux.HandleFunc(logRoute("/debug/pprof/"), pprof.Index)
-	mux.HandleFunc(logRoute("/debug/pprof/cmdline"), pprof.Cmdline)
-	mux.HandleFunc(logRoute("/debug/pprof/profile"), pprof.Profile)
-	mux.HandleFunc(logRoute("/debug/pprof/symbol"), pprof.Symbol)
-	mux.HandleFunc(logRoute("/debug/pprof/trace"), pprof.Trace)
+	s.addr.Store(listener.Addr().String())
 	s.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.cfg.PrometheusPort),
-		Handler: mux,
+		Addr:              listener.Addr().String(),
+		Handler:           s.handler(),
+		IdleTimeout:       s.cfg.HTTPServer.IdleTimeout,
+		ReadHeaderTimeout: s.cfg.HTTPServer.ReadHeaderTimeout,
 	}
-	go s.startHTTPServer()
+	s.server.SetKeepAlivesEnabled(!s.cfg.HTTPServer.DisableKeepAlives)
+	if s.cfg.HTTPServer.H2C {
+		enableH2C(s.cfg, s.server)
+	}
+	go s.startHTTPServer(listener)
 	go func() {
 		select {
 		case <-s.closeCh:
@@ -83,6 +123,117 @@ func (s *promHttpServer) Start() {
 	}()
 }
 
+// routes returns the mux serving the metrics, when the server has an exporter handler, and the health check,
+// configuration and profiling endpoints, when the server serves the management endpoints.
+func (s *promHttpServer) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	logRoute := func(route string) string {
+		s.cfg.WriteInfoOrNot(fmt.Sprintf("http handler, method:Get, uri:%s, port:%d", route, s.port))
+		return route
+	}
+	if s.exporterHandler != nil {
+		mux.Handle(logRoute("/metrics"), s.allowed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !s.acquireScrape(r) {
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+			defer s.releaseScrape()
+			sw := &statusWriter{ResponseWriter: w}
+			s.exporterHandler.ServeHTTP(sw, r)
+			if sw.Status() == http.StatusOK {
+				s.cfg.MarkExported()
+			}
+		})))
+	}
+	if !s.management {
+		return mux
+	}
+	mux.HandleFunc(logRoute("/actuator/health"), s.healthCheck)
+	mux.Handle(logRoute("/actuator/metrics-config"), s.allowed(http.HandlerFunc(s.metricsConfig)))
+	mux.Handle(logRoute("/debug/pprof/"), s.allowed(http.HandlerFunc(pprof.Index)))
+	mux.Handle(logRoute("/debug/pprof/cmdline"), s.allowed(http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle(logRoute("/debug/pprof/profile"), s.allowed(http.HandlerFunc(pprof.Profile)))
+	mux.Handle(logRoute("/debug/pprof/symbol"), s.allowed(http.HandlerFunc(pprof.Symbol)))
+	mux.Handle(logRoute("/debug/pprof/trace"), s.allowed(http.HandlerFunc(pprof.Trace)))
+	return mux
+}
+
+// handler returns the routes of the server, logged when the access log is enabled.
+func (s *promHttpServer) handler() http.Handler {
+	routes := s.routes()
+	if !s.cfg.AccessLog {
+		return routes
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := s.cfg.GetClock().Now()
+		sw := &statusWriter{ResponseWriter: w}
+		routes.ServeHTTP(sw, r)
+		entry := config.AccessLogEntry{
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Route:      r.Pattern,
+			RemoteAddr: r.RemoteAddr,
+			Status:     sw.Status(),
+			Size:       sw.size,
+			Duration:   s.cfg.GetClock().Since(start),
+		}
+		s.cfg.WriteInfoOrNot(fmt.Sprintf("access: %s %s from %s, status:%d, size:%d, duration:%s",
+			entry.Method, entry.Path, entry.RemoteAddr, entry.Status, entry.Size, entry.Duration))
+		if s.cfg.AccessLogHook != nil {
+			s.cfg.AccessLogHook(entry)
+		}
+		if s.observe != nil {
+			s.observe(entry)
+		}
+	})
+}
+
+// acquireScrape waits for a slot among the concurrent scrapes, if they are bounded, and reports whether it got one
+// before the request was canceled.
+func (s *promHttpServer) acquireScrape(r *http.Request) bool {
+	if s.scrapes == nil {
+		return true
+	}
+	select {
+	case s.scrapes <- struct{}{}:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// releaseScrape releases the slot of a scrape.
+func (s *promHttpServer) releaseScrape() {
+	if s.scrapes != nil {
+		<-s.scrapes
+	}
+}
+
+// allowed restricts next to the clients whose address is in the scrape allowlist, every client when it is empty.
+// The address is the one of the peer of the connection, the forwarding headers being under the control of the client.
+func (s *promHttpServer) allowed(next http.Handler) http.Handler {
+	if len(s.allowlist) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		if addr, err := netip.ParseAddr(host); err == nil {
+			addr = addr.Unmap()
+			for _, prefix := range s.allowlist {
+				if prefix.Contains(addr) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+		s.cfg.WriteDebugOrNot("refused request of " + r.RemoteAddr + " to " + r.URL.Path + ", not in the scrape allowlist")
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	})
+}
+
 // Stop halts the promHTTP server operation by setting its running state to stopped, logging the action, and signaling the close channel to initiate a shutdown sequence.
 func (s *promHttpServer) Stop() {
 	if !atomic.CompareAndSwapInt32(&s.running, 1, 0) {
@@ -90,25 +241,136 @@ func (s *promHttpServer) Stop() {
 		return
 	}
 	s.cfg.WriteInfoOrNot("stopping prom http server")
+	if s.fallback {
+		s.cfg.SetListenAddr("")
+	}
+	s.addr.Store("")
 	s.closeCh <- struct{}{}
 }
 
+// State returns the kind of the server, the address it is bound to, empty while it does not listen, and whether it
+// is running.
+func (s *promHttpServer) State() config.ServerState {
+	kind := config.ServerKindMetrics
+	if s.exporterHandler == nil {
+		kind = config.ServerKindManagement
+	}
+	addr, _ := s.addr.Load().(string)
+	return config.ServerState{
+		Kind:    kind,
+		Addr:    addr,
+		Running: atomic.LoadInt32(&s.running) == 1,
+	}
+}
+
+// Flush does nothing for the HTTP server, metrics are pulled by the scraper on its own schedule.
+func (s *promHttpServer) Flush(_ context.Context) error {
+	return nil
+}
+
+// listen binds the port of the server. When it is taken, the metrics server retries on the ports of the configured
+// fallback range then on an ephemeral port if enabled, and records the address bound with config.SetListenAddr.
+func (s *promHttpServer) listen() (net.Listener, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err == nil || !s.fallback {
+		if err == nil && s.fallback {
+			s.cfg.SetListenAddr(listener.Addr().String())
+		}
+		return listener, err
+	}
+	candidates := make([]int, 0)
+	if from, to := s.cfg.HTTPServer.PortFallbackFrom, s.cfg.HTTPServer.PortFallbackTo; from > 0 {
+		for port := from; port <= to; port++ {
+			if port != s.port {
+				candidates = append(candidates, port)
+			}
+		}
+	}
+	if s.cfg.HTTPServer.EphemeralPortFallback {
+		candidates = append(candidates, 0)
+	}
+	for _, port := range candidates {
+		fallback, fallbackErr := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if fallbackErr != nil {
+			continue
+		}
+		s.cfg.WriteErrorOrNot(fmt.Sprintf("prom http server port %d is unavailable (%s), falling back to %s",
+			s.port, err.Error(), fallback.Addr().String()))
+		s.cfg.SetListenAddr(fallback.Addr().String())
+		return fallback, nil
+	}
+	return nil, err
+}
+
 // startHTTPServer initiates the HTTP server to serve Prometheus metrics and other endpoints.
-// It listens on the configured PrometheusPort and handles errors during startup, logging them accordingly.
-func (s *promHttpServer) startHTTPServer() {
-	s.cfg.WriteInfoOrNot("prom http server listen and server on: " + strconv.Itoa(s.cfg.PrometheusPort))
-	err := s.server.ListenAndServe()
+// It serves on the listener bound by Start and handles errors, logging them accordingly.
+func (s *promHttpServer) startHTTPServer(listener net.Listener) {
+	s.cfg.WriteInfoOrNot("prom http server listen and server on: " + listener.Addr().String())
+	err := s.server.Serve(listener)
 	if err != nil && !errors.Is(err, http.ErrServerClosed) {
-		s.cfg.WriteErrorOrNot(fmt.Sprintf("faield to start prom http server on : %d with error: %s ",
-			s.cfg.PrometheusPort, err.Error()))
+		s.cfg.WriteErrorOrNot(fmt.Sprintf("faield to start prom http server on : %s with error: %s ",
+			listener.Addr().String(), err.Error()))
 	}
 }
 
 // healthCheck responds to HTTP requests with a JSON message indicating the service status is "UP".
 // It sets the "Content-Type" header to "application/json" and marshals a simple JSON object with a "status" field.
-// This endpoint is typically used to check the availability of the service.
+// This endpoint is typically used to check the availability of the service. With the readiness gate enabled, it
+// responds "DOWN" with the status 503 until the metrics are scraped or pushed successfully for the first time.
 func (s *promHttpServer) healthCheck(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("content-type", "text/json")
-	msg, _ := json.Marshal(map[string]interface{}{"status": "UP"})
+	status := "UP"
+	if !s.cfg.Ready() {
+		status = "DOWN"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	msg, _ := json.Marshal(map[string]interface{}{"status": status})
 	_, _ = w.Write(msg)
 }
+
+// statusWriter captures the status code and the size of the response written by a handler.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+// Write counts the bytes of the response.
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Status returns the written status code, 200 if the handler wrote nothing.
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// WriteHeader records the status code before writing it.
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// metricsConfig responds with the effective configuration of the meter as JSON, the secrets being redacted,
+// to debug mis-deployed services.
+func (s *promHttpServer) metricsConfig(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("content-type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(s.cfg.Describe())
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessGate(t *testing.T) {
+	cfg := &config.Config{ReadinessGate: true, InfoLogWrite: func(string) {}}
+	exporter := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("# metrics\n"))
+	})
+	mux := NewPromHttpServer(cfg, exporter, nil).(*promHttpServer).routes()
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, get("/actuator/health"))
+	assert.Equal(t, http.StatusOK, get("/metrics"))
+	assert.Equal(t, http.StatusOK, get("/actuator/health"))
+}
+
+func TestManagementPort(t *testing.T) {
+	cfg := &config.Config{PrometheusPort: 9464, ManagementPort: 9465, InfoLogWrite: func(string) {}}
+	exporter := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {})
+	get := func(mux *http.ServeMux, path string) int {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	metrics := NewPromHttpServer(cfg, exporter, nil).(*promHttpServer).routes()
+	assert.Equal(t, http.StatusOK, get(metrics, "/metrics"))
+	assert.Equal(t, http.StatusNotFound, get(metrics, "/actuator/health"))
+	assert.Equal(t, http.StatusNotFound, get(metrics, "/debug/pprof/"))
+
+	management := NewManagementServer(cfg, nil).(*promHttpServer).routes()
+	assert.Equal(t, http.StatusNotFound, get(management, "/metrics"))
+	assert.Equal(t, http.StatusOK, get(management, "/actuator/health"))
+	assert.Equal(t, http.StatusOK, get(management, "/actuator/metrics-config"))
+}
+
+func TestScrapeAllowlist(t *testing.T) {
+	cfg := &config.Config{ScrapeAllowlist: []string{"10.1.0.0/16", "192.0.2.7"}, InfoLogWrite: func(string) {}}
+	exporter := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {})
+	mux := NewPromHttpServer(cfg, exporter, nil).(*promHttpServer).routes()
+	get := func(path, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get("/metrics", "10.1.2.3:40000"))
+	assert.Equal(t, http.StatusOK, get("/metrics", "[::ffff:192.0.2.7]:40000"))
+	assert.Equal(t, http.StatusForbidden, get("/metrics", "192.0.2.8:40000"))
+	assert.Equal(t, http.StatusForbidden, get("/debug/pprof/", "172.16.0.1:40000"))
+	assert.Equal(t, http.StatusOK, get("/actuator/health", "172.16.0.1:40000"))
+
+	_, err := (&config.Config{ScrapeAllowlist: []string{"10.0.0.0/33"}}).ParseScrapeAllowlist()
+	assert.ErrorIs(t, err, config.ErrInvalidAllowlist)
+}
+
+func TestMaxConcurrentScrapes(t *testing.T) {
+	cfg := &config.Config{HTTPServer: config.HTTPServerCfg{MaxConcurrentScrapes: 1}, InfoLogWrite: func(string) {}}
+	started, release := make(chan struct{}), make(chan struct{})
+	exporter := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		started <- struct{}{}
+		<-release
+	})
+	mux := NewPromHttpServer(cfg, exporter, nil).(*promHttpServer).routes()
+
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		done <- rec.Code
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil).WithContext(ctx))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "the second scrape waits for the first one")
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+}
+
+func TestAccessLog(t *testing.T) {
+	var logged []config.AccessLogEntry
+	cfg := &config.Config{AccessLog: true, InfoLogWrite: func(string) {}}
+	cfg.AccessLogHook = func(entry config.AccessLogEntry) {
+		logged = append(logged, entry)
+	}
+	exporter := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("up 1\n"))
+	})
+	var observed int
+	handler := NewPromHttpServer(cfg, exporter, func(config.AccessLogEntry) { observed++ }).(*promHttpServer).handler()
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/random/123", nil))
+
+	require.Len(t, logged, 2)
+	assert.Equal(t, "/metrics", logged[0].Path)
+	assert.Equal(t, "/metrics", logged[0].Route)
+	assert.Equal(t, "10.0.0.1:5000", logged[0].RemoteAddr)
+	assert.Equal(t, http.StatusOK, logged[0].Status)
+	assert.Equal(t, int64(5), logged[0].Size)
+	assert.Equal(t, "/random/123", logged[1].Path)
+	assert.Empty(t, logged[1].Route, "no route matched the request")
+	assert.Equal(t, http.StatusNotFound, logged[1].Status)
+	assert.Equal(t, 2, observed)
+}
+
+func TestPortFallback(t *testing.T) {
+	taken, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer taken.Close()
+	port := taken.Addr().(*net.TCPAddr).Port
+
+	cfg := &config.Config{
+		PrometheusPort: port,
+		HTTPServer:     config.HTTPServerCfg{EphemeralPortFallback: true},
+		InfoLogWrite:   func(string) {},
+		ErrorLogWrite:  func(string) {},
+	}
+	exporter := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {})
+	s := NewPromHttpServer(cfg, exporter, nil)
+	s.Start()
+	addr := cfg.ListenAddr()
+	require.NotEmpty(t, addr)
+	_, fallbackPort, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	assert.NotEqual(t, strconv.Itoa(port), fallbackPort)
+
+	resp, err := http.Get("http://127.0.0.1:" + fallbackPort + "/metrics")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	s.Stop()
+
+	cfg.HTTPServer.EphemeralPortFallback = false
+	s = NewPromHttpServer(cfg, exporter, nil)
+	s.Start()
+	assert.Empty(t, cfg.ListenAddr())
+}
+
+func TestReportServer(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var announcement config.Announcement
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&announcement))
+		mu.Lock()
+		calls = append(calls, r.Method+" "+announcement.Service+" "+announcement.Instance+announcement.Path)
+		mu.Unlock()
+	}))
+	defer metadata.Close()
+
+	cfg := &config.Config{
+		LocalIP:       "10.0.0.7",
+		ReportMetric:  &config.ReportMetricCfg{Address: metadata.URL, Service: "orders"},
+		InfoLogWrite:  func(string) {},
+		ErrorLogWrite: func(string) {},
+	}
+	require.NoError(t, cfg.Validate())
+	cfg.SetListenAddr("[::]:9464")
+	s := NewReportServer(cfg)
+	s.Start()
+	assert.True(t, s.State().Running)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(calls) == 1
+	}, time.Second, 5*time.Millisecond)
+	s.Stop()
+
+	assert.Equal(t, []string{
+		"POST orders 10.0.0.7:9464/metrics",
+		"DELETE orders 10.0.0.7:9464/metrics",
+	}, calls)
+	assert.False(t, s.State().Running)
+}
@@ -1,69 +1,279 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"github.com/liangweijiang/go-metric/internal/export"
 	"github.com/liangweijiang/go-metric/pkg/config"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/utils"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+	"net/http"
+	"strings"
 	"sync/atomic"
 	"time"
 )
 
+// promPushGatewayServer periodically pushes the gathered metrics to a Prometheus push gateway.
+// The push loop is bound to a context derived from the configured one, cancelling it performs a final best-effort push.
+// With an export queue, the push loop gathers the metrics and a sender pushes them, the gatherings not fitting in the
+// queue being dropped. The metrics rejected by the gateway are quarantined, the others keep being pushed.
 type promPushGatewayServer struct {
-	cfg     *config.Config
-	pusher  *push.Pusher
-	running int32
-	closeCh chan struct{}
+	cfg        *config.Config
+	gatherer   prometheus.Gatherer
+	client     *export.Client
+	quarantine *export.Quarantine
+	queue      chan []*dto.MetricFamily
+	onDrop     func()
+	running    int32
+	cancel     context.CancelFunc
+	doneCh     chan struct{}
+	window     time.Time
 }
 
-func NewPromPushGatewayServer(cfg *config.Config, g prometheus.Gatherer) interfaces.MeterServer {
+// NewPromPushGatewayServer creates a server pushing the metrics gathered from g to the configured gateway, through the
+// configured export pipeline. onDrop, which may be nil, is called for every gathering dropped because the export queue
+// was full, e.g. to record self-metrics.
+func NewPromPushGatewayServer(cfg *config.Config, g prometheus.Gatherer, onDrop func()) interfaces.MeterServer {
 	pushServer := promPushGatewayServer{
-		cfg:     cfg,
-		running: 0,
-		closeCh: make(chan struct{}),
+		cfg:        cfg,
+		gatherer:   g,
+		client:     export.NewClient(cfg, nil),
+		quarantine: export.NewQuarantine(),
+		onDrop:     onDrop,
+		running:    0,
+	}
+	if cfg.Export != nil && cfg.Export.QueueSize > 0 {
+		pushServer.queue = make(chan []*dto.MetricFamily, cfg.Export.QueueSize)
 	}
-	pushServer.pusher = push.New(cfg.PushGateway.GatewayAddress, cfg.LocalIP).Gatherer(g)
 
 	return &pushServer
 }
 
+// ProbePushGateway checks that the configured push gateway answers on its health endpoint within the probe timeout.
+// It returns nil without probing when the probe is not enabled, otherwise an error wrapping config.ErrGatewayUnreachable.
+func ProbePushGateway(cfg *config.Config) error {
+	if !cfg.PushGateway.Enabled() || cfg.PushGateway.ProbeTimeout <= 0 {
+		return nil
+	}
+	u, err := cfg.PushGateway.GatewayURL()
+	if err != nil {
+		return err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/-/healthy"
+
+	ctx, cancel := context.WithTimeout(cfg.GetContext(), cfg.PushGateway.ProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", config.ErrGatewayUnreachable, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", config.ErrGatewayUnreachable, err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s returned status %d", config.ErrGatewayUnreachable, u.String(), resp.StatusCode)
+	}
+	cfg.WriteInfoOrNot("push gateway probe succeeded: " + u.String())
+	return nil
+}
+
+// Start launches the push loop bound to a context derived from the configured one.
 func (s *promPushGatewayServer) Start() {
 	if !(atomic.CompareAndSwapInt32(&s.running, 0, 1)) {
 		return
 	}
-	go s.push()
+	ctx, cancel := context.WithCancel(s.cfg.GetContext())
+	s.cancel = cancel
+	s.doneCh = make(chan struct{})
+	go s.push(ctx, s.doneCh)
 }
 
+// Stop cancels the push loop and waits for its final push to complete.
 func (s *promPushGatewayServer) Stop() {
 	if !(atomic.CompareAndSwapInt32(&s.running, 1, 0)) {
 		return
 	}
-	s.closeCh <- struct{}{}
+	s.cancel()
+	<-s.doneCh
 }
 
-func (s *promPushGatewayServer) push() {
-	pushTicker := time.NewTicker(s.cfg.PushGateway.PushPeriod)
-	defer pushTicker.Stop()
+// Flush pushes the current metrics to the gateway immediately, independently of the push period.
+func (s *promPushGatewayServer) Flush(ctx context.Context) error {
+	return s.pushOnce(ctx)
+}
 
-	now := time.Now()
-	if err := s.pusher.Push(); err != nil {
-		s.cfg.WriteErrorOrNot("failed to push to gateway: " + err.Error())
-	} else {
-		s.cfg.WriteInfoOrNot(fmt.Sprintf("successfully pushed to gateway, tick = %s, now = %s", time.Now().Sub(now), time.Now().Local().String()))
+// State returns the kind of the server, the redacted address of the gateway and whether the push loop is running.
+func (s *promPushGatewayServer) State() config.ServerState {
+	return config.ServerState{
+		Kind:    config.ServerKindPushGateway,
+		Addr:    s.cfg.PushGateway.RedactedAddress(),
+		Running: atomic.LoadInt32(&s.running) == 1,
 	}
+}
+
+// push pushes the metrics every push period, randomized by the configured jitter, at most once per resolution window if configured,
+// until ctx is done, then performs a final push bounded by the configured final push timeout so the last interval of data is not lost,
+// and closes doneCh.
+func (s *promPushGatewayServer) push(ctx context.Context, doneCh chan struct{}) {
+	defer close(doneCh)
+	pushTimer := s.cfg.GetClock().NewTimer(utils.Jitter(s.cfg.GetPushPeriod(), s.cfg.TickerJitter))
+	defer pushTimer.Stop()
+
+	var sentCh chan struct{}
+	if s.queue != nil {
+		sentCh = make(chan struct{})
+		go s.send(ctx, sentCh)
+	}
+
+	s.pushWindow(ctx)
 	for {
 		select {
-		case <-pushTicker.C:
-			now = time.Now()
-			if err := s.pusher.Push(); err != nil {
-				s.cfg.WriteErrorOrNot("failed to push to gateway: " + err.Error())
-			} else {
-				s.cfg.WriteInfoOrNot(fmt.Sprintf("successfully pushed to gateway, tick = %s, now = %s", time.Now().Sub(now), time.Now().Local().String()))
+		case <-pushTimer.C():
+			s.pushWindow(ctx)
+			pushTimer.Reset(utils.Jitter(s.cfg.GetPushPeriod(), s.cfg.TickerJitter))
+		case <-ctx.Done():
+			// the loop may end because the configured context is done, allow Start to be called again.
+			atomic.CompareAndSwapInt32(&s.running, 1, 0)
+			if sentCh != nil {
+				<-sentCh
 			}
-		case <-s.closeCh:
+			finalCtx, cancel := context.WithTimeout(context.Background(), s.cfg.PushGateway.GetFinalPushTimeout())
+			_ = s.pushOnce(finalCtx)
+			cancel()
 			s.cfg.WriteInfoOrNot("push gateway server is closed")
 			return
 		}
 	}
 }
+
+// pushWindow pushes once per window of the configured resolution, aligned on the clock, the pushes in a window
+// already pushed in being skipped, or pushes every time without resolution.
+func (s *promPushGatewayServer) pushWindow(ctx context.Context) {
+	if resolution := s.cfg.PushGateway.Resolution; resolution > 0 {
+		window := s.cfg.GetClock().Now().Truncate(resolution)
+		if window.Equal(s.window) {
+			s.cfg.WriteDebugOrNot("already pushed in the current resolution window, skip pushing to gateway")
+			return
+		}
+		s.window = window
+	}
+	if s.queue != nil {
+		s.enqueue(ctx)
+		return
+	}
+	_ = s.pushOnce(ctx)
+}
+
+// send pushes the gatherings of the queue until ctx is done, then closes sentCh. The gatherings left in the queue
+// are superseded by the final push.
+func (s *promPushGatewayServer) send(ctx context.Context, sentCh chan struct{}) {
+	defer close(sentCh)
+	for {
+		select {
+		case mfs := <-s.queue:
+			_ = s.pushFamilies(ctx, mfs)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// enqueue gathers the metrics and queues them to be pushed, dropping them when the queue is full.
+func (s *promPushGatewayServer) enqueue(ctx context.Context) {
+	if !s.shouldPush(ctx) {
+		return
+	}
+	mfs, err := s.gatherer.Gather()
+	if err != nil {
+		s.cfg.WriteErrorOrNot("failed to gather metrics to push to gateway: " + err.Error())
+		return
+	}
+	select {
+	case s.queue <- mfs:
+	default:
+		s.cfg.WriteErrorOrNot(fmt.Sprintf("export queue of %d pushes is full, drop the metrics gathered", cap(s.queue)))
+		if s.onDrop != nil {
+			s.onDrop()
+		}
+	}
+}
+
+// shouldPush reports whether this replica pushes. Nothing is pushed when a leader hook is configured and this replica
+// is not the leader.
+func (s *promPushGatewayServer) shouldPush(ctx context.Context) bool {
+	if !s.cfg.PushGateway.ShouldPush(ctx) {
+		s.cfg.WriteDebugOrNot("not the push leader, skip pushing to gateway")
+		// the replicas which are not the leader never push, they must not hold the readiness gate.
+		s.cfg.MarkExported()
+		return false
+	}
+	return true
+}
+
+// pushBatch pushes the batch of metric families, replacing the metrics of the group if first, otherwise adding them.
+func (s *promPushGatewayServer) pushBatch(ctx context.Context, batch []*dto.MetricFamily, first bool) error {
+	pusher := push.New(s.cfg.PushGateway.GatewayAddress, s.cfg.LocalIP).
+		Gatherer(prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) { return batch, nil })).
+		Client(s.client)
+	if first {
+		return pusher.PushContext(ctx)
+	}
+	return pusher.AddContext(ctx)
+}
+
+// Quarantined returns the sorted names of the metrics rejected by the gateway, which are no longer pushed.
+func (s *promPushGatewayServer) Quarantined() []string {
+	return s.quarantine.Names()
+}
+
+// pushOnce pushes the gathered metrics to the gateway once and logs the outcome.
+// Nothing is pushed when a leader hook is configured and this replica is not the leader.
+func (s *promPushGatewayServer) pushOnce(ctx context.Context) error {
+	if !s.shouldPush(ctx) {
+		return nil
+	}
+	mfs, err := s.gatherer.Gather()
+	if err != nil {
+		s.cfg.WriteErrorOrNot("failed to gather metrics to push to gateway: " + err.Error())
+		return err
+	}
+	return s.pushFamilies(ctx, mfs)
+}
+
+// pushFamilies pushes the metric families to the gateway in batches of the configured size, the first replacing the
+// metrics of the group and the others added to it, and logs the outcome. The metrics of a batch rejected by the
+// gateway are quarantined and the rest of the batch pushed again.
+func (s *promPushGatewayServer) pushFamilies(ctx context.Context, mfs []*dto.MetricFamily) error {
+	now := s.cfg.GetClock().Now()
+	batchSize := 0
+	if s.cfg.Export != nil {
+		batchSize = s.cfg.Export.BatchSize
+	}
+	for i, batch := range export.Batches(s.quarantine.Filter(mfs), batchSize) {
+		for {
+			err := s.pushBatch(ctx, batch, i == 0)
+			if err == nil {
+				break
+			}
+			var quarantined []string
+			if export.IsRejection(err) {
+				quarantined = s.quarantine.Add(batch, err.Error())
+			}
+			if len(quarantined) == 0 {
+				s.cfg.WriteErrorOrNot("failed to push to gateway: " + err.Error())
+				return err
+			}
+			s.cfg.WriteErrorOrNot("push gateway rejected metrics " + strings.Join(quarantined, ", ") +
+				", quarantined until restart: " + err.Error())
+			batch = s.quarantine.Filter(batch)
+		}
+	}
+	s.cfg.MarkExported()
+	s.cfg.WriteInfoOrNot(fmt.Sprintf("successfully pushed to gateway, tick = %s, now = %s", s.cfg.GetClock().Since(now), s.cfg.GetClock().Now().Local().String()))
+	return nil
+}
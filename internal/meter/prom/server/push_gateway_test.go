@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushResolution(t *testing.T) {
+	var pushes int32
+	gateway := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		atomic.AddInt32(&pushes, 1)
+	}))
+	defer gateway.Close()
+
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 50, 0, time.UTC))
+	cfg := &config.Config{
+		Clock:        fake,
+		LocalIP:      "10.0.0.7",
+		PushGateway:  &config.PushGatewayCfg{GatewayAddress: gateway.URL, PushPeriod: 15 * time.Second, Resolution: time.Minute},
+		InfoLogWrite: func(string) {},
+	}
+	pushServer := NewPromPushGatewayServer(cfg, prometheus.NewRegistry(), nil).(*promPushGatewayServer)
+	ctx := context.Background()
+
+	pushServer.pushWindow(ctx)
+	fake.Advance(5 * time.Second)
+	pushServer.pushWindow(ctx)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&pushes), "pushed once in the window")
+
+	fake.Advance(15 * time.Second)
+	pushServer.pushWindow(ctx)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&pushes), "pushed again in the next window")
+
+	assert.NoError(t, pushServer.Flush(ctx))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&pushes), "flush always pushes")
+}
+
+func TestPushBatches(t *testing.T) {
+	var mu sync.Mutex
+	var methods []string
+	gateway := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method+" "+r.Header.Get("Content-Encoding"))
+		mu.Unlock()
+	}))
+	defer gateway.Close()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "orders_total"}),
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "refunds_total"}))
+	cfg := &config.Config{
+		LocalIP:      "10.0.0.7",
+		PushGateway:  &config.PushGatewayCfg{GatewayAddress: gateway.URL, PushPeriod: time.Minute},
+		Export:       &config.ExportCfg{BatchSize: 1, Compression: config.CompressionGzip},
+		InfoLogWrite: func(string) {},
+	}
+	pushServer := NewPromPushGatewayServer(cfg, registry, nil)
+	assert.NoError(t, pushServer.Flush(context.Background()))
+	assert.Equal(t, []string{"PUT gzip", "POST gzip"}, methods, "the first batch replaces the group, the others are added")
+}
+
+func TestPushFinalPush(t *testing.T) {
+	tests := []struct {
+		name string
+		stop func(s *promPushGatewayServer, cancel context.CancelFunc)
+	}{
+		{
+			name: "Stop",
+			stop: func(s *promPushGatewayServer, _ context.CancelFunc) { s.Stop() },
+		},
+		{
+			name: "ContextDone",
+			stop: func(_ *promPushGatewayServer, cancel context.CancelFunc) { cancel() },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pushes int32
+			gateway := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				atomic.AddInt32(&pushes, 1)
+			}))
+			defer gateway.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cfg := &config.Config{
+				Context:       ctx,
+				LocalIP:       "10.0.0.7",
+				PushGateway:   &config.PushGatewayCfg{GatewayAddress: gateway.URL, PushPeriod: time.Hour},
+				InfoLogWrite:  func(string) {},
+				ErrorLogWrite: func(string) {},
+			}
+			pushServer := NewPromPushGatewayServer(cfg, prometheus.NewRegistry(), nil).(*promPushGatewayServer)
+			pushServer.Start()
+			assert.Eventually(t, func() bool { return atomic.LoadInt32(&pushes) == 1 }, time.Second, time.Millisecond,
+				"pushed when started")
+
+			tt.stop(pushServer, cancel)
+			assert.Eventually(t, func() bool { return atomic.LoadInt32(&pushes) == 2 }, time.Second, time.Millisecond,
+				"pushed a last time when stopped")
+			assert.Eventually(t, func() bool { return !pushServer.State().Running }, time.Second, time.Millisecond)
+		})
+	}
+}
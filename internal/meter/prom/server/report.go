@@ -0,0 +1,165 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/utils"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// reportServer announces the scrape endpoint of the meter to a metadata service when started, re-announces it every
+// configured interval, and withdraws it when stopped. It must be started after the metrics server, whose bound address
+// is announced.
+type reportServer struct {
+	cfg       *config.Config
+	client    *http.Client
+	running   int32
+	announced atomic.Value
+	cancel    context.CancelFunc
+	doneCh    chan struct{}
+}
+
+// NewReportServer creates a server announcing the scrape endpoint of the meter to the configured metadata service.
+func NewReportServer(cfg *config.Config) interfaces.MeterServer {
+	return &reportServer{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.ReportMetric.GetTimeout()},
+	}
+}
+
+// Start launches the announcement loop bound to a context derived from the configured one.
+func (s *reportServer) Start() {
+	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+		return
+	}
+	ctx, cancel := context.WithCancel(s.cfg.GetContext())
+	s.cancel = cancel
+	s.doneCh = make(chan struct{})
+	go s.report(ctx, s.doneCh)
+}
+
+// Stop cancels the announcement loop and waits for the endpoint to be withdrawn.
+func (s *reportServer) Stop() {
+	if !atomic.CompareAndSwapInt32(&s.running, 1, 0) {
+		return
+	}
+	s.cancel()
+	<-s.doneCh
+}
+
+// Flush returns nil, the server does not export metrics.
+func (s *reportServer) Flush(context.Context) error {
+	return nil
+}
+
+// State returns the kind of the server, the redacted address of the metadata service and whether it is running.
+func (s *reportServer) State() config.ServerState {
+	return config.ServerState{
+		Kind:    config.ServerKindReport,
+		Addr:    s.cfg.ReportMetric.RedactedAddress(),
+		Running: atomic.LoadInt32(&s.running) == 1,
+	}
+}
+
+// report announces the endpoint, then again every interval, randomized by the configured jitter, if one is
+// configured, until ctx is done, then withdraws the announced endpoint within the request timeout and closes doneCh.
+func (s *reportServer) report(ctx context.Context, doneCh chan struct{}) {
+	defer close(doneCh)
+	_ = s.announce(ctx)
+
+	var timer clock.Timer
+	var renew <-chan time.Time
+	interval := s.cfg.ReportMetric.Interval
+	if interval > 0 {
+		timer = s.cfg.GetClock().NewTimer(utils.Jitter(interval, s.cfg.TickerJitter))
+		defer timer.Stop()
+		renew = timer.C()
+	}
+	for {
+		select {
+		case <-renew:
+			_ = s.announce(ctx)
+			timer.Reset(utils.Jitter(interval, s.cfg.TickerJitter))
+		case <-ctx.Done():
+			// the loop may end because the configured context is done, allow Start to be called again.
+			atomic.CompareAndSwapInt32(&s.running, 1, 0)
+			withdrawCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ReportMetric.GetTimeout())
+			_ = s.withdraw(withdrawCtx)
+			cancel()
+			return
+		}
+	}
+}
+
+// announce sends the current scrape endpoint to the metadata service, and remembers it to be withdrawn.
+func (s *reportServer) announce(ctx context.Context) error {
+	instance, ok := s.cfg.ScrapeEndpoint()
+	if !ok {
+		err := fmt.Errorf("no scrape endpoint to report, listen address %q, local ip %q", s.cfg.ListenAddr(), s.cfg.LocalIP)
+		s.cfg.WriteErrorOrNot("failed to announce to metadata service: " + err.Error())
+		return err
+	}
+	announcement := s.announcement(instance)
+	if err := s.send(ctx, http.MethodPost, announcement); err != nil {
+		s.cfg.WriteErrorOrNot("failed to announce to metadata service: " + err.Error())
+		return err
+	}
+	s.announced.Store(announcement)
+	s.cfg.WriteInfoOrNot("announced scrape endpoint " + instance + " to metadata service")
+	return nil
+}
+
+// withdraw removes the announced scrape endpoint from the metadata service, if one was announced.
+func (s *reportServer) withdraw(ctx context.Context) error {
+	announcement, ok := s.announced.Load().(config.Announcement)
+	if !ok || announcement.Instance == "" {
+		return nil
+	}
+	if err := s.send(ctx, http.MethodDelete, announcement); err != nil {
+		s.cfg.WriteErrorOrNot("failed to withdraw from metadata service: " + err.Error())
+		return err
+	}
+	s.announced.Store(config.Announcement{})
+	s.cfg.WriteInfoOrNot("withdrew scrape endpoint " + announcement.Instance + " from metadata service")
+	return nil
+}
+
+// announcement returns the announcement of the scrape endpoint instance.
+func (s *reportServer) announcement(instance string) config.Announcement {
+	return config.Announcement{
+		Service:  s.cfg.ReportMetric.Service,
+		Instance: instance,
+		Path:     "/metrics",
+		Env:      s.cfg.Env,
+		Tags:     s.cfg.BaseTags,
+	}
+}
+
+// send sends the announcement to the metadata service with the given method, a status other than 2xx being an error.
+func (s *reportServer) send(ctx context.Context, method string, announcement config.Announcement) error {
+	body, err := json.Marshal(announcement)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.cfg.ReportMetric.Address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%s %s returned status %d", method, s.cfg.ReportMetric.RedactedAddress(), resp.StatusCode)
+	}
+	return nil
+}
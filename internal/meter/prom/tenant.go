@@ -0,0 +1,69 @@
+package prom
+
+import (
+	"github.com/liangweijiang/go-metric/pkg/config"
+	cliprom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"net/http"
+)
+
+// newTenantHandler returns the handler of the metrics endpoint serving each request the series of the tenant resolved
+// by cfg.ScrapeFilter, requests the filter rejects are answered with 401 Unauthorized.
+func newTenantHandler(cfg *config.Config, gatherer cliprom.Gatherer, opts promhttp.HandlerOpts) http.Handler {
+	all := promhttp.HandlerFor(gatherer, opts)
+	label := cfg.GetScrapeTenantLabel()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant, err := cfg.ScrapeFilter(r)
+		if err != nil {
+			cfg.WriteErrorOrNot("rejected scrape of " + r.RemoteAddr + ": " + err.Error())
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		if tenant == "" {
+			all.ServeHTTP(w, r)
+			return
+		}
+		promhttp.HandlerFor(&tenantGatherer{
+			Gatherer: gatherer,
+			label:    label,
+			tenant:   tenant,
+		}, opts).ServeHTTP(w, r)
+	})
+}
+
+// tenantGatherer keeps the series whose label holds the tenant, dropping the families left empty.
+type tenantGatherer struct {
+	cliprom.Gatherer
+	label  string
+	tenant string
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *tenantGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	filtered := mfs[:0]
+	for _, mf := range mfs {
+		metrics := mf.Metric[:0]
+		for _, m := range mf.Metric {
+			if g.matches(m) {
+				metrics = append(metrics, m)
+			}
+		}
+		if len(metrics) > 0 {
+			mf.Metric = metrics
+			filtered = append(filtered, mf)
+		}
+	}
+	return filtered, err
+}
+
+// matches reports whether the series is labeled with the tenant.
+func (g *tenantGatherer) matches(m *dto.Metric) bool {
+	for _, l := range m.GetLabel() {
+		if l.GetName() == g.label {
+			return l.GetValue() == g.tenant
+		}
+	}
+	return false
+}
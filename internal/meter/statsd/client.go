@@ -0,0 +1,68 @@
+package statsd
+
+import (
+	"net"
+	"sync"
+)
+
+// client buffers the StatsD lines in packets of at most maxPacketSize bytes, separated by newlines, and sends a
+// packet when the next line does not fit in it, and on flush.
+type client struct {
+	conn          net.Conn
+	maxPacketSize int
+	mu            sync.Mutex
+	buf           []byte
+	err           error
+}
+
+// newClient creates the client sending the packets to the UDP address. No packet is sent and no daemon needs to
+// listen on address when it is created.
+func newClient(address string, maxPacketSize int) (*client, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &client{
+		conn:          conn,
+		maxPacketSize: maxPacketSize,
+		buf:           make([]byte, 0, maxPacketSize),
+	}, nil
+}
+
+// write buffers line, sending the current packet first if line does not fit in it. A line larger than a packet is
+// sent alone.
+func (c *client) write(line string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.buf) > 0 && len(c.buf)+1+len(line) > c.maxPacketSize {
+		c.send()
+	}
+	if len(c.buf) > 0 {
+		c.buf = append(c.buf, '\n')
+	}
+	c.buf = append(c.buf, line...)
+	if len(c.buf) >= c.maxPacketSize {
+		c.send()
+	}
+}
+
+// flush sends the current packet, and returns the last error of the sends since the previous flush.
+func (c *client) flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.send()
+	err := c.err
+	c.err = nil
+	return err
+}
+
+// send sends the current packet if not empty, keeping the error for the next flush. c.mu must be held.
+func (c *client) send() {
+	if len(c.buf) == 0 {
+		return
+	}
+	if _, err := c.conn.Write(c.buf); err != nil {
+		c.err = err
+	}
+	c.buf = c.buf[:0]
+}
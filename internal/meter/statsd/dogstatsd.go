@@ -0,0 +1,77 @@
+package statsd
+
+import (
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"go.opentelemetry.io/otel/attribute"
+	"sort"
+	"strings"
+)
+
+// NewDogStatsDMeter creates the meter sending the measurements as DogStatsD lines to the Datadog agent configured by
+// cfg.StatsD, the default address if nil. The tags are sent as Datadog tags, e.g.
+// http_requests:1|c|#env:prod,method:GET, after the base tags, and the instance tags if they go to the resource. The
+// up/down counters are sent as counters, and the histograms as distributions if cfg.StatsD.Distributions is set.
+func NewDogStatsDMeter(cfg *config.Config) (*Meter, error) {
+	return newMeter(cfg, dialectDogStatsD)
+}
+
+// constantTags returns the Datadog tags sent with every measurement, sorted by key and joined by commas.
+func constantTags(cfg *config.Config) string {
+	tags := cfg.WithBaseTags()
+	if cfg.InstanceTagsInResource() {
+		for key, value := range cfg.InstanceTags() {
+			tags = append(tags, attribute.String(key, value))
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Key < tags[j].Key
+	})
+	var b strings.Builder
+	appendTags(&b, tags)
+	return b.String()
+}
+
+// dogStatsDLine returns the DogStatsD line of a measurement of value to the metric with the given tags.
+func (m *Meter) dogStatsDLine(name string, tags []attribute.KeyValue, value, typ string) string {
+	var b strings.Builder
+	b.WriteString(m.prefix)
+	b.WriteString(sanitize(name, false))
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(typ)
+	if m.constTags == "" && len(tags) == 0 {
+		return b.String()
+	}
+	b.WriteString("|#")
+	b.WriteString(m.constTags)
+	if m.constTags != "" && len(tags) > 0 {
+		b.WriteByte(',')
+	}
+	appendTags(&b, tags)
+	return b.String()
+}
+
+// appendTags writes the tags as comma-separated key:value Datadog tags.
+func appendTags(b *strings.Builder, tags []attribute.KeyValue) {
+	for i, kv := range tags {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(sanitizeTag(string(kv.Key)))
+		b.WriteByte(':')
+		b.WriteString(sanitizeTag(kv.Value.Emit()))
+	}
+}
+
+// sanitizeTag replaces the characters ending a Datadog tag, the commas, pipes and line breaks, by underscores.
+func sanitizeTag(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ',', '|', '\n', '\r':
+			return '_'
+		default:
+			return r
+		}
+	}, s)
+}
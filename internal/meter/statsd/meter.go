@@ -0,0 +1,451 @@
+// Package statsd implements the meters sending the measurements as StatsD lines over UDP, for the deployments running
+// a statsd daemon, a telegraf agent or a Datadog agent instead of scraping a Prometheus endpoint.
+package statsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/liangweijiang/go-metric/internal/component"
+	"github.com/liangweijiang/go-metric/internal/meter/core"
+	"github.com/liangweijiang/go-metric/internal/metrics/dual"
+	"github.com/liangweijiang/go-metric/internal/metrics/nop"
+	"github.com/liangweijiang/go-metric/internal/process"
+	"github.com/liangweijiang/go-metric/internal/registry"
+	"github.com/liangweijiang/go-metric/internal/runtime"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/semconv"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// _ is a blank identifier used for type assertion to ensure that *Meter implements the interfaces.Meter interface.
+var _ interfaces.Meter = (*Meter)(nil)
+
+// Meter is the meter of the StatsD and DogStatsD providers: every measurement is written as a StatsD line, the counters as
+// increments, the gauges as values, the up/down counters as gauge deltas and the histograms as timings in milliseconds
+// if they are in seconds or milliseconds, as histogram samples otherwise. The aggregation, and so the histogram
+// boundaries, are left to the daemon. The lines are buffered in packets sent every flush interval, when the observable
+// gauges are observed as well. It exposes no endpoint, and runs the runtime and process collectors while switched on.
+// See NewDogStatsDMeter for the lines of the DogStatsD provider.
+type Meter struct {
+	cfg         *config.Config
+	dialect     dialect
+	client      *client
+	prefix      string
+	constTags   string
+	registry    *registry.Registry
+	dropAuditor *registry.DropAuditor
+	collectors  []interfaces.MetricCollector
+	running     int32
+	started     int32
+	switchMu    sync.Mutex
+	loopMu      sync.Mutex
+	stopLoop    chan struct{}
+	loopDone    chan struct{}
+	mu          sync.Mutex
+	gauges      map[int]*observer
+	nextID      int
+}
+
+// NewStatsDMeter creates the meter sending the measurements to the daemon configured by cfg.StatsD, the default
+// address if nil.
+func NewStatsDMeter(cfg *config.Config) (*Meter, error) {
+	return newMeter(cfg, dialectStatsD)
+}
+
+// newMeter creates the meter sending the lines of the dialect to the daemon configured by cfg.StatsD.
+func newMeter(cfg *config.Config, d dialect) (*Meter, error) {
+	c, err := newClient(cfg.StatsD.GetAddress(), cfg.StatsD.GetMaxPacketSize())
+	if err != nil {
+		cfg.WriteErrorOrNot("failed to create " + d.String() + " client: " + err.Error())
+		return nil, fmt.Errorf("%w: %v", config.ErrExporterInit, err)
+	}
+	dropAuditor := registry.NewDropAuditor(cfg)
+	r := core.NewRegistry(cfg, dropAuditor)
+	r.SetPanicPolicy(cfg.GetPanicLimit(), func(kind, name string, recovered any, disabled bool) {
+		message := fmt.Sprintf("%s %s panicked: %v", kind, name, recovered)
+		if disabled {
+			message += ", disabled"
+		}
+		cfg.WriteErrorOrNot(message)
+	})
+	m := &Meter{
+		cfg:         cfg,
+		dialect:     d,
+		client:      c,
+		registry:    r,
+		dropAuditor: dropAuditor,
+		running:     1,
+		gauges:      make(map[int]*observer),
+	}
+	if cfg.StatsD != nil && cfg.StatsD.Prefix != "" {
+		m.prefix = sanitize(cfg.StatsD.Prefix, false) + "."
+	}
+	if d == dialectDogStatsD {
+		m.constTags = constantTags(cfg)
+	}
+	m.collectors = []interfaces.MetricCollector{
+		runtime.NewRuntimeCollector(cfg, m),
+		process.NewFDCollector(cfg, m),
+		process.NewDiskCollector(cfg, m),
+		process.NewUptimeCollector(cfg, m),
+	}
+	if !cfg.DeferStart {
+		m.start()
+	}
+	return m, nil
+}
+
+// start switches the meter on and starts the collectors and the flush loop, once.
+func (m *Meter) start() {
+	if !atomic.CompareAndSwapInt32(&m.started, 0, 1) {
+		return
+	}
+	// the meter may have been switched off before its deferred start.
+	atomic.StoreInt32(&m.running, 1)
+	for _, collector := range m.collectors {
+		collector.Start()
+	}
+	m.dropAuditor.Start()
+	m.startLoop()
+}
+
+// startLoop starts the goroutine flushing the lines every flush interval, unless it runs already. Every loop has its
+// own stop channel, so that a loop being stopped does not prevent the start of the next one.
+func (m *Meter) startLoop() {
+	m.loopMu.Lock()
+	defer m.loopMu.Unlock()
+	if m.stopLoop != nil {
+		return
+	}
+	m.stopLoop, m.loopDone = make(chan struct{}), make(chan struct{})
+	go m.loop(m.stopLoop, m.loopDone)
+}
+
+// endLoop stops the running flush loop, if any, and waits for its last flush.
+func (m *Meter) endLoop() {
+	m.loopMu.Lock()
+	stop, done := m.stopLoop, m.loopDone
+	m.stopLoop, m.loopDone = nil, nil
+	m.loopMu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// loop flushes the lines every flush interval until stop is closed, and a last time then, closing done on return.
+func (m *Meter) loop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(m.cfg.StatsD.GetFlushInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = m.Flush(context.Background())
+		case <-stop:
+			_ = m.Flush(context.Background())
+			return
+		}
+	}
+}
+
+// GetHandler returns nil, the StatsD meter exposes no endpoint.
+func (m *Meter) GetHandler() http.Handler {
+	return nil
+}
+
+// ServerInfo describes the StatsD meter, which sends the measurements to the daemon and runs no server.
+func (m *Meter) ServerInfo() config.ServerInfo {
+	return config.ServerInfo{
+		Provider:  m.cfg.MeterProvider.String(),
+		Running:   m.isRunning(),
+		Exporters: []string{m.dialect.String()},
+		Servers: []config.ServerState{{
+			Kind:    m.dialect.String(),
+			Addr:    m.cfg.StatsD.GetAddress(),
+			Running: m.isRunning(),
+		}},
+	}
+}
+
+// WithRunning switches the meter on or off, starting or stopping the collectors and the flush loop. The lines
+// buffered so far are sent when the meter is switched off. A meter whose start is deferred is started by the first
+// call with true, and only switched off by false before. The switches are serialized, so that the loop being stopped
+// by a switch off is never mistaken for the loop of the next switch on.
+func (m *Meter) WithRunning(on bool) {
+	m.switchMu.Lock()
+	defer m.switchMu.Unlock()
+	if atomic.LoadInt32(&m.started) == 0 {
+		if on {
+			m.start()
+		} else {
+			atomic.StoreInt32(&m.running, 0)
+		}
+		return
+	}
+	if on {
+		if !atomic.CompareAndSwapInt32(&m.running, 0, 1) {
+			return
+		}
+		m.cfg.WriteInfoOrNot(m.dialect.String() + " meter is started")
+		for _, collector := range m.collectors {
+			collector.Start()
+		}
+		m.dropAuditor.Start()
+		m.startLoop()
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&m.running, 1, 0) {
+		return
+	}
+	m.cfg.WriteInfoOrNot(m.dialect.String() + " meter is stopped")
+	for _, collector := range m.collectors {
+		collector.Stop()
+	}
+	m.dropAuditor.Stop()
+	m.endLoop()
+}
+
+// isRunning reports whether the meter is switched on.
+func (m *Meter) isRunning() bool {
+	return atomic.LoadInt32(&m.running) == 1
+}
+
+// DisableMetric stops sending the measurements of the metric with the given name.
+func (m *Meter) DisableMetric(metricName string) {
+	m.cfg.WriteInfoOrNot("disable metric: " + metricName)
+	m.registry.Disable(metricName)
+}
+
+// EnableMetric resumes sending the measurements of a metric disabled with DisableMetric.
+func (m *Meter) EnableMetric(metricName string) {
+	m.cfg.WriteInfoOrNot("enable metric: " + metricName)
+	m.registry.Enable(metricName)
+}
+
+// DeprecateMetric marks the metric with the given name as deprecated in favor of replacement, which may be empty: its
+// measurements are sent with the deprecated="true" tag from then on.
+func (m *Meter) DeprecateMetric(metricName, replacement string) {
+	message := "deprecate metric: " + metricName
+	if replacement != "" {
+		message += ", replaced by " + replacement
+	}
+	m.cfg.WriteInfoOrNot(message)
+	m.registry.Deprecate(metricName, replacement)
+}
+
+// Use appends interceptor to the interceptors of the measurements, which are sent as modified by them.
+func (m *Meter) Use(interceptor interfaces.Interceptor) {
+	m.registry.Use(interceptor)
+}
+
+// SetLogLevel changes the level of the SDK logging.
+func (m *Meter) SetLogLevel(level config.LogLevel) {
+	m.cfg.SetLogLevel(level)
+}
+
+// Flush observes the observable gauges and sends the lines buffered so far. The errors of the callbacks and of the
+// sends since the previous flush are logged and returned.
+func (m *Meter) Flush(ctx context.Context) error {
+	m.mu.Lock()
+	gauges := make([]*observer, 0, len(m.gauges))
+	for _, g := range m.gauges {
+		gauges = append(gauges, g)
+	}
+	m.mu.Unlock()
+	var errs []error
+	for _, g := range gauges {
+		if err := g.observe(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("observable gauge %s: %w", g.name, err))
+		}
+	}
+	if err := m.client.flush(); err != nil {
+		errs = append(errs, fmt.Errorf("send to %s: %w", m.cfg.StatsD.GetAddress(), err))
+	}
+	err := errors.Join(errs...)
+	if err != nil {
+		m.cfg.WriteErrorOrNot("failed to flush " + m.dialect.String() + " meter: " + err.Error())
+	}
+	return err
+}
+
+// NewCounter creates a Counter, sent as increments. During the transition of a renamed metric, the counter sends to
+// both its old and new names.
+func (m *Meter) NewCounter(metricName, desc, unit string) interfaces.Counter {
+	name, alias := m.registry.Rename(metricName)
+	if alias == "" {
+		return m.newCounter(name, desc, unit)
+	}
+	return dual.NewCounter(m.newCounter(name, desc, unit), m.newCounter(alias, desc, unit))
+}
+
+// newCounter creates the counter of the metric with the given name, see NewCounter.
+func (m *Meter) newCounter(metricName, desc, unit string) interfaces.Counter {
+	name, _, ok := m.admit("counter", metricName, desc, unit)
+	if !ok {
+		return nop.Counter
+	}
+	return &counter{instrument: m.newInstrument(interfaces.KindCounter, name, typeCounter)}
+}
+
+// NewUpDownCounter creates an UpDownCounter, sent as gauge deltas, or as signed increments with DogStatsD whose gauges
+// take no deltas.
+func (m *Meter) NewUpDownCounter(metricName, desc, unit string) interfaces.UpDownCounter {
+	name, alias := m.registry.Rename(metricName)
+	if alias == "" {
+		return m.newUpDownCounter(name, desc, unit)
+	}
+	return dual.NewUpDownCounter(m.newUpDownCounter(name, desc, unit), m.newUpDownCounter(alias, desc, unit))
+}
+
+// newUpDownCounter creates the up/down counter of the metric with the given name, see NewUpDownCounter.
+func (m *Meter) newUpDownCounter(metricName, desc, unit string) interfaces.UpDownCounter {
+	name, _, ok := m.admit("updowncounter", metricName, desc, unit)
+	if !ok {
+		return nop.UpDownCounter
+	}
+	if m.dialect == dialectDogStatsD {
+		return &upDownCounter{instrument: m.newInstrument(interfaces.KindUpDownCounter, name, typeCounter)}
+	}
+	return &upDownCounter{instrument: m.newInstrument(interfaces.KindUpDownCounter, name, typeGauge)}
+}
+
+// NewGauge creates a Gauge.
+func (m *Meter) NewGauge(metricName, desc, unit string) interfaces.Gauge {
+	name, alias := m.registry.Rename(metricName)
+	if alias == "" {
+		return m.newGauge(name, desc, unit)
+	}
+	return dual.NewGauge(m.newGauge(name, desc, unit), m.newGauge(alias, desc, unit))
+}
+
+// newGauge creates the gauge of the metric with the given name, see NewGauge.
+func (m *Meter) newGauge(metricName, desc, unit string) interfaces.Gauge {
+	name, _, ok := m.admit("gauge", metricName, desc, unit)
+	if !ok {
+		return nop.Gauge
+	}
+	return &gauge{instrument: m.newInstrument(interfaces.KindGauge, name, typeGauge)}
+}
+
+// NewHistogram creates a Histogram, sent as timings if its unit is seconds or milliseconds, or as a distribution in
+// its unit with DogStatsD if configured so. The histograms in seconds are guarded against values in milliseconds
+// against the configured boundaries.
+func (m *Meter) NewHistogram(metricName, desc, unit string) interfaces.Histogram {
+	name, alias := m.registry.Rename(metricName)
+	if alias == "" {
+		return m.newHistogram(name, desc, unit)
+	}
+	return dual.NewHistogram(m.newHistogram(name, desc, unit), m.newHistogram(alias, desc, unit))
+}
+
+// newHistogram creates the histogram of the metric with the given name, see NewHistogram.
+func (m *Meter) newHistogram(metricName, desc, unit string) interfaces.Histogram {
+	name, unit, ok := m.admit("histogram", metricName, desc, unit)
+	if !ok {
+		return nop.Histogram
+	}
+	m.registry.WatchUnit(name, unit, m.cfg.HistogramBoundaries)
+	h := &histogram{instrument: m.newInstrument(interfaces.KindHistogram, name, typeHistogram)}
+	if m.dialect == dialectDogStatsD && m.cfg.StatsD != nil && m.cfg.StatsD.Distributions {
+		h.typ = typeDistribution
+		return h
+	}
+	switch unit {
+	case "s":
+		h.typ, h.scale = typeTiming, 1000
+	case "ms":
+		h.typ = typeTiming
+	}
+	return h
+}
+
+// NewHistogramWithBuckets creates a Histogram like NewHistogram, the boundaries being those of the daemon.
+func (m *Meter) NewHistogramWithBuckets(metricName, desc, unit string, _ []float64) interfaces.Histogram {
+	return m.NewHistogram(metricName, desc, unit)
+}
+
+// NewSizeHistogram creates a Histogram in bytes.
+func (m *Meter) NewSizeHistogram(metricName, desc string) interfaces.Histogram {
+	return m.NewHistogram(metricName, desc, config.UnitBytes)
+}
+
+// NewCountHistogram creates a count Histogram.
+func (m *Meter) NewCountHistogram(metricName, desc string) interfaces.Histogram {
+	return m.NewHistogram(metricName, desc, config.UnitCount)
+}
+
+// NewObservableGauge registers callback, called on every flush. During the transition of a renamed metric, the gauge
+// is registered under both its old and new names, and callback is called once for each.
+func (m *Meter) NewObservableGauge(metricName, desc, unit string, callback interfaces.ObservableCallback) interfaces.Registration {
+	name, alias := m.registry.Rename(metricName)
+	if alias == "" {
+		return m.registerObservableGauge(name, desc, unit, callback)
+	}
+	return dual.NewRegistration(m.registerObservableGauge(name, desc, unit, callback),
+		m.registerObservableGauge(alias, desc, unit, callback))
+}
+
+// registerObservableGauge registers the callback of the metric with the given name, see NewObservableGauge.
+func (m *Meter) registerObservableGauge(metricName, desc, unit string, callback interfaces.ObservableCallback) interfaces.Registration {
+	name, _, ok := m.admit("observablegauge", metricName, desc, unit)
+	if !ok {
+		return nop.Registration
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := m.nextID
+	m.nextID++
+	m.gauges[id] = &observer{
+		meter:    m,
+		name:     name,
+		callback: callback,
+	}
+	return &registration{
+		meter: m,
+		id:    id,
+	}
+}
+
+// admit applies the configuration of the registry to the creation of an instrument of the kind, like the core meter:
+// the unit suffixes, the metrics dictionary, the name collision policy, the feature gate and the instrument budgets.
+// It returns the name and the unit to create the instrument with, and false if it must be refused.
+func (m *Meter) admit(kind, metricName, desc, unit string) (string, string, bool) {
+	if m.cfg.UnitSuffixes {
+		metricName = semconv.AppendUnitSuffix(metricName, unit, kind == "counter")
+	}
+	desc = m.registry.Enrich(metricName, desc)
+	name, id, ok := m.registry.Resolve(metricName, registry.Identity{Kind: kind, Unit: unit, Desc: desc})
+	gateKind := kind
+	if kind == "observablegauge" {
+		gateKind = "gauge"
+	}
+	if !ok || m.registry.Gated(gateKind, name, id.Unit) || !m.registry.AdmitBudget(name) {
+		return "", "", false
+	}
+	m.registry.Created(name)
+	return name, id.Unit, true
+}
+
+// newInstrument creates the common part of an instrument of the kind sending lines of type typ.
+func (m *Meter) newInstrument(kind, name, typ string) instrument {
+	return instrument{
+		meter: m,
+		kind:  kind,
+		name:  name,
+		typ:   typ,
+	}
+}
+
+// Components returns the standard components, whose measurements are sent like those of the other instruments.
+func (m *Meter) Components() interfaces.Components {
+	return component.NewComponents(func() interfaces.BaseMeter {
+		return m
+	})
+}
@@ -0,0 +1,361 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Types of the StatsD lines, the distributions being DogStatsD only.
+const (
+	typeCounter      = "c"
+	typeGauge        = "g"
+	typeTiming       = "ms"
+	typeHistogram    = "h"
+	typeDistribution = "d"
+)
+
+// dialect is the flavor of the StatsD lines sent by a meter.
+type dialect int
+
+const (
+	// dialectStatsD is the original StatsD syntax, the tags being segments of the names.
+	dialectStatsD dialect = iota
+	// dialectDogStatsD is the DogStatsD syntax, the tags following the type, see NewDogStatsDMeter.
+	dialectDogStatsD
+)
+
+// String returns the name of the provider of the dialect.
+func (d dialect) String() string {
+	if d == dialectDogStatsD {
+		return "dogstatsd"
+	}
+	return "statsd"
+}
+
+// line returns the line of a measurement of value to the series of the metric with the given tags.
+func (m *Meter) line(name string, tags []attribute.KeyValue, value, typ string) string {
+	if m.dialect == dialectDogStatsD {
+		return m.dogStatsDLine(name, tags, value, typ)
+	}
+	return m.key(name, tags) + ":" + value + "|" + typ
+}
+
+// key returns the name of the series of the metric with the given tags, the prefix and the name followed by the key
+// and the value of every tag, sorted by key, as dot-separated segments since StatsD has no tags, e.g.
+// app.http_requests.method.GET.
+func (m *Meter) key(name string, tags []attribute.KeyValue) string {
+	var b strings.Builder
+	b.WriteString(m.prefix)
+	b.WriteString(sanitize(name, false))
+	if len(tags) == 0 {
+		return b.String()
+	}
+	sorted := make([]attribute.KeyValue, len(tags))
+	copy(sorted, tags)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+	for _, kv := range sorted {
+		b.WriteByte('.')
+		b.WriteString(sanitize(string(kv.Key), true))
+		b.WriteByte('.')
+		b.WriteString(sanitize(kv.Value.Emit(), true))
+	}
+	return b.String()
+}
+
+// sanitize replaces the characters of the StatsD syntax and the spaces of s by underscores, and the dots as well if
+// segment is true, so that a tag makes a single segment of the name.
+func sanitize(s string, segment bool) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == ':' || r == '|' || r == '@' || r == '#' || r == ',' || unicode.IsSpace(r):
+			return '_'
+		case r == '.' && segment:
+			return '_'
+		default:
+			return r
+		}
+	}, s)
+}
+
+// formatValue formats v in the shortest representation, with a sign if signed is true.
+func formatValue(v float64, signed bool) string {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if signed && v >= 0 {
+		return "+" + s
+	}
+	return s
+}
+
+// instrument holds the tags of an instrument of the StatsD meter and writes its lines.
+type instrument struct {
+	meter *Meter
+	kind  string
+	name  string
+	typ   string
+	tags  []attribute.KeyValue
+}
+
+// measure returns the tags and the value of a measurement of v, with the tags of the tag providers and of the
+// instrument, as modified by the interceptors, and false if the measurement must be dropped, e.g. when an interceptor
+// drops it or it would exceed the cardinality limit of the metric.
+func (i *instrument) measure(ctx context.Context, v float64) ([]attribute.KeyValue, float64, bool) {
+	if !i.meter.isRunning() || !i.meter.registry.Allow(i.name) {
+		return nil, 0, false
+	}
+	tags := i.meter.registry.DeprecatedAttributes(i.name, i.meter.registry.Attributes(ctx, i.tags))
+	tags, v, ok := i.meter.registry.Intercept(ctx, i.kind, i.name, tags, v)
+	if !ok || !i.meter.registry.AdmitSeries(i.name, tags) {
+		return nil, 0, false
+	}
+	return tags, v, true
+}
+
+// send writes the line of a measurement of v, with a sign if signed is true.
+func (i *instrument) send(ctx context.Context, v float64, signed bool) {
+	if tags, v, ok := i.measure(ctx, v); ok {
+		i.meter.client.write(i.meter.line(i.name, tags, formatValue(v, signed), i.typ))
+	}
+}
+
+// withTags appends the tags of the map.
+func (i *instrument) withTags(tags map[string]string) {
+	for k, v := range tags {
+		i.tags = append(i.tags, attribute.String(k, v))
+	}
+}
+
+// counter is the interfaces.Counter of the StatsD meter.
+type counter struct {
+	instrument
+}
+
+// Incr sends an increment of delta.
+func (c *counter) Incr(ctx context.Context, delta float64) {
+	c.send(ctx, delta, false)
+}
+
+// IncrOne sends an increment of one.
+func (c *counter) IncrOne(ctx context.Context) {
+	c.Incr(ctx, 1)
+}
+
+// AddTag adds a tag to the counter.
+func (c *counter) AddTag(key string, value string) interfaces.Counter {
+	return c.AddAttributes(attribute.String(key, value))
+}
+
+// WithTags adds the tags of the map to the counter.
+func (c *counter) WithTags(tags map[string]string) interfaces.Counter {
+	c.withTags(tags)
+	return c
+}
+
+// AddAttributes adds typed attributes to the counter.
+func (c *counter) AddAttributes(attrs ...attribute.KeyValue) interfaces.Counter {
+	c.tags = append(c.tags, attrs...)
+	return c
+}
+
+// upDownCounter is the interfaces.UpDownCounter of the StatsD meter, sent as a gauge updated by signed deltas, or as
+// a counter with DogStatsD.
+type upDownCounter struct {
+	instrument
+}
+
+// Update sends a gauge delta, or an increment, of delta.
+func (c *upDownCounter) Update(ctx context.Context, delta float64) {
+	c.send(ctx, delta, c.typ == typeGauge)
+}
+
+// IncrOne sends a gauge delta of one.
+func (c *upDownCounter) IncrOne(ctx context.Context) {
+	c.Update(ctx, 1)
+}
+
+// DecrOne sends a gauge delta of minus one.
+func (c *upDownCounter) DecrOne(ctx context.Context) {
+	c.Update(ctx, -1)
+}
+
+// AddTag adds a tag to the counter.
+func (c *upDownCounter) AddTag(key string, value string) interfaces.UpDownCounter {
+	return c.AddAttributes(attribute.String(key, value))
+}
+
+// WithTags adds the tags of the map to the counter.
+func (c *upDownCounter) WithTags(tags map[string]string) interfaces.UpDownCounter {
+	c.withTags(tags)
+	return c
+}
+
+// AddAttributes adds typed attributes to the counter.
+func (c *upDownCounter) AddAttributes(attrs ...attribute.KeyValue) interfaces.UpDownCounter {
+	c.tags = append(c.tags, attrs...)
+	return c
+}
+
+// gauge is the interfaces.Gauge of the StatsD meter.
+type gauge struct {
+	instrument
+}
+
+// Update sends the value v. A negative value would be taken for a delta by StatsD, so the gauge is reset to zero
+// first, in the same packet.
+func (g *gauge) Update(ctx context.Context, v float64) {
+	tags, v, ok := g.measure(ctx, v)
+	if !ok {
+		return
+	}
+	line := g.meter.line(g.name, tags, formatValue(v, false), typeGauge)
+	if v < 0 && g.meter.dialect == dialectStatsD {
+		line = g.meter.line(g.name, tags, "0", typeGauge) + "\n" + line
+	}
+	g.meter.client.write(line)
+}
+
+// AddTag adds a tag to the gauge.
+func (g *gauge) AddTag(key string, value string) interfaces.Gauge {
+	return g.AddAttributes(attribute.String(key, value))
+}
+
+// WithTags adds the tags of the map to the gauge.
+func (g *gauge) WithTags(tags map[string]string) interfaces.Gauge {
+	g.withTags(tags)
+	return g
+}
+
+// AddAttributes adds typed attributes to the gauge.
+func (g *gauge) AddAttributes(attrs ...attribute.KeyValue) interfaces.Gauge {
+	g.tags = append(g.tags, attrs...)
+	return g
+}
+
+// histogram is the interfaces.Histogram of the StatsD meter. The values of a histogram in seconds are scaled to
+// the milliseconds of the timings.
+type histogram struct {
+	instrument
+	scale float64
+}
+
+// Update sends a duration.
+func (h *histogram) Update(ctx context.Context, d time.Duration) {
+	h.UpdateInSeconds(ctx, d.Seconds())
+}
+
+// UpdateInSeconds sends a duration in seconds.
+func (h *histogram) UpdateInSeconds(ctx context.Context, s float64) {
+	h.Record(ctx, s)
+}
+
+// UpdateInMilliseconds sends a duration in milliseconds, as is if the histogram is sent as timings in milliseconds
+// and the interceptors left it unchanged.
+func (h *histogram) UpdateInMilliseconds(ctx context.Context, m float64) {
+	if h.scale == 1000 {
+		tags, s, ok := h.measure(ctx, m/1000)
+		if !ok {
+			return
+		}
+		if s != m/1000 {
+			m = s * 1000
+		}
+		h.meter.client.write(h.meter.line(h.name, tags, formatValue(m, false), h.typ))
+		return
+	}
+	h.UpdateInSeconds(ctx, m/1000)
+}
+
+// UpdateSine sends the time elapsed since start.
+func (h *histogram) UpdateSine(ctx context.Context, start time.Time) {
+	h.Update(ctx, time.Since(start))
+}
+
+// Time sends the duration of f.
+func (h *histogram) Time(f func()) {
+	start := time.Now()
+	f()
+	h.UpdateSine(context.Background(), start)
+}
+
+// Record sends the raw value v, scaled once intercepted.
+func (h *histogram) Record(ctx context.Context, v float64) {
+	tags, v, ok := h.measure(ctx, h.meter.registry.CoerceUnit(h.name, v))
+	if !ok {
+		return
+	}
+	if h.scale != 0 {
+		v *= h.scale
+	}
+	h.meter.client.write(h.meter.line(h.name, tags, formatValue(v, false), h.typ))
+}
+
+// AddTag adds a tag to the histogram.
+func (h *histogram) AddTag(key string, value string) interfaces.Histogram {
+	return h.AddAttributes(attribute.String(key, value))
+}
+
+// WithTags adds the tags of the map to the histogram.
+func (h *histogram) WithTags(tags map[string]string) interfaces.Histogram {
+	h.withTags(tags)
+	return h
+}
+
+// AddAttributes adds typed attributes to the histogram.
+func (h *histogram) AddAttributes(attrs ...attribute.KeyValue) interfaces.Histogram {
+	h.tags = append(h.tags, attrs...)
+	return h
+}
+
+// observer is an observable gauge of the StatsD meter, it sends the values reported by its callback.
+type observer struct {
+	meter    *Meter
+	ctx      context.Context
+	name     string
+	callback interfaces.ObservableCallback
+}
+
+// Observe sends v with the given tags.
+func (o *observer) Observe(v float64, tags map[string]string) {
+	g := &gauge{instrument: o.meter.newInstrument(interfaces.KindGauge, o.name, typeGauge)}
+	g.WithTags(tags).Update(o.ctx, v)
+}
+
+// observe calls the callback with ctx, unless it was disabled after panicking too often. A panic of the callback is
+// recovered and returned as an error, the flush loop running in its own goroutine.
+func (o *observer) observe(ctx context.Context) (err error) {
+	registry := o.meter.registry
+	if registry.CallbackDisabled(o.name) {
+		return nil
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			registry.CallbackPanicked(o.name, recovered)
+			err = fmt.Errorf("callback panicked: %v", recovered)
+		}
+	}()
+	observed := *o
+	observed.ctx = ctx
+	return o.callback(ctx, &observed)
+}
+
+// registration removes an observable gauge from the StatsD meter.
+type registration struct {
+	meter *Meter
+	id    int
+}
+
+// Unregister stops calling the callback on flush.
+func (r *registration) Unregister() error {
+	r.meter.mu.Lock()
+	defer r.meter.mu.Unlock()
+	delete(r.meter.gauges, r.id)
+	return nil
+}
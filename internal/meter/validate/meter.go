@@ -0,0 +1,216 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"github.com/liangweijiang/go-metric/internal/component"
+	"github.com/liangweijiang/go-metric/internal/registry"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/validate"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// _ is a blank identifier used for type assertion to ensure that *Meter implements the interfaces.Meter interface.
+var _ interfaces.Meter = (*Meter)(nil)
+
+// _ is a blank identifier used for type assertion to ensure that *Meter implements the interfaces.Reloadable interface.
+var _ interfaces.Reloadable = (*Meter)(nil)
+
+// Meter is the dry-run meter of the validate provider: it accepts every call, exports nothing, and checks the
+// instruments and measurements with a validate.Validator, logging every new violation as an error.
+// Observable gauges are observed on Flush.
+type Meter struct {
+	cfg       *config.Config
+	running   int32
+	validator *validate.Validator
+	registry  *registry.Registry
+	mu        sync.Mutex
+	gauges    map[int]*observer
+	nextID    int
+}
+
+// NewValidateMeter creates the dry-run meter reporting the metrics with more than cfg.CardinalityLimit tag sets.
+func NewValidateMeter(cfg *config.Config) interfaces.Meter {
+	r := registry.NewRegistry(nil)
+	r.SetTagProviders(cfg.TagProviders)
+	return &Meter{
+		cfg:     cfg,
+		running: 1,
+		validator: validate.NewValidator(cfg.GetCardinalityLimit(), func(v validate.Violation) {
+			cfg.WriteErrorOrNot("metric validation: " + v.String())
+		}),
+		registry: r,
+		gauges:   make(map[int]*observer),
+	}
+}
+
+// Violations returns the violations found so far.
+func (m *Meter) Violations() []validate.Violation {
+	return m.validator.Violations()
+}
+
+// GetHandler returns a handler writing the violations found so far, one per line.
+func (m *Meter) GetHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, v := range m.Violations() {
+			_, _ = fmt.Fprintln(w, v.String())
+		}
+	})
+}
+
+// ServerInfo describes the validate meter, which exports nothing and runs no server.
+func (m *Meter) ServerInfo() config.ServerInfo {
+	return config.ServerInfo{
+		Provider:  m.cfg.MeterProvider.String(),
+		Running:   m.isRunning(),
+		Exporters: []string{},
+		Servers:   []config.ServerState{},
+	}
+}
+
+// WithRunning switches the validation on or off.
+func (m *Meter) WithRunning(on bool) {
+	if on {
+		atomic.StoreInt32(&m.running, 1)
+		return
+	}
+	atomic.StoreInt32(&m.running, 0)
+}
+
+// isRunning reports whether the validation is switched on.
+func (m *Meter) isRunning() bool {
+	return atomic.LoadInt32(&m.running) == 1
+}
+
+// DisableMetric stops validating the measurements of the metric with the given name.
+func (m *Meter) DisableMetric(metricName string) {
+	m.registry.Disable(metricName)
+}
+
+// EnableMetric resumes validating the measurements of a metric disabled with DisableMetric.
+func (m *Meter) EnableMetric(metricName string) {
+	m.registry.Enable(metricName)
+}
+
+// DeprecateMetric tags the measurements of the metric with the given name with deprecated="true" before they are
+// validated.
+func (m *Meter) DeprecateMetric(metricName, replacement string) {
+	m.registry.Deprecate(metricName, replacement)
+}
+
+// Use appends interceptor to the interceptors of the measurements, which are validated as modified by them.
+func (m *Meter) Use(interceptor interfaces.Interceptor) {
+	m.registry.Use(interceptor)
+}
+
+// SetLogLevel changes the level of the SDK logging.
+func (m *Meter) SetLogLevel(level config.LogLevel) {
+	m.cfg.SetLogLevel(level)
+}
+
+// SetPushPeriod records the push period, the validate meter does not push.
+func (m *Meter) SetPushPeriod(period time.Duration) {
+	m.cfg.SetPushPeriod(period)
+}
+
+// SetCardinalityLimit changes the number of distinct tag sets beyond which a metric is reported.
+func (m *Meter) SetCardinalityLimit(limit int) {
+	m.cfg.SetCardinalityLimit(limit)
+	m.validator.SetLimit(limit)
+}
+
+// Flush observes the observable gauges so that their measurements are validated as well.
+func (m *Meter) Flush(ctx context.Context) error {
+	m.mu.Lock()
+	gauges := make([]*observer, 0, len(m.gauges))
+	for _, g := range m.gauges {
+		gauges = append(gauges, g)
+	}
+	m.mu.Unlock()
+	for _, g := range gauges {
+		o := *g
+		o.ctx = ctx
+		if err := g.callback(ctx, &o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewCounter checks and creates a Counter.
+func (m *Meter) NewCounter(metricName, desc, unit string) interfaces.Counter {
+	return &counter{instrument: m.newInstrument("counter", metricName, unit, nil)}
+}
+
+// NewUpDownCounter checks and creates an UpDownCounter.
+func (m *Meter) NewUpDownCounter(metricName, desc, unit string) interfaces.UpDownCounter {
+	return &upDownCounter{instrument: m.newInstrument("updowncounter", metricName, unit, nil)}
+}
+
+// NewGauge checks and creates a Gauge.
+func (m *Meter) NewGauge(metricName, desc, unit string) interfaces.Gauge {
+	return &gauge{instrument: m.newInstrument("gauge", metricName, unit, nil)}
+}
+
+// NewHistogram checks and creates a Histogram with the configured boundaries.
+func (m *Meter) NewHistogram(metricName, desc, unit string) interfaces.Histogram {
+	return m.NewHistogramWithBuckets(metricName, desc, unit, m.cfg.HistogramBoundaries)
+}
+
+// NewHistogramWithBuckets checks and creates a Histogram with the given boundaries.
+func (m *Meter) NewHistogramWithBuckets(metricName, desc, unit string, buckets []float64) interfaces.Histogram {
+	return &histogram{instrument: m.newInstrument("histogram", metricName, unit, buckets)}
+}
+
+// NewSizeHistogram checks and creates a Histogram in bytes.
+func (m *Meter) NewSizeHistogram(metricName, desc string) interfaces.Histogram {
+	return m.NewHistogramWithBuckets(metricName, desc, config.UnitBytes, config.DefaultSizeBoundaries)
+}
+
+// NewCountHistogram checks and creates a count Histogram.
+func (m *Meter) NewCountHistogram(metricName, desc string) interfaces.Histogram {
+	return m.NewHistogramWithBuckets(metricName, desc, config.UnitCount, config.DefaultCountBoundaries)
+}
+
+// NewObservableGauge checks the gauge and registers callback, called on Flush.
+func (m *Meter) NewObservableGauge(metricName, desc, unit string, callback interfaces.ObservableCallback) interfaces.Registration {
+	m.validator.CheckInstrument("gauge", metricName, unit, nil)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := m.nextID
+	m.nextID++
+	m.gauges[id] = &observer{
+		meter:    m,
+		name:     metricName,
+		callback: callback,
+	}
+	return &registration{
+		meter: m,
+		id:    id,
+	}
+}
+
+// newInstrument checks the creation of an instrument and creates its common part.
+func (m *Meter) newInstrument(kind, name, unit string, buckets []float64) instrument {
+	if m.isRunning() {
+		m.validator.CheckInstrument(kind, name, unit, buckets)
+	}
+	return instrument{
+		meter:     m,
+		kind:      kind,
+		name:      name,
+		monotonic: kind == interfaces.KindCounter,
+	}
+}
+
+// Components returns the standard components, whose measurements are checked like those of the other instruments.
+func (m *Meter) Components() interfaces.Components {
+	return component.NewComponents(func() interfaces.BaseMeter {
+		return m
+	})
+}
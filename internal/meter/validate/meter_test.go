@@ -0,0 +1,137 @@
+package validate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rules returns the rules of the violations found by m.
+func rules(m interfaces.Meter) []string {
+	var out []string
+	for _, v := range m.(*Meter).Violations() {
+		out = append(out, v.Metric+" "+v.Rule)
+	}
+	return out
+}
+
+func TestValidateMeter(t *testing.T) {
+	tests := []struct {
+		name     string
+		record   func(ctx context.Context, m interfaces.Meter)
+		expected []string
+	}{
+		{
+			name: "Valid",
+			record: func(ctx context.Context, m interfaces.Meter) {
+				m.NewCounter("orders", "", "").AddTag("status", "paid").IncrOne(ctx)
+				m.NewHistogram("rpc_duration", "", "s").Update(ctx, time.Second)
+			},
+		},
+		{
+			name: "InvalidInstruments",
+			record: func(ctx context.Context, m interfaces.Meter) {
+				m.NewCounter("orders_total", "", "").IncrOne(ctx)
+				m.NewGauge("http-requests", "", "").Update(ctx, 1)
+				m.NewHistogramWithBuckets("latency", "", "s", []float64{1, 0.5}).Record(ctx, 1)
+			},
+			expected: []string{
+				"orders_total " + validate.RuleReservedSuffix,
+				"http-requests " + validate.RuleMetricName,
+				"latency " + validate.RuleBuckets,
+			},
+		},
+		{
+			name: "InvalidMeasurements",
+			record: func(ctx context.Context, m interfaces.Meter) {
+				m.NewCounter("orders", "", "").Incr(ctx, -1)
+				m.NewGauge("queue_size", "", "").AddTag("queue-name", "emails").Update(ctx, 1)
+			},
+			expected: []string{
+				"orders " + validate.RuleNegativeIncrement,
+				"queue_size " + validate.RuleTagKey,
+			},
+		},
+		{
+			name: "ObservableGauge",
+			record: func(ctx context.Context, m interfaces.Meter) {
+				m.NewObservableGauge("pool_size", "", "", func(_ context.Context, o interfaces.Observer) error {
+					o.Observe(1, map[string]string{"pool-name": "main"})
+					return nil
+				})
+				require.NoError(t, m.Flush(ctx))
+			},
+			expected: []string{"pool_size " + validate.RuleTagKey},
+		},
+		{
+			name: "DisabledMetric",
+			record: func(ctx context.Context, m interfaces.Meter) {
+				m.DisableMetric("orders")
+				m.NewCounter("orders", "", "").Incr(ctx, -1)
+			},
+		},
+		{
+			name: "Stopped",
+			record: func(ctx context.Context, m interfaces.Meter) {
+				m.WithRunning(false)
+				m.NewCounter("orders_total", "", "").Incr(ctx, -1)
+			},
+		},
+		{
+			name: "Cardinality",
+			record: func(ctx context.Context, m interfaces.Meter) {
+				m.(*Meter).SetCardinalityLimit(1)
+				m.NewCounter("orders", "", "").AddTag("user", "1").IncrOne(ctx)
+				m.NewCounter("orders", "", "").AddTag("user", "2").IncrOne(ctx)
+			},
+			expected: []string{"orders " + validate.RuleCardinality},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewValidateMeter(&config.Config{ErrorLogWrite: func(string) {}})
+
+			tt.record(context.Background(), m)
+
+			assert.Equal(t, tt.expected, rules(m))
+		})
+	}
+}
+
+func TestValidateMeterHandler(t *testing.T) {
+	m := NewValidateMeter(&config.Config{ErrorLogWrite: func(string) {}})
+	m.NewCounter("orders_total", "", "").IncrOne(context.Background())
+
+	rec := httptest.NewRecorder()
+	m.GetHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Contains(t, rec.Body.String(), "orders_total: "+validate.RuleReservedSuffix)
+}
+
+func TestValidateMeterReportRecords(t *testing.T) {
+	var m interfaces.Meter
+	cfg := &config.Config{ErrorLogWrite: func(string) {
+		// the logger counts the violations with the meter it logs for.
+		m.NewCounter("logged_errors", "", "").IncrOne(context.Background())
+	}}
+	m = NewValidateMeter(cfg)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.NewCounter("orders_total", "", "").IncrOne(context.Background())
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the report of a violation recording a metric deadlocked")
+	}
+	assert.Equal(t, []string{"orders_total " + validate.RuleReservedSuffix}, rules(m))
+}
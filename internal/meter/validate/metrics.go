@@ -0,0 +1,216 @@
+package validate
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"time"
+)
+
+// instrument holds the tags of an instrument of the validate meter and checks its measurements.
+type instrument struct {
+	meter     *Meter
+	kind      string
+	name      string
+	monotonic bool
+	tags      []attribute.KeyValue
+}
+
+// record checks a measurement of value v, with the tags of the tag providers and of the instrument, as modified by the
+// interceptors.
+func (i *instrument) record(ctx context.Context, v float64) {
+	if !i.meter.isRunning() || !i.meter.registry.Allow(i.name) {
+		return
+	}
+	attributes := i.meter.registry.DeprecatedAttributes(i.name, i.meter.registry.Attributes(ctx, i.tags))
+	attributes, v, ok := i.meter.registry.Intercept(ctx, i.kind, i.name, attributes, v)
+	if !ok {
+		return
+	}
+	i.meter.validator.CheckRecord(i.name, attributes, v, i.monotonic)
+}
+
+// withTags appends the tags of the map.
+func (i *instrument) withTags(tags map[string]string) {
+	for k, v := range tags {
+		i.tags = append(i.tags, attribute.String(k, v))
+	}
+}
+
+// counter is the interfaces.Counter of the validate meter.
+type counter struct {
+	instrument
+}
+
+// Incr checks an increment of delta.
+func (c *counter) Incr(ctx context.Context, delta float64) {
+	c.record(ctx, delta)
+}
+
+// IncrOne checks an increment of one.
+func (c *counter) IncrOne(ctx context.Context) {
+	c.Incr(ctx, 1)
+}
+
+// AddTag adds a tag to the counter.
+func (c *counter) AddTag(key string, value string) interfaces.Counter {
+	return c.AddAttributes(attribute.String(key, value))
+}
+
+// WithTags adds the tags of the map to the counter.
+func (c *counter) WithTags(tags map[string]string) interfaces.Counter {
+	c.withTags(tags)
+	return c
+}
+
+// AddAttributes adds typed attributes to the counter.
+func (c *counter) AddAttributes(attrs ...attribute.KeyValue) interfaces.Counter {
+	c.tags = append(c.tags, attrs...)
+	return c
+}
+
+// upDownCounter is the interfaces.UpDownCounter of the validate meter.
+type upDownCounter struct {
+	instrument
+}
+
+// Update checks an update of delta.
+func (c *upDownCounter) Update(ctx context.Context, delta float64) {
+	c.record(ctx, delta)
+}
+
+// IncrOne checks an increment of one.
+func (c *upDownCounter) IncrOne(ctx context.Context) {
+	c.Update(ctx, 1)
+}
+
+// DecrOne checks a decrement of one.
+func (c *upDownCounter) DecrOne(ctx context.Context) {
+	c.Update(ctx, -1)
+}
+
+// AddTag adds a tag to the counter.
+func (c *upDownCounter) AddTag(key string, value string) interfaces.UpDownCounter {
+	return c.AddAttributes(attribute.String(key, value))
+}
+
+// WithTags adds the tags of the map to the counter.
+func (c *upDownCounter) WithTags(tags map[string]string) interfaces.UpDownCounter {
+	c.withTags(tags)
+	return c
+}
+
+// AddAttributes adds typed attributes to the counter.
+func (c *upDownCounter) AddAttributes(attrs ...attribute.KeyValue) interfaces.UpDownCounter {
+	c.tags = append(c.tags, attrs...)
+	return c
+}
+
+// gauge is the interfaces.Gauge of the validate meter.
+type gauge struct {
+	instrument
+}
+
+// Update checks the value v.
+func (g *gauge) Update(ctx context.Context, v float64) {
+	g.record(ctx, v)
+}
+
+// AddTag adds a tag to the gauge.
+func (g *gauge) AddTag(key string, value string) interfaces.Gauge {
+	return g.AddAttributes(attribute.String(key, value))
+}
+
+// WithTags adds the tags of the map to the gauge.
+func (g *gauge) WithTags(tags map[string]string) interfaces.Gauge {
+	g.withTags(tags)
+	return g
+}
+
+// AddAttributes adds typed attributes to the gauge.
+func (g *gauge) AddAttributes(attrs ...attribute.KeyValue) interfaces.Gauge {
+	g.tags = append(g.tags, attrs...)
+	return g
+}
+
+// histogram is the interfaces.Histogram of the validate meter.
+type histogram struct {
+	instrument
+}
+
+// Update checks a duration.
+func (h *histogram) Update(ctx context.Context, d time.Duration) {
+	h.UpdateInSeconds(ctx, d.Seconds())
+}
+
+// UpdateInSeconds checks a duration in seconds.
+func (h *histogram) UpdateInSeconds(ctx context.Context, s float64) {
+	h.Record(ctx, s)
+}
+
+// UpdateInMilliseconds checks a duration in milliseconds.
+func (h *histogram) UpdateInMilliseconds(ctx context.Context, m float64) {
+	h.UpdateInSeconds(ctx, m/1000)
+}
+
+// UpdateSine checks the time elapsed since start.
+func (h *histogram) UpdateSine(ctx context.Context, start time.Time) {
+	h.Update(ctx, time.Since(start))
+}
+
+// Time checks the duration of f.
+func (h *histogram) Time(f func()) {
+	start := time.Now()
+	f()
+	h.UpdateSine(context.Background(), start)
+}
+
+// Record checks the raw value v.
+func (h *histogram) Record(ctx context.Context, v float64) {
+	h.record(ctx, v)
+}
+
+// AddTag adds a tag to the histogram.
+func (h *histogram) AddTag(key string, value string) interfaces.Histogram {
+	return h.AddAttributes(attribute.String(key, value))
+}
+
+// WithTags adds the tags of the map to the histogram.
+func (h *histogram) WithTags(tags map[string]string) interfaces.Histogram {
+	h.withTags(tags)
+	return h
+}
+
+// AddAttributes adds typed attributes to the histogram.
+func (h *histogram) AddAttributes(attrs ...attribute.KeyValue) interfaces.Histogram {
+	h.tags = append(h.tags, attrs...)
+	return h
+}
+
+// observer is an observable gauge of the validate meter, it checks the values reported by its callback.
+type observer struct {
+	meter    *Meter
+	ctx      context.Context
+	name     string
+	callback interfaces.ObservableCallback
+}
+
+// Observe checks v with the given tags.
+func (o *observer) Observe(v float64, tags map[string]string) {
+	g := &gauge{instrument: instrument{meter: o.meter, kind: interfaces.KindGauge, name: o.name}}
+	g.WithTags(tags).Update(o.ctx, v)
+}
+
+// registration removes an observable gauge from the validate meter.
+type registration struct {
+	meter *Meter
+	id    int
+}
+
+// Unregister stops calling the callback on Flush.
+func (r *registration) Unregister() error {
+	r.meter.mu.Lock()
+	defer r.meter.mu.Unlock()
+	delete(r.meter.gauges, r.id)
+	return nil
+}
@@ -0,0 +1,273 @@
+// Package wrap implements a meter forwarding the measurements to another meter through a hook, which can inspect,
+// modify or drop every measurement before it reaches the backend of the wrapped meter.
+package wrap
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/internal/component"
+	"github.com/liangweijiang/go-metric/internal/registry"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// _ is a blank identifier used for type assertion to ensure that *Meter implements the interfaces.Meter interface.
+var _ interfaces.Meter = (*Meter)(nil)
+
+// Kinds of the instruments recording the measurements.
+const (
+	KindCounter       = interfaces.KindCounter
+	KindUpDownCounter = interfaces.KindUpDownCounter
+	KindGauge         = interfaces.KindGauge
+	KindHistogram     = interfaces.KindHistogram
+)
+
+// Measurement is a measurement going through a wrapping meter. Histograms durations are in seconds.
+type Measurement = interfaces.Measurement
+
+// Hook inspects a measurement before it is recorded to the wrapped meter, it may modify it in place and returns
+// false to drop it. The measurements of the observable gauges are given to the hook while the wrapped meter collects:
+// the hook must not create instruments, and changing the name of these measurements has no effect.
+type Hook = interfaces.Interceptor
+
+// Meter is an interfaces.Meter recording to the wrapped meter the measurements accepted by its hook.
+// The methods not creating instruments are forwarded to the wrapped meter, unless the meter is isolated, see
+// NewIsolated.
+type Meter struct {
+	inner interfaces.Meter
+	hook  Hook
+	local *localState
+}
+
+// localState is the state of an isolated meter, switched, muted and intercepted apart from the wrapped meter.
+type localState struct {
+	stopped      atomic.Bool
+	disabled     sync.Map
+	deprecated   sync.Map
+	mu           sync.Mutex
+	interceptors atomic.Pointer[[]interfaces.Interceptor]
+}
+
+// New wraps inner, every measurement is given to hook before being recorded.
+func New(inner interfaces.Meter, hook Hook) *Meter {
+	return &Meter{
+		inner: inner,
+		hook:  hook,
+	}
+}
+
+// NewIsolated wraps inner like New for one of the users sharing inner, e.g. a tenant: WithRunning, DisableMetric,
+// EnableMetric, DeprecateMetric and Use only apply to the measurements recorded through the returned meter, and the
+// settings of inner shared by all its users, the log level, the push period and the cardinality limit, are left
+// unchanged.
+func NewIsolated(inner interfaces.Meter, hook Hook) *Meter {
+	return &Meter{
+		inner: inner,
+		hook:  hook,
+		local: &localState{},
+	}
+}
+
+// allow reports whether a measurement of the metric must be recorded, and the tags to add to it: the measurements
+// of an isolated meter switched off or of its disabled metrics are dropped, and those of its deprecated metrics are
+// tagged deprecated="true".
+func (m *Meter) allow(name string) ([]attribute.KeyValue, bool) {
+	if m.local == nil {
+		return nil, true
+	}
+	if m.local.stopped.Load() {
+		return nil, false
+	}
+	if _, disabled := m.local.disabled.Load(name); disabled {
+		return nil, false
+	}
+	if _, deprecated := m.local.deprecated.Load(name); deprecated {
+		return []attribute.KeyValue{attribute.String(registry.TagDeprecated, "true")}, true
+	}
+	return nil, true
+}
+
+// Unwrap returns the wrapped meter.
+func (m *Meter) Unwrap() interfaces.Meter {
+	return m.inner
+}
+
+// Clock returns the clock of the wrapped meter.
+func (m *Meter) Clock() clock.Clock {
+	return clock.From(m.inner)
+}
+
+// GetHandler returns the handler of the wrapped meter.
+func (m *Meter) GetHandler() http.Handler {
+	return m.inner.GetHandler()
+}
+
+// WithRunning switches the wrapped meter on or off, or only the isolated meter.
+func (m *Meter) WithRunning(on bool) {
+	if m.local != nil {
+		m.local.stopped.Store(!on)
+		return
+	}
+	m.inner.WithRunning(on)
+}
+
+// DisableMetric mutes the metric in the wrapped meter, or only in the isolated meter.
+func (m *Meter) DisableMetric(metricName string) {
+	if m.local != nil {
+		m.local.disabled.Store(metricName, struct{}{})
+		return
+	}
+	m.inner.DisableMetric(metricName)
+}
+
+// EnableMetric restores the metric in the wrapped meter, or only in the isolated meter.
+func (m *Meter) EnableMetric(metricName string) {
+	if m.local != nil {
+		m.local.disabled.Delete(metricName)
+		return
+	}
+	m.inner.EnableMetric(metricName)
+}
+
+// DeprecateMetric marks the metric as deprecated in the wrapped meter, or only tags the measurements of the isolated
+// meter deprecated="true".
+func (m *Meter) DeprecateMetric(metricName, replacement string) {
+	if m.local != nil {
+		m.local.deprecated.Store(metricName, replacement)
+		return
+	}
+	m.inner.DeprecateMetric(metricName, replacement)
+}
+
+// Use appends interceptor to the interceptors of the wrapped meter, or to those of the isolated meter, given the
+// measurements accepted by the hook.
+func (m *Meter) Use(interceptor interfaces.Interceptor) {
+	if m.local == nil {
+		m.inner.Use(interceptor)
+		return
+	}
+	m.local.mu.Lock()
+	defer m.local.mu.Unlock()
+	var chain []interfaces.Interceptor
+	if current := m.local.interceptors.Load(); current != nil {
+		chain = append(chain, *current...)
+	}
+	chain = append(chain, interceptor)
+	m.local.interceptors.Store(&chain)
+}
+
+// intercept gives the measurement to the interceptors of an isolated meter, returning false if one of them drops it.
+func (m *Meter) intercept(ctx context.Context, measurement *Measurement) bool {
+	if m.local == nil {
+		return true
+	}
+	chain := m.local.interceptors.Load()
+	if chain == nil {
+		return true
+	}
+	for _, interceptor := range *chain {
+		if !interceptor(ctx, measurement) {
+			return false
+		}
+	}
+	return true
+}
+
+// SetLogLevel changes the level of the SDK logging of the wrapped meter. It does nothing on an isolated meter.
+func (m *Meter) SetLogLevel(level config.LogLevel) {
+	if m.local == nil {
+		m.inner.SetLogLevel(level)
+	}
+}
+
+// SetPushPeriod changes the push period of the wrapped meter if it is reloadable. It does nothing on an isolated
+// meter.
+func (m *Meter) SetPushPeriod(period time.Duration) {
+	if r, ok := m.inner.(interfaces.Reloadable); ok && m.local == nil {
+		r.SetPushPeriod(period)
+	}
+}
+
+// SetCardinalityLimit changes the cardinality limit of the wrapped meter if it is reloadable. It does nothing on an
+// isolated meter.
+func (m *Meter) SetCardinalityLimit(limit int) {
+	if r, ok := m.inner.(interfaces.Reloadable); ok && m.local == nil {
+		r.SetCardinalityLimit(limit)
+	}
+}
+
+// Flush flushes the wrapped meter.
+func (m *Meter) Flush(ctx context.Context) error {
+	return m.inner.Flush(ctx)
+}
+
+// Components returns the standard components recording through the hook.
+func (m *Meter) Components() interfaces.Components {
+	return component.NewComponents(func() interfaces.BaseMeter {
+		return m
+	})
+}
+
+// NewCounter creates a Counter recording through the hook.
+func (m *Meter) NewCounter(metricName, desc, unit string) interfaces.Counter {
+	return &counter{instrument: m.newInstrument(KindCounter, metricName, desc, unit, nil)}
+}
+
+// NewUpDownCounter creates an UpDownCounter recording through the hook.
+func (m *Meter) NewUpDownCounter(metricName, desc, unit string) interfaces.UpDownCounter {
+	return &upDownCounter{instrument: m.newInstrument(KindUpDownCounter, metricName, desc, unit, nil)}
+}
+
+// NewGauge creates a Gauge recording through the hook.
+func (m *Meter) NewGauge(metricName, desc, unit string) interfaces.Gauge {
+	return &gauge{instrument: m.newInstrument(KindGauge, metricName, desc, unit, nil)}
+}
+
+// NewHistogram creates a Histogram with the boundaries of the wrapped meter recording through the hook.
+func (m *Meter) NewHistogram(metricName, desc, unit string) interfaces.Histogram {
+	return m.NewHistogramWithBuckets(metricName, desc, unit, nil)
+}
+
+// NewHistogramWithBuckets creates a Histogram with the given boundaries recording through the hook.
+func (m *Meter) NewHistogramWithBuckets(metricName, desc, unit string, buckets []float64) interfaces.Histogram {
+	return &histogram{instrument: m.newInstrument(KindHistogram, metricName, desc, unit, buckets)}
+}
+
+// NewSizeHistogram creates a Histogram in bytes recording through the hook.
+func (m *Meter) NewSizeHistogram(metricName, desc string) interfaces.Histogram {
+	return m.NewHistogramWithBuckets(metricName, desc, config.UnitBytes, config.DefaultSizeBoundaries)
+}
+
+// NewCountHistogram creates a count Histogram recording through the hook.
+func (m *Meter) NewCountHistogram(metricName, desc string) interfaces.Histogram {
+	return m.NewHistogramWithBuckets(metricName, desc, config.UnitCount, config.DefaultCountBoundaries)
+}
+
+// NewObservableGauge registers an observable gauge on the wrapped meter whose observations go through the hook.
+func (m *Meter) NewObservableGauge(metricName, desc, unit string, callback interfaces.ObservableCallback) interfaces.Registration {
+	return m.inner.NewObservableGauge(metricName, desc, unit, func(ctx context.Context, o interfaces.Observer) error {
+		return callback(ctx, &observer{
+			meter: m,
+			ctx:   ctx,
+			name:  metricName,
+			inner: o,
+		})
+	})
+}
+
+// newInstrument creates the common part of the instruments.
+func (m *Meter) newInstrument(kind, name, desc, unit string, buckets []float64) instrument {
+	return instrument{
+		meter:   m,
+		kind:    kind,
+		name:    name,
+		desc:    desc,
+		unit:    unit,
+		buckets: buckets,
+	}
+}
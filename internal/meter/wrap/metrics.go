@@ -0,0 +1,265 @@
+package wrap
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/tag"
+	"go.opentelemetry.io/otel/attribute"
+	"maps"
+	"time"
+)
+
+// instrument holds the definition and the tags of an instrument of the wrapping meter.
+type instrument struct {
+	meter   *Meter
+	kind    string
+	name    string
+	desc    string
+	unit    string
+	buckets []float64
+	tags    []attribute.KeyValue
+}
+
+// measure gives the measurement of value v to the hook, then to the interceptors of an isolated meter, returning the
+// measurement to record and whether to record it.
+func (i *instrument) measure(ctx context.Context, v float64) (*Measurement, bool) {
+	tags, ok := i.meter.allow(i.name)
+	if !ok {
+		return nil, false
+	}
+	m := &Measurement{
+		Kind:  i.kind,
+		Name:  i.name,
+		Tags:  append(append([]attribute.KeyValue(nil), i.tags...), tags...),
+		Value: v,
+	}
+	if i.meter.hook != nil && !i.meter.hook(ctx, m) {
+		return nil, false
+	}
+	if !i.meter.intercept(ctx, m) {
+		return nil, false
+	}
+	return m, true
+}
+
+// withTags appends the tags of the map.
+func (i *instrument) withTags(tags map[string]string) {
+	for k, v := range tags {
+		i.tags = append(i.tags, attribute.String(k, v))
+	}
+}
+
+// counter is the interfaces.Counter of the wrapping meter.
+type counter struct {
+	instrument
+}
+
+// Incr records an increment of delta.
+func (c *counter) Incr(ctx context.Context, delta float64) {
+	if m, ok := c.measure(ctx, delta); ok {
+		tag.Add(c.meter.inner.NewCounter(m.Name, c.desc, c.unit), m.Tags...).Incr(ctx, m.Value)
+	}
+}
+
+// IncrOne records an increment of one.
+func (c *counter) IncrOne(ctx context.Context) {
+	c.Incr(ctx, 1)
+}
+
+// AddTag adds a tag to the counter.
+func (c *counter) AddTag(key string, value string) interfaces.Counter {
+	return c.AddAttributes(attribute.String(key, value))
+}
+
+// WithTags adds the tags of the map to the counter.
+func (c *counter) WithTags(tags map[string]string) interfaces.Counter {
+	c.withTags(tags)
+	return c
+}
+
+// AddAttributes adds typed attributes to the counter.
+func (c *counter) AddAttributes(attrs ...attribute.KeyValue) interfaces.Counter {
+	c.tags = append(c.tags, attrs...)
+	return c
+}
+
+// upDownCounter is the interfaces.UpDownCounter of the wrapping meter.
+type upDownCounter struct {
+	instrument
+}
+
+// Update records an update of delta.
+func (c *upDownCounter) Update(ctx context.Context, delta float64) {
+	if m, ok := c.measure(ctx, delta); ok {
+		tag.Add(c.meter.inner.NewUpDownCounter(m.Name, c.desc, c.unit), m.Tags...).Update(ctx, m.Value)
+	}
+}
+
+// IncrOne records an increment of one.
+func (c *upDownCounter) IncrOne(ctx context.Context) {
+	c.Update(ctx, 1)
+}
+
+// DecrOne records a decrement of one.
+func (c *upDownCounter) DecrOne(ctx context.Context) {
+	c.Update(ctx, -1)
+}
+
+// AddTag adds a tag to the counter.
+func (c *upDownCounter) AddTag(key string, value string) interfaces.UpDownCounter {
+	return c.AddAttributes(attribute.String(key, value))
+}
+
+// WithTags adds the tags of the map to the counter.
+func (c *upDownCounter) WithTags(tags map[string]string) interfaces.UpDownCounter {
+	c.withTags(tags)
+	return c
+}
+
+// AddAttributes adds typed attributes to the counter.
+func (c *upDownCounter) AddAttributes(attrs ...attribute.KeyValue) interfaces.UpDownCounter {
+	c.tags = append(c.tags, attrs...)
+	return c
+}
+
+// gauge is the interfaces.Gauge of the wrapping meter.
+type gauge struct {
+	instrument
+}
+
+// Update records the value v.
+func (g *gauge) Update(ctx context.Context, v float64) {
+	if m, ok := g.measure(ctx, v); ok {
+		tag.Add(g.meter.inner.NewGauge(m.Name, g.desc, g.unit), m.Tags...).Update(ctx, m.Value)
+	}
+}
+
+// AddTag adds a tag to the gauge.
+func (g *gauge) AddTag(key string, value string) interfaces.Gauge {
+	return g.AddAttributes(attribute.String(key, value))
+}
+
+// WithTags adds the tags of the map to the gauge.
+func (g *gauge) WithTags(tags map[string]string) interfaces.Gauge {
+	g.withTags(tags)
+	return g
+}
+
+// AddAttributes adds typed attributes to the gauge.
+func (g *gauge) AddAttributes(attrs ...attribute.KeyValue) interfaces.Gauge {
+	g.tags = append(g.tags, attrs...)
+	return g
+}
+
+// histogram is the interfaces.Histogram of the wrapping meter.
+type histogram struct {
+	instrument
+}
+
+// Update records a duration.
+func (h *histogram) Update(ctx context.Context, d time.Duration) {
+	h.Record(ctx, d.Seconds())
+}
+
+// UpdateInSeconds records a duration in seconds.
+func (h *histogram) UpdateInSeconds(ctx context.Context, s float64) {
+	h.Record(ctx, s)
+}
+
+// UpdateInMilliseconds records a duration in milliseconds.
+func (h *histogram) UpdateInMilliseconds(ctx context.Context, m float64) {
+	h.Record(ctx, m/1000)
+}
+
+// UpdateSine records the time elapsed since start, measured with the clock of the wrapped meter.
+func (h *histogram) UpdateSine(ctx context.Context, start time.Time) {
+	h.Update(ctx, h.meter.Clock().Since(start))
+}
+
+// Time records the duration of f.
+func (h *histogram) Time(f func()) {
+	start := h.meter.Clock().Now()
+	f()
+	h.UpdateSine(context.Background(), start)
+}
+
+// Record records the raw value v.
+func (h *histogram) Record(ctx context.Context, v float64) {
+	m, ok := h.measure(ctx, v)
+	if !ok {
+		return
+	}
+	var inner interfaces.Histogram
+	if h.buckets == nil {
+		inner = h.meter.inner.NewHistogram(m.Name, h.desc, h.unit)
+	} else {
+		inner = h.meter.inner.NewHistogramWithBuckets(m.Name, h.desc, h.unit, h.buckets)
+	}
+	tag.Add(inner, m.Tags...).Record(ctx, m.Value)
+}
+
+// AddTag adds a tag to the histogram.
+func (h *histogram) AddTag(key string, value string) interfaces.Histogram {
+	return h.AddAttributes(attribute.String(key, value))
+}
+
+// WithTags adds the tags of the map to the histogram.
+func (h *histogram) WithTags(tags map[string]string) interfaces.Histogram {
+	h.withTags(tags)
+	return h
+}
+
+// AddAttributes adds typed attributes to the histogram.
+func (h *histogram) AddAttributes(attrs ...attribute.KeyValue) interfaces.Histogram {
+	h.tags = append(h.tags, attrs...)
+	return h
+}
+
+// observer gives the observations of an observable gauge to the hook before passing them to the observer of the
+// wrapped meter.
+type observer struct {
+	meter *Meter
+	ctx   context.Context
+	name  string
+	inner interfaces.Observer
+}
+
+// Observe observes v with the given tags.
+func (o *observer) Observe(v float64, tags map[string]string) {
+	extra, ok := o.meter.allow(o.name)
+	if !ok {
+		return
+	}
+	if len(extra) > 0 {
+		tags = maps.Clone(tags)
+		if tags == nil {
+			tags = make(map[string]string, len(extra))
+		}
+		for _, kv := range extra {
+			tags[string(kv.Key)] = kv.Value.Emit()
+		}
+	}
+	if o.meter.hook == nil && (o.meter.local == nil || o.meter.local.interceptors.Load() == nil) {
+		o.inner.Observe(v, tags)
+		return
+	}
+	m := &Measurement{
+		Kind:  KindGauge,
+		Name:  o.name,
+		Value: v,
+	}
+	for k, value := range tags {
+		m.Tags = append(m.Tags, attribute.String(k, value))
+	}
+	if o.meter.hook != nil && !o.meter.hook(o.ctx, m) {
+		return
+	}
+	if !o.meter.intercept(o.ctx, m) {
+		return
+	}
+	observed := make(map[string]string, len(m.Tags))
+	for _, kv := range m.Tags {
+		observed[string(kv.Key)] = kv.Value.Emit()
+	}
+	o.inner.Observe(m.Value, observed)
+}
@@ -0,0 +1,249 @@
+// Package dual provides the instruments recording every measurement to two instruments, the instruments of the new and
+// the old name of a renamed metric during its transition period.
+package dual
+
+import (
+	"context"
+	"errors"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/tag"
+	"go.opentelemetry.io/otel/attribute"
+	"time"
+)
+
+var (
+	_ interfaces.Counter       = (*Counter)(nil)
+	_ interfaces.Readable      = (*Counter)(nil)
+	_ interfaces.UpDownCounter = (*UpDownCounter)(nil)
+	_ interfaces.Readable      = (*UpDownCounter)(nil)
+	_ interfaces.Gauge         = (*Gauge)(nil)
+	_ interfaces.Readable      = (*Gauge)(nil)
+	_ interfaces.Histogram     = (*Histogram)(nil)
+	_ interfaces.Registration  = (*Registration)(nil)
+)
+
+// value returns the value read back from the primary instrument, if readable.
+func value(ctx context.Context, primary any) (float64, bool) {
+	if readable, ok := primary.(interfaces.Readable); ok {
+		return readable.Value(ctx)
+	}
+	return 0, false
+}
+
+// Counter records every increment to both counters. Its value is read back from the primary one.
+type Counter struct {
+	primary, alias interfaces.Counter
+}
+
+// NewCounter creates the Counter recording to primary and alias.
+func NewCounter(primary, alias interfaces.Counter) *Counter {
+	return &Counter{primary: primary, alias: alias}
+}
+
+// Incr increments both counters by delta.
+func (c *Counter) Incr(ctx context.Context, delta float64) {
+	c.primary.Incr(ctx, delta)
+	c.alias.Incr(ctx, delta)
+}
+
+// IncrOne increments both counters by one.
+func (c *Counter) IncrOne(ctx context.Context) {
+	c.Incr(ctx, 1)
+}
+
+// Value returns the value of the primary counter.
+func (c *Counter) Value(ctx context.Context) (float64, bool) {
+	return value(ctx, c.primary)
+}
+
+// AddTag adds a tag to both counters.
+func (c *Counter) AddTag(key string, value string) interfaces.Counter {
+	c.primary, c.alias = c.primary.AddTag(key, value), c.alias.AddTag(key, value)
+	return c
+}
+
+// WithTags adds the tags of the map to both counters.
+func (c *Counter) WithTags(tags map[string]string) interfaces.Counter {
+	c.primary, c.alias = c.primary.WithTags(tags), c.alias.WithTags(tags)
+	return c
+}
+
+// AddAttributes adds typed attributes to both counters.
+func (c *Counter) AddAttributes(attrs ...attribute.KeyValue) interfaces.Counter {
+	c.primary, c.alias = tag.Add(c.primary, attrs...), tag.Add(c.alias, attrs...)
+	return c
+}
+
+// UpDownCounter records every update to both counters. Its value is read back from the primary one.
+type UpDownCounter struct {
+	primary, alias interfaces.UpDownCounter
+}
+
+// NewUpDownCounter creates the UpDownCounter recording to primary and alias.
+func NewUpDownCounter(primary, alias interfaces.UpDownCounter) *UpDownCounter {
+	return &UpDownCounter{primary: primary, alias: alias}
+}
+
+// Update updates both counters by delta.
+func (c *UpDownCounter) Update(ctx context.Context, delta float64) {
+	c.primary.Update(ctx, delta)
+	c.alias.Update(ctx, delta)
+}
+
+// IncrOne increments both counters by one.
+func (c *UpDownCounter) IncrOne(ctx context.Context) {
+	c.Update(ctx, 1)
+}
+
+// DecrOne decrements both counters by one.
+func (c *UpDownCounter) DecrOne(ctx context.Context) {
+	c.Update(ctx, -1)
+}
+
+// Value returns the value of the primary counter.
+func (c *UpDownCounter) Value(ctx context.Context) (float64, bool) {
+	return value(ctx, c.primary)
+}
+
+// AddTag adds a tag to both counters.
+func (c *UpDownCounter) AddTag(key string, value string) interfaces.UpDownCounter {
+	c.primary, c.alias = c.primary.AddTag(key, value), c.alias.AddTag(key, value)
+	return c
+}
+
+// WithTags adds the tags of the map to both counters.
+func (c *UpDownCounter) WithTags(tags map[string]string) interfaces.UpDownCounter {
+	c.primary, c.alias = c.primary.WithTags(tags), c.alias.WithTags(tags)
+	return c
+}
+
+// AddAttributes adds typed attributes to both counters.
+func (c *UpDownCounter) AddAttributes(attrs ...attribute.KeyValue) interfaces.UpDownCounter {
+	c.primary, c.alias = tag.Add(c.primary, attrs...), tag.Add(c.alias, attrs...)
+	return c
+}
+
+// Gauge records every value to both gauges. Its value is read back from the primary one.
+type Gauge struct {
+	primary, alias interfaces.Gauge
+}
+
+// NewGauge creates the Gauge recording to primary and alias.
+func NewGauge(primary, alias interfaces.Gauge) *Gauge {
+	return &Gauge{primary: primary, alias: alias}
+}
+
+// Update records the value v to both gauges.
+func (g *Gauge) Update(ctx context.Context, v float64) {
+	g.primary.Update(ctx, v)
+	g.alias.Update(ctx, v)
+}
+
+// Value returns the value of the primary gauge.
+func (g *Gauge) Value(ctx context.Context) (float64, bool) {
+	return value(ctx, g.primary)
+}
+
+// AddTag adds a tag to both gauges.
+func (g *Gauge) AddTag(key string, value string) interfaces.Gauge {
+	g.primary, g.alias = g.primary.AddTag(key, value), g.alias.AddTag(key, value)
+	return g
+}
+
+// WithTags adds the tags of the map to both gauges.
+func (g *Gauge) WithTags(tags map[string]string) interfaces.Gauge {
+	g.primary, g.alias = g.primary.WithTags(tags), g.alias.WithTags(tags)
+	return g
+}
+
+// AddAttributes adds typed attributes to both gauges.
+func (g *Gauge) AddAttributes(attrs ...attribute.KeyValue) interfaces.Gauge {
+	g.primary, g.alias = tag.Add(g.primary, attrs...), tag.Add(g.alias, attrs...)
+	return g
+}
+
+// Histogram records every value to both histograms. The durations are measured once, on the clock of the primary
+// one, so that both record the same value.
+type Histogram struct {
+	primary, alias interfaces.Histogram
+}
+
+// NewHistogram creates the Histogram recording to primary and alias.
+func NewHistogram(primary, alias interfaces.Histogram) *Histogram {
+	return &Histogram{primary: primary, alias: alias}
+}
+
+// Clock returns the clock of the primary histogram.
+func (h *Histogram) Clock() clock.Clock {
+	return clock.From(h.primary)
+}
+
+// Update records a duration to both histograms.
+func (h *Histogram) Update(ctx context.Context, d time.Duration) {
+	h.primary.Update(ctx, d)
+	h.alias.Update(ctx, d)
+}
+
+// UpdateInSeconds records a duration in seconds to both histograms.
+func (h *Histogram) UpdateInSeconds(ctx context.Context, s float64) {
+	h.primary.UpdateInSeconds(ctx, s)
+	h.alias.UpdateInSeconds(ctx, s)
+}
+
+// UpdateInMilliseconds records a duration in milliseconds to both histograms.
+func (h *Histogram) UpdateInMilliseconds(ctx context.Context, m float64) {
+	h.primary.UpdateInMilliseconds(ctx, m)
+	h.alias.UpdateInMilliseconds(ctx, m)
+}
+
+// UpdateSine records the duration elapsed since start to both histograms.
+func (h *Histogram) UpdateSine(ctx context.Context, start time.Time) {
+	h.Update(ctx, h.Clock().Since(start))
+}
+
+// Time records the duration of f, called once, to both histograms.
+func (h *Histogram) Time(f func()) {
+	start := h.Clock().Now()
+	f()
+	h.UpdateSine(context.Background(), start)
+}
+
+// Record records the raw value v to both histograms.
+func (h *Histogram) Record(ctx context.Context, v float64) {
+	h.primary.Record(ctx, v)
+	h.alias.Record(ctx, v)
+}
+
+// AddTag adds a tag to both histograms.
+func (h *Histogram) AddTag(key string, value string) interfaces.Histogram {
+	h.primary, h.alias = h.primary.AddTag(key, value), h.alias.AddTag(key, value)
+	return h
+}
+
+// WithTags adds the tags of the map to both histograms.
+func (h *Histogram) WithTags(tags map[string]string) interfaces.Histogram {
+	h.primary, h.alias = h.primary.WithTags(tags), h.alias.WithTags(tags)
+	return h
+}
+
+// AddAttributes adds typed attributes to both histograms.
+func (h *Histogram) AddAttributes(attrs ...attribute.KeyValue) interfaces.Histogram {
+	h.primary, h.alias = tag.Add(h.primary, attrs...), tag.Add(h.alias, attrs...)
+	return h
+}
+
+// Registration unregisters the callbacks of both observable instruments.
+type Registration struct {
+	primary, alias interfaces.Registration
+}
+
+// NewRegistration creates the Registration unregistering primary and alias.
+func NewRegistration(primary, alias interfaces.Registration) *Registration {
+	return &Registration{primary: primary, alias: alias}
+}
+
+// Unregister unregisters both callbacks, returning their errors joined.
+func (r *Registration) Unregister() error {
+	return errors.Join(r.primary.Unregister(), r.alias.Unregister())
+}
@@ -0,0 +1,21 @@
+package nop
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"time"
+)
+
+var _ interfaces.ComponentHistogram = (*nopComponentHistogram)(nil)
+
+type nopComponentHistogram struct{}
+
+var ComponentHistogram = &nopComponentHistogram{}
+
+func (n *nopComponentHistogram) Update(_ context.Context, _ time.Duration) {}
+
+func (n *nopComponentHistogram) UpdateInSeconds(_ context.Context) {}
+
+func (n *nopComponentHistogram) UpdateInMilliseconds(_ context.Context) {}
+
+func (n *nopComponentHistogram) UpdateSine(_ context.Context, _ time.Time) {}
@@ -3,6 +3,7 @@ package nop
 import (
 	"context"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // _ is a blank identifier used for type assertion to ensure that nopCounter satisfies the interfaces.Counter interface requirements.
@@ -29,3 +30,6 @@ func (n *nopCounter) AddTag(_ string, _ string) interfaces.Counter { return n }
 
 // WithTags initializes all tags for the counter using the provided map. It adheres to the same tag key-value format validation rules. This method is part of the no-operation logic and returns the receiver as is.
 func (n *nopCounter) WithTags(_ map[string]string) interfaces.Counter { return n }
+
+// AddAttributes ignores the typed attributes and returns the receiver as is.
+func (n *nopCounter) AddAttributes(_ ...attribute.KeyValue) interfaces.Counter { return n }
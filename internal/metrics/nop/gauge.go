@@ -3,6 +3,7 @@ package nop
 import (
 	"context"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // _ is a blank identifier used for type assertion to ensure that nopGauge implements the interfaces.Gauge interface.
@@ -39,3 +40,6 @@ func (n *nopGauge) AddTag(_ string, _ string) interfaces.Gauge { return n }
 //
 //	The gauge instance with updated tags.
 func (n *nopGauge) WithTags(_ map[string]string) interfaces.Gauge { return n }
+
+// AddAttributes ignores the typed attributes and returns the receiver as is.
+func (n *nopGauge) AddAttributes(_ ...attribute.KeyValue) interfaces.Gauge { return n }
@@ -3,6 +3,7 @@ package nop
 import (
 	"context"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
 	"time"
 )
 
@@ -22,6 +23,11 @@ func (n *nopHistogram) UpdateSine(_ context.Context, _ time.Time) {}
 
 func (n *nopHistogram) Time(_ func()) {}
 
+func (n *nopHistogram) Record(_ context.Context, _ float64) {}
+
 func (n *nopHistogram) AddTag(_ string, _ string) interfaces.Histogram { return n }
 
 func (n *nopHistogram) WithTags(_ map[string]string) interfaces.Histogram { return n }
+
+// AddAttributes ignores the typed attributes and returns the receiver as is.
+func (n *nopHistogram) AddAttributes(_ ...attribute.KeyValue) interfaces.Histogram { return n }
@@ -0,0 +1,15 @@
+package nop
+
+import "github.com/liangweijiang/go-metric/pkg/interfaces"
+
+// _ is a blank identifier used for type assertion to ensure that nopRegistration implements the interfaces.Registration interface.
+var _ interfaces.Registration = (*nopRegistration)(nil)
+
+// nopRegistration is the registration of an observable instrument whose callback is never called.
+type nopRegistration struct{}
+
+// Registration is a no-operation registration returned by no-op observable instruments.
+var Registration = &nopRegistration{}
+
+// Unregister does nothing and returns nil.
+func (n *nopRegistration) Unregister() error { return nil }
@@ -3,6 +3,7 @@ package nop
 import (
 	"context"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // _ is a blank identifier assignment to assert that (*nopUpDownCounter)(nil) implements the interfaces.UpDownCounter interface.
@@ -32,3 +33,6 @@ func (n *nopUpDownCounter) AddTag(_ string, _ string) interfaces.UpDownCounter {
 
 // WithTags returns a new UpDownCounter with the provided tags set. This operation is a no-op and the original instance is returned unmodified.
 func (n *nopUpDownCounter) WithTags(_ map[string]string) interfaces.UpDownCounter { return n }
+
+// AddAttributes ignores the typed attributes and returns the receiver as is.
+func (n *nopUpDownCounter) AddAttributes(_ ...attribute.KeyValue) interfaces.UpDownCounter { return n }
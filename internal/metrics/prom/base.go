@@ -1,6 +1,8 @@
 package prom
 
 import (
+	"context"
+	"github.com/liangweijiang/go-metric/internal/registry"
 	"github.com/liangweijiang/go-metric/internal/tag"
 	"go.opentelemetry.io/otel/attribute"
 	"sync/atomic"
@@ -11,13 +13,39 @@ type Base struct {
 	name      string
 	tags      tag.Tags
 	completed int32
+	registry  *registry.Registry
 }
 
 // ready checks if the Base instance is ready for operations by atomically swapping the completed status from 0 to 1.
-// It returns true if the swap was successful, indicating the Base is ready; otherwise, false.
+// It returns true if the swap was successful and the metric is not disabled in the registry, otherwise false.
 // This method ensures thread-safe initialization status checking.
 func (b *Base) ready() bool {
-	return atomic.CompareAndSwapInt32(&b.completed, 0, 1)
+	if !atomic.CompareAndSwapInt32(&b.completed, 0, 1) {
+		return false
+	}
+	return b.registry.Allow(b.name)
+}
+
+// attributes returns the attributes of a measurement recorded with ctx: the tags computed by the tag providers of the
+// registry followed by the tags of the instrument, and deprecated="true" if the metric is deprecated.
+func (b *Base) attributes(ctx context.Context) []attribute.KeyValue {
+	return b.registry.DeprecatedAttributes(b.name, b.registry.Attributes(ctx, b.tags))
+}
+
+// measure returns the attributes and the value of a measurement of v recorded with ctx, as modified by the
+// interceptors of the registry, and false if they dropped it or it exceeds the cardinality limit of the metric.
+func (b *Base) measure(ctx context.Context, kind string, v float64) ([]attribute.KeyValue, float64, bool) {
+	attrs, v, ok := b.registry.Intercept(ctx, kind, b.name, b.attributes(ctx), v)
+	if !ok || !b.registry.AdmitSeries(b.name, attrs) {
+		return nil, 0, false
+	}
+	return attrs, v, true
+}
+
+// value returns the value tracked by the registry for the series of the metric with the attributes of a measurement
+// recorded with ctx.
+func (b *Base) value(ctx context.Context) (float64, bool) {
+	return b.registry.Value(b.name, b.attributes(ctx))
 }
 
 // AddTag adds a tag with the specified key and value to the Base's tags collection.
@@ -26,6 +54,11 @@ func (b *Base) AddTag(key, value string) {
 	b.tags = append(b.tags, attribute.String(key, value))
 }
 
+// AddAttributes appends typed attributes to the Base's tags collection, keeping the type of their values.
+func (b *Base) AddAttributes(attrs ...attribute.KeyValue) {
+	b.tags = append(b.tags, attrs...)
+}
+
 // WithTags sets the provided tags on the Base instance, appending them to existing tags.
 // If the input map is nil or empty, the function does nothing.
 // This method is intended to be used to add contextual metadata to metrics.
@@ -2,13 +2,21 @@ package prom
 
 import (
 	"context"
+	"github.com/liangweijiang/go-metric/internal/registry"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
 // _ is a blank identifier used for type assertion to ensure that *Counter implements the interfaces.Counter interface.
 var _ interfaces.Counter = (*Counter)(nil)
 
+// _ is a blank identifier used for type assertion to ensure that *Counter implements the interfaces.Attributed interface.
+var _ interfaces.Attributed[interfaces.Counter] = (*Counter)(nil)
+
+// _ is a blank identifier used for type assertion to ensure that *Counter implements the interfaces.Readable interface.
+var _ interfaces.Readable = (*Counter)(nil)
+
 // Counter combines a Base structure for metric identification and tagging with a metric.Float64Counter to track incremental values.
 // It provides methods to increment the counter, add tags, and manage context-specific metadata.
 type Counter struct {
@@ -22,12 +30,14 @@ type Counter struct {
 //
 //	name: The name of the counter metric.
 //	counter: The underlying Float64Counter to wrap with the Counter interface.
+//	registry: The registry of the owning meter, consulted before every increment.
 //
 // Returns an implementation of interfaces.Counter.
-func NewCounter(name string, counter metric.Float64Counter) interfaces.Counter {
+func NewCounter(name string, counter metric.Float64Counter, registry *registry.Registry) interfaces.Counter {
 	return &Counter{
 		base: Base{
-			name: name,
+			name:     name,
+			registry: registry,
 		},
 		counter: counter,
 	}
@@ -38,7 +48,18 @@ func (c *Counter) Incr(ctx context.Context, delta float64) {
 	if !c.base.ready() {
 		return
 	}
-	c.counter.Add(ctx, delta, metric.WithAttributes(c.base.tags...))
+	attrs, delta, ok := c.base.measure(ctx, interfaces.KindCounter, delta)
+	if !ok {
+		return
+	}
+	c.counter.Add(ctx, delta, metric.WithAttributes(attrs...))
+	c.base.registry.AddValue(c.base.name, attrs, delta)
+}
+
+// Value returns the total of the series of the counter with its tags, tracked locally when the meter reads values
+// back. ok is false when the values are not tracked or the series was never incremented.
+func (c *Counter) Value(ctx context.Context) (v float64, ok bool) {
+	return c.base.value(ctx)
 }
 
 // IncrOne increments the counter by one, given a context. It is a convenience method wrapping around Incr with a fixed delta of 1.
@@ -75,3 +96,10 @@ func (c *Counter) WithTags(tags map[string]string) interfaces.Counter {
 	c.base.WithTags(tags)
 	return c
 }
+
+// AddAttributes adds typed attributes, e.g. tag.Key("status").Int(200), to the Counter's base tags.
+// It returns the Counter instance to allow for method chaining.
+func (c *Counter) AddAttributes(attrs ...attribute.KeyValue) interfaces.Counter {
+	c.base.AddAttributes(attrs...)
+	return c
+}
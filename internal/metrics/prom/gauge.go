@@ -2,13 +2,21 @@ package prom
 
 import (
 	"context"
+	"github.com/liangweijiang/go-metric/internal/registry"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
 // _ is a blank identifier used for type assertion to ensure that the Gauge struct implements the interfaces.Gauge interface.
 var _ interfaces.Gauge = (*Gauge)(nil)
 
+// _ is a blank identifier used for type assertion to ensure that *Gauge implements the interfaces.Attributed interface.
+var _ interfaces.Attributed[interfaces.Gauge] = (*Gauge)(nil)
+
+// _ is a blank identifier used for type assertion to ensure that the Gauge struct implements the interfaces.Readable interface.
+var _ interfaces.Readable = (*Gauge)(nil)
+
 // Gauge is a struct representing a metric gauge which measures non-cumulative values like memory usage or CPU utilization.
 // It embeds a Base for common attributes and a Float64Gauge for gauge operations.
 type Gauge struct {
@@ -18,10 +26,11 @@ type Gauge struct {
 
 // NewGauge creates a new Gauge interface instance wrapping a metric.Float64Gauge with a given name and initial gauge.
 // It initializes the Gauge with a Base that includes the name and no initial tags.
-func NewGauge(name string, gauge metric.Float64Gauge) interfaces.Gauge {
+func NewGauge(name string, gauge metric.Float64Gauge, registry *registry.Registry) interfaces.Gauge {
 	return &Gauge{
 		base: Base{
-			name: name,
+			name:     name,
+			registry: registry,
 		},
 		gauge: gauge,
 	}
@@ -39,7 +48,18 @@ func (g *Gauge) Update(ctx context.Context, v float64) {
 	if !g.base.ready() {
 		return
 	}
-	g.gauge.Record(ctx, v, metric.WithAttributes(g.base.tags...))
+	attrs, v, ok := g.base.measure(ctx, interfaces.KindGauge, v)
+	if !ok {
+		return
+	}
+	g.gauge.Record(ctx, v, metric.WithAttributes(attrs...))
+	g.base.registry.SetValue(g.base.name, attrs, v)
+}
+
+// Value returns the last value recorded to the series of the gauge with its tags, tracked locally when the meter
+// reads values back. ok is false when the values are not tracked or the series was never updated.
+func (g *Gauge) Value(ctx context.Context) (v float64, ok bool) {
+	return g.base.value(ctx)
 }
 
 // AddTag adds a tag with the specified key and value to the Gauge's tags.
@@ -67,3 +87,10 @@ func (g *Gauge) WithTags(tags map[string]string) interfaces.Gauge {
 	g.base.WithTags(tags)
 	return g
 }
+
+// AddAttributes adds typed attributes, e.g. tag.Key("status").Int(200), to the Gauge's base tags.
+// It returns the Gauge instance to allow for method chaining.
+func (g *Gauge) AddAttributes(attrs ...attribute.KeyValue) interfaces.Gauge {
+	g.base.AddAttributes(attrs...)
+	return g
+}
@@ -2,7 +2,10 @@ package prom
 
 import (
 	"context"
+	"github.com/liangweijiang/go-metric/internal/registry"
+	"github.com/liangweijiang/go-metric/pkg/clock"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"time"
 )
@@ -10,6 +13,9 @@ import (
 // _ is a blank identifier used for type assertion to ensure that (*Histogram) implements the interfaces.Histogram interface.
 var _ interfaces.Histogram = (*Histogram)(nil)
 
+// _ is a blank identifier used for type assertion to ensure that *Histogram implements the interfaces.Attributed interface.
+var _ interfaces.Attributed[interfaces.Histogram] = (*Histogram)(nil)
+
 // Histogram represents a distribution of values over time.
 // It is used to measure value distributions and supports updating with different time units.
 // Histogram also allows adding tags for context and provides a method to time functions and record their durations.
@@ -24,14 +30,16 @@ type Histogram struct {
 //
 //	name: The name of the histogram metric.
 //	histogram: The underlying float64 histogram implementation to use.
+//	registry: The registry of the owning meter, consulted before every record.
 //
 // Returns:
 //
 //	An interfaces.Histogram instance for tracking value distributions over time.
-func NewHistogram(name string, histogram metric.Float64Histogram) interfaces.Histogram {
+func NewHistogram(name string, histogram metric.Float64Histogram, registry *registry.Registry) interfaces.Histogram {
 	return &Histogram{
 		base: Base{
-			name: name,
+			name:     name,
+			registry: registry,
 		},
 		histogram: histogram,
 	}
@@ -48,10 +56,23 @@ func (h *Histogram) Update(ctx context.Context, d time.Duration) {
 // It requires a context to optionally associate the update with a tracing span.
 // No operation is performed if the histogram's base is not ready.
 func (h *Histogram) UpdateInSeconds(ctx context.Context, s float64) {
+	h.Record(ctx, s)
+}
+
+// Record records the raw value v to the histogram, e.g. a size in bytes or a count.
+// No operation is performed if the histogram's base is not ready.
+func (h *Histogram) Record(ctx context.Context, v float64) {
 	if !h.base.ready() {
 		return
 	}
-	h.histogram.Record(ctx, s, metric.WithAttributes(h.base.tags...))
+	v = h.base.registry.CoerceUnit(h.base.name, v)
+	attrs, v, ok := h.base.measure(ctx, interfaces.KindHistogram, v)
+	if !ok {
+		return
+	}
+	h.histogram.Record(ctx, v, metric.WithAttributes(attrs...))
+	h.base.registry.RecordExtrema(h.base.name, attrs, v)
+	h.base.registry.RecordDistribution(h.base.name, v)
 }
 
 // UpdateInMilliseconds updates the histogram with a value in milliseconds, converting it to seconds before recording.
@@ -63,6 +84,11 @@ func (h *Histogram) UpdateInMilliseconds(ctx context.Context, m float64) {
 	h.UpdateInSeconds(ctx, m/1000)
 }
 
+// Clock returns the clock of the meter of the histogram, from which the start times given to UpdateSine are taken.
+func (h *Histogram) Clock() clock.Clock {
+	return h.base.registry.Clock()
+}
+
 // UpdateSine calculates the elapsed time since the given start time and updates the histogram using UpdateInSeconds.
 // This method is useful for timing the execution of a function or process and recording its duration in seconds.
 // The update is associated with the provided context, which can include tracing spans.
@@ -71,7 +97,7 @@ func (h *Histogram) UpdateInMilliseconds(ctx context.Context, m float64) {
 //	ctx: The context carrying optional tracing information.
 //	start: The start time from which to calculate elapsed time.
 func (h *Histogram) UpdateSine(ctx context.Context, start time.Time) {
-	elapsed := time.Now().Sub(start)
+	elapsed := h.base.registry.Clock().Since(start)
 	h.UpdateInSeconds(ctx, elapsed.Seconds())
 }
 
@@ -79,7 +105,7 @@ func (h *Histogram) UpdateSine(ctx context.Context, start time.Time) {
 // It starts a timer before calling f, and upon completion, it calculates the elapsed time and updates the histogram using UpdateSine.
 // The context.Background() is used for this operation, which can be useful for tracing purposes.
 func (h *Histogram) Time(f func()) {
-	start := time.Now()
+	start := h.base.registry.Clock().Now()
 	f()
 	h.UpdateSine(context.Background(), start)
 }
@@ -108,3 +134,10 @@ func (h *Histogram) WithTags(tags map[string]string) interfaces.Histogram {
 	h.base.WithTags(tags)
 	return h
 }
+
+// AddAttributes adds typed attributes, e.g. tag.Key("status").Int(200), to the Histogram's base tags.
+// It returns the Histogram instance to allow for method chaining.
+func (h *Histogram) AddAttributes(attrs ...attribute.KeyValue) interfaces.Histogram {
+	h.base.AddAttributes(attrs...)
+	return h
+}
@@ -0,0 +1,63 @@
+package prom
+
+import (
+	"context"
+	"fmt"
+	"github.com/liangweijiang/go-metric/internal/registry"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// _ is a blank identifier used for type assertion to ensure that *observer implements the interfaces.Observer interface.
+var _ interfaces.Observer = (*observer)(nil)
+
+// observer adapts the OTel observer passed to a callback to interfaces.Observer for a single observable instrument.
+type observer struct {
+	ctx        context.Context
+	name       string
+	observer   metric.Observer
+	observable metric.Float64Observable
+	registry   *registry.Registry
+}
+
+// Observe reports v with the given tags and the tags of the tag providers, unless the metric is disabled in the registry
+// or the measurement is dropped by its interceptors.
+func (o *observer) Observe(v float64, tags map[string]string) {
+	if !o.registry.Allow(o.name) {
+		return
+	}
+	attributes := make([]attribute.KeyValue, 0, len(tags))
+	for k, tv := range tags {
+		attributes = append(attributes, attribute.String(k, tv))
+	}
+	attributes = o.registry.DeprecatedAttributes(o.name, o.registry.Attributes(o.ctx, attributes))
+	attributes, v, ok := o.registry.Intercept(o.ctx, interfaces.KindGauge, o.name, attributes, v)
+	if !ok || !o.registry.AdmitSeries(o.name, attributes) {
+		return
+	}
+	o.observer.ObserveFloat64(o.observable, v, metric.WithAttributes(attributes...))
+}
+
+// NewObservableCallback wraps callback into an OTel callback reporting the values of observable under the given name.
+func NewObservableCallback(name string, observable metric.Float64Observable, callback interfaces.ObservableCallback,
+	registry *registry.Registry) metric.Callback {
+	return func(ctx context.Context, o metric.Observer) (err error) {
+		if registry.CallbackDisabled(name) {
+			return nil
+		}
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				registry.CallbackPanicked(name, recovered)
+				err = fmt.Errorf("observable gauge %s callback panicked: %v", name, recovered)
+			}
+		}()
+		return callback(ctx, &observer{
+			ctx:        ctx,
+			name:       name,
+			observer:   o,
+			observable: observable,
+			registry:   registry,
+		})
+	}
+}
@@ -2,13 +2,21 @@ package prom
 
 import (
 	"context"
+	"github.com/liangweijiang/go-metric/internal/registry"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
 // _ is a blank identifier used for type assertion to ensure that *UpDownCounter implements the interfaces.UpDownCounter interface.
 var _ interfaces.UpDownCounter = (*UpDownCounter)(nil)
 
+// _ is a blank identifier used for type assertion to ensure that *UpDownCounter implements the interfaces.Attributed interface.
+var _ interfaces.Attributed[interfaces.UpDownCounter] = (*UpDownCounter)(nil)
+
+// _ is a blank identifier used for type assertion to ensure that *UpDownCounter implements the interfaces.Readable interface.
+var _ interfaces.Readable = (*UpDownCounter)(nil)
+
 type UpDownCounter struct {
 	base    Base
 	counter metric.Float64UpDownCounter
@@ -16,10 +24,11 @@ type UpDownCounter struct {
 
 // NewUpDownCounter creates a new UpDownCounter instance wrapping the provided metric.Float64UpDownCounter with a given name and optional tags management.
 // It returns an implementation of interfaces.UpDownCounter that delegates to the underlying counter for Update, IncrOne, DecrOne, AddTag, and WithTags operations.
-func NewUpDownCounter(name string, counter metric.Float64UpDownCounter) interfaces.UpDownCounter {
+func NewUpDownCounter(name string, counter metric.Float64UpDownCounter, registry *registry.Registry) interfaces.UpDownCounter {
 	return &UpDownCounter{
 		base: Base{
-			name: name,
+			name:     name,
+			registry: registry,
 		},
 		counter: counter,
 	}
@@ -33,7 +42,18 @@ func (c *UpDownCounter) Update(ctx context.Context, delta float64) {
 	if !c.base.ready() {
 		return
 	}
-	c.counter.Add(ctx, delta, metric.WithAttributes(c.base.tags...))
+	attrs, delta, ok := c.base.measure(ctx, interfaces.KindUpDownCounter, delta)
+	if !ok {
+		return
+	}
+	c.counter.Add(ctx, delta, metric.WithAttributes(attrs...))
+	c.base.registry.AddValue(c.base.name, attrs, delta)
+}
+
+// Value returns the sum of the series of the UpDownCounter with its tags, tracked locally when the meter reads values
+// back. ok is false when the values are not tracked or the series was never updated.
+func (c *UpDownCounter) Value(ctx context.Context) (v float64, ok bool) {
+	return c.base.value(ctx)
 }
 
 // IncrOne increments the UpDownCounter by one, given a context. This is a convenience method wrapping around Update with a delta of 1.
@@ -60,3 +80,10 @@ func (c *UpDownCounter) WithTags(tags map[string]string) interfaces.UpDownCounte
 	c.base.WithTags(tags)
 	return c
 }
+
+// AddAttributes adds typed attributes, e.g. tag.Key("status").Int(200), to the UpDownCounter's base tags.
+// It returns the UpDownCounter instance to allow for method chaining.
+func (c *UpDownCounter) AddAttributes(attrs ...attribute.KeyValue) interfaces.UpDownCounter {
+	c.base.AddAttributes(attrs...)
+	return c
+}
@@ -0,0 +1,109 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/utils"
+	"sync/atomic"
+)
+
+// Names and tags of the metrics exported by the disk usage collector, all tagged with the watched path.
+const (
+	diskUsedMetric       = "disk_used"
+	diskFreeMetric       = "disk_free"
+	diskInodesUsedMetric = "disk_inodes_used"
+	diskInodesFreeMetric = "disk_inodes_free"
+	tagPath              = "path"
+)
+
+// errStatfsUnsupported is returned by statfs on the platforms without statfs(2).
+var errStatfsUnsupported = errors.New("disk usage is not supported on this platform")
+
+// diskUsage holds the usage of the filesystem holding a path.
+type diskUsage struct {
+	usedBytes  uint64
+	freeBytes  uint64
+	usedInodes uint64
+	freeInodes uint64
+}
+
+// diskCollector exports, on an interval, the used and free bytes and inodes of the filesystems holding the configured
+// paths, such as the data and log directories of a daemon owning its storage.
+type diskCollector struct {
+	cfg     *config.Config
+	meter   interfaces.Meter
+	running int32
+	closeCh chan struct{}
+}
+
+// NewDiskCollector initializes a collector of the usage of the filesystems holding cfg.DiskUsagePaths.
+// It only collects when at least one path is configured.
+func NewDiskCollector(cfg *config.Config, meter interfaces.Meter) interfaces.MetricCollector {
+	return &diskCollector{
+		cfg:     cfg,
+		meter:   meter,
+		closeCh: make(chan struct{}),
+	}
+}
+
+// Start spawns the collection loop if paths are configured and it is not running yet.
+func (c *diskCollector) Start() {
+	if len(c.cfg.DiskUsagePaths) == 0 {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&c.running, 0, 1) {
+		c.cfg.WriteErrorOrNot("disk usage metrics collect is already running")
+		return
+	}
+	c.cfg.WriteInfoOrNot("disk usage metrics collect is enabled")
+	go c.collect()
+}
+
+// collect records the usage of the paths right away, then at every interval, randomized by the configured jitter,
+// until the collector is stopped.
+func (c *diskCollector) collect() {
+	c.collectDiskUsage()
+	interval := c.cfg.GetDiskUsageInterval()
+	timer := c.cfg.GetClock().NewTimer(utils.Jitter(interval, c.cfg.TickerJitter))
+	defer timer.Stop()
+	for {
+		select {
+		case <-c.closeCh:
+			c.cfg.WriteInfoOrNot("stop disk usage metrics collect")
+			return
+		case <-timer.C():
+			c.collectDiskUsage()
+			timer.Reset(utils.Jitter(interval, c.cfg.TickerJitter))
+		}
+	}
+}
+
+// Stop halts the collection loop, it does nothing if the collector is not running.
+func (c *diskCollector) Stop() {
+	if !atomic.CompareAndSwapInt32(&c.running, 1, 0) {
+		return
+	}
+	c.closeCh <- struct{}{}
+}
+
+// collectDiskUsage updates the gauges of every configured path, the paths that cannot be read are logged and skipped.
+func (c *diskCollector) collectDiskUsage() {
+	ctx := context.Background()
+	for _, path := range c.cfg.DiskUsagePaths {
+		usage, err := statfs(path)
+		if err != nil {
+			c.cfg.WriteErrorOrNot("failed to collect disk usage of " + path + ": " + err.Error())
+			continue
+		}
+		c.meter.NewGauge(diskUsedMetric, "used bytes of the filesystem holding the path", config.UnitBytes).
+			AddTag(tagPath, path).Update(ctx, float64(usage.usedBytes))
+		c.meter.NewGauge(diskFreeMetric, "bytes of the filesystem holding the path available to the process", config.UnitBytes).
+			AddTag(tagPath, path).Update(ctx, float64(usage.freeBytes))
+		c.meter.NewGauge(diskInodesUsedMetric, "used inodes of the filesystem holding the path", "").
+			AddTag(tagPath, path).Update(ctx, float64(usage.usedInodes))
+		c.meter.NewGauge(diskInodesFreeMetric, "free inodes of the filesystem holding the path", "").
+			AddTag(tagPath, path).Update(ctx, float64(usage.freeInodes))
+	}
+}
@@ -0,0 +1,34 @@
+package process
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatfs(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("statfs is only supported on linux")
+	}
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "Directory", path: t.TempDir()},
+		{name: "Missing", path: filepath.Join(t.TempDir(), "missing"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage, err := statfs(tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Positive(t, usage.usedBytes+usage.freeBytes)
+		})
+	}
+}
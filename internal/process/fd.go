@@ -0,0 +1,201 @@
+package process
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Names and tags of the metrics exported by the file descriptor collector.
+const (
+	openFDsMetric        = "process_open_fds"
+	maxFDsMetric         = "process_max_fds"
+	tcpConnectionsMetric = "process_tcp_connections"
+	tagTCPState          = "state"
+)
+
+// procDir is the root of the proc filesystem, only available on Linux.
+const procDir = "/proc"
+
+// tcpStates maps the hexadecimal states of /proc/net/tcp to their names.
+var tcpStates = map[string]string{
+	"01": "established",
+	"02": "syn_sent",
+	"03": "syn_recv",
+	"04": "fin_wait1",
+	"05": "fin_wait2",
+	"06": "time_wait",
+	"07": "close",
+	"08": "close_wait",
+	"09": "last_ack",
+	"0A": "listen",
+	"0B": "closing",
+}
+
+// fdCollector exports the number of open file descriptors against their limit and the number of TCP connections
+// per state, read from /proc at every collection, so that the exhaustion of descriptors can be alerted on before EMFILE.
+type fdCollector struct {
+	cfg           *config.Config
+	meter         interfaces.Meter
+	running       int32
+	mu            sync.Mutex
+	registrations []interfaces.Registration
+}
+
+// NewFDCollector initializes a collector of the file descriptors and TCP connections of the process.
+// It only collects when enabled in the configuration and /proc is available.
+func NewFDCollector(cfg *config.Config, meter interfaces.Meter) interfaces.MetricCollector {
+	return &fdCollector{
+		cfg:   cfg,
+		meter: meter,
+	}
+}
+
+// Start registers the observable gauges of the collector if it is enabled and /proc is available.
+func (c *fdCollector) Start() {
+	if !c.cfg.FDMetricsCollect {
+		return
+	}
+	if _, err := os.Stat(procDir); err != nil {
+		c.cfg.WriteErrorOrNot("file descriptor metrics collect is not supported without " + procDir)
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&c.running, 0, 1) {
+		c.cfg.WriteErrorOrNot("file descriptor metrics collect is already running")
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.registrations = append(c.registrations,
+		c.meter.NewObservableGauge(openFDsMetric, "number of open file descriptors", "",
+			func(_ context.Context, o interfaces.Observer) error {
+				n, err := countOpenFDs()
+				if err != nil {
+					return err
+				}
+				o.Observe(float64(n), nil)
+				return nil
+			}),
+		c.meter.NewObservableGauge(maxFDsMetric, "soft limit of open file descriptors", "",
+			func(_ context.Context, o interfaces.Observer) error {
+				n, err := readMaxFDs()
+				if err != nil {
+					return err
+				}
+				o.Observe(float64(n), nil)
+				return nil
+			}),
+		c.meter.NewObservableGauge(tcpConnectionsMetric, "number of TCP connections per state", "",
+			func(_ context.Context, o interfaces.Observer) error {
+				states, err := readTCPStates()
+				for state, n := range states {
+					o.Observe(float64(n), map[string]string{tagTCPState: state})
+				}
+				return err
+			}),
+	)
+	c.cfg.WriteInfoOrNot("file descriptor metrics collect is enabled")
+}
+
+// Stop unregisters the observable gauges of the collector.
+func (c *fdCollector) Stop() {
+	if !atomic.CompareAndSwapInt32(&c.running, 1, 0) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, registration := range c.registrations {
+		if err := registration.Unregister(); err != nil {
+			c.cfg.WriteErrorOrNot("failed to unregister file descriptor metrics: " + err.Error())
+		}
+	}
+	c.registrations = nil
+	c.cfg.WriteInfoOrNot("stop file descriptor metrics collect")
+}
+
+// countOpenFDs returns the number of entries of /proc/self/fd.
+func countOpenFDs() (int, error) {
+	entries, err := os.ReadDir(procDir + "/self/fd")
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// readMaxFDs returns the soft limit of open files of /proc/self/limits.
+func readMaxFDs() (uint64, error) {
+	f, err := os.Open(procDir + "/self/limits")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return parseMaxFDs(f)
+}
+
+// parseMaxFDs parses the soft limit of the "Max open files" line of a limits file.
+func parseMaxFDs(r io.Reader) (uint64, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		if len(fields) == 0 {
+			break
+		}
+		if fields[0] == "unlimited" {
+			return 0, errors.New("unlimited open files")
+		}
+		return strconv.ParseUint(fields[0], 10, 64)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("no open files limit found")
+}
+
+// readTCPStates counts the TCP connections per state of /proc/self/net/tcp and tcp6, the latter being optional.
+func readTCPStates() (map[string]int, error) {
+	states := make(map[string]int)
+	for _, name := range []string{"tcp", "tcp6"} {
+		f, err := os.Open(procDir + "/self/net/" + name)
+		if err != nil {
+			if name == "tcp6" && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return states, err
+		}
+		err = parseTCPStates(f, states)
+		f.Close()
+		if err != nil {
+			return states, err
+		}
+	}
+	return states, nil
+}
+
+// parseTCPStates adds the connections per state of a /proc/net/tcp file to states.
+func parseTCPStates(r io.Reader, states map[string]int) error {
+	scanner := bufio.NewScanner(r)
+	// the first line is the header.
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		if state, ok := tcpStates[fields[3]]; ok {
+			states[state]++
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,35 @@
+package process
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMaxFDs(t *testing.T) {
+	limits := `Limit                     Soft Limit           Hard Limit           Units
+Max cpu time              unlimited            unlimited            seconds
+Max open files            1024                 524288               files
+Max locked memory         8388608              8388608              bytes
+`
+	n, err := parseMaxFDs(strings.NewReader(limits))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1024), n)
+
+	_, err = parseMaxFDs(strings.NewReader("Max cpu time unlimited unlimited seconds\n"))
+	assert.Error(t, err)
+}
+
+func TestParseTCPStates(t *testing.T) {
+	tcp := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F90 0100007F:D2A4 01 00000000:00000000 00:00000000 00000000     0        0 2 1 0000000000000000 20 4 30 10 -1
+   2: 0100007F:D2A4 0100007F:1F90 01 00000000:00000000 00:00000000 00000000     0        0 3 1 0000000000000000 20 4 30 10 -1
+   3: 0100007F:D2A6 0100007F:1F90 06 00000000:00000000 03:00001770 00000000     0        0 0 3 0000000000000000
+`
+	states := make(map[string]int)
+	require.NoError(t, parseTCPStates(strings.NewReader(tcp), states))
+	assert.Equal(t, map[string]int{"listen": 1, "established": 2, "time_wait": 1}, states)
+}
@@ -0,0 +1,20 @@
+//go:build linux
+
+package process
+
+import "syscall"
+
+// statfs returns the usage of the filesystem holding path, the free bytes being those available to unprivileged users.
+func statfs(path string) (diskUsage, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return diskUsage{}, err
+	}
+	bsize := uint64(st.Bsize)
+	return diskUsage{
+		usedBytes:  (st.Blocks - st.Bfree) * bsize,
+		freeBytes:  st.Bavail * bsize,
+		usedInodes: st.Files - st.Ffree,
+		freeInodes: st.Ffree,
+	}, nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package process
+
+// statfs is not supported outside Linux.
+func statfs(_ string) (diskUsage, error) {
+	return diskUsage{}, errStatfsUnsupported
+}
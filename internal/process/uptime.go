@@ -0,0 +1,151 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Names of the metrics exported by the uptime collector.
+// restartsMetric is the number of starts of the process recorded in the state file minus one, it survives the restarts
+// so that restart storms show up with delta() even though every other counter is reset.
+const (
+	uptimeMetric    = "process_uptime"
+	startTimeMetric = "process_start_time"
+	restartsMetric  = "process_restarts"
+)
+
+// restartState is the content of the state file.
+type restartState struct {
+	Starts    uint64    `json:"starts"`
+	LastStart time.Time `json:"last_start"`
+}
+
+// uptimeCollector exports the uptime and start time of the process and, when a state file is configured,
+// the number of restarts persisted in that file.
+type uptimeCollector struct {
+	cfg           *config.Config
+	meter         interfaces.Meter
+	start         time.Time
+	running       int32
+	once          sync.Once
+	restarts      uint64
+	persisted     bool
+	mu            sync.Mutex
+	registrations []interfaces.Registration
+}
+
+// NewUptimeCollector initializes a collector of the uptime of the process, started now.
+// It only collects when enabled in the configuration.
+func NewUptimeCollector(cfg *config.Config, meter interfaces.Meter) interfaces.MetricCollector {
+	return &uptimeCollector{
+		cfg:   cfg,
+		meter: meter,
+		start: cfg.GetClock().Now(),
+	}
+}
+
+// Start registers the observable gauges of the collector if it is enabled.
+// The first start of the process increments the restart count of the state file.
+func (c *uptimeCollector) Start() {
+	if !c.cfg.UptimeCollect {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&c.running, 0, 1) {
+		c.cfg.WriteErrorOrNot("uptime metrics collect is already running")
+		return
+	}
+	c.once.Do(func() {
+		if c.cfg.RestartStateFile == "" {
+			return
+		}
+		restarts, err := recordStart(c.cfg.RestartStateFile, c.start)
+		if err != nil {
+			c.cfg.WriteErrorOrNot("failed to persist restart count to " + c.cfg.RestartStateFile + ": " + err.Error())
+			return
+		}
+		c.restarts, c.persisted = restarts, true
+	})
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.registrations = append(c.registrations,
+		c.meter.NewObservableGauge(uptimeMetric, "time elapsed since the start of the process", "s",
+			func(_ context.Context, o interfaces.Observer) error {
+				o.Observe(c.cfg.GetClock().Since(c.start).Seconds(), nil)
+				return nil
+			}),
+		c.meter.NewObservableGauge(startTimeMetric, "start time of the process since unix epoch", "s",
+			func(_ context.Context, o interfaces.Observer) error {
+				o.Observe(float64(c.start.UnixNano())/float64(time.Second), nil)
+				return nil
+			}),
+	)
+	if c.persisted {
+		c.registrations = append(c.registrations,
+			c.meter.NewObservableGauge(restartsMetric, "number of restarts of the process persisted across restarts", "",
+				func(_ context.Context, o interfaces.Observer) error {
+					o.Observe(float64(c.restarts), nil)
+					return nil
+				}))
+	}
+	c.cfg.WriteInfoOrNot("uptime metrics collect is enabled")
+}
+
+// Stop unregisters the observable gauges of the collector.
+func (c *uptimeCollector) Stop() {
+	if !atomic.CompareAndSwapInt32(&c.running, 1, 0) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, registration := range c.registrations {
+		if err := registration.Unregister(); err != nil {
+			c.cfg.WriteErrorOrNot("failed to unregister uptime metrics: " + err.Error())
+		}
+	}
+	c.registrations = nil
+}
+
+// recordStart increments the number of starts of the state file at path, created if missing, and returns the number
+// of restarts, that is the starts before this one. The file is replaced atomically so that a crash cannot corrupt it.
+func recordStart(path string, start time.Time) (uint64, error) {
+	var state restartState
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return 0, err
+	default:
+		if err := json.Unmarshal(data, &state); err != nil {
+			return 0, err
+		}
+	}
+	state.Starts++
+	state.LastStart = start
+	if data, err = json.Marshal(state); err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+	return state.Starts - 1, nil
+}
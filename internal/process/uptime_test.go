@@ -0,0 +1,29 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "restarts.json")
+
+	for want := uint64(0); want < 3; want++ {
+		restarts, err := recordStart(path, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, want, restarts)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files must be removed")
+
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o644))
+	_, err = recordStart(path, time.Now())
+	assert.Error(t, err)
+}
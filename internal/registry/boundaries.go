@@ -0,0 +1,16 @@
+package registry
+
+// MarkUnbounded records that the histogram of the metric was created without bucket boundaries, so that it is
+// aggregated with the boundaries of the histogram view of the provider, if any.
+func (r *Registry) MarkUnbounded(name string) {
+	r.unbounded.Store(name, struct{}{})
+}
+
+// Unbounded reports whether the histogram of the metric was created without bucket boundaries.
+func (r *Registry) Unbounded(name string) bool {
+	if r == nil {
+		return false
+	}
+	_, ok := r.unbounded.Load(name)
+	return ok
+}
@@ -0,0 +1,180 @@
+package registry
+
+import (
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// sdkPackagePrefix is the prefix of the packages of the SDK, skipped when looking for the module creating a metric.
+const sdkPackagePrefix = "github.com/liangweijiang/go-metric/"
+
+// Budgets tracks the distinct metrics created by the code of every module against the number of instruments it declared.
+// A module is a package path prefix, e.g. github.com/acme/shop/payment, a metric is accounted to the longest declared
+// module containing the first package outside of the SDK creating it. It is safe for concurrent use.
+type Budgets struct {
+	budgets  map[string]int
+	strict   bool
+	report   func(s string)
+	mu       sync.Mutex
+	metrics  map[string]bool
+	usage    map[string]int
+	refused  map[string]int
+	exceeded map[string]bool
+}
+
+// NewBudgets creates the tracker of the given budgets by module. report, which may be nil, is called once for every
+// module exceeding its budget, and in strict mode the metrics beyond the budget are refused.
+func NewBudgets(budgets map[string]int, strict bool, report func(s string)) *Budgets {
+	return &Budgets{
+		budgets:  budgets,
+		strict:   strict,
+		report:   report,
+		metrics:  make(map[string]bool),
+		usage:    make(map[string]int),
+		refused:  make(map[string]int),
+		exceeded: make(map[string]bool),
+	}
+}
+
+// Admit accounts the creation of an instrument of the metric to the module of its caller and reports whether it may
+// be created: in strict mode, the metrics making a module exceed its budget are refused.
+func (b *Budgets) Admit(name string) bool {
+	if b == nil || len(b.budgets) == 0 {
+		return true
+	}
+	b.mu.Lock()
+	admitted, seen := b.metrics[name]
+	b.mu.Unlock()
+	if seen {
+		return admitted
+	}
+	module := b.moduleOf(callerPackage())
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if admitted, seen := b.metrics[name]; seen {
+		return admitted
+	}
+	if module == "" {
+		b.metrics[name] = true
+		return true
+	}
+	budget := b.budgets[module]
+	if b.usage[module] >= budget && !b.exceeded[module] {
+		b.exceeded[module] = true
+		if b.report != nil {
+			b.report(fmt.Sprintf("module %s exceeds its budget of %d instruments with metric %s", module, budget, name))
+		}
+	}
+	if b.usage[module] >= budget && b.strict {
+		b.refused[module]++
+		b.metrics[name] = false
+		return false
+	}
+	b.usage[module]++
+	b.metrics[name] = true
+	return true
+}
+
+// Usage returns the usage of the budget of every declared module, sorted by module.
+func (b *Budgets) Usage() []config.BudgetUsage {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	usage := make([]config.BudgetUsage, 0, len(b.budgets))
+	for module, budget := range b.budgets {
+		usage = append(usage, config.BudgetUsage{
+			Module:      module,
+			Budget:      budget,
+			Instruments: b.usage[module],
+			Refused:     b.refused[module],
+		})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Module < usage[j].Module
+	})
+	return usage
+}
+
+// moduleOf returns the longest declared module containing the package pkg, empty if none does.
+func (b *Budgets) moduleOf(pkg string) string {
+	var module string
+	for declared := range b.budgets {
+		if (pkg == declared || strings.HasPrefix(pkg, declared+"/")) && len(declared) > len(module) {
+			module = declared
+		}
+	}
+	return module
+}
+
+// callerPackage returns the package of the first function of the call stack outside of the SDK, see callerFrame.
+func callerPackage() string {
+	frame, ok := callerFrame()
+	if !ok {
+		return ""
+	}
+	return packageOf(frame.Function)
+}
+
+// callerFrame returns the first frame of the call stack outside of the SDK, the tests of the SDK packages being
+// considered outside, and false if there is none, e.g. in a goroutine of the SDK.
+func callerFrame() (runtime.Frame, bool) {
+	pcs := make([]uintptr, 32)
+	return frameOutsideSDK(pcs[:runtime.Callers(3, pcs)])
+}
+
+// frameOutsideSDK returns the first frame of the call stack pcs outside of the SDK, see callerFrame.
+func frameOutsideSDK(pcs []uintptr) (runtime.Frame, bool) {
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		pkg := packageOf(frame.Function)
+		if pkg == "runtime" {
+			return runtime.Frame{}, false
+		}
+		if !strings.HasPrefix(pkg, sdkPackagePrefix) || strings.HasSuffix(pkg, "_test") ||
+			strings.HasSuffix(frame.File, "_test.go") {
+			return frame, true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// createdBySDK reports whether the instrument being created is created by the SDK itself, e.g. a runtime metric or a
+// standard metric of the components, rather than by the application: whether the first frame of the call stack
+// outside of the meters is in the SDK, the tests of the SDK packages being considered outside.
+func createdBySDK() bool {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		pkg := packageOf(frame.Function)
+		if strings.HasSuffix(frame.File, "_test.go") {
+			return false
+		}
+		if pkg != sdkPackagePrefix+"meter" && pkg != sdkPackagePrefix+"internal/registry" &&
+			!strings.HasPrefix(pkg, sdkPackagePrefix+"internal/meter/") {
+			return strings.HasPrefix(pkg, sdkPackagePrefix) && !strings.HasSuffix(pkg, "_test")
+		}
+		if !more {
+			return false
+		}
+	}
+}
+
+// packageOf returns the package path of a fully qualified function name, e.g. github.com/acme/shop/payment.(*Service).Pay.
+func packageOf(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
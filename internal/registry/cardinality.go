@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"sync"
+)
+
+// cardinalitySeries holds the distinct attribute sets recorded to a metric while a cardinality limit is set.
+type cardinalitySeries struct {
+	mu   sync.Mutex
+	sets map[attribute.Distinct]struct{}
+}
+
+// LimitCardinality makes the registry refuse the measurements of the new series of a metric once it has as many
+// series as returned by limit, read for every new series so that it can be changed at runtime. A limit that is not
+// positive allows every series. It must be called before any instrument is created.
+func (r *Registry) LimitCardinality(limit func() int) {
+	r.cardinalityLimit = limit
+}
+
+// AdmitSeries reports whether a measurement of the series of the metric with the given attributes may be recorded,
+// accounting the refused measurements as dropped. The series already recorded are always admitted, a lowered limit
+// only refusing the new ones.
+func (r *Registry) AdmitSeries(name string, attrs []attribute.KeyValue) bool {
+	if r == nil || r.cardinalityLimit == nil {
+		return true
+	}
+	limit := r.cardinalityLimit()
+	if limit <= 0 {
+		return true
+	}
+	series, ok := r.cardinality.Load(name)
+	if !ok {
+		series, _ = r.cardinality.LoadOrStore(name, &cardinalitySeries{sets: make(map[attribute.Distinct]struct{})})
+	}
+	s := series.(*cardinalitySeries)
+	set := attribute.NewSet(attrs...)
+	key := set.Equivalent()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sets[key]; ok {
+		return true
+	}
+	if len(s.sets) >= limit {
+		r.drops.Record(DropReasonCardinality, name)
+		return false
+	}
+	s.sets[key] = struct{}{}
+	return true
+}
@@ -0,0 +1,74 @@
+package registry
+
+import (
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"strings"
+)
+
+// Identity is the kind, unit and description an instrument is created with.
+type Identity struct {
+	Kind string
+	Unit string
+	Desc string
+}
+
+// collision is a refused identity of a metric name, logged once.
+type collision struct {
+	name string
+	id   Identity
+}
+
+// SetNameCollisionPolicy sets the policy applied by Resolve. It must be called before any instrument is created.
+func (r *Registry) SetNameCollisionPolicy(policy config.NameCollisionPolicy) {
+	r.collisionPolicy = policy
+}
+
+// Resolve applies the name collision policy to the creation of an instrument of the metric name with id, and returns
+// the name and the identity to create it with. ok is false when the instrument must be refused.
+// The identity of the first instrument of every name, including the resolved ones, is remembered, and every refused
+// identity is logged once.
+func (r *Registry) Resolve(name string, id Identity) (string, Identity, bool) {
+	if r.collisionPolicy == config.NameCollisionIgnore {
+		return name, id, true
+	}
+	first, loaded := r.identities.LoadOrStore(name, id)
+	if !loaded || first.(Identity) == id {
+		return name, id, true
+	}
+	firstID := first.(Identity)
+	policy := r.collisionPolicy
+	if policy == config.NameCollisionSuffix && firstID.Kind == id.Kind && firstID.Unit == id.Unit {
+		policy = config.NameCollisionMerge
+	}
+	if policy == config.NameCollisionMerge && firstID.Kind != id.Kind {
+		policy = config.NameCollisionError
+	}
+	switch policy {
+	case config.NameCollisionMerge:
+		return name, Identity{Kind: id.Kind, Unit: firstID.Unit, Desc: firstID.Desc}, true
+	case config.NameCollisionSuffix:
+		suffix := sanitizeSuffix(id.Unit)
+		if firstID.Unit == id.Unit || suffix == "" {
+			suffix = id.Kind
+		}
+		return r.Resolve(name+"_"+suffix, id)
+	default:
+		if _, logged := r.collisions.LoadOrStore(collision{name: name, id: id}, struct{}{}); !logged && r.warn != nil {
+			r.warn(fmt.Sprintf("metric name collision: metric=%q kind=%q unit=%q, created before with kind=%q unit=%q",
+				name, id.Kind, id.Unit, firstID.Kind, firstID.Unit))
+		}
+		r.Drop(DropReasonNameCollision, name)
+		return name, id, false
+	}
+}
+
+// sanitizeSuffix turns a unit into a suffix of metric name, e.g. "ms" or "By", dropping the other characters.
+func sanitizeSuffix(unit string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, unit)
+}
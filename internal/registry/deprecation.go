@@ -0,0 +1,152 @@
+package registry
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// TagDeprecated is the tag added, with the value "true", to the series of the deprecated metrics.
+const TagDeprecated = "deprecated"
+
+// deprecatedAttribute is the attribute added to the series of the deprecated metrics.
+var deprecatedAttribute = attribute.String(TagDeprecated, "true")
+
+// deprecation is a deprecated metric, with the number of instruments created by every package still using it.
+type deprecation struct {
+	replacement string
+	mu          sync.Mutex
+	consumers   map[string]int64
+}
+
+// callSite is the call stack creating an instrument, the key of the consumer cached for the call site.
+type callSite [32]uintptr
+
+// DeprecatedUse is the number of instruments of a deprecated metric created by a consumer, the package calling the
+// meter.
+type DeprecatedUse struct {
+	Metric      string
+	Replacement string
+	Consumer    string
+	Count       int64
+}
+
+// Deprecate marks the metric as deprecated in favor of replacement, which may be empty: its series are tagged
+// deprecated="true", and the packages creating its instruments are accounted and logged once each.
+func (r *Registry) Deprecate(name, replacement string) {
+	value, loaded := r.deprecations.LoadOrStore(name, &deprecation{
+		replacement: replacement,
+		consumers:   make(map[string]int64),
+	})
+	if loaded {
+		d := value.(*deprecation)
+		d.mu.Lock()
+		d.replacement = replacement
+		d.mu.Unlock()
+	}
+	r.hasDeprecations.Store(true)
+}
+
+// Deprecated returns the replacement of the metric and true if the metric is deprecated.
+func (r *Registry) Deprecated(name string) (string, bool) {
+	d, ok := r.deprecation(name)
+	if !ok {
+		return "", false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.replacement, true
+}
+
+// deprecation returns the deprecation of the metric, if deprecated.
+func (r *Registry) deprecation(name string) (*deprecation, bool) {
+	if r == nil || !r.hasDeprecations.Load() {
+		return nil, false
+	}
+	value, ok := r.deprecations.Load(name)
+	if !ok {
+		return nil, false
+	}
+	return value.(*deprecation), true
+}
+
+// DeprecatedAttributes returns the attributes of a measurement of the metric: attrs, followed by deprecated="true" if
+// the metric is deprecated.
+func (r *Registry) DeprecatedAttributes(name string, attrs []attribute.KeyValue) []attribute.KeyValue {
+	if _, ok := r.deprecation(name); !ok {
+		return attrs
+	}
+	return append(attrs[:len(attrs):len(attrs)], deprecatedAttribute)
+}
+
+// usedDeprecated accounts the creation of an instrument of the metric, if deprecated, to the package of its caller,
+// logging the first use of every package. The instruments created by the SDK alone are not accounted.
+func (r *Registry) usedDeprecated(name string) {
+	d, ok := r.deprecation(name)
+	if !ok {
+		return
+	}
+	consumer := r.consumer()
+	if consumer == "" {
+		return
+	}
+	d.mu.Lock()
+	d.consumers[consumer]++
+	first, replacement := d.consumers[consumer] == 1, d.replacement
+	d.mu.Unlock()
+	if !first || r.warn == nil {
+		return
+	}
+	message := "deprecated metric " + name + " is still recorded by " + consumer
+	if replacement != "" {
+		message += ", use " + replacement + " instead"
+	}
+	r.warn(message)
+}
+
+// consumer returns the package of the caller creating the instrument, empty if created by the SDK alone. The package
+// is resolved once per call site, the instruments being created on every measurement.
+func (r *Registry) consumer() string {
+	var site callSite
+	n := runtime.Callers(4, site[:])
+	if consumer, ok := r.consumers.Load(site); ok {
+		return consumer.(string)
+	}
+	var consumer string
+	if frame, ok := frameOutsideSDK(site[:n]); ok {
+		consumer = packageOf(frame.Function)
+	}
+	r.consumers.Store(site, consumer)
+	return consumer
+}
+
+// DeprecatedUses returns the number of instruments of every deprecated metric created by every consumer, sorted by
+// metric and consumer.
+func (r *Registry) DeprecatedUses() []DeprecatedUse {
+	if r == nil || !r.hasDeprecations.Load() {
+		return nil
+	}
+	var uses []DeprecatedUse
+	r.deprecations.Range(func(name, value any) bool {
+		d := value.(*deprecation)
+		d.mu.Lock()
+		for consumer, count := range d.consumers {
+			uses = append(uses, DeprecatedUse{
+				Metric:      name.(string),
+				Replacement: d.replacement,
+				Consumer:    consumer,
+				Count:       count,
+			})
+		}
+		d.mu.Unlock()
+		return true
+	})
+	sort.Slice(uses, func(i, j int) bool {
+		if uses[i].Metric != uses[j].Metric {
+			return uses[i].Metric < uses[j].Metric
+		}
+		return uses[i].Consumer < uses[j].Consumer
+	})
+	return uses
+}
@@ -0,0 +1,58 @@
+package registry
+
+import (
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"sort"
+)
+
+// SetDictionary makes the registry describe the metrics created without a description from d, and report once
+// through warn every metric of the application missing from d. It must be called before any instrument is created.
+func (r *Registry) SetDictionary(d config.Dictionary, warn func(s string)) {
+	r.dictionary = d
+	r.dictionaryWarn = warn
+}
+
+// HasDictionary reports whether SetDictionary was called.
+func (r *Registry) HasDictionary() bool {
+	return r != nil && r.dictionary != nil
+}
+
+// Enrich returns the description to create the metric with: desc, or the help of its definition in the dictionary
+// when desc is empty. A metric missing from the dictionary is flagged on its first creation, unless it is created by
+// the SDK, e.g. a runtime metric.
+func (r *Registry) Enrich(name, desc string) string {
+	if !r.HasDictionary() {
+		return desc
+	}
+	if definition, ok := r.dictionary[name]; ok {
+		if desc == "" {
+			return definition.Help()
+		}
+		return desc
+	}
+	if _, ok := r.unknown.Load(name); ok {
+		return desc
+	}
+	flagged := !createdBySDK()
+	if _, loaded := r.unknown.LoadOrStore(name, flagged); !loaded && flagged && r.dictionaryWarn != nil {
+		r.dictionaryWarn("metric " + name + " is not in the metrics dictionary")
+	}
+	return desc
+}
+
+// UnknownMetrics returns the metrics of the application created so far and missing from the dictionary, sorted, nil
+// if there is no dictionary.
+func (r *Registry) UnknownMetrics() []string {
+	if !r.HasDictionary() {
+		return nil
+	}
+	var names []string
+	r.unknown.Range(func(name, flagged any) bool {
+		if flagged.(bool) {
+			names = append(names, name.(string))
+		}
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
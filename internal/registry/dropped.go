@@ -0,0 +1,161 @@
+package registry
+
+import (
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DropReason describes why a measurement was not delivered to the backend.
+type DropReason string
+
+const (
+
+	// DropReasonNopFallback is used when an instrument fell back to its no-op implementation,
+	// either because the meter is stopped or because the backend refused to create the instrument.
+	DropReasonNopFallback DropReason = "nop_fallback"
+
+	// DropReasonDisabled is used when the metric was muted at runtime through DisableMetric.
+	DropReasonDisabled DropReason = "disabled"
+
+	// DropReasonCardinality is used when recording the measurement would exceed a cardinality limit.
+	DropReasonCardinality DropReason = "cardinality_limit"
+
+	// DropReasonBudget is used when the metric was refused because its module exceeded its instrument budget.
+	DropReasonBudget DropReason = "budget"
+
+	// DropReasonNameCollision is used when the metric was refused because its name collides with a metric of another
+	// kind, unit or description, see config.NameCollisionError.
+	DropReasonNameCollision DropReason = "name_collision"
+
+	// DropReasonCallbackTimeout is used when the values of an observable callback were not reported because it was not
+	// done within the callback timeout, or was still running from a previous collection.
+	DropReasonCallbackTimeout DropReason = "callback_timeout"
+)
+
+// summaryTopN is the number of metrics listed per reason in a drop summary.
+const summaryTopN = 5
+
+// _ is a blank identifier used for type assertion to ensure that *DropAuditor implements the interfaces.MetricCollector interface.
+var _ interfaces.MetricCollector = (*DropAuditor)(nil)
+
+// DropAuditor counts the measurements dropped by the SDK and periodically writes a single summary line per interval,
+// instead of logging every dropped measurement on its own.
+type DropAuditor struct {
+	cfg     *config.Config
+	mu      sync.Mutex
+	counts  map[DropReason]map[string]int64
+	running int32
+	closeCh chan struct{}
+}
+
+// NewDropAuditor creates a DropAuditor writing its summaries through the logging functions of the given configuration.
+func NewDropAuditor(cfg *config.Config) *DropAuditor {
+	return &DropAuditor{
+		cfg:     cfg,
+		counts:  make(map[DropReason]map[string]int64),
+		closeCh: make(chan struct{}),
+	}
+}
+
+// Record accounts one dropped measurement of the named metric for the given reason.
+// A nil DropAuditor ignores the call.
+func (a *DropAuditor) Record(reason DropReason, metricName string) {
+	if a == nil {
+		return
+	}
+	a.cfg.WriteDebugOrNot(fmt.Sprintf("dropped measurement, metric:%s, reason:%s", metricName, reason))
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	byName, ok := a.counts[reason]
+	if !ok {
+		byName = make(map[string]int64)
+		a.counts[reason] = byName
+	}
+	byName[metricName]++
+}
+
+// Start launches the goroutine writing the periodic summaries, it does nothing if the auditor is already running.
+func (a *DropAuditor) Start() {
+	if !atomic.CompareAndSwapInt32(&a.running, 0, 1) {
+		return
+	}
+	go a.loop()
+}
+
+// Stop halts the summary goroutine after writing a last summary of the measurements dropped so far.
+func (a *DropAuditor) Stop() {
+	if !atomic.CompareAndSwapInt32(&a.running, 1, 0) {
+		return
+	}
+	a.closeCh <- struct{}{}
+}
+
+// loop writes a summary every configured interval until a stop signal is received.
+func (a *DropAuditor) loop() {
+	ticker := time.NewTicker(a.cfg.GetDropSummaryInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.summarize()
+		case <-a.closeCh:
+			a.summarize()
+			return
+		}
+	}
+}
+
+// summarize writes the counts accumulated since the previous summary and resets them.
+// Nothing is written when no measurement was dropped during the interval.
+func (a *DropAuditor) summarize() {
+	a.mu.Lock()
+	counts := a.counts
+	a.counts = make(map[DropReason]map[string]int64)
+	a.mu.Unlock()
+	if len(counts) == 0 {
+		return
+	}
+
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, string(reason))
+	}
+	sort.Strings(reasons)
+
+	parts := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		parts = append(parts, summarizeReason(reason, counts[DropReason(reason)]))
+	}
+	a.cfg.WriteErrorOrNot(fmt.Sprintf("dropped measurements in the last %s: %s",
+		a.cfg.GetDropSummaryInterval(), strings.Join(parts, "; ")))
+}
+
+// summarizeReason formats the total of a reason followed by its most dropped metrics, e.g. "disabled=12 (foo=10, bar=2)".
+func summarizeReason(reason string, byName map[string]int64) string {
+	names := make([]string, 0, len(byName))
+	var total int64
+	for name, count := range byName {
+		names = append(names, name)
+		total += count
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if byName[names[i]] == byName[names[j]] {
+			return names[i] < names[j]
+		}
+		return byName[names[i]] > byName[names[j]]
+	})
+	if len(names) > summaryTopN {
+		names = names[:summaryTopN]
+	}
+	top := make([]string, 0, len(names))
+	for _, name := range names {
+		top = append(top, fmt.Sprintf("%s=%d", name, byName[name]))
+	}
+	return fmt.Sprintf("%s=%d (%s)", reason, total, strings.Join(top, ", "))
+}
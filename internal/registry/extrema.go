@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"sync"
+)
+
+// extremaSeries holds the smallest and the largest value recorded to a series of a histogram since the last
+// collection. collected is set once the series was collected, the measurements racing with the collection then
+// recording to a new series.
+type extremaSeries struct {
+	attrs     attribute.Set
+	mu        sync.Mutex
+	min, max  float64
+	collected bool
+}
+
+// Extrema is the smallest and the largest value recorded to a series of a histogram during a collection interval.
+type Extrema struct {
+	Attrs    attribute.Set
+	Min, Max float64
+}
+
+// TrackExtrema makes the registry keep the smallest and the largest value recorded to every series of the
+// histograms, collected with CollectExtrema. It must be called before any instrument is created.
+func (r *Registry) TrackExtrema() {
+	r.extrema = &sync.Map{}
+}
+
+// TracksExtrema reports whether TrackExtrema was called.
+func (r *Registry) TracksExtrema() bool {
+	return r.extrema != nil
+}
+
+// RecordExtrema updates the extrema of the series of the histogram with the given attributes with v.
+func (r *Registry) RecordExtrema(name string, attrs []attribute.KeyValue, v float64) {
+	if r.extrema == nil {
+		return
+	}
+	series, ok := r.extrema.Load(name)
+	if !ok {
+		series, _ = r.extrema.LoadOrStore(name, &sync.Map{})
+	}
+	set := attribute.NewSet(attrs...)
+	for {
+		s, ok := series.(*sync.Map).Load(set.Equivalent())
+		if !ok {
+			s, ok = series.(*sync.Map).LoadOrStore(set.Equivalent(), &extremaSeries{attrs: set, min: v, max: v})
+			if !ok {
+				return
+			}
+		}
+		e := s.(*extremaSeries)
+		e.mu.Lock()
+		if !e.collected {
+			e.min = min(e.min, v)
+			e.max = max(e.max, v)
+			e.mu.Unlock()
+			return
+		}
+		e.mu.Unlock()
+	}
+}
+
+// CollectExtrema returns the extrema of the series of the histogram recorded to since the previous collection and
+// resets them, so that every collection interval reports its own extrema. Every series is removed before it is read, the
+// values recorded meanwhile going to the next collection.
+func (r *Registry) CollectExtrema(name string) []Extrema {
+	if r.extrema == nil {
+		return nil
+	}
+	series, ok := r.extrema.Load(name)
+	if !ok {
+		return nil
+	}
+	var result []Extrema
+	series.(*sync.Map).Range(func(key, _ any) bool {
+		s, ok := series.(*sync.Map).LoadAndDelete(key)
+		if !ok {
+			return true
+		}
+		e := s.(*extremaSeries)
+		e.mu.Lock()
+		e.collected = true
+		result = append(result, Extrema{Attrs: e.attrs, Min: e.min, Max: e.max})
+		e.mu.Unlock()
+		return true
+	})
+	return result
+}
@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Use appends interceptor to the chain of interceptors of the measurements, applied from then on to the measurements
+// of every instrument of the registry, including those created before.
+func (r *Registry) Use(interceptor interfaces.Interceptor) {
+	r.interceptorsMu.Lock()
+	defer r.interceptorsMu.Unlock()
+	var chain []interfaces.Interceptor
+	if current := r.interceptors.Load(); current != nil {
+		chain = append(chain, *current...)
+	}
+	chain = append(chain, interceptor)
+	r.interceptors.Store(&chain)
+}
+
+// Intercept gives the measurement of the metric, with the final tags of the measurement, to the interceptors in the
+// order they were added, each seeing the measurement modified by the previous ones, and returns the tags and the
+// value to record. ok is false when an interceptor dropped the measurement, the following ones are not called.
+// The name of the measurement is the name of the instrument, an interceptor changing it has no effect.
+func (r *Registry) Intercept(ctx context.Context, kind, name string, tags []attribute.KeyValue, v float64) ([]attribute.KeyValue, float64, bool) {
+	if r == nil {
+		return tags, v, true
+	}
+	chain := r.interceptors.Load()
+	if chain == nil {
+		return tags, v, true
+	}
+	m := &interfaces.Measurement{
+		Kind:  kind,
+		Name:  name,
+		Tags:  tags,
+		Value: v,
+	}
+	for _, interceptor := range *chain {
+		if !interceptor(ctx, m) {
+			return nil, 0, false
+		}
+	}
+	return m.Tags, m.Value, true
+}
@@ -0,0 +1,53 @@
+package registry
+
+import (
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"sort"
+	"sync"
+)
+
+// TrackOrigins makes the registry remember where the first instrument of every metric is created, the caller being
+// looked up once per metric. It must be called before any instrument is created.
+func (r *Registry) TrackOrigins() {
+	r.origins = &sync.Map{}
+}
+
+// TracksOrigins reports whether TrackOrigins was called.
+func (r *Registry) TracksOrigins() bool {
+	return r != nil && r.origins != nil
+}
+
+// recordOrigin records the caller creating the metric if it is the first instrument of the metric. The metrics
+// created by the SDK alone, such as its self-metrics, have no origin.
+func (r *Registry) recordOrigin(name string) {
+	if r.origins == nil {
+		return
+	}
+	if _, ok := r.origins.Load(name); ok {
+		return
+	}
+	origin := config.InstrumentOrigin{Metric: name}
+	if frame, ok := callerFrame(); ok {
+		origin.Function, origin.File, origin.Line = frame.Function, frame.File, frame.Line
+	}
+	r.origins.LoadOrStore(name, origin)
+}
+
+// Origins returns where the first instrument of the metrics was created, sorted by metric, nil if the origins are not
+// tracked.
+func (r *Registry) Origins() []config.InstrumentOrigin {
+	if !r.TracksOrigins() {
+		return nil
+	}
+	var origins []config.InstrumentOrigin
+	r.origins.Range(func(_, value any) bool {
+		if origin := value.(config.InstrumentOrigin); origin.File != "" {
+			origins = append(origins, origin)
+		}
+		return true
+	})
+	sort.Slice(origins, func(i, j int) bool {
+		return origins[i].Metric < origins[j].Metric
+	})
+	return origins
+}
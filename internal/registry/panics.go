@@ -0,0 +1,75 @@
+package registry
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Kinds of the user code whose panics are recovered by the registry.
+const (
+	PanicKindCallback    = "callback"
+	PanicKindTagProvider = "tag_provider"
+)
+
+// PanicHook is called with every panic recovered, the kind and name of the code which panicked, the recovered value
+// and whether the code is disabled from now on.
+type PanicHook func(kind, name string, recovered any, disabled bool)
+
+// panicPolicy counts the panics recovered from the observable callbacks and the tag providers and disables those
+// panicking too often, instead of crashing the goroutine collecting or recording the metrics.
+type panicPolicy struct {
+	limit    int
+	hook     PanicHook
+	counts   sync.Map
+	disabled sync.Map
+}
+
+// SetPanicPolicy disables the observable callbacks and the tag providers after limit panics, never if limit is not
+// positive, and calls hook, which may be nil, with every panic recovered. It must be called before any measurement.
+func (r *Registry) SetPanicPolicy(limit int, hook PanicHook) {
+	r.panics.limit = limit
+	r.panics.hook = hook
+}
+
+// CallbackDisabled reports whether the observable callback of the metric was disabled after panicking too often.
+func (r *Registry) CallbackDisabled(name string) bool {
+	return r.panicDisabled(PanicKindCallback, name)
+}
+
+// CallbackPanicked accounts a panic recovered from the observable callback of the metric.
+func (r *Registry) CallbackPanicked(name string, recovered any) {
+	r.panicked(PanicKindCallback, name, recovered)
+}
+
+// panicDisabled reports whether the code of the kind was disabled after panicking too often. A nil Registry disables
+// nothing.
+func (r *Registry) panicDisabled(kind, name string) bool {
+	if r == nil {
+		return false
+	}
+	_, disabled := r.panics.disabled.Load(kind + "/" + name)
+	return disabled
+}
+
+// panicked counts a panic recovered from the code of the kind, disabling it when it reaches the limit.
+func (r *Registry) panicked(kind, name string, recovered any) {
+	if r == nil {
+		return
+	}
+	key := kind + "/" + name
+	count, _ := r.panics.counts.LoadOrStore(key, new(int64))
+	n := atomic.AddInt64(count.(*int64), 1)
+	disabled := r.panics.limit > 0 && n >= int64(r.panics.limit)
+	if disabled {
+		r.panics.disabled.Store(key, struct{}{})
+	}
+	if r.panics.hook != nil {
+		r.panics.hook(kind, name, recovered, disabled)
+	}
+}
+
+// tagProviderName names the tag provider at index for the panics, after its type.
+func tagProviderName(index int, provider any) string {
+	return fmt.Sprintf("%T[%d]", provider, index)
+}
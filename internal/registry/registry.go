@@ -0,0 +1,260 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/semconv"
+	"go.opentelemetry.io/otel/attribute"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Registry keeps the runtime switches of the metrics created by a meter.
+// Every instrument holds a reference to the registry of its meter and consults it before a measurement is recorded,
+// so a metric can be muted or restored at runtime without rebuilding the instruments or redeploying the service.
+// It also carries the tag providers of the meter, whose tags are computed for every measurement,
+// the checker warning about the tag keys that nearly duplicate each other, the clock of the meter and the cached
+// decisions of its feature gate, tracks the usage of the metrics to report the unused ones and, if enabled, the values
+// of their series to read them back and the extrema of the histograms.
+type Registry struct {
+	disabled         sync.Map
+	gate             config.FeatureGate
+	gateInfo         config.InstrumentInfo
+	gated            sync.Map
+	budgets          *Budgets
+	usageWindow      time.Duration
+	usage            sync.Map
+	values           *values
+	extrema          *sync.Map
+	cardinality      sync.Map
+	cardinalityLimit func() int
+	drops            *DropAuditor
+	tagProviders     []config.TagProvider
+	tagProviderIDs   []string
+	panics           panicPolicy
+	tuning           *bucketTuning
+	origins          *sync.Map
+	dictionary       config.Dictionary
+	dictionaryWarn   func(s string)
+	unknown          sync.Map
+	deprecations     sync.Map
+	hasDeprecations  atomic.Bool
+	consumers        sync.Map
+	renames          *renames
+	unitGuards       sync.Map
+	unitCoercion     bool
+	unitWarn         func(s string)
+	keyChecker       *semconv.KeyChecker
+	nameChecker      *semconv.KeyChecker
+	conflicts        sync.Map
+	identities       sync.Map
+	collisions       sync.Map
+	unbounded        sync.Map
+	interceptorsMu   sync.Mutex
+	interceptors     atomic.Pointer[[]interfaces.Interceptor]
+	collisionPolicy  config.NameCollisionPolicy
+	warn             func(s string)
+	clock            clock.Clock
+}
+
+// NewRegistry creates an empty Registry in which all metrics are enabled.
+// Measurements refused by the registry are accounted to the given DropAuditor, which may be nil.
+func NewRegistry(drops *DropAuditor) *Registry {
+	return &Registry{
+		drops: drops,
+	}
+}
+
+// SetTagProviders sets the providers of the tags computed at record time, it must be called before any measurement.
+func (r *Registry) SetTagProviders(providers []config.TagProvider) {
+	r.tagProviders = providers
+	r.tagProviderIDs = make([]string, len(providers))
+	for i, provider := range providers {
+		r.tagProviderIDs[i] = tagProviderName(i, provider)
+	}
+}
+
+// SetClock sets the clock timing the measurements of the instruments, it must be called before any measurement.
+func (r *Registry) SetClock(c clock.Clock) {
+	r.clock = c
+}
+
+// Clock returns the clock timing the measurements of the instruments, clock.Real if none is set.
+func (r *Registry) Clock() clock.Clock {
+	if r == nil || r.clock == nil {
+		return clock.Real
+	}
+	return r.clock
+}
+
+// SetFeatureGate sets the gate deciding whether the instruments of a metric record, info holding the base tags and
+// the instance of the meter. It must be called before any instrument is created.
+func (r *Registry) SetFeatureGate(gate config.FeatureGate, info config.InstrumentInfo) {
+	r.gate = gate
+	r.gateInfo = info
+}
+
+// Gated reports whether the instruments of the metric are gated off by the feature gate. The gate is asked once per
+// metric name, later instruments of the metric reuse the answer. A nil Registry gates nothing.
+func (r *Registry) Gated(kind, name, unit string) bool {
+	if r == nil || r.gate == nil {
+		return false
+	}
+	if enabled, ok := r.gated.Load(name); ok {
+		return !enabled.(bool)
+	}
+	info := r.gateInfo
+	info.Name, info.Kind, info.Unit = name, kind, unit
+	enabled, _ := r.gated.LoadOrStore(name, r.gate.InstrumentEnabled(info))
+	return !enabled.(bool)
+}
+
+// SetBudgets sets the instrument budgets of the modules, it must be called before any instrument is created.
+func (r *Registry) SetBudgets(budgets *Budgets) {
+	r.budgets = budgets
+}
+
+// Budgets returns the instrument budgets of the modules, nil if none is set.
+func (r *Registry) Budgets() *Budgets {
+	if r == nil {
+		return nil
+	}
+	return r.budgets
+}
+
+// AdmitBudget accounts the creation of an instrument of the metric to the budget of the module creating it and
+// reports whether it may be created, accounting the refused metrics as dropped.
+func (r *Registry) AdmitBudget(name string) bool {
+	if r.Budgets().Admit(name) {
+		return true
+	}
+	r.drops.Record(DropReasonBudget, name)
+	return false
+}
+
+// EnableKeyCheck makes the registry check the tag keys of the instruments, warn is called once for every key that
+// nearly duplicates a key seen before, e.g. statusCode and status_code. It must be called before any measurement.
+func (r *Registry) EnableKeyCheck(warn func(s string)) {
+	r.keyChecker = semconv.NewKeyChecker()
+	r.warn = warn
+}
+
+// EnableNameCheck makes the registry check the names of the metrics created, warn is called once for every name that
+// nearly duplicates a name seen before, differing only by case or separators such as requestCount and request_count,
+// typically the same metric misspelled by different teams. It must be called before any instrument is created.
+func (r *Registry) EnableNameCheck(warn func(s string)) {
+	r.nameChecker = semconv.NewKeyChecker()
+	r.warn = warn
+}
+
+// checkName checks the name of a metric created when the name check is enabled.
+func (r *Registry) checkName(name string) {
+	if r == nil || r.nameChecker == nil {
+		return
+	}
+	if first, conflict := r.nameChecker.Check(name); conflict {
+		r.conflicts.Store(name, first)
+		r.warn(fmt.Sprintf("metric name conflict: metric=%q conflicts_with=%q normalized=%q, use a single name for the same metric",
+			name, first, semconv.Normalize(name)))
+	}
+}
+
+// NameConflicts returns the names of the metrics nearly duplicating a metric created before, mapped to its name.
+func (r *Registry) NameConflicts() map[string]string {
+	conflicts := make(map[string]string)
+	r.conflicts.Range(func(name, first any) bool {
+		conflicts[name.(string)] = first.(string)
+		return true
+	})
+	return conflicts
+}
+
+// checkKeys checks the keys of tags when the key check is enabled.
+func (r *Registry) checkKeys(tags []attribute.KeyValue) {
+	if r.keyChecker == nil {
+		return
+	}
+	for _, kv := range tags {
+		if first, conflict := r.keyChecker.Check(string(kv.Key)); conflict {
+			r.warn(fmt.Sprintf("tag key %q nearly duplicates tag key %q, use a single key for the same dimension", kv.Key, first))
+		}
+	}
+}
+
+// Attributes returns the tags of the tag providers for a measurement recorded with ctx followed by the tags of the
+// instrument, which take precedence on duplicate keys. A nil Registry only returns the tags of the instrument.
+func (r *Registry) Attributes(ctx context.Context, tags []attribute.KeyValue) []attribute.KeyValue {
+	if r == nil {
+		return tags
+	}
+	r.checkKeys(tags)
+	if len(r.tagProviders) == 0 {
+		return tags
+	}
+	var attributes []attribute.KeyValue
+	for i, provider := range r.tagProviders {
+		attributes = append(attributes, r.providerTags(ctx, i, provider)...)
+	}
+	return append(attributes, tags...)
+}
+
+// providerTags returns the tags of the provider at index, none if it panics or was disabled after panicking too
+// often.
+func (r *Registry) providerTags(ctx context.Context, index int, provider config.TagProvider) (tags []attribute.KeyValue) {
+	name := r.tagProviderIDs[index]
+	if r.panicDisabled(PanicKindTagProvider, name) {
+		return nil
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			r.panicked(PanicKindTagProvider, name, recovered)
+			tags = nil
+		}
+	}()
+	return provider.Tags(ctx)
+}
+
+// Disable mutes the metric with the given name, measurements recorded to it are dropped until Enable is called.
+func (r *Registry) Disable(name string) {
+	r.disabled.Store(name, struct{}{})
+}
+
+// Enable restores the metric with the given name, previously disabled by Disable.
+func (r *Registry) Enable(name string) {
+	r.disabled.Delete(name)
+}
+
+// Enabled reports whether measurements of the metric with the given name should be recorded.
+// A nil Registry treats every metric as enabled.
+func (r *Registry) Enabled(name string) bool {
+	if r == nil {
+		return true
+	}
+	_, disabled := r.disabled.Load(name)
+	return !disabled
+}
+
+// Allow reports whether a measurement of the metric with the given name should be recorded,
+// accounting the measurement as dropped when the metric is disabled.
+func (r *Registry) Allow(name string) bool {
+	if r.Enabled(name) {
+		if r != nil {
+			r.recorded(name)
+		}
+		return true
+	}
+	r.drops.Record(DropReasonDisabled, name)
+	return false
+}
+
+// Drop accounts a measurement of the metric with the given name as dropped for the given reason.
+func (r *Registry) Drop(reason DropReason, name string) {
+	if r == nil {
+		return
+	}
+	r.drops.Record(reason, name)
+}
@@ -0,0 +1,186 @@
+package registry
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestRegistryAllow(t *testing.T) {
+	drops := NewDropAuditor(config.GetConfig())
+	r := NewRegistry(drops)
+
+	assert.True(t, r.Allow("http_requests"))
+
+	r.Disable("http_requests")
+	assert.False(t, r.Allow("http_requests"))
+	assert.False(t, r.Allow("http_requests"))
+	assert.True(t, r.Allow("db_requests"))
+	assert.Equal(t, int64(2), drops.counts[DropReasonDisabled]["http_requests"])
+
+	r.Enable("http_requests")
+	assert.True(t, r.Allow("http_requests"))
+}
+
+func TestRegistryAttributes(t *testing.T) {
+	tags := []attribute.KeyValue{attribute.String("shard", "explicit")}
+	var nilRegistry *Registry
+	assert.Equal(t, tags, nilRegistry.Attributes(context.Background(), tags))
+
+	r := NewRegistry(nil)
+	r.SetTagProviders([]config.TagProvider{config.TagProviderFunc(func(context.Context) []attribute.KeyValue {
+		return []attribute.KeyValue{attribute.String("shard", "3"), attribute.String("config_hash", "abc")}
+	})})
+	set := attribute.NewSet(r.Attributes(context.Background(), tags)...)
+	shard, _ := set.Value("shard")
+	assert.Equal(t, "explicit", shard.AsString(), "the tags of the instrument take precedence")
+	assert.True(t, set.HasValue("config_hash"))
+}
+
+func TestSummarizeReason(t *testing.T) {
+	got := summarizeReason("disabled", map[string]int64{"a": 1, "b": 10, "c": 1})
+	assert.Equal(t, "disabled=12 (b=10, a=1, c=1)", got)
+}
+
+func TestRegistryGated(t *testing.T) {
+	var nilRegistry *Registry
+	assert.False(t, nilRegistry.Gated("histogram", "rpc_latency_fine", "s"))
+
+	calls := 0
+	r := NewRegistry(nil)
+	r.SetFeatureGate(config.FeatureGateFunc(func(info config.InstrumentInfo) bool {
+		calls++
+		assert.Equal(t, "checkout", info.BaseTags["service"])
+		return info.Kind != "histogram"
+	}), config.InstrumentInfo{BaseTags: map[string]string{"service": "checkout"}})
+
+	assert.True(t, r.Gated("histogram", "rpc_latency_fine", "s"))
+	assert.True(t, r.Gated("histogram", "rpc_latency_fine", "s"))
+	assert.False(t, r.Gated("counter", "rpc_requests", ""))
+	assert.Equal(t, 2, calls, "the decision is cached per metric")
+}
+
+func TestRegistryNameCheck(t *testing.T) {
+	var warnings []string
+	r := NewRegistry(nil)
+	r.EnableNameCheck(func(s string) { warnings = append(warnings, s) })
+
+	r.Created("request_count")
+	r.Created("request_count")
+	r.Created("requestCount")
+	r.Created("requestCount")
+	r.Created("request_latency")
+
+	assert.Len(t, warnings, 1, "a conflict is reported once")
+	assert.Contains(t, warnings[0], `metric="requestCount" conflicts_with="request_count"`)
+	assert.Equal(t, map[string]string{"requestCount": "request_count"}, r.NameConflicts())
+}
+
+func TestRegistryCoerceUnit(t *testing.T) {
+	var warnings []string
+	r := NewRegistry(nil)
+	r.GuardUnits(true, func(s string) { warnings = append(warnings, s) })
+	r.WatchUnit("query_seconds", "s", []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10})
+	r.WatchUnit("payload_bytes", config.UnitBytes, config.DefaultSizeBoundaries)
+
+	assert.Equal(t, 0.2, r.CoerceUnit("query_seconds", 0.2))
+	for i := 0; i < unitGuardStreak-1; i++ {
+		assert.Equal(t, 250.0, r.CoerceUnit("query_seconds", 250))
+	}
+	assert.Empty(t, warnings)
+	assert.Equal(t, 250.0, r.CoerceUnit("query_seconds", 250), "the values before the detection are kept")
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, 0.25, r.CoerceUnit("query_seconds", 250))
+	assert.Len(t, warnings, 1, "the warning is written once")
+	assert.Equal(t, 0.2, r.CoerceUnit("query_seconds", 0.2), "the values which look like seconds are kept")
+	assert.Equal(t, 1.5, r.CoerceUnit("query_seconds", 1500))
+	assert.Equal(t, 4096.0, r.CoerceUnit("payload_bytes", 4096))
+}
+
+func TestRegistryAdmitSeries(t *testing.T) {
+	drops := NewDropAuditor(config.GetConfig())
+	r := NewRegistry(drops)
+	assert.True(t, r.AdmitSeries("http_requests", []attribute.KeyValue{attribute.String("path", "/a")}),
+		"every series is admitted without a limit")
+
+	limit := 2
+	r = NewRegistry(drops)
+	r.LimitCardinality(func() int { return limit })
+	series := func(path string) []attribute.KeyValue {
+		return []attribute.KeyValue{attribute.String("path", path)}
+	}
+
+	assert.True(t, r.AdmitSeries("http_requests", series("/a")))
+	assert.True(t, r.AdmitSeries("http_requests", series("/b")))
+	assert.False(t, r.AdmitSeries("http_requests", series("/c")))
+	assert.True(t, r.AdmitSeries("http_requests", series("/a")), "the recorded series are still admitted")
+	assert.True(t, r.AdmitSeries("db_requests", series("/c")), "the limit applies per metric")
+	assert.Equal(t, int64(1), drops.counts[DropReasonCardinality]["http_requests"])
+
+	limit = 3
+	assert.True(t, r.AdmitSeries("http_requests", series("/c")), "a raised limit admits new series")
+	limit = 0
+	assert.True(t, r.AdmitSeries("http_requests", series("/d")), "a limit that is not positive admits every series")
+}
+
+func TestRegistryCollectExtrema(t *testing.T) {
+	r := NewRegistry(nil)
+	r.TrackExtrema()
+	attrs := []attribute.KeyValue{attribute.String("path", "/a")}
+	r.RecordExtrema("latency", attrs, 2)
+	r.RecordExtrema("latency", attrs, 0.5)
+	assert.Equal(t, []Extrema{{Attrs: attribute.NewSet(attrs...), Min: 0.5, Max: 2}}, r.CollectExtrema("latency"))
+	assert.Empty(t, r.CollectExtrema("latency"), "the extrema are reset by the collection")
+
+	// the values recorded during the collections are reported by one of them.
+	const n = 1000
+	var wg sync.WaitGroup
+	for i := 1; i <= n; i++ {
+		wg.Add(1)
+		go func(v float64) {
+			defer wg.Done()
+			r.RecordExtrema("latency", attrs, v)
+		}(float64(i))
+	}
+	lowest, highest := float64(n+1), 0.0
+	collect := func() {
+		for _, e := range r.CollectExtrema("latency") {
+			lowest, highest = min(lowest, e.Min), max(highest, e.Max)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		collect()
+	}
+	wg.Wait()
+	collect()
+	assert.Equal(t, 1.0, lowest)
+	assert.Equal(t, float64(n), highest)
+}
+
+func TestRegistryDeprecatedConsumer(t *testing.T) {
+	var warnings []string
+	r := NewRegistry(nil)
+	r.warn = func(s string) { warnings = append(warnings, s) }
+	r.Deprecate("orders_total", "orders_placed_total")
+	for i := 0; i < 3; i++ {
+		r.Created("orders_total")
+	}
+
+	sites := 0
+	r.consumers.Range(func(_, _ any) bool {
+		sites++
+		return true
+	})
+	assert.Equal(t, 1, sites, "the consumer is resolved once per call site")
+	assert.Equal(t, []DeprecatedUse{{
+		Metric:      "orders_total",
+		Replacement: "orders_placed_total",
+		Consumer:    "github.com/liangweijiang/go-metric/internal/registry",
+		Count:       3,
+	}}, r.DeprecatedUses())
+	assert.Len(t, warnings, 1)
+}
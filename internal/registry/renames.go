@@ -0,0 +1,51 @@
+package registry
+
+import (
+	"time"
+)
+
+// renames is the transition of renamed metrics, from their old to their new names.
+type renames struct {
+	to    map[string]string
+	from  map[string]string
+	until time.Time
+}
+
+// SetRenames renames the metrics of renamed, from their old to their new names. Until the deadline, never if zero,
+// the instruments of either name record to both names, afterward the instruments of the old names record to the new
+// names alone.
+func (r *Registry) SetRenames(renamed map[string]string, until time.Time) {
+	if len(renamed) == 0 {
+		r.renames = nil
+		return
+	}
+	rn := &renames{
+		to:    make(map[string]string, len(renamed)),
+		from:  make(map[string]string, len(renamed)),
+		until: until,
+	}
+	for from, to := range renamed {
+		rn.to[from] = to
+		rn.from[to] = from
+	}
+	r.renames = rn
+}
+
+// Rename returns the name to create the instruments of the metric with, and the alias to record them to as well,
+// empty when the metric is not renamed or its transition has ended.
+func (r *Registry) Rename(name string) (string, string) {
+	if r == nil || r.renames == nil {
+		return name, ""
+	}
+	transition := r.renames.until.IsZero() || r.Clock().Now().Before(r.renames.until)
+	if to, ok := r.renames.to[name]; ok {
+		if !transition {
+			return to, ""
+		}
+		return to, name
+	}
+	if from, ok := r.renames.from[name]; ok && transition {
+		return name, from
+	}
+	return name, ""
+}
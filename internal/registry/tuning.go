@@ -0,0 +1,106 @@
+package registry
+
+import (
+	"github.com/liangweijiang/go-metric/pkg/analyze"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// bucketTuning records the distribution of the values of every histogram during a window, and recommends at the end
+// of the window boundaries fitting them better than the configured ones.
+type bucketTuning struct {
+	window     time.Duration
+	report     func([]analyze.BucketRecommendation)
+	end        atomic.Int64
+	histograms sync.Map
+	mu         sync.Mutex
+	last       []analyze.BucketRecommendation
+}
+
+// tunedHistogram is the distribution of the values recorded to a histogram during the current window.
+type tunedHistogram struct {
+	bounds       []float64
+	distribution atomic.Pointer[analyze.Distribution]
+}
+
+// TuneBuckets makes the registry record the distribution of the values of the histograms for window, and recommend
+// better boundaries at the end of every window, passed to report, which may be nil. The windows are closed by the
+// first record or call of BucketRecommendations after their end. It must be called before any instrument is created.
+func (r *Registry) TuneBuckets(window time.Duration, report func([]analyze.BucketRecommendation)) {
+	r.tuning = &bucketTuning{
+		window: window,
+		report: report,
+	}
+	r.tuning.end.Store(r.Clock().Now().Add(window).UnixNano())
+}
+
+// TunesBuckets reports whether TuneBuckets was called.
+func (r *Registry) TunesBuckets() bool {
+	return r != nil && r.tuning != nil
+}
+
+// WatchBuckets starts recording the distribution of the histogram with the given boundaries, on its first creation.
+func (r *Registry) WatchBuckets(name string, bounds []float64) {
+	if r.tuning == nil {
+		return
+	}
+	if _, ok := r.tuning.histograms.Load(name); ok {
+		return
+	}
+	h := &tunedHistogram{bounds: bounds}
+	h.distribution.Store(analyze.NewDistribution())
+	r.tuning.histograms.LoadOrStore(name, h)
+}
+
+// RecordDistribution accounts v in the distribution of the histogram, closing the window when it ended.
+func (r *Registry) RecordDistribution(name string, v float64) {
+	if r.tuning == nil {
+		return
+	}
+	if h, ok := r.tuning.histograms.Load(name); ok {
+		h.(*tunedHistogram).distribution.Load().Observe(v)
+	}
+	r.closeTuningWindow()
+}
+
+// BucketRecommendations returns the recommendations of the last window closed, sorted by metric, nil if the buckets
+// are not tuned or no window was closed yet.
+func (r *Registry) BucketRecommendations() []analyze.BucketRecommendation {
+	if r.tuning == nil {
+		return nil
+	}
+	r.closeTuningWindow()
+	r.tuning.mu.Lock()
+	defer r.tuning.mu.Unlock()
+	return r.tuning.last
+}
+
+// closeTuningWindow recommends the boundaries of the histograms recorded to during the window if it ended, and
+// starts the next window.
+func (r *Registry) closeTuningWindow() {
+	now := r.Clock().Now()
+	end := r.tuning.end.Load()
+	if now.UnixNano() < end || !r.tuning.end.CompareAndSwap(end, now.Add(r.tuning.window).UnixNano()) {
+		return
+	}
+	var recommendations []analyze.BucketRecommendation
+	r.tuning.histograms.Range(func(name, value any) bool {
+		h := value.(*tunedHistogram)
+		distribution := h.distribution.Swap(analyze.NewDistribution())
+		if distribution.Count() > 0 {
+			recommendations = append(recommendations, analyze.RecommendBuckets(name.(string), h.bounds, distribution))
+		}
+		return true
+	})
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].Metric < recommendations[j].Metric
+	})
+	r.tuning.mu.Lock()
+	r.tuning.last = recommendations
+	r.tuning.mu.Unlock()
+	if r.tuning.report != nil && len(recommendations) > 0 {
+		r.tuning.report(recommendations)
+	}
+}
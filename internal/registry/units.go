@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+)
+
+// Heuristic of the unit guard: a histogram in seconds recorded to with unitGuardStreak consecutive values larger than
+// its largest boundary and unitGuardFactor times its smallest one is most likely recorded to in milliseconds.
+const (
+	unitGuardStreak = 20
+	unitGuardFactor = 1000
+)
+
+// unitGuard watches the values recorded to a histogram in seconds.
+type unitGuard struct {
+	threshold float64
+	streak    atomic.Int32
+	detected  atomic.Bool
+}
+
+// GuardUnits makes the registry warn once about every histogram in seconds recorded to with values which look like
+// milliseconds, and divide by 1000 its following values which look so if coerce is true. It must be called before any
+// instrument is created.
+func (r *Registry) GuardUnits(coerce bool, warn func(s string)) {
+	r.unitCoercion = coerce
+	r.unitWarn = warn
+}
+
+// WatchUnit starts guarding the unit of the histogram with the given boundaries, on its first creation. Only the
+// histograms in seconds with finite boundaries are guarded.
+func (r *Registry) WatchUnit(name, unit string, bounds []float64) {
+	if r.unitWarn == nil || unit != "s" || len(bounds) == 0 {
+		return
+	}
+	if _, ok := r.unitGuards.Load(name); ok {
+		return
+	}
+	smallest, largest := math.Inf(1), math.Inf(-1)
+	for _, bound := range bounds {
+		if bound > 0 {
+			smallest = math.Min(smallest, bound)
+		}
+		largest = math.Max(largest, bound)
+	}
+	if math.IsInf(smallest, 0) || math.IsInf(largest, 0) {
+		return
+	}
+	g := &unitGuard{threshold: math.Max(largest, unitGuardFactor*smallest)}
+	r.unitGuards.LoadOrStore(name, g)
+}
+
+// CoerceUnit returns the value to record to the histogram: v, or v converted to seconds if it looks like milliseconds,
+// above the threshold of the guard, once the histogram was found recorded to in milliseconds and the unit coercion is
+// enabled. The values which look like seconds are kept, the histogram may be recorded to in both units.
+func (r *Registry) CoerceUnit(name string, v float64) float64 {
+	if r == nil || r.unitWarn == nil {
+		return v
+	}
+	value, ok := r.unitGuards.Load(name)
+	if !ok {
+		return v
+	}
+	g := value.(*unitGuard)
+	if g.detected.Load() {
+		if r.unitCoercion && v > g.threshold {
+			return v / 1000
+		}
+		return v
+	}
+	if v <= g.threshold {
+		g.streak.Store(0)
+		return v
+	}
+	if g.streak.Add(1) < unitGuardStreak || !g.detected.CompareAndSwap(false, true) {
+		return v
+	}
+	message := fmt.Sprintf("histogram %s in seconds was recorded to with %d consecutive values above %g, "+
+		"they look like milliseconds", name, unitGuardStreak, g.threshold)
+	if r.unitCoercion {
+		message += ", the next values above the threshold are divided by 1000"
+	}
+	r.unitWarn(message)
+	return v
+}
@@ -0,0 +1,81 @@
+package registry
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// usage holds when a metric was first created and last recorded, in nanoseconds of the clock of the registry.
+type usage struct {
+	created  int64
+	recorded atomic.Int64
+}
+
+// TrackUsage makes the registry remember when every metric is created and recorded, so that Unused can report the
+// metrics not recorded for window. It must be called before any instrument is created.
+func (r *Registry) TrackUsage(window time.Duration) {
+	r.usageWindow = window
+}
+
+// UsageWindow returns the window of TrackUsage, zero if the usage is not tracked.
+func (r *Registry) UsageWindow() time.Duration {
+	if r == nil {
+		return 0
+	}
+	return r.usageWindow
+}
+
+// Created records the creation of an instrument of the metric when the usage is tracked, its origin when the origins
+// are tracked and its consumer when the metric is deprecated, and checks its name when the name check is enabled.
+func (r *Registry) Created(name string) {
+	r.checkName(name)
+	r.recordOrigin(name)
+	r.usedDeprecated(name)
+	if r.UsageWindow() <= 0 {
+		return
+	}
+	if _, ok := r.usage.Load(name); ok {
+		return
+	}
+	r.usage.LoadOrStore(name, &usage{created: r.Clock().Now().UnixNano()})
+}
+
+// Untrack stops tracking the usage of the metric until its next creation, e.g. for a self-metric observing nothing
+// most of the time, and forgets its origin.
+func (r *Registry) Untrack(name string) {
+	r.usage.Delete(name)
+	if r.origins != nil {
+		r.origins.Delete(name)
+	}
+}
+
+// recorded records a measurement of the metric when the usage is tracked.
+func (r *Registry) recorded(name string) {
+	if r.usageWindow <= 0 {
+		return
+	}
+	if u, ok := r.usage.Load(name); ok {
+		u.(*usage).recorded.Store(r.Clock().Now().UnixNano())
+	}
+}
+
+// Unused returns the sorted names of the metrics created more than the window of TrackUsage ago and not recorded
+// during the last window, nil if the usage is not tracked.
+func (r *Registry) Unused() []string {
+	window := r.UsageWindow()
+	if window <= 0 {
+		return nil
+	}
+	since := r.Clock().Now().Add(-window).UnixNano()
+	var unused []string
+	r.usage.Range(func(name, value any) bool {
+		u := value.(*usage)
+		if u.created <= since && u.recorded.Load() <= since {
+			unused = append(unused, name.(string))
+		}
+		return true
+	})
+	sort.Strings(unused)
+	return unused
+}
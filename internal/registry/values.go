@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// values holds the series recorded since TrackValues, by metric name.
+type values struct {
+	metrics sync.Map
+}
+
+// seriesValue is the value of a series, as the bits of float64, with its attribute set.
+type seriesValue struct {
+	attrs attribute.Set
+	bits  atomic.Uint64
+}
+
+// SeriesValue is the value of a series tracked by the registry.
+type SeriesValue struct {
+	Attrs attribute.Set
+	Value float64
+}
+
+// TrackValues makes the registry keep the value of every series of the counters, up-down counters and gauges, so
+// that it can be read back with Value, e.g. by a rate limiter consuming the numbers being exported.
+// It must be called before any instrument is created.
+func (r *Registry) TrackValues() {
+	r.values = &values{}
+}
+
+// AddValue adds delta to the tracked value of the series of the metric with the given attributes.
+func (r *Registry) AddValue(name string, attrs []attribute.KeyValue, delta float64) {
+	if r.values == nil {
+		return
+	}
+	s := r.values.load(name, attrs)
+	for {
+		old := s.bits.Load()
+		if s.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// SetValue replaces the tracked value of the series of the metric with the given attributes.
+func (r *Registry) SetValue(name string, attrs []attribute.KeyValue, v float64) {
+	if r.values == nil {
+		return
+	}
+	r.values.load(name, attrs).bits.Store(math.Float64bits(v))
+}
+
+// Value returns the tracked value of the series of the metric with the given attributes, ok is false when the values
+// are not tracked or the series was never recorded.
+func (r *Registry) Value(name string, attrs []attribute.KeyValue) (v float64, ok bool) {
+	if r.values == nil {
+		return 0, false
+	}
+	series, ok := r.values.metrics.Load(name)
+	if !ok {
+		return 0, false
+	}
+	set := attribute.NewSet(attrs...)
+	s, ok := series.(*sync.Map).Load(set.Equivalent())
+	if !ok {
+		return 0, false
+	}
+	return math.Float64frombits(s.(*seriesValue).bits.Load()), true
+}
+
+// Series returns the tracked values of all the series of the metric, nil when the values are not tracked.
+func (r *Registry) Series(name string) []SeriesValue {
+	if r.values == nil {
+		return nil
+	}
+	series, ok := r.values.metrics.Load(name)
+	if !ok {
+		return nil
+	}
+	var result []SeriesValue
+	series.(*sync.Map).Range(func(_, s any) bool {
+		result = append(result, SeriesValue{
+			Attrs: s.(*seriesValue).attrs,
+			Value: math.Float64frombits(s.(*seriesValue).bits.Load()),
+		})
+		return true
+	})
+	return result
+}
+
+// load returns the value of the series, storing zero on its first record. The attributes are deduplicated and sorted
+// like those of the exported series.
+func (v *values) load(name string, attrs []attribute.KeyValue) *seriesValue {
+	series, ok := v.metrics.Load(name)
+	if !ok {
+		series, _ = v.metrics.LoadOrStore(name, &sync.Map{})
+	}
+	set := attribute.NewSet(attrs...)
+	key := set.Equivalent()
+	if s, ok := series.(*sync.Map).Load(key); ok {
+		return s.(*seriesValue)
+	}
+	s, _ := series.(*sync.Map).LoadOrStore(key, &seriesValue{attrs: set})
+	return s.(*seriesValue)
+}
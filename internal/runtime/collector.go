@@ -56,19 +56,20 @@ func (c *collector) Start() {
 }
 
 // Collect continuously fetches runtime metrics at a predefined interval until a stop signal is received.
-// It initiates a ticker that triggers the collection process, which involves calling `collectRuntimeMetric`.
+// It initiates a timer, randomized by the configured jitter, that triggers the collection process, which involves calling `collectRuntimeMetric`.
 // The method stops when a signal is sent through `closeCh`.
 func (c *collector) Collect() {
 	c.cfg.WriteInfoOrNot("start runtime metrics collect")
-	ticker := time.NewTicker(defaultRuntimeCollectInterval)
-	defer ticker.Stop()
+	timer := c.cfg.GetClock().NewTimer(utils.Jitter(defaultRuntimeCollectInterval, c.cfg.TickerJitter))
+	defer timer.Stop()
 	for {
 		select {
 		case <-c.closeCh:
 			c.cfg.WriteInfoOrNot("stop runtime metrics collect")
 			return
-		case <-ticker.C:
+		case <-timer.C():
 			c.collectRuntimeMetric()
+			timer.Reset(utils.Jitter(defaultRuntimeCollectInterval, c.cfg.TickerJitter))
 		}
 	}
 }
@@ -0,0 +1,138 @@
+package meter
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Conventional names of the Apdex instruments: the counter of the requests, tagged with ApdexTagZone, and the gauge of
+// the score, suffixed to the name of the Apdex.
+const (
+	ApdexRequestsSuffix = "_apdex_requests"
+	ApdexScoreSuffix    = "_apdex_score"
+	ApdexTagZone        = "apdex_zone"
+)
+
+// Zones of the Apdex, the value of ApdexTagZone.
+const (
+	ApdexSatisfied  = "satisfied"
+	ApdexTolerating = "tolerating"
+	ApdexFrustrated = "frustrated"
+)
+
+// apdexCounts counts the requests of a tag set by zone since the previous collection.
+type apdexCounts struct {
+	tags       map[string]string
+	satisfied  uint64
+	tolerating uint64
+	frustrated uint64
+}
+
+// Apdex classifies the durations of the requests, e.g. of every endpoint, against a threshold T: satisfied up to T,
+// tolerating up to 4T and frustrated beyond or on error. The requests are counted by zone, and the score
+// (satisfied + tolerating/2) / total of the requests of every tag set since the previous collection is exported as a
+// gauge, tag sets without requests during the interval not being exported.
+type Apdex struct {
+	meter     interfaces.Meter
+	name      string
+	threshold time.Duration
+	mu        sync.Mutex
+	counts    map[string]*apdexCounts
+	score     interfaces.Registration
+}
+
+// NewApdex creates the Apdex name of m, e.g. NewApdex(m, "checkout", 300*time.Millisecond), exporting the counter
+// name followed by ApdexRequestsSuffix and the gauge name followed by ApdexScoreSuffix. Call Close to stop exporting
+// the score.
+func NewApdex(m interfaces.Meter, name string, threshold time.Duration) *Apdex {
+	a := &Apdex{
+		meter:     m,
+		name:      name,
+		threshold: threshold,
+		counts:    make(map[string]*apdexCounts),
+	}
+	a.score = m.NewObservableGauge(name+ApdexScoreSuffix, "apdex score of "+name+" for a threshold of "+threshold.String(), "",
+		func(_ context.Context, o interfaces.Observer) error {
+			a.mu.Lock()
+			counts := a.counts
+			a.counts = make(map[string]*apdexCounts, len(counts))
+			a.mu.Unlock()
+			for _, c := range counts {
+				total := c.satisfied + c.tolerating + c.frustrated
+				o.Observe((float64(c.satisfied)+float64(c.tolerating)/2)/float64(total), c.tags)
+			}
+			return nil
+		})
+	return a
+}
+
+// Zone returns the zone of a request of duration d.
+func (a *Apdex) Zone(d time.Duration) string {
+	switch {
+	case d <= a.threshold:
+		return ApdexSatisfied
+	case d <= 4*a.threshold:
+		return ApdexTolerating
+	default:
+		return ApdexFrustrated
+	}
+}
+
+// Record counts a request of duration d with tags, e.g. the route, frustrated if err is not nil.
+func (a *Apdex) Record(ctx context.Context, d time.Duration, err error, tags map[string]string) {
+	zone := ApdexFrustrated
+	if err == nil {
+		zone = a.Zone(d)
+	}
+	a.meter.NewCounter(a.name+ApdexRequestsSuffix, "requests of "+a.name+" by apdex zone", "").
+		WithTags(tags).AddTag(ApdexTagZone, zone).IncrOne(ctx)
+
+	key := tagsKey(tags)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	c, ok := a.counts[key]
+	if !ok {
+		c = &apdexCounts{tags: tags}
+		a.counts[key] = c
+	}
+	switch zone {
+	case ApdexSatisfied:
+		c.satisfied++
+	case ApdexTolerating:
+		c.tolerating++
+	default:
+		c.frustrated++
+	}
+}
+
+// RecordSince counts a request started at start, see Record.
+func (a *Apdex) RecordSince(ctx context.Context, start time.Time, err error, tags map[string]string) {
+	a.Record(ctx, clock.From(a.meter).Since(start), err, tags)
+}
+
+// Close stops exporting the score.
+func (a *Apdex) Close() error {
+	return a.score.Unregister()
+}
+
+// tagsKey returns a key identifying a tag set.
+func tagsKey(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte(0xff)
+		sb.WriteString(tags[k])
+		sb.WriteByte(0xff)
+	}
+	return sb.String()
+}
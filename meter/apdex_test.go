@@ -0,0 +1,46 @@
+package meter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApdex(t *testing.T) {
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	a := NewApdex(m, "checkout", 100*time.Millisecond)
+	assert.Equal(t, ApdexSatisfied, a.Zone(100*time.Millisecond))
+	assert.Equal(t, ApdexTolerating, a.Zone(400*time.Millisecond))
+	assert.Equal(t, ApdexFrustrated, a.Zone(401*time.Millisecond))
+
+	pay := map[string]string{"route": "/pay"}
+	a.Record(ctx, 50*time.Millisecond, nil, pay)
+	a.Record(ctx, 80*time.Millisecond, nil, pay)
+	a.Record(ctx, 300*time.Millisecond, nil, pay)
+	a.Record(ctx, 10*time.Millisecond, errors.New("declined"), pay)
+	a.Record(ctx, time.Second, nil, map[string]string{"route": "/cart"})
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`checkout_apdex_requests_total{route="/pay",apdex_zone="satisfied"} 2`,
+		`checkout_apdex_requests_total{route="/pay",apdex_zone="tolerating"} 1`,
+		`checkout_apdex_requests_total{route="/pay",apdex_zone="frustrated"} 1`,
+		`checkout_apdex_score{route="/pay"} 0.625`,
+		`checkout_apdex_score{route="/cart"} 0`,
+	)
+
+	// the score covers the requests since the previous collection.
+	a.Record(ctx, 50*time.Millisecond, nil, pay)
+	snapshot := metertest.Scrape(t, m.GetHandler())
+	require.Len(t, snapshot["checkout_apdex_score"].GetMetric(), 1)
+	assert.Equal(t, 1.0, snapshot["checkout_apdex_score"].GetMetric()[0].GetGauge().GetValue())
+	require.NoError(t, a.Close())
+}
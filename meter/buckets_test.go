@@ -0,0 +1,78 @@
+package meter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramBoundaries(t *testing.T) {
+	tests := []struct {
+		name       string
+		boundaries []float64
+		create     func(m interfaces.Meter) interfaces.Histogram
+		want       []string
+	}{
+		{
+			name: "default boundaries",
+			create: func(m interfaces.Meter) interfaces.Histogram {
+				return m.NewHistogram("latency", "", "s")
+			},
+			want: []string{`latency_seconds_bucket{le="+Inf"} 1`},
+		},
+		{
+			name:       "configured boundaries",
+			boundaries: []float64{1, 5},
+			create: func(m interfaces.Meter) interfaces.Histogram {
+				return m.NewHistogram("latency", "", "s")
+			},
+			want: []string{`latency_seconds_bucket{le="1"} 1`, `latency_seconds_bucket{le="5"} 1`},
+		},
+		{
+			name:       "size histogram keeps its boundaries",
+			boundaries: []float64{1, 5},
+			create: func(m interfaces.Meter) interfaces.Histogram {
+				return m.NewSizeHistogram("payload", "")
+			},
+			want: []string{`payload_bytes_bucket{le="64"} 1`},
+		},
+		{
+			name: "histogram with buckets keeps its boundaries",
+			create: func(m interfaces.Meter) interfaces.Histogram {
+				return m.NewHistogramWithBuckets("latency", "", "s", []float64{0.1, 0.5})
+			},
+			want: []string{`latency_seconds_bucket{le="0.1"} 0`, `latency_seconds_bucket{le="0.5"} 1`},
+		},
+		{
+			name:       "configured preset",
+			boundaries: config.BucketsHTTPServer,
+			create: func(m interfaces.Meter) interfaces.Histogram {
+				return m.NewHistogram("latency", "", "s")
+			},
+			want: []string{`latency_seconds_bucket{le="0.1"} 0`, `latency_seconds_bucket{le="0.25"} 1`},
+		},
+		{
+			name: "histogram with preset buckets",
+			create: func(m interfaces.Meter) interfaces.Histogram {
+				return m.NewHistogramWithBuckets("query", "", "s", config.BucketsDB)
+			},
+			want: []string{`query_seconds_bucket{le="0.1"} 0`, `query_seconds_bucket{le="0.25"} 1`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus),
+				WithHistogramBoundaries(tt.boundaries))
+			require.NoError(t, err)
+			defer m.WithRunning(false)
+
+			tt.create(m).Record(context.Background(), 0.2)
+
+			metertest.ScrapeAndAssert(t, m.GetHandler(), tt.want...)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package meter_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricBudget(t *testing.T) {
+	var errs []string
+	m, err := meter.NewMeter(
+		meter.WithProviderType(config.MeterProviderTypePrometheus),
+		meter.WithMetricBudget("github.com/liangweijiang/go-metric/meter_test", 2),
+		meter.WithMetricBudget("github.com/acme/payment", 10),
+		meter.WithStrictBudgets(),
+		meter.WithErrorLogWrite(func(s string) {
+			if strings.Contains(s, "budget") {
+				errs = append(errs, s)
+			}
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, name := range []string{"budget_first", "budget_second", "budget_third", "budget_first"} {
+		m.NewCounter(name, "budgeted", config.UnitCount).IncrOne(ctx)
+	}
+	usage, ok := meter.BudgetUsage(m)
+	require.True(t, ok)
+	assert.Equal(t, []config.BudgetUsage{
+		{Module: "github.com/acme/payment", Budget: 10},
+		{Module: "github.com/liangweijiang/go-metric/meter_test", Budget: 2, Instruments: 2, Refused: 1},
+	}, usage)
+	assert.True(t, usage[1].Exceeded())
+	assert.Len(t, errs, 1)
+
+	snapshot := metertest.Scrape(t, m.GetHandler())
+	assert.Contains(t, snapshot, "budget_second_total")
+	assert.NotContains(t, snapshot, "budget_third_total")
+	metertest.ScrapeAndAssert(t, m.GetHandler(), `budget_first_total 2`)
+}
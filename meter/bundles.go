@@ -0,0 +1,160 @@
+package meter
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"sync/atomic"
+	"time"
+)
+
+// bundleMeter returns m, or the current global meter when m is nil.
+func bundleMeter(m interfaces.Meter) interfaces.Meter {
+	if m == nil {
+		return GetGlobalMeter()
+	}
+	return m
+}
+
+// REDBundle groups the Rate, Errors and Duration instruments of a service or endpoint under conventional names:
+// <name>_requests, <name>_errors and <name>_duration (in seconds, with config.BucketsHTTPServer boundaries).
+// Every accessor returns a fresh instrument of the meter of the bundle.
+type REDBundle struct {
+	meter interfaces.Meter
+	name  string
+}
+
+// NewREDBundle creates the RED bundle of the given name recording to m, e.g. NewREDBundle(m, "payment_api").
+// A nil m records to the global meter of the time of every measurement, so the bundle can be created once at package
+// level, before the global meter is set.
+func NewREDBundle(m interfaces.Meter, name string) *REDBundle {
+	return &REDBundle{
+		meter: m,
+		name:  name,
+	}
+}
+
+// Requests returns the counter of handled requests.
+func (b *REDBundle) Requests() interfaces.Counter {
+	return bundleMeter(b.meter).NewCounter(b.name+"_requests", "number of requests handled by "+b.name, "")
+}
+
+// Errors returns the counter of failed requests.
+func (b *REDBundle) Errors() interfaces.Counter {
+	return bundleMeter(b.meter).NewCounter(b.name+"_errors", "number of requests of "+b.name+" that failed", "")
+}
+
+// Duration returns the histogram of request durations.
+func (b *REDBundle) Duration() interfaces.Histogram {
+	return bundleMeter(b.meter).NewHistogramWithBuckets(b.name+"_duration", "duration of the requests handled by "+b.name,
+		"s", config.BucketsHTTPServer)
+}
+
+// Observe records one request started at start: it increments the request counter, the error counter when err is not nil,
+// and records the elapsed time, all three with the same tags.
+func (b *REDBundle) Observe(ctx context.Context, start time.Time, err error, tags map[string]string) {
+	b.Requests().WithTags(tags).IncrOne(ctx)
+	if err != nil {
+		b.Errors().WithTags(tags).IncrOne(ctx)
+	}
+	b.Duration().WithTags(tags).UpdateSine(ctx, start)
+}
+
+// USEBundle groups the Utilization, Saturation and Errors instruments of a resource (pool, queue, disk...)
+// under conventional names: <name>_utilization (ratio), <name>_saturation and <name>_errors.
+// Every accessor returns a fresh instrument of the meter of the bundle.
+type USEBundle struct {
+	meter interfaces.Meter
+	name  string
+}
+
+// NewUSEBundle creates the USE bundle of the given resource name recording to m, e.g. NewUSEBundle(m, "db_pool").
+// A nil m records to the global meter of the time of every measurement, as for NewREDBundle.
+func NewUSEBundle(m interfaces.Meter, name string) *USEBundle {
+	return &USEBundle{
+		meter: m,
+		name:  name,
+	}
+}
+
+// Utilization returns the gauge of the busy ratio of the resource, between 0 and 1.
+func (b *USEBundle) Utilization() interfaces.Gauge {
+	return bundleMeter(b.meter).NewGauge(b.name+"_utilization", "ratio of the time or capacity "+b.name+" is busy", "1")
+}
+
+// Saturation returns the gauge of the work the resource cannot serve yet, e.g. queued requests.
+func (b *USEBundle) Saturation() interfaces.Gauge {
+	return bundleMeter(b.meter).NewGauge(b.name+"_saturation", "amount of work waiting for "+b.name, "")
+}
+
+// Errors returns the counter of errors of the resource.
+func (b *USEBundle) Errors() interfaces.Counter {
+	return bundleMeter(b.meter).NewCounter(b.name+"_errors", "number of errors of "+b.name, "")
+}
+
+// Observe records the utilization and saturation of the resource with the same tags.
+func (b *USEBundle) Observe(ctx context.Context, utilization, saturation float64, tags map[string]string) {
+	b.Utilization().WithTags(tags).Update(ctx, utilization)
+	b.Saturation().WithTags(tags).Update(ctx, saturation)
+}
+
+// ThroughputBundle groups the instruments of Little's Law for an operation under conventional names:
+// <name>_arrivals, <name>_completions and <name>_in_flight, so that the throughput, the concurrency and, from them,
+// the mean time in the system are consistent with each other.
+// Every accessor returns a fresh instrument of the meter of the bundle.
+type ThroughputBundle struct {
+	meter interfaces.Meter
+	name  string
+}
+
+// InFlight is an operation begun with ThroughputBundle.Begin, its End records the completion with the same tags.
+type InFlight struct {
+	bundle *ThroughputBundle
+	tags   map[string]string
+	ended  int32
+}
+
+// NewThroughputBundle creates the throughput bundle of the given operation name recording to m, e.g.
+// NewThroughputBundle(m, "import_job"). A nil m records to the global meter, as for NewREDBundle.
+func NewThroughputBundle(m interfaces.Meter, name string) *ThroughputBundle {
+	return &ThroughputBundle{
+		meter: m,
+		name:  name,
+	}
+}
+
+// Arrivals returns the counter of begun operations.
+func (b *ThroughputBundle) Arrivals() interfaces.Counter {
+	return bundleMeter(b.meter).NewCounter(b.name+"_arrivals", "number of operations of "+b.name+" begun", "")
+}
+
+// Completions returns the counter of ended operations.
+func (b *ThroughputBundle) Completions() interfaces.Counter {
+	return bundleMeter(b.meter).NewCounter(b.name+"_completions", "number of operations of "+b.name+" ended", "")
+}
+
+// InFlight returns the up-down counter of operations begun and not ended yet.
+func (b *ThroughputBundle) InFlight() interfaces.UpDownCounter {
+	return bundleMeter(b.meter).NewUpDownCounter(b.name+"_in_flight", "number of operations of "+b.name+" in flight", "")
+}
+
+// Begin records the arrival of an operation and increments the operations in flight, both with tags.
+// The returned InFlight must be ended once the operation is done.
+func (b *ThroughputBundle) Begin(ctx context.Context, tags map[string]string) *InFlight {
+	b.Arrivals().WithTags(tags).IncrOne(ctx)
+	b.InFlight().WithTags(tags).IncrOne(ctx)
+	return &InFlight{
+		bundle: b,
+		tags:   tags,
+	}
+}
+
+// End records the completion of the operation and decrements the operations in flight with the tags given to Begin.
+// Calling it again has no effect.
+func (f *InFlight) End(ctx context.Context) {
+	if !atomic.CompareAndSwapInt32(&f.ended, 0, 1) {
+		return
+	}
+	f.bundle.Completions().WithTags(f.tags).IncrOne(ctx)
+	f.bundle.InFlight().WithTags(f.tags).DecrOne(ctx)
+}
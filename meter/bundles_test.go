@@ -0,0 +1,129 @@
+package meter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestREDBundle(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		wants []string
+	}{
+		{
+			name: "success",
+			wants: []string{
+				`payment_api_requests_total{route="/pay"} 1`,
+				`payment_api_duration_seconds_count{route="/pay"} 1`,
+			},
+		},
+		{
+			name: "failure",
+			err:  errors.New("declined"),
+			wants: []string{
+				`payment_api_requests_total{route="/pay"} 1`,
+				`payment_api_errors_total{route="/pay"} 1`,
+				`payment_api_duration_seconds_count{route="/pay"} 1`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus))
+			require.NoError(t, err)
+			defer m.WithRunning(false)
+
+			NewREDBundle(m, "payment_api").Observe(context.Background(), time.Now(), tt.err, map[string]string{"route": "/pay"})
+
+			metertest.ScrapeAndAssert(t, m.GetHandler(), tt.wants...)
+		})
+	}
+}
+
+func TestUSEBundle(t *testing.T) {
+	tests := []struct {
+		name        string
+		utilization float64
+		saturation  float64
+		wants       []string
+	}{
+		{
+			name:        "idle",
+			utilization: 0,
+			saturation:  0,
+			wants:       []string{`db_pool_utilization_ratio{pool="main"} 0`, `db_pool_saturation{pool="main"} 0`},
+		},
+		{
+			name:        "saturated",
+			utilization: 1,
+			saturation:  12,
+			wants:       []string{`db_pool_utilization_ratio{pool="main"} 1`, `db_pool_saturation{pool="main"} 12`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the bundle without meter records to the global meter.
+			m := globalMeter(t)
+
+			NewUSEBundle(nil, "db_pool").Observe(context.Background(), tt.utilization, tt.saturation,
+				map[string]string{"pool": "main"})
+
+			metertest.ScrapeAndAssert(t, m.GetHandler(), tt.wants...)
+		})
+	}
+}
+
+func TestThroughputBundle(t *testing.T) {
+	tests := []struct {
+		name  string
+		ends  int
+		wants []string
+	}{
+		{
+			name: "in flight",
+			wants: []string{
+				`import_job_arrivals_total{source="s3"} 1`,
+				`import_job_in_flight{source="s3"} 1`,
+			},
+		},
+		{
+			name: "ended",
+			ends: 1,
+			wants: []string{
+				`import_job_arrivals_total{source="s3"} 1`,
+				`import_job_completions_total{source="s3"} 1`,
+				`import_job_in_flight{source="s3"} 0`,
+			},
+		},
+		{
+			name: "ended twice",
+			ends: 2,
+			wants: []string{
+				`import_job_completions_total{source="s3"} 1`,
+				`import_job_in_flight{source="s3"} 0`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus))
+			require.NoError(t, err)
+			defer m.WithRunning(false)
+			ctx := context.Background()
+
+			op := NewThroughputBundle(m, "import_job").Begin(ctx, map[string]string{"source": "s3"})
+			for i := 0; i < tt.ends; i++ {
+				op.End(ctx)
+			}
+
+			metertest.ScrapeAndAssert(t, m.GetHandler(), tt.wants...)
+		})
+	}
+}
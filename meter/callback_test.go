@@ -0,0 +1,42 @@
+package meter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbackWorkers(t *testing.T) {
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithPrometheusPort(0),
+		WithCallbackWorkers(2, 50*time.Millisecond))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	release := make(chan struct{})
+	defer close(release)
+	m.NewObservableGauge("slow_queue", "", "", func(ctx context.Context, o interfaces.Observer) error {
+		<-release
+		o.Observe(3, nil)
+		return nil
+	})
+	for _, name := range []string{"fast_a", "fast_b", "fast_c"} {
+		m.NewObservableGauge(name, "", "", func(ctx context.Context, o interfaces.Observer) error {
+			o.Observe(1, nil)
+			return nil
+		})
+	}
+
+	start := time.Now()
+	snapshot := metertest.Scrape(t, m.GetHandler())
+	assert.Less(t, time.Since(start), time.Second, "the slow callback does not delay the collection")
+	for _, name := range []string{"fast_a", "fast_b", "fast_c"} {
+		assert.Contains(t, snapshot, name)
+	}
+	assert.NotContains(t, snapshot, "slow_queue")
+}
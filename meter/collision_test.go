@@ -0,0 +1,60 @@
+package meter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameCollisionPolicy(t *testing.T) {
+	tests := []struct {
+		policy   config.NameCollisionPolicy
+		expected []string
+		absent   string
+		errors   int
+	}{
+		{
+			policy: config.NameCollisionMerge,
+			// the unit of the first instrument is kept, the values are not converted.
+			expected: []string{`latency_seconds_sum 502`, `latency_seconds_count 2`},
+			absent:   "latency_milliseconds",
+		},
+		{
+			policy:   config.NameCollisionSuffix,
+			expected: []string{`latency_seconds_sum 2`, `latency_ms_milliseconds_sum 500`},
+		},
+		{
+			policy:   config.NameCollisionError,
+			expected: []string{`latency_seconds_sum 2`, `latency_seconds_count 1`},
+			absent:   "latency_milliseconds",
+			errors:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			var errors int
+			m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithNameCollisionPolicy(tt.policy),
+				WithErrorLogWrite(func(s string) {
+					if strings.Contains(s, "name collision") {
+						errors++
+					}
+				}))
+			require.NoError(t, err)
+			ctx := context.Background()
+			m.NewHistogram("latency", "request latency", "s").Record(ctx, 2)
+			m.NewHistogram("latency", "latency in ms", "ms").Record(ctx, 500)
+			m.NewHistogram("latency", "latency in ms", "ms")
+			assert.Equal(t, tt.errors, errors, "a collision is logged once")
+
+			metertest.ScrapeAndAssert(t, m.GetHandler(), tt.expected...)
+			if tt.absent != "" {
+				assert.NotContains(t, metertest.Scrape(t, m.GetHandler()), tt.absent)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package meter
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeferredStart(t *testing.T) {
+	l, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithPrometheusPort(port), WithDeferredStart())
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	_, listening := ListenAddr(m)
+	assert.False(t, listening, "the server is not started before the meter")
+	m.NewCounter("orders", "", "").IncrOne(context.Background())
+	metertest.ScrapeAndAssert(t, m.GetHandler(), `orders_total 1`)
+
+	m.WithRunning(true)
+	_, listening = ListenAddr(m)
+	assert.True(t, listening, "switching the meter on starts the server")
+	m.NewCounter("orders", "", "").IncrOne(context.Background())
+	metertest.ScrapeAndAssert(t, m.GetHandler(), `orders_total 2`)
+}
@@ -0,0 +1,37 @@
+package meter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecateMetric(t *testing.T) {
+	var warnings []string
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithPrometheusPort(0),
+		WithErrorLogWrite(func(s string) {
+			if strings.Contains(s, "deprecated") {
+				warnings = append(warnings, s)
+			}
+		}))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	m.DeprecateMetric("orders_total", "orders_placed_total")
+	for i := 0; i < 2; i++ {
+		m.NewCounter("orders_total", "", "").AddTag("region", "eu").IncrOne(context.Background())
+	}
+	m.NewCounter("orders_placed_total", "", "").IncrOne(context.Background())
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`orders_total{deprecated="true",region="eu"} 2`,
+		`orders_placed_total 1`,
+		`go_metric_deprecated_uses{consumer="github.com/liangweijiang/go-metric/meter",metric="orders_total",replacement="orders_placed_total"} 2`)
+	assert.Equal(t, []string{"[go-metrics] deprecated metric orders_total is still recorded by " +
+		"github.com/liangweijiang/go-metric/meter, use orders_placed_total instead"}, warnings)
+}
@@ -0,0 +1,34 @@
+package meter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDerivedMetric(t *testing.T) {
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus),
+		WithDerivedMetric("error_ratio", "errors per request", "errors / requests", "route"),
+		WithDerivedMetric("total_errors", "", "errors"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	m.NewCounter("requests", "", "").AddTag("route", "/a").Incr(ctx, 4)
+	m.NewCounter("requests", "", "").AddTag("route", "/b").Incr(ctx, 2)
+	m.NewCounter("errors", "", "").AddTag("route", "/a").AddTag("code", "500").IncrOne(ctx)
+	m.NewCounter("errors", "", "").AddTag("route", "/a").AddTag("code", "503").IncrOne(ctx)
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`error_ratio{route="/a"} 0.5`,
+		`error_ratio{route="/b"} 0`,
+		`total_errors 2`,
+	)
+
+	_, err = NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithDerivedMetric("broken", "", "errors /"))
+	assert.True(t, errors.Is(err, config.ErrInvalidDerivedMetric))
+}
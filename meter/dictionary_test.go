@@ -0,0 +1,48 @@
+package meter
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDictionary(t *testing.T) {
+	dictionary, err := config.ParseDictionary([]byte(`
+metrics:
+  orders_total:
+    description: Orders placed.
+    owner: checkout-team
+`))
+	require.NoError(t, err)
+
+	var warnings []string
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithDictionary(dictionary),
+		WithUptimeCollector(""), WithErrorLogWrite(func(s string) {
+			if strings.Contains(s, "dictionary") {
+				warnings = append(warnings, s)
+			}
+		}))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	m.NewCounter("orders_total", "", "").IncrOne(context.Background())
+	m.NewCounter("ad_hoc_total", "", "").IncrOne(context.Background())
+	m.NewCounter("ad_hoc_total", "", "").IncrOne(context.Background())
+
+	recorder := httptest.NewRecorder()
+	m.GetHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, recorder.Body.String(), "# HELP orders_total Orders placed. Owner: checkout-team.")
+
+	unknown, ok := UnknownMetrics(m)
+	require.True(t, ok)
+	assert.Equal(t, []string{"ad_hoc_total"}, unknown, "the uptime metrics of the SDK are not flagged")
+	assert.Equal(t, []string{"[go-metrics] metric ad_hoc_total is not in the metrics dictionary"}, warnings)
+
+	_, err = config.ParseDictionary([]byte("metrics: [orders_total]"))
+	assert.ErrorIs(t, err, config.ErrInvalidDictionary)
+}
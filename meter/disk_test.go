@@ -0,0 +1,43 @@
+package meter
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskUsageCollector(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("disk usage is only collected on linux")
+	}
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus),
+		WithDiskUsageCollector(time.Hour, dir, missing), WithErrorLogWrite(func(string) {}))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	paths := func(name string) []string {
+		var paths []string
+		if mf, ok := metertest.Scrape(t, m.GetHandler())[name]; ok {
+			for _, metric := range mf.Metric {
+				for _, label := range metric.Label {
+					if label.GetName() == "path" {
+						paths = append(paths, label.GetValue())
+					}
+				}
+			}
+		}
+		return paths
+	}
+	assert.Eventually(t, func() bool { return len(paths("disk_used_bytes")) > 0 }, time.Second, 10*time.Millisecond)
+	for _, name := range []string{"disk_used_bytes", "disk_free_bytes", "disk_inodes_used", "disk_inodes_free"} {
+		assert.Equal(t, []string{dir}, paths(name), "the paths that cannot be read are skipped")
+	}
+}
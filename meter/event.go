@@ -0,0 +1,133 @@
+package meter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// EventPrefix is the prefix of the metric names of the business events, added to the event names lacking it.
+const EventPrefix = "event_"
+
+// Errors returned when registering or emitting an event.
+var (
+	// ErrInvalidEvent is returned by RegisterEvent for a schema with an invalid name or tag keys.
+	ErrInvalidEvent = errors.New("invalid event schema")
+	// ErrEventConflict is returned by RegisterEvent for an event registered before with another schema.
+	ErrEventConflict = errors.New("event registered with another schema")
+	// ErrUnregisteredEvent is returned by Emit for an event without registered schema.
+	ErrUnregisteredEvent = errors.New("unregistered event")
+	// ErrUndeclaredTag is returned by Emit for a tag not declared by the schema of the event.
+	ErrUndeclaredTag = errors.New("tag not declared by the event schema")
+)
+
+// eventNamePattern is the pattern of the event names and tag keys.
+var eventNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// EventSchema declares a business event, counted under the metric EventPrefix + Name with the tags Tags.
+type EventSchema struct {
+	Name string
+	Desc string
+	Tags []string
+}
+
+// events holds the registered schemas by metric name.
+var events sync.Map
+
+// eventMetricName returns the metric name of the event name.
+func eventMetricName(name string) string {
+	if strings.HasPrefix(name, EventPrefix) {
+		return name
+	}
+	return EventPrefix + name
+}
+
+// RegisterEvent registers the schema of an event, typically from the init function of the package emitting it,
+// so that every emission of the event is counted under the same name with the same tags. Registering the same
+// schema again is allowed, registering another schema for the same event returns ErrEventConflict.
+func RegisterEvent(schema EventSchema) error {
+	if !eventNamePattern.MatchString(schema.Name) {
+		return fmt.Errorf("%w: event name %q must be lower snake case", ErrInvalidEvent, schema.Name)
+	}
+	declared := make(map[string]bool, len(schema.Tags))
+	for _, key := range schema.Tags {
+		if !eventNamePattern.MatchString(key) || declared[key] {
+			return fmt.Errorf("%w: %s: invalid or duplicate tag key %q", ErrInvalidEvent, schema.Name, key)
+		}
+		declared[key] = true
+	}
+	name := eventMetricName(schema.Name)
+	schema.Name = name
+	schema.Tags = append([]string(nil), schema.Tags...)
+	if registered, loaded := events.LoadOrStore(name, schema); loaded {
+		if !slices.Equal(registered.(EventSchema).Tags, schema.Tags) {
+			return fmt.Errorf("%w: %s", ErrEventConflict, name)
+		}
+	}
+	return nil
+}
+
+// EventEmitter counts the emissions of an event, it is created by Event.
+type EventEmitter struct {
+	name string
+	tags map[string]string
+}
+
+// Event returns the emitter of the registered event name, e.g.
+//
+//	meter.Event("order_created").WithTags(map[string]string{"channel": "web"}).Emit(ctx)
+//
+// counted as event_order_created_total by the Prometheus exporter. The event prefix may be omitted.
+func Event(name string) *EventEmitter {
+	return &EventEmitter{
+		name: eventMetricName(name),
+		tags: make(map[string]string),
+	}
+}
+
+// WithTags sets the tags of the emission, whose keys must be declared by the schema of the event.
+func (e *EventEmitter) WithTags(tags map[string]string) *EventEmitter {
+	for k, v := range tags {
+		e.tags[k] = v
+	}
+	return e
+}
+
+// AddTag sets a tag of the emission, whose key must be declared by the schema of the event.
+func (e *EventEmitter) AddTag(key, value string) *EventEmitter {
+	e.tags[key] = value
+	return e
+}
+
+// Emit counts one emission of the event to the global meter. The declared tags left unset are set to "unknown".
+// Nothing is counted and an error is returned when the event is not registered or a tag is not declared.
+func (e *EventEmitter) Emit(ctx context.Context) error {
+	return e.EmitN(ctx, 1)
+}
+
+// EmitN counts n emissions of the event, like Emit.
+func (e *EventEmitter) EmitN(ctx context.Context, n float64) error {
+	registered, ok := events.Load(e.name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnregisteredEvent, e.name)
+	}
+	schema := registered.(EventSchema)
+	tags := make(map[string]string, len(schema.Tags))
+	for _, key := range schema.Tags {
+		tags[key] = "unknown"
+	}
+	for k, v := range e.tags {
+		if _, declared := tags[k]; !declared {
+			return fmt.Errorf("%w: %s: %s", ErrUndeclaredTag, e.name, k)
+		}
+		if v != "" {
+			tags[k] = v
+		}
+	}
+	GetGlobalMeter().NewCounter(schema.Name, schema.Desc, "").WithTags(tags).Incr(ctx, n)
+	return nil
+}
@@ -0,0 +1,30 @@
+package meter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvent(t *testing.T) {
+	m := globalMeter(t)
+
+	require.NoError(t, RegisterEvent(EventSchema{Name: "order_created", Desc: "orders created", Tags: []string{"channel", "plan"}}))
+	require.NoError(t, RegisterEvent(EventSchema{Name: "event_order_created", Tags: []string{"channel", "plan"}}))
+	assert.ErrorIs(t, RegisterEvent(EventSchema{Name: "order_created", Tags: []string{"channel"}}), ErrEventConflict)
+	assert.ErrorIs(t, RegisterEvent(EventSchema{Name: "OrderCreated"}), ErrInvalidEvent)
+
+	ctx := context.Background()
+	assert.NoError(t, Event("order_created").AddTag("channel", "web").Emit(ctx))
+	assert.NoError(t, Event("event_order_created").WithTags(map[string]string{"channel": "web", "plan": "pro"}).EmitN(ctx, 2))
+	assert.ErrorIs(t, Event("order_created").AddTag("user_id", "42").Emit(ctx), ErrUndeclaredTag)
+	assert.ErrorIs(t, Event("order_deleted").Emit(ctx), ErrUnregisteredEvent)
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`event_order_created_total{channel="web",plan="unknown"} 1`,
+		`event_order_created_total{channel="web",plan="pro"} 2`,
+	)
+}
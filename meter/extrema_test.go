@@ -0,0 +1,35 @@
+package meter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramExtrema(t *testing.T) {
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithHistogramExtrema())
+	require.NoError(t, err)
+	ctx := context.Background()
+	for _, v := range []float64{0.3, 0.05, 2.5} {
+		m.NewHistogram("latency", "", "s").AddTag("route", "/a").UpdateInSeconds(ctx, v)
+	}
+	m.NewHistogram("latency", "", "s").AddTag("route", "/b").UpdateInSeconds(ctx, 1)
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`latency_min_seconds{route="/a"} 0.05`,
+		`latency_max_seconds{route="/a"} 2.5`,
+		`latency_min_seconds{route="/b"} 1`,
+		`latency_max_seconds{route="/b"} 1`,
+	)
+
+	// the extrema are reset at every collection.
+	m.NewHistogram("latency", "", "s").AddTag("route", "/a").UpdateInSeconds(ctx, 0.7)
+	snapshot := metertest.Scrape(t, m.GetHandler())
+	require.Len(t, snapshot["latency_min_seconds"].GetMetric(), 1)
+	assert.Equal(t, 0.7, snapshot["latency_min_seconds"].GetMetric()[0].GetGauge().GetValue())
+	assert.Equal(t, 0.7, snapshot["latency_max_seconds"].GetMetric()[0].GetGauge().GetValue())
+}
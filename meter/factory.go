@@ -2,22 +2,76 @@
 package meter
 
 import (
+	"context"
+	"github.com/liangweijiang/go-metric/internal/meter/graphite"
 	"github.com/liangweijiang/go-metric/internal/meter/nop"
+	"github.com/liangweijiang/go-metric/internal/meter/otlp"
 	"github.com/liangweijiang/go-metric/internal/meter/prom"
+	"github.com/liangweijiang/go-metric/internal/meter/statsd"
+	"github.com/liangweijiang/go-metric/internal/meter/validate"
+	"github.com/liangweijiang/go-metric/internal/registry"
+	"github.com/liangweijiang/go-metric/pkg/analyze"
 	"github.com/liangweijiang/go-metric/pkg/config"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	pkgvalidate "github.com/liangweijiang/go-metric/pkg/validate"
 )
 
 // NewMeter creates a new meter instance based on the provided options and configuration.
 // It allows customization through options which modify the configuration before deciding the meter provider.
-// In a development environment, it returns a no-op meter. For Prometheus configuration, it initializes a Prometheus meter.
+// The validate provider returns a dry-run meter checking the instrumentation, see Violations.
+// In a development environment, it returns a no-op meter. For Prometheus configuration, it initializes a Prometheus meter,
+// for the OTLP gRPC provider a meter pushing to an OpenTelemetry Collector, see WithOTLPEndpoint,
+// for the StatsD and DogStatsD providers a meter sending StatsD or DogStatsD lines over UDP, see WithStatsD,
+// for the Graphite provider a meter pushing plaintext lines to carbon over TCP, see WithGraphite,
+// and for the type of a provider registered with RegisterProvider, the meter built by its factory.
 // Otherwise, it defaults to a no-op meter.
-// Returns a meter implementation and an error if one occurs during initialization.
+// Returns a meter implementation and an error if one occurs during initialization,
+// the error wraps one of config.ErrInvalidPort, config.ErrUnsupportedProvider or config.ErrExporterInit when applicable.
 func NewMeter(options ...interfaces.Option) (interfaces.Meter, error) {
 	cfg := config.GetConfig()
 	for _, option := range options {
 		option.ApplyConfig(cfg)
 	}
+	return NewMeterWithConfig(cfg)
+}
+
+// MustNewMeter is like NewMeter but panics if the meter cannot be created, e.g. in the main function of programs which
+// must not start without their metrics.
+func MustNewMeter(options ...interfaces.Option) interfaces.Meter {
+	m, err := NewMeter(options...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// NewMeterWithConfig creates a meter from a configuration built programmatically instead of with options, e.g.
+// decoded from a configuration file. The meter keeps cfg and updates its runtime state, such as the address it listens
+// on, so cfg must not be shared with another meter. A nil cfg is the default configuration.
+// See NewMeter for the meter returned and the errors.
+func NewMeterWithConfig(cfg *config.Config) (interfaces.Meter, error) {
+	if cfg == nil {
+		cfg = config.GetConfig()
+	}
+	if err := cfg.ResolveProviderName(); err != nil {
+		cfg.WriteErrorOrNot("invalid meter config: " + err.Error())
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		cfg.WriteErrorOrNot("invalid meter config: " + err.Error())
+		return nil, err
+	}
+	if cfg.NeedsLocalIP() {
+		cfg.ResolveLocalIP()
+	}
+	if cfg.Serverless {
+		cfg.DeferStart = true
+	}
+
+	if cfg.MeterProvider == config.MeterProviderTypeValidate {
+		cfg.WriteInfoOrNot("using the validate meter, metrics are checked and not exported")
+		return validate.NewValidateMeter(cfg), nil
+	}
 
 	if cfg.IsDev() {
 		cfg.WriteInfoOrNot("under test environment, using NopMeter")
@@ -26,13 +80,191 @@ func NewMeter(options ...interfaces.Option) (interfaces.Meter, error) {
 
 	switch cfg.MeterProvider {
 	case config.MeterProviderTypePrometheus:
+		if cfg.LazyInit {
+			cfg.WriteInfoOrNot("using the lazy prometheus meter, initialized at the first scrape")
+			return prom.NewLazyMeter(cfg), nil
+		}
 		meter, err := prom.NewPrometheusMeter(cfg)
 		if err != nil {
 			cfg.WriteErrorOrNot("set prometheus meter provider error: " + err.Error())
 			return nil, err
 		}
 		return meter, err
+	case config.MeterProviderTypeOTLPGrpc:
+		meter, err := otlp.NewOTLPMeter(cfg)
+		if err != nil {
+			cfg.WriteErrorOrNot("set otlp meter provider error: " + err.Error())
+			return nil, err
+		}
+		return meter, nil
+	case config.MeterProviderTypeStatsD:
+		meter, err := statsd.NewStatsDMeter(cfg)
+		if err != nil {
+			cfg.WriteErrorOrNot("set statsd meter provider error: " + err.Error())
+			return nil, err
+		}
+		return meter, nil
+	case config.MeterProviderTypeDogStatsD:
+		meter, err := statsd.NewDogStatsDMeter(cfg)
+		if err != nil {
+			cfg.WriteErrorOrNot("set dogstatsd meter provider error: " + err.Error())
+			return nil, err
+		}
+		return meter, nil
+	case config.MeterProviderTypeGraphite:
+		meter, err := graphite.NewGraphiteMeter(cfg)
+		if err != nil {
+			cfg.WriteErrorOrNot("set graphite meter provider error: " + err.Error())
+			return nil, err
+		}
+		return meter, nil
 	default:
+		if factory, ok := registeredProvider(cfg.MeterProvider); ok {
+			meter, err := factory(cfg)
+			if err != nil {
+				cfg.WriteErrorOrNot("set " + cfg.MeterProvider.String() + " meter provider error: " + err.Error())
+				return nil, err
+			}
+			return meter, nil
+		}
 		return nop.NewNopMeter(), nil
 	}
 }
+
+// Violations returns the violations of the naming, tagging and cardinality rules found so far by a meter of the
+// validate provider, typically asserted empty at the end of an integration test. ok is false for other meters.
+func Violations(m interfaces.Meter) (violations []pkgvalidate.Violation, ok bool) {
+	reporter, ok := m.(interface {
+		Violations() []pkgvalidate.Violation
+	})
+	if !ok {
+		return nil, false
+	}
+	return reporter.Violations(), true
+}
+
+// BudgetUsage returns the usage of the instrument budgets declared with WithMetricBudget, sorted by module.
+// ok is false for the meters without budgets.
+func BudgetUsage(m interfaces.Meter) (usage []config.BudgetUsage, ok bool) {
+	r, ok := m.(interface {
+		Registry() *registry.Registry
+	})
+	if !ok || r.Registry().Budgets() == nil {
+		return nil, false
+	}
+	return r.Registry().Budgets().Usage(), true
+}
+
+// UnusedInstruments returns the sorted names of the metrics created but not recorded to during the window given to
+// WithUnusedDetection. ok is false for the meters not tracking the usage of their instruments.
+func UnusedInstruments(m interfaces.Meter) (names []string, ok bool) {
+	r, ok := m.(interface {
+		Registry() *registry.Registry
+	})
+	if !ok || r.Registry().UsageWindow() <= 0 {
+		return nil, false
+	}
+	return r.Registry().Unused(), true
+}
+
+// InstrumentOrigins returns where the first instrument of every metric was created, sorted by metric, the metrics
+// created by the SDK alone being left out. ok is false for the meters not created WithCreationAudit.
+func InstrumentOrigins(m interfaces.Meter) (origins []config.InstrumentOrigin, ok bool) {
+	r, ok := m.(interface {
+		Registry() *registry.Registry
+	})
+	if !ok || !r.Registry().TracksOrigins() {
+		return nil, false
+	}
+	return r.Registry().Origins(), true
+}
+
+// UnknownMetrics returns the metrics created so far by the application and missing from the dictionary given to
+// WithDictionary, sorted. ok is false for the meters without a dictionary.
+func UnknownMetrics(m interfaces.Meter) (names []string, ok bool) {
+	r, ok := m.(interface {
+		Registry() *registry.Registry
+	})
+	if !ok || !r.Registry().HasDictionary() {
+		return nil, false
+	}
+	return r.Registry().UnknownMetrics(), true
+}
+
+// BucketRecommendations returns the bucket boundaries recommended to the histograms at the end of the last window given
+// to WithBucketTuning, sorted by metric, none before the end of the first window. ok is false for the meters not
+// tuning the buckets.
+func BucketRecommendations(m interfaces.Meter) (recommendations []analyze.BucketRecommendation, ok bool) {
+	r, ok := m.(interface {
+		Registry() *registry.Registry
+	})
+	if !ok || !r.Registry().TunesBuckets() {
+		return nil, false
+	}
+	return r.Registry().BucketRecommendations(), true
+}
+
+// ListenAddr returns the address the embedded server of the meter serves /metrics on, e.g. [::]:9464, which differs
+// from the configured port when it fell back to another one with WithPortFallback. ok is false while the meter does
+// not listen, e.g. when the server is disabled or stopped.
+func ListenAddr(m interfaces.Meter) (addr string, ok bool) {
+	l, ok := m.(interface {
+		ListenAddr() string
+	})
+	if !ok {
+		return "", false
+	}
+	addr = l.ListenAddr()
+	return addr, addr != ""
+}
+
+// ServerInfo describes where the metrics of the meter are exposed, the addresses its servers are bound to, their
+// states and the exporters, e.g. to log it at startup or register it with a discovery service. The meters wrapping
+// another one, such as those of NewTenantMeter, are unwrapped. ok is false for the no-op meter.
+func ServerInfo(m interfaces.Meter) (info config.ServerInfo, ok bool) {
+	for {
+		if s, ok := m.(interface {
+			ServerInfo() config.ServerInfo
+		}); ok {
+			return s.ServerInfo(), true
+		}
+		u, ok := m.(interface {
+			Unwrap() interfaces.Meter
+		})
+		if !ok {
+			return config.ServerInfo{}, false
+		}
+		m = u.Unwrap()
+	}
+}
+
+// QuarantinedMetrics returns the sorted names of the metrics rejected by the push backends of the meter, e.g. the push
+// gateway answering 400 on series inconsistent with those pushed by another job, which are no longer exported to
+// them until the process restarts while the other metrics keep being exported. The meters wrapping another one are
+// unwrapped. ok is false for the meters without push backends, such as the no-op meter.
+func QuarantinedMetrics(m interfaces.Meter) (names []string, ok bool) {
+	for {
+		if q, ok := m.(interface {
+			Quarantined() []string
+		}); ok {
+			return q.Quarantined(), true
+		}
+		u, ok := m.(interface {
+			Unwrap() interfaces.Meter
+		})
+		if !ok {
+			return nil, false
+		}
+		m = u.Unwrap()
+	}
+}
+
+// Shutdown shuts down the meter holding connections to its backends, such as the OTLP meter, pushing the metrics
+// recorded so far a last time and closing the connections, e.g. before the process exits. It returns nil for the
+// meters without such connections.
+func Shutdown(ctx context.Context, m interfaces.Meter) error {
+	if s, ok := m.(interfaces.Shutdowner); ok {
+		return s.Shutdown(ctx)
+	}
+	return nil
+}
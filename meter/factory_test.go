@@ -1,13 +1,19 @@
 package meter
 
 import (
+	"context"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/liangweijiang/go-metric/internal/meter/nop"
 	"github.com/liangweijiang/go-metric/internal/meter/prom"
+	"github.com/liangweijiang/go-metric/internal/meter/validate"
 	"github.com/liangweijiang/go-metric/pkg/config"
 	"github.com/stretchr/testify/assert"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 )
 
 func TestNewMeter(t *testing.T) {
@@ -16,6 +22,7 @@ func TestNewMeter(t *testing.T) {
 		options    []interfaces.Option
 		wantMeter  interfaces.Meter
 		wantErr    bool
+		wantErrIs  error
 		errMessage string
 	}{
 		{
@@ -28,12 +35,85 @@ func TestNewMeter(t *testing.T) {
 			wantMeter: &prom.PrometheusMeter{},
 			wantErr:   false,
 		},
+		{
+			name:      "ValidateProvider",
+			options:   []interfaces.Option{WithProviderType(config.MeterProviderTypeValidate)},
+			wantMeter: &validate.Meter{},
+			wantErr:   false,
+		},
 		{
 			name:       "UnknownMeterProvider",
 			wantMeter:  &nop.Meter{},
 			wantErr:    false,
 			errMessage: "set prometheus meter provider error: unsupported meter provider type: unknown",
 		},
+		{
+			name:       "InvalidPort",
+			options:    []interfaces.Option{WithProviderType(config.MeterProviderTypePrometheus), WithPrometheusPort(70000)},
+			wantErr:    true,
+			wantErrIs:  config.ErrInvalidPort,
+			errMessage: "invalid prometheus port: 70000",
+		},
+		{
+			name:      "InvalidGatewayAddress",
+			options:   []interfaces.Option{WithProviderType(config.MeterProviderTypePrometheus), WithPushGateway("ftp://gateway:9091", time.Second)},
+			wantErr:   true,
+			wantErrIs: config.ErrInvalidGatewayAddress,
+		},
+		{
+			name:      "InvalidPushPeriod",
+			options:   []interfaces.Option{WithProviderType(config.MeterProviderTypePrometheus), WithPushGateway("gateway:9091", 0)},
+			wantErr:   true,
+			wantErrIs: config.ErrInvalidPushPeriod,
+		},
+		{
+			name: "PushGatewaySettingsWithoutGateway",
+			options: []interfaces.Option{WithProviderType(config.MeterProviderTypePrometheus),
+				WithPushGatewayFinalPushTimeout(time.Second), WithPushGatewayProbe(time.Second)},
+			wantErr:   true,
+			wantErrIs: config.ErrInvalidGatewayAddress,
+		},
+		{
+			name: "PushGatewayLeaderWithoutGateway",
+			options: []interfaces.Option{WithProviderType(config.MeterProviderTypePrometheus),
+				WithPushGatewayLeader(func(context.Context) bool { return true })},
+			wantErr:   true,
+			wantErrIs: config.ErrInvalidGatewayAddress,
+		},
+		{
+			name: "PushAggregationWithoutGateway",
+			options: []interfaces.Option{WithProviderType(config.MeterProviderTypePrometheus),
+				WithPushAggregation(time.Minute, "instance")},
+			wantErr:   true,
+			wantErrIs: config.ErrInvalidGatewayAddress,
+		},
+		{
+			name: "PushGatewayExportZstd",
+			options: []interfaces.Option{WithProviderType(config.MeterProviderTypePrometheus),
+				WithPushGateway("http://gateway:9091", time.Minute), WithExportCompression("zstd")},
+			wantErr:   true,
+			wantErrIs: config.ErrInvalidExport,
+		},
+		{
+			name: "OTLPExportBatching",
+			options: []interfaces.Option{WithProviderType(config.MeterProviderTypeOTLPGrpc),
+				WithExportBatching(100, 0)},
+			wantErr:   true,
+			wantErrIs: config.ErrInvalidExport,
+		},
+		{
+			name: "OTLPExportSnappy",
+			options: []interfaces.Option{WithProviderType(config.MeterProviderTypeOTLPGrpc),
+				WithExportCompression("snappy")},
+			wantErr:   true,
+			wantErrIs: config.ErrInvalidExport,
+		},
+		{
+			name:      "UnsupportedProvider",
+			options:   []interfaces.Option{WithProviderType(config.MeterProviderType(-1))},
+			wantErr:   true,
+			wantErrIs: config.ErrUnsupportedProvider,
+		},
 	}
 
 	for _, tt := range tests {
@@ -47,6 +127,9 @@ func TestNewMeter(t *testing.T) {
 
 			if tt.wantErr {
 				assert.Error(t, err)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
 				if tt.errMessage != "" {
 					assert.Contains(t, err.Error(), tt.errMessage)
 				}
@@ -57,3 +140,63 @@ func TestNewMeter(t *testing.T) {
 		})
 	}
 }
+
+func TestNewMeterWithConfig(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.MeterProvider = config.MeterProviderTypeValidate
+	m, err := NewMeterWithConfig(cfg)
+	assert.NoError(t, err)
+	assert.IsType(t, &validate.Meter{}, m)
+
+	m, err = NewMeterWithConfig(nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &nop.Meter{}, m)
+
+	cfg = config.GetConfig()
+	cfg.MeterProvider = config.MeterProviderType(-1)
+	_, err = NewMeterWithConfig(cfg)
+	assert.ErrorIs(t, err, config.ErrUnsupportedProvider)
+}
+
+func TestMustNewMeter(t *testing.T) {
+	assert.IsType(t, &validate.Meter{}, MustNewMeter(WithProviderType(config.MeterProviderTypeValidate)))
+	assert.Panics(t, func() {
+		MustNewMeter(WithProviderType(config.MeterProviderType(-1)))
+	})
+}
+
+func TestNewMeterWithReader(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithReader(reader))
+	assert.NoError(t, err)
+	m.NewCounter("orders", "", "").AddTag("status", "paid").Incr(context.Background(), 2)
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+	if assert.Len(t, rm.ScopeMetrics, 1) && assert.Len(t, rm.ScopeMetrics[0].Metrics, 1) {
+		sum := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[float64])
+		assert.Equal(t, 2.0, sum.DataPoints[0].Value)
+	}
+}
+
+func TestServerInfo(t *testing.T) {
+	taken, err := net.Listen("tcp", ":0")
+	assert.NoError(t, err)
+	defer taken.Close()
+
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus),
+		WithPrometheusPort(taken.Addr().(*net.TCPAddr).Port), WithPortFallback(0, 0, true))
+	assert.NoError(t, err)
+	defer m.WithRunning(false)
+
+	addr, ok := ListenAddr(m)
+	assert.True(t, ok)
+	info, ok := ServerInfo(m)
+	assert.True(t, ok)
+	assert.Equal(t, "prometheus", info.Provider)
+	assert.Equal(t, []string{"prometheus"}, info.Exporters)
+	assert.Equal(t, []config.ServerState{{Kind: config.ServerKindMetrics, Addr: addr, Running: true}}, info.Servers)
+
+	_, ok = ServerInfo(nop.NewNopMeter())
+	assert.False(t, ok)
+}
@@ -0,0 +1,66 @@
+package meter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		name    string
+		gateway bool
+		status  int
+		pushes  int
+		wantErr bool
+	}{
+		{name: "WithoutPushGateway"},
+		{name: "PushGateway", gateway: true, status: http.StatusOK, pushes: 1},
+		{name: "PushGatewayFailing", gateway: true, status: http.StatusInternalServerError, pushes: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var pushed []string
+			gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				pushed = append(pushed, string(body))
+				mu.Unlock()
+				w.WriteHeader(tt.status)
+			}))
+			defer gateway.Close()
+
+			options := []interfaces.Option{WithProviderType(config.MeterProviderTypePrometheus), WithPrometheusPort(0),
+				WithDeferredStart()}
+			if tt.gateway {
+				options = append(options, WithPushGateway(gateway.URL, time.Hour), WithLocalIP("10.0.0.7"))
+			}
+			m, err := NewMeter(options...)
+			require.NoError(t, err)
+			ctx := context.Background()
+			m.NewCounter("orders", "", "").IncrOne(ctx)
+
+			if tt.wantErr {
+				assert.Error(t, m.Flush(ctx))
+			} else {
+				assert.NoError(t, m.Flush(ctx))
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			require.Len(t, pushed, tt.pushes, "the metrics are pushed right away, before the push period")
+			for _, body := range pushed {
+				assert.Contains(t, body, "orders_total")
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package meter
+
+import (
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/stretchr/testify/require"
+)
+
+// globalMeter creates a PrometheusMeter configured with options and installs it as the global meter, like
+// fixture.Global which the package cannot import. The previous global meter is restored and the meter stopped when
+// the test ends.
+func globalMeter(t *testing.T, options ...interfaces.Option) interfaces.Meter {
+	t.Helper()
+	m, err := NewMeter(append(options, WithProviderType(config.MeterProviderTypePrometheus))...)
+	require.NoError(t, err)
+	previous := GetGlobalMeter()
+	SetGlobalMeter(m)
+	t.Cleanup(func() {
+		SetGlobalMeter(previous)
+		m.WithRunning(false)
+	})
+	return m
+}
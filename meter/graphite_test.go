@@ -0,0 +1,140 @@
+package meter
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// carbon returns the address of a plaintext listener and the channel of the paths and values of the lines it
+// receives, without their timestamps.
+func carbon(t *testing.T) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	lines := make(chan string, 1024)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					fields := strings.Fields(scanner.Text())
+					lines <- strings.Join(fields[:len(fields)-1], " ")
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), lines
+}
+
+// receive returns the lines received until none arrives for 200 milliseconds.
+func receive(lines <-chan string) []string {
+	var received []string
+	for {
+		select {
+		case line := <-lines:
+			received = append(received, line)
+		case <-time.After(200 * time.Millisecond):
+			return received
+		}
+	}
+}
+
+func TestGraphiteMeter(t *testing.T) {
+	address, lines := carbon(t)
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypeGraphite), WithGraphite(address, time.Hour),
+		WithGraphitePrefix("servers.checkout"))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	ctx := context.Background()
+	m.NewCounter("orders_total", "", "").AddTag("region", "eu.west").Incr(ctx, 3)
+	m.NewHistogram("rpc_seconds", "", "s").Update(ctx, 2*time.Second)
+	require.NoError(t, m.Flush(ctx))
+	received := receive(lines)
+	assert.Contains(t, received, "servers.checkout.orders_total.region.eu_west 3")
+	assert.Contains(t, received, "servers.checkout.rpc_seconds.count 1")
+	assert.Contains(t, received, "servers.checkout.rpc_seconds.sum 2")
+	assert.Contains(t, received, "servers.checkout.rpc_seconds.max 2")
+
+	address, lines = carbon(t)
+	tagged, err := NewMeter(WithProviderType(config.MeterProviderTypeGraphite), WithGraphite(address, time.Hour),
+		WithGraphiteTagFormat(config.GraphiteTagsTagged))
+	require.NoError(t, err)
+	defer tagged.WithRunning(false)
+
+	tagged.NewGauge("queue_depth", "", "").WithTags(map[string]string{"queue": "emails", "shard": "a b"}).Update(ctx, 7)
+	require.NoError(t, tagged.Flush(ctx))
+	assert.Contains(t, receive(lines), "queue_depth;queue=emails;shard=a_b 7")
+
+	_, err = NewMeter(WithProviderType(config.MeterProviderTypeGraphite), WithGraphiteTagFormat(3))
+	assert.ErrorIs(t, err, config.ErrInvalidGraphite)
+}
+
+func TestGraphiteMeterDeferredStart(t *testing.T) {
+	address, lines := carbon(t)
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypeGraphite), WithGraphite(address, 50*time.Millisecond),
+		WithDeferredStart())
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	m.NewCounter("orders_total", "", "").IncrOne(context.Background())
+	assert.Empty(t, receive(lines), "nothing is pushed before the start")
+
+	m.WithRunning(true)
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case line := <-lines:
+			if line == "orders_total 1" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("orders_total was not pushed after the start")
+		}
+	}
+}
+
+func TestGraphiteMeterLifecycle(t *testing.T) {
+	address, lines := carbon(t)
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypeGraphite), WithGraphite(address, 50*time.Millisecond))
+	require.NoError(t, err)
+	m.NewCounter("orders_total", "", "").IncrOne(context.Background())
+	// drained is the number of lines received after the pushes in flight, these lines arrive in the first 100ms.
+	drained := func() int {
+		time.Sleep(100 * time.Millisecond)
+		n := len(lines)
+		for i := 0; i < n; i++ {
+			<-lines
+		}
+		return n
+	}
+	assert.Positive(t, drained(), "the metrics are pushed while switched on")
+
+	m.WithRunning(false)
+	drained()
+	time.Sleep(200 * time.Millisecond)
+	assert.Zero(t, drained(), "nothing is pushed once switched off")
+
+	m.WithRunning(true)
+	assert.Positive(t, drained(), "the pushes resume once switched on")
+
+	require.NoError(t, Shutdown(context.Background(), m))
+	drained()
+	m.WithRunning(true)
+	time.Sleep(200 * time.Millisecond)
+	assert.Zero(t, drained(), "nothing is pushed once shut down")
+	assert.NoError(t, Shutdown(context.Background(), m), "shutting down twice does nothing")
+}
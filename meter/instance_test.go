@@ -0,0 +1,30 @@
+package meter
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceTags(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithLocalIP("10.0.0.7"), WithInstanceTags(false))
+	require.NoError(t, err)
+	m.NewCounter("orders", "", "").IncrOne(context.Background())
+	metertest.ScrapeAndAssert(t, m.GetHandler(), `orders_total{ip="10.0.0.7",pid="`+pid+`"} 1`)
+
+	m, err = NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithLocalIP("10.0.0.7"), WithInstanceTags(true))
+	require.NoError(t, err)
+	m.NewCounter("orders", "", "").IncrOne(context.Background())
+	snapshot := metertest.Scrape(t, m.GetHandler())
+	require.Len(t, snapshot["orders_total"].GetMetric(), 1)
+	assert.Empty(t, snapshot["orders_total"].GetMetric()[0].GetLabel(), "pulled series are not labeled")
+	metertest.ScrapeAndAssert(t, m.GetHandler(), `target_info{ip="10.0.0.7",pid="`+pid+`"} 1`)
+}
@@ -0,0 +1,36 @@
+package meter
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazyInit(t *testing.T) {
+	l, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithPrometheusPort(port), WithLazyInit())
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+	lazy := m.(interface{ Initialized() bool })
+
+	_, listening := ListenAddr(m)
+	assert.True(t, listening, "the server binds at once")
+	info, ok := ServerInfo(m)
+	require.True(t, ok)
+	require.Len(t, info.Servers, 1)
+	assert.False(t, lazy.Initialized(), "describing the servers does not initialize the meter")
+
+	metertest.Scrape(t, m.GetHandler())
+	assert.True(t, lazy.Initialized(), "the first scrape initializes the meter")
+	m.NewCounter("orders", "", "").IncrOne(context.Background())
+	metertest.ScrapeAndAssert(t, m.GetHandler(), `orders_total 1`)
+}
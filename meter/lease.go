@@ -0,0 +1,88 @@
+package meter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileLease is a best-effort leader election through a lease file shared by the replicas, e.g. on a shared volume.
+// The leader renews the lease whenever IsLeader is called, the other replicas take it over once it expired,
+// so the lease ttl must be longer than the push period. Its IsLeader method fits WithPushGatewayLeader.
+type FileLease struct {
+	path string
+	id   string
+	ttl  time.Duration
+	mu   sync.Mutex
+}
+
+// NewFileLease creates a lease stored at path, held under id, typically the host name without spaces,
+// for ttl once acquired or renewed.
+func NewFileLease(path, id string, ttl time.Duration) *FileLease {
+	return &FileLease{
+		path: path,
+		id:   id,
+		ttl:  ttl,
+	}
+}
+
+// IsLeader acquires or renews the lease if it is free, expired or already held by this replica,
+// and reports whether this replica holds it. Any error reading or writing the lease file means not being the leader.
+func (l *FileLease) IsLeader(_ context.Context) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	holder, expiry, err := l.read()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	if holder != "" && holder != l.id && now.Before(expiry) {
+		return false
+	}
+	if err := l.write(now.Add(l.ttl)); err != nil {
+		return false
+	}
+	// another replica may have taken the lease concurrently, the last writer wins.
+	holder, _, err = l.read()
+	return err == nil && holder == l.id
+}
+
+// read returns the holder and the expiry of the lease file, a malformed file has no holder and can be taken over.
+func (l *FileLease) read() (string, time.Time, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return "", time.Time{}, nil
+	}
+	nanos, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, nil
+	}
+	return fields[0], time.Unix(0, nanos), nil
+}
+
+// write replaces atomically the lease file with this replica as holder until expiry.
+func (l *FileLease) write(expiry time.Time) error {
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := fmt.Fprintf(tmp, "%s %d\n", l.id, expiry.UnixNano()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), l.path)
+}
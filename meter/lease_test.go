@@ -0,0 +1,30 @@
+package meter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLease(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "push.lease")
+	a := NewFileLease(path, "replica-a", 50*time.Millisecond)
+	b := NewFileLease(path, "replica-b", 50*time.Millisecond)
+
+	assert.True(t, a.IsLeader(ctx), "a free lease is acquired")
+	assert.False(t, b.IsLeader(ctx), "a held lease is not taken over")
+	assert.True(t, a.IsLeader(ctx), "the holder renews its lease")
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, b.IsLeader(ctx), "an expired lease is taken over")
+	assert.False(t, a.IsLeader(ctx))
+
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o644))
+	assert.True(t, a.IsLeader(ctx), "a malformed lease is taken over")
+}
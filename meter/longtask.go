@@ -0,0 +1,132 @@
+package meter
+
+import (
+	"context"
+	"errors"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"sync"
+	"time"
+)
+
+// Suffixes of the gauges of a LongTaskTimer, the Prometheus exporter appends _seconds to the durations.
+const (
+	LongTaskActiveSuffix   = "_active_tasks"
+	LongTaskDurationSuffix = "_active_duration"
+	LongTaskMaxSuffix      = "_max_duration"
+)
+
+// LongTaskTimer tracks the tasks in flight, such as batch jobs outliving the scrape interval, whose duration a
+// histogram only records once they end. Like the LongTaskTimer of Micrometer, it exports at every collection the
+// number of active tasks, the sum of their durations so far and the duration of the oldest one.
+type LongTaskTimer struct {
+	clock         clock.Clock
+	mu            sync.Mutex
+	active        map[uint64]time.Time
+	nextID        uint64
+	registrations []interfaces.Registration
+}
+
+// LongTask is a task started by a LongTaskTimer.
+type LongTask struct {
+	timer    *LongTaskTimer
+	id       uint64
+	start    time.Time
+	once     sync.Once
+	duration time.Duration
+}
+
+// NewLongTaskTimer creates a LongTaskTimer exporting the gauges name followed by LongTaskActiveSuffix,
+// LongTaskDurationSuffix and LongTaskMaxSuffix on m, tagged with tags. Call Close to stop exporting them.
+func NewLongTaskTimer(m interfaces.Meter, name, desc string, tags map[string]string) *LongTaskTimer {
+	t := &LongTaskTimer{
+		clock:  clock.From(m),
+		active: make(map[uint64]time.Time),
+	}
+	t.registrations = []interfaces.Registration{
+		m.NewObservableGauge(name+LongTaskActiveSuffix, "active tasks of "+desc, "",
+			func(_ context.Context, o interfaces.Observer) error {
+				o.Observe(float64(t.ActiveTasks()), tags)
+				return nil
+			}),
+		m.NewObservableGauge(name+LongTaskDurationSuffix, "duration so far of the active tasks of "+desc, "s",
+			func(_ context.Context, o interfaces.Observer) error {
+				o.Observe(t.Duration().Seconds(), tags)
+				return nil
+			}),
+		m.NewObservableGauge(name+LongTaskMaxSuffix, "duration so far of the oldest active task of "+desc, "s",
+			func(_ context.Context, o interfaces.Observer) error {
+				o.Observe(t.Max().Seconds(), tags)
+				return nil
+			}),
+	}
+	return t
+}
+
+// Start starts tracking a task, which must be stopped once done.
+func (t *LongTaskTimer) Start() *LongTask {
+	now := t.clock.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	t.active[t.nextID] = now
+	return &LongTask{timer: t, id: t.nextID, start: now}
+}
+
+// Time tracks f as a task for the time it runs.
+func (t *LongTaskTimer) Time(f func()) {
+	task := t.Start()
+	defer task.Stop()
+	f()
+}
+
+// ActiveTasks returns the number of tasks started and not stopped yet.
+func (t *LongTaskTimer) ActiveTasks() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.active)
+}
+
+// Duration returns the sum of the durations so far of the active tasks.
+func (t *LongTaskTimer) Duration() time.Duration {
+	now := t.clock.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var total time.Duration
+	for _, start := range t.active {
+		total += now.Sub(start)
+	}
+	return total
+}
+
+// Max returns the duration so far of the oldest active task, zero without active task.
+func (t *LongTaskTimer) Max() time.Duration {
+	now := t.clock.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var longest time.Duration
+	for _, start := range t.active {
+		longest = max(longest, now.Sub(start))
+	}
+	return longest
+}
+
+// Close stops exporting the gauges, the tasks can still be tracked.
+func (t *LongTaskTimer) Close() error {
+	var errs []error
+	for _, r := range t.registrations {
+		errs = append(errs, r.Unregister())
+	}
+	return errors.Join(errs...)
+}
+
+// Stop stops tracking the task and returns its duration, calling it again has no effect and returns the same duration.
+func (l *LongTask) Stop() time.Duration {
+	l.once.Do(func() {
+		l.duration = l.timer.clock.Since(l.start)
+		l.timer.mu.Lock()
+		defer l.timer.mu.Unlock()
+		delete(l.timer.active, l.id)
+	})
+	return l.duration
+}
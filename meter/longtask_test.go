@@ -0,0 +1,39 @@
+package meter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongTaskTimer(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithClock(fake))
+	require.NoError(t, err)
+
+	timer := NewLongTaskTimer(m, "reindex", "reindex jobs", map[string]string{"index": "orders"})
+	first := timer.Start()
+	fake.Advance(10 * time.Minute)
+	second := timer.Start()
+	fake.Advance(5 * time.Minute)
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`reindex_active_tasks{index="orders"} 2`,
+		`reindex_active_duration_seconds{index="orders"} 1200`,
+		`reindex_max_duration_seconds{index="orders"} 900`,
+	)
+
+	assert.Equal(t, 15*time.Minute, first.Stop())
+	fake.Advance(time.Minute)
+	assert.Equal(t, 15*time.Minute, first.Stop(), "stopping twice keeps the first duration")
+	assert.Equal(t, 1, timer.ActiveTasks())
+	assert.Equal(t, 6*time.Minute, timer.Max())
+	assert.Equal(t, 6*time.Minute, second.Stop())
+	assert.Zero(t, timer.ActiveTasks())
+	require.NoError(t, timer.Close())
+}
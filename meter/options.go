@@ -1,8 +1,13 @@
 package meter
 
 import (
+	"context"
+	"crypto/tls"
+	"github.com/liangweijiang/go-metric/pkg/clock"
 	"github.com/liangweijiang/go-metric/pkg/config"
 	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/tag"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"time"
 )
 
@@ -53,6 +58,7 @@ type meterProviderOption struct {
 // None
 func (m *meterProviderOption) ApplyConfig(cfg *config.Config) {
 	cfg.MeterProvider = m.providerType
+	cfg.ProviderName = ""
 }
 
 // WithProviderType returns an Option that sets the meter provider type in a Config.
@@ -68,6 +74,25 @@ func WithProviderType(providerType config.MeterProviderType) interfaces.Option {
 	}
 }
 
+// providerNameOption holds the name of the meter provider to use.
+type providerNameOption struct {
+	name string
+}
+
+// ApplyConfig sets the ProviderName field of the provided config.Config, resolved by NewMeter.
+func (p *providerNameOption) ApplyConfig(cfg *config.Config) {
+	cfg.ProviderName = p.name
+}
+
+// WithProvider returns an Option selecting the meter provider by its name, e.g. "prometheus" or the name given to
+// RegisterProvider, so that configuration systems do not need the config.MeterProviderType values. NewMeter returns
+// an error wrapping config.ErrUnsupportedProvider when no provider has this name.
+func WithProvider(name string) interfaces.Option {
+	return &providerNameOption{
+		name: name,
+	}
+}
+
 // baseTagsOption holds a set of base tags to be applied to configurations.
 type baseTagsOption struct {
 	baseTags map[string]string
@@ -95,16 +120,17 @@ type pushGatewayOption struct {
 }
 
 // ApplyConfig applies the push gateway configuration options to the provided config instance.
-// It sets the GatewayAddress and PushPeriod within the config's PushGateway field.
+// It sets the GatewayAddress and PushPeriod within the config's PushGateway field, keeping the other push gateway settings.
 // Parameters:
 // cfg (*config.Config): The configuration to be updated with push gateway settings.
 // Returns:
 // None
 func (p *pushGatewayOption) ApplyConfig(cfg *config.Config) {
-	cfg.PushGateway = &config.PushGatewayCfg{
-		GatewayAddress: p.address,
-		PushPeriod:     p.period,
+	if cfg.PushGateway == nil {
+		cfg.PushGateway = &config.PushGatewayCfg{}
 	}
+	cfg.PushGateway.GatewayAddress = p.address
+	cfg.PushGateway.PushPeriod = p.period
 }
 
 // WithPushGateway creates an Option that configures the address and push period for a Push Gateway integration.
@@ -121,6 +147,253 @@ func WithPushGateway(address string, period time.Duration) interfaces.Option {
 	}
 }
 
+// reportMetricOption holds the metadata service the scrape endpoint of the meter is announced to.
+type reportMetricOption struct {
+	address  string
+	service  string
+	interval time.Duration
+}
+
+// ApplyConfig sets the Address, Service and Interval of the report metric configuration, keeping its timeout.
+func (r *reportMetricOption) ApplyConfig(cfg *config.Config) {
+	if cfg.ReportMetric == nil {
+		cfg.ReportMetric = &config.ReportMetricCfg{}
+	}
+	cfg.ReportMetric.Address = r.address
+	cfg.ReportMetric.Service = r.service
+	cfg.ReportMetric.Interval = r.interval
+}
+
+// WithReportMetric returns an Option that announces the scrape endpoint of the meter, the IP:port the metrics server
+// is bound to, to the metadata service at address when the meter starts, and withdraws it when the meter stops.
+// The endpoint is announced again every interval if it is positive. When the server listens on every interface, the
+// configured local IP is announced.
+func WithReportMetric(address, service string, interval time.Duration) interfaces.Option {
+	return &reportMetricOption{
+		address:  address,
+		service:  service,
+		interval: interval,
+	}
+}
+
+// localIPOption holds the IP address of the host the meter runs on.
+type localIPOption struct {
+	ip string
+}
+
+// ApplyConfig sets the LocalIP field of the provided config.Config.
+func (l *localIPOption) ApplyConfig(cfg *config.Config) {
+	cfg.LocalIP = l.ip
+}
+
+// WithLocalIP returns an Option setting the IP address of the host, which names the push gateway job and replaces the
+// unspecified address of the metrics server in the endpoint announced by WithReportMetric.
+// Without it, the IP is read from the config.LocalIPEnv environment variable or detected, see WithLocalIPInterfaces.
+func WithLocalIP(ip string) interfaces.Option {
+	return &localIPOption{
+		ip: ip,
+	}
+}
+
+// localIPInterfacesOption holds the network interfaces the local IP is detected on first.
+type localIPInterfacesOption struct {
+	names []string
+}
+
+// ApplyConfig sets the LocalIPInterfaces field of the provided config.Config.
+func (l *localIPInterfacesOption) ApplyConfig(cfg *config.Config) {
+	cfg.LocalIPInterfaces = l.names
+}
+
+// WithLocalIPInterfaces returns an Option detecting the local IP on the given network interfaces first, in order,
+// e.g. eth0 before the bridges of the container runtime. The first interface of the host with an address which is
+// neither loopback nor link-local is used otherwise.
+func WithLocalIPInterfaces(names ...string) interfaces.Option {
+	return &localIPInterfacesOption{
+		names: names,
+	}
+}
+
+// finalPushTimeoutOption holds the time allowed to the last push performed when the push gateway server is stopped.
+type finalPushTimeoutOption struct {
+	timeout time.Duration
+}
+
+// ApplyConfig sets the FinalPushTimeout of the push gateway configuration, the other push gateway settings are kept.
+func (f *finalPushTimeoutOption) ApplyConfig(cfg *config.Config) {
+	if cfg.PushGateway == nil {
+		cfg.PushGateway = &config.PushGatewayCfg{}
+	}
+	cfg.PushGateway.FinalPushTimeout = f.timeout
+}
+
+// WithPushGatewayFinalPushTimeout returns an Option that bounds the best-effort push performed when the push gateway
+// server is stopped or its context is cancelled, so the last interval of data is not lost on SIGTERM.
+func WithPushGatewayFinalPushTimeout(timeout time.Duration) interfaces.Option {
+	return &finalPushTimeoutOption{
+		timeout: timeout,
+	}
+}
+
+// pushGatewayProbeOption holds the timeout of the connectivity probe of the push gateway.
+type pushGatewayProbeOption struct {
+	timeout time.Duration
+}
+
+// ApplyConfig sets the ProbeTimeout of the push gateway configuration, the other push gateway settings are kept.
+func (p *pushGatewayProbeOption) ApplyConfig(cfg *config.Config) {
+	if cfg.PushGateway == nil {
+		cfg.PushGateway = &config.PushGatewayCfg{}
+	}
+	cfg.PushGateway.ProbeTimeout = p.timeout
+}
+
+// WithPushGatewayProbe returns an Option that checks the push gateway is reachable when the meter is created.
+// A failed probe makes NewMeter return an error wrapping config.ErrGatewayUnreachable,
+// instead of discovering a wrong address only through the periodic push error logs.
+func WithPushGatewayProbe(timeout time.Duration) interfaces.Option {
+	return &pushGatewayProbeOption{
+		timeout: timeout,
+	}
+}
+
+// pushGatewayLeaderOption holds the hook electing the replica pushing to the gateway.
+type pushGatewayLeaderOption struct {
+	isLeader func(ctx context.Context) bool
+}
+
+// ApplyConfig sets the IsLeader hook of the push gateway configuration, the other push gateway settings are kept.
+func (p *pushGatewayLeaderOption) ApplyConfig(cfg *config.Config) {
+	if cfg.PushGateway == nil {
+		cfg.PushGateway = &config.PushGatewayCfg{}
+	}
+	cfg.PushGateway.IsLeader = p.isLeader
+}
+
+// WithPushGatewayLeader returns an Option that makes only the leader of replicated jobs push to the gateway,
+// the others skip their pushes, avoiding duplicate series of aggregate metrics.
+// isLeader is called before every push, it can wrap any leader election, e.g. a Kubernetes lease, or a FileLease.
+// It has no effect until a gateway address is configured with WithPushGateway.
+func WithPushGatewayLeader(isLeader func(ctx context.Context) bool) interfaces.Option {
+	return &pushGatewayLeaderOption{
+		isLeader: isLeader,
+	}
+}
+
+// pushAggregationOption holds the aggregation of the metrics before they are pushed to the gateway.
+type pushAggregationOption struct {
+	resolution time.Duration
+	labels     []string
+}
+
+// ApplyConfig sets the aggregation of the push gateway configuration, the other push gateway settings are kept.
+func (p *pushAggregationOption) ApplyConfig(cfg *config.Config) {
+	if cfg.PushGateway == nil {
+		cfg.PushGateway = &config.PushGatewayCfg{}
+	}
+	cfg.PushGateway.Resolution = p.resolution
+	cfg.PushGateway.AggregateLabels = p.labels
+}
+
+// WithPushAggregation returns an Option aggregating the metrics before they are pushed, so that thousands of
+// instances pushing every period do not overwhelm the gateway: the labels are removed from the series pushed, the
+// counters and the gauges left with the same labels being summed and their histograms merged, and the pushes are
+// limited to one per window of resolution, aligned on the clock, if positive. The final push and Flush always push.
+// It has no effect until a gateway address is configured with WithPushGateway.
+func WithPushAggregation(resolution time.Duration, labels ...string) interfaces.Option {
+	return &pushAggregationOption{
+		resolution: resolution,
+		labels:     labels,
+	}
+}
+
+// exportBatchingOption holds the batch size and the queue size of the push exporters.
+type exportBatchingOption struct {
+	batchSize int
+	queueSize int
+}
+
+// ApplyConfig sets the BatchSize and QueueSize of the export configuration, the other export settings are kept.
+func (e *exportBatchingOption) ApplyConfig(cfg *config.Config) {
+	if cfg.Export == nil {
+		cfg.Export = &config.ExportCfg{}
+	}
+	cfg.Export.BatchSize = e.batchSize
+	cfg.Export.QueueSize = e.queueSize
+}
+
+// WithExportBatching returns an Option sending at most batchSize metric families per request of the push exporters,
+// all of them if zero, and queueing up to queueSize exports while a previous one is sent or retried, none if zero.
+// The exports not fitting in the queue are dropped and counted by the go_metric_export_dropped metric.
+func WithExportBatching(batchSize, queueSize int) interfaces.Option {
+	return &exportBatchingOption{
+		batchSize: batchSize,
+		queueSize: queueSize,
+	}
+}
+
+// exportCompressionOption holds the compression of the bodies sent by the push exporters.
+type exportCompressionOption struct {
+	compression config.Compression
+}
+
+// ApplyConfig sets the Compression of the export configuration, the other export settings are kept.
+func (e *exportCompressionOption) ApplyConfig(cfg *config.Config) {
+	if cfg.Export == nil {
+		cfg.Export = &config.ExportCfg{}
+	}
+	cfg.Export.Compression = e.compression
+}
+
+// WithExportCompression returns an Option compressing the bodies sent by the push exporters with gzip, set as their
+// Content-Encoding, the only compression supported by the push gateway and the OTLP exporter. NewMeter refuses the
+// other compressions.
+func WithExportCompression(compression config.Compression) interfaces.Option {
+	return &exportCompressionOption{
+		compression: compression,
+	}
+}
+
+// exportRetryOption holds the retry policy of the push exporters.
+type exportRetryOption struct {
+	policy config.RetryPolicy
+}
+
+// ApplyConfig sets the Retry policy of the export configuration, the other export settings are kept.
+func (e *exportRetryOption) ApplyConfig(cfg *config.Config) {
+	if cfg.Export == nil {
+		cfg.Export = &config.ExportCfg{}
+	}
+	cfg.Export.Retry = e.policy
+}
+
+// WithExportRetry returns an Option retrying the requests of the push exporters failing with a network error, a 429
+// or a 5xx status according to policy.
+func WithExportRetry(policy config.RetryPolicy) interfaces.Option {
+	return &exportRetryOption{
+		policy: policy,
+	}
+}
+
+// contextOption holds the context bounding the lifetime of the background loops of the meter.
+type contextOption struct {
+	ctx context.Context
+}
+
+// ApplyConfig sets the Context field of the provided config.Config.
+func (c *contextOption) ApplyConfig(cfg *config.Config) {
+	cfg.Context = c.ctx
+}
+
+// WithContext returns an Option binding the background loops of the meter, such as the push gateway loop, to ctx.
+// Once ctx is done, the push gateway performs a final best-effort push and stops,
+// which makes it a natural fit for a context returned by signal.NotifyContext.
+func WithContext(ctx context.Context) interfaces.Option {
+	return &contextOption{
+		ctx: ctx,
+	}
+}
+
 // histogramBoundariesOption is a configuration option for setting histogram boundary values used to define data buckets in a metrics setup.
 type histogramBoundariesOption struct {
 
@@ -135,12 +408,609 @@ func (h *histogramBoundariesOption) ApplyConfig(cfg *config.Config) {
 
 // WithHistogramBoundaries creates an Option to set custom histogram bucket boundaries for metric configurations.
 // It returns an interfaces.Option that applies the provided float64 slice boundaries to the HistogramBoundaries field of a config.Config when applied.
+// The presets config.BucketsHTTPServer, config.BucketsDB and config.BucketsCacheFast can be used as global boundaries.
 func WithHistogramBoundaries(boundaries []float64) interfaces.Option {
 	return &histogramBoundariesOption{
 		boundaries: boundaries,
 	}
 }
 
+// nativeHistogramsOption represents an option to emit Prometheus native histograms for Histogram instruments.
+type nativeHistogramsOption struct{}
+
+// ApplyConfig sets the NativeHistograms flag to true in the provided config.Config instance.
+func (n *nativeHistogramsOption) ApplyConfig(cfg *config.Config) {
+	cfg.NativeHistograms = true
+}
+
+// WithNativeHistograms returns an Option that makes Histogram instruments carry Prometheus native (sparse) buckets
+// next to the explicit ones. Scrapers negotiating the protobuf format get the native buckets,
+// the others fall back to the explicit buckets configured through WithHistogramBoundaries.
+func WithNativeHistograms() interfaces.Option {
+	return &nativeHistogramsOption{}
+}
+
+// createdTimestampsOption represents an option to expose the OpenMetrics created timestamps of counters and histograms.
+type createdTimestampsOption struct{}
+
+// ApplyConfig sets the CreatedTimestamps flag to true in the provided config.Config instance.
+func (c *createdTimestampsOption) ApplyConfig(cfg *config.Config) {
+	cfg.CreatedTimestamps = true
+}
+
+// WithCreatedTimestamps returns an Option that exposes the created timestamp of counter and histogram series,
+// as _created samples in the OpenMetrics text format and as created_timestamp in the protobuf format,
+// enabling more accurate rate calculations across restarts in compatible backends.
+func WithCreatedTimestamps() interfaces.Option {
+	return &createdTimestampsOption{}
+}
+
+// deltaBucketsOption represents an option to export the per-scrape increments of the histogram buckets.
+type deltaBucketsOption struct{}
+
+// ApplyConfig sets the DeltaBuckets flag to true in the provided config.Config instance.
+func (d *deltaBucketsOption) ApplyConfig(cfg *config.Config) {
+	cfg.DeltaBuckets = true
+}
+
+// WithDeltaBuckets returns an Option that exports, next to every histogram, the gauge <name>_delta_bucket holding the
+// increment of each cumulative bucket since the previous scrape, labeled with le, so that Grafana heatmaps render
+// correctly on backends without rate-of-histogram support. The increments are computed per scrape, the meter should
+// be scraped by a single scraper.
+func WithDeltaBuckets() interfaces.Option {
+	return &deltaBucketsOption{}
+}
+
+// histogramExtremaOption represents an option to export the extrema of the histograms.
+type histogramExtremaOption struct{}
+
+// ApplyConfig sets the HistogramExtrema flag to true in the provided config.Config instance.
+func (h *histogramExtremaOption) ApplyConfig(cfg *config.Config) {
+	cfg.HistogramExtrema = true
+}
+
+// WithHistogramExtrema returns an Option that exports, next to every histogram, the gauges <name>_min and <name>_max
+// holding the smallest and the largest value recorded to each series since the previous collection, which the
+// buckets of Prometheus histograms lose. It keeps two values per series recorded to during the interval.
+func WithHistogramExtrema() interfaces.Option {
+	return &histogramExtremaOption{}
+}
+
+// unitCoercionOption represents an option to convert to seconds the values in milliseconds of the histograms in seconds.
+type unitCoercionOption struct{}
+
+// ApplyConfig sets the UnitCoercion flag to true in the provided config.Config instance.
+func (u *unitCoercionOption) ApplyConfig(cfg *config.Config) {
+	cfg.UnitCoercion = true
+}
+
+// WithUnitCoercion returns an Option converting to seconds the values of a histogram in seconds once they look like
+// milliseconds: 20 consecutive values above its largest boundary and 1000 times its smallest one. Without it, such a
+// histogram is only reported once through the error log. Only the values above the threshold are converted, those
+// recorded before the detection are not.
+func WithUnitCoercion() interfaces.Option {
+	return &unitCoercionOption{}
+}
+
+// creationAuditOption represents an option to record where the instruments are created.
+type creationAuditOption struct{}
+
+// ApplyConfig sets the CreationAudit flag to true in the provided config.Config instance.
+func (c *creationAuditOption) ApplyConfig(cfg *config.Config) {
+	cfg.CreationAudit = true
+}
+
+// WithCreationAudit returns an Option recording where the first instrument of every metric is created, the function
+// and the file:line of the first caller outside of the SDK, returned by InstrumentOrigins, to find the code behind a
+// metric. The call stack is walked once per metric.
+func WithCreationAudit() interfaces.Option {
+	return &creationAuditOption{}
+}
+
+// dictionaryOption holds the metrics dictionary of the organization.
+type dictionaryOption struct {
+	dictionary config.Dictionary
+}
+
+// ApplyConfig sets the Dictionary field of the provided config.Config.
+func (d *dictionaryOption) ApplyConfig(cfg *config.Config) {
+	cfg.Dictionary = d.dictionary
+}
+
+// WithDictionary returns an Option describing the instruments created with an empty description from the metrics
+// dictionary, e.g. loaded with config.LoadDictionary, and reporting once through the error log every metric of the
+// application missing from it, returned by UnknownMetrics as well. The metrics of the SDK, e.g. the runtime metrics,
+// are not reported.
+func WithDictionary(dictionary config.Dictionary) interfaces.Option {
+	return &dictionaryOption{
+		dictionary: dictionary,
+	}
+}
+
+// renamesOption holds the renamed metrics and the end of their transition period.
+type renamesOption struct {
+	renames config.Renames
+	until   time.Time
+}
+
+// ApplyConfig sets the Renames and RenameUntil fields of the provided config.Config.
+func (r *renamesOption) ApplyConfig(cfg *config.Config) {
+	cfg.Renames = r.renames
+	cfg.RenameUntil = r.until
+}
+
+// WithRenames returns an Option renaming the metrics of renames, from their old to their new names. Until the
+// deadline, forever if zero, the measurements of either name are recorded under both names, so that the dashboards
+// can be migrated to the new series without a gap in the data. Afterward, the instruments created with an old name
+// record to the new name alone. The renames cannot be chained, see config.Renames.
+func WithRenames(renames config.Renames, until time.Time) interfaces.Option {
+	return &renamesOption{
+		renames: renames,
+		until:   until,
+	}
+}
+
+// bucketTuningOption holds the window over which the distributions of the histograms are analyzed.
+type bucketTuningOption struct {
+	window time.Duration
+}
+
+// ApplyConfig sets the BucketTuningWindow field of the provided config.Config.
+func (b *bucketTuningOption) ApplyConfig(cfg *config.Config) {
+	cfg.BucketTuningWindow = b.window
+}
+
+// WithBucketTuning returns an Option recording the distribution of the values of every histogram for window, and
+// recommending at the end of each window bucket boundaries placed at the quantiles of the values, logged at the info
+// level and returned by BucketRecommendations, along with how many of the current buckets were used. It helps to
+// replace the default buckets fitting none of the values. The distributions cost a few kilobytes per histogram.
+func WithBucketTuning(window time.Duration) interfaces.Option {
+	return &bucketTuningOption{
+		window: window,
+	}
+}
+
+// nameCollisionPolicyOption holds the policy applied to the instruments colliding by name.
+type nameCollisionPolicyOption struct {
+	policy config.NameCollisionPolicy
+}
+
+// ApplyConfig sets the NameCollisionPolicy field of the provided config.Config.
+func (n *nameCollisionPolicyOption) ApplyConfig(cfg *config.Config) {
+	cfg.NameCollisionPolicy = n.policy
+}
+
+// WithNameCollisionPolicy returns an Option deciding what happens when an instrument is created with the name of a
+// metric created before with another kind, unit or description: merged into the first one, created under a suffixed
+// name, or refused with an error log. By default, the instruments are left to the OpenTelemetry SDK.
+func WithNameCollisionPolicy(policy config.NameCollisionPolicy) interfaces.Option {
+	return &nameCollisionPolicyOption{
+		policy: policy,
+	}
+}
+
+// unitSuffixesOption represents an option to append the unit suffixes to the instrument names.
+type unitSuffixesOption struct{}
+
+// ApplyConfig sets the UnitSuffixes flag to true in the provided config.Config instance.
+func (u *unitSuffixesOption) ApplyConfig(cfg *config.Config) {
+	cfg.UnitSuffixes = true
+}
+
+// WithUnitSuffixes returns an Option that appends to the instrument names the canonical suffix of their unit, such as
+// _seconds for s or _bytes for By, and _total to the counters, so that every reader exports the names promtool lint
+// expects and not only the Prometheus exporter. The suffixes a name already ends with are not appended twice.
+func WithUnitSuffixes() interfaces.Option {
+	return &unitSuffixesOption{}
+}
+
+// instanceTagsOption holds where the instance tags are injected in pull and in push mode.
+type instanceTagsOption struct {
+	pull config.InstanceTagPlacement
+	push config.InstanceTagPlacement
+}
+
+// ApplyConfig sets the InstanceTagsPull and InstanceTagsPush fields of the provided config.Config.
+func (i *instanceTagsOption) ApplyConfig(cfg *config.Config) {
+	cfg.InstanceTagsPull = i.pull
+	cfg.InstanceTagsPush = i.push
+}
+
+// WithInstanceTags returns an Option injecting the hostname, pid and ip tags identifying the instance of the
+// application. With auto, they are added to the resource in pull mode, where the scraper labels the series with their
+// target already, and as constant labels of every series pushed to the gateway, where the instances are not told
+// apart otherwise. Without auto, they are constant labels of every series in both modes.
+func WithInstanceTags(auto bool) interfaces.Option {
+	if auto {
+		return WithInstanceTagPlacement(config.InstanceTagsResource, config.InstanceTagsLabels)
+	}
+	return WithInstanceTagPlacement(config.InstanceTagsLabels, config.InstanceTagsLabels)
+}
+
+// WithInstanceTagPlacement returns an Option deciding separately where the instance tags are injected in pull mode,
+// i.e. the series scraped from the metrics server, and in push mode, i.e. the series pushed to the gateway.
+func WithInstanceTagPlacement(pull, push config.InstanceTagPlacement) interfaces.Option {
+	return &instanceTagsOption{
+		pull: pull,
+		push: push,
+	}
+}
+
+// lazyInitOption represents an option to defer the initialization of the meter to the first scrape.
+type lazyInitOption struct{}
+
+// ApplyConfig sets the LazyInit flag to true in the provided config.Config instance.
+func (l *lazyInitOption) ApplyConfig(cfg *config.Config) {
+	cfg.LazyInit = true
+}
+
+// WithLazyInit returns an Option that binds the metrics server at once but defers the initialization of the
+// Prometheus exporter and provider, including the resource detection and the collectors, to the first scrape, cutting
+// the startup latency of the processes that may never be scraped, e.g. CLIs. The first use of the meter needing them,
+// such as the creation of an instrument, initializes them as well. Its errors are logged, the meter falling back to a
+// no-op meter.
+func WithLazyInit() interfaces.Option {
+	return &lazyInitOption{}
+}
+
+// deferStartOption represents an option to spawn no goroutine until the meter is started explicitly.
+type deferStartOption struct{}
+
+// ApplyConfig sets the DeferStart flag to true in the provided config.Config instance.
+func (d *deferStartOption) ApplyConfig(cfg *config.Config) {
+	cfg.DeferStart = true
+}
+
+// WithDeferredStart returns an Option building the meter synchronously, without spawning any goroutine: the resource
+// is detected in the calling goroutine, and the servers, the collectors and the loops of the meter are only started by
+// an explicit WithRunning(true), e.g. from the handler of a FaaS function, or never in tests and init functions.
+// The instruments record before the start, the metrics are exported once started.
+func WithDeferredStart() interfaces.Option {
+	return &deferStartOption{}
+}
+
+// serverlessOption represents an option to run the meter in a function frozen between its invocations.
+type serverlessOption struct{}
+
+// ApplyConfig sets the Serverless flag to true in the provided config.Config instance.
+func (s *serverlessOption) ApplyConfig(cfg *config.Config) {
+	cfg.Serverless = true
+}
+
+// WithServerless returns an Option running the meter in a function frozen between its invocations, e.g. on AWS
+// Lambda: no server is scraped from nor announced, the start is deferred as with WithDeferredStart, and the metrics
+// are collected and exported only when the meter is flushed, to the push gateway and the configured readers.
+// See the faas package to flush at the end of every invocation.
+func WithServerless() interfaces.Option {
+	return &serverlessOption{}
+}
+
+// signalDumpOption holds the file the snapshot of the metrics is dumped to on SIGUSR1.
+type signalDumpOption struct {
+	path string
+}
+
+// ApplyConfig enables the dump of the snapshot on SIGUSR1 to the path of the provided config.Config.
+func (s *signalDumpOption) ApplyConfig(cfg *config.Config) {
+	cfg.SignalDump = true
+	cfg.SignalDumpPath = s.path
+}
+
+// WithSignalDump returns an Option dumping the snapshot of the metrics, as scraped from /metrics, when the process
+// receives SIGUSR1, e.g. kill -USR1 <pid>, a quick diagnostic path when the HTTP endpoint is unreachable. The snapshot
+// replaces the content of the file at path, or is written to stderr if path is empty. Signals are not supported on
+// Windows, where the option is ignored.
+func WithSignalDump(path string) interfaces.Option {
+	return &signalDumpOption{
+		path: path,
+	}
+}
+
+// callbackWorkersOption holds the pool of workers running the observable callbacks and their timeout.
+type callbackWorkersOption struct {
+	workers int
+	timeout time.Duration
+}
+
+// ApplyConfig sets the CallbackWorkers and CallbackTimeout fields of the provided config.Config.
+func (c *callbackWorkersOption) ApplyConfig(cfg *config.Config) {
+	cfg.CallbackWorkers = c.workers
+	cfg.CallbackTimeout = c.timeout
+}
+
+// WithCallbackWorkers returns an Option running the callbacks of the observable gauges concurrently on up to workers
+// goroutines at every collection, each within timeout, one second if not positive, so that a slow callback does not
+// delay the whole collection. The values of a callback not done in time are dropped for the collection, and the
+// callback is skipped until it returns. The callbacks are run one after the other by the collection if workers is
+// not positive.
+func WithCallbackWorkers(workers int, timeout time.Duration) interfaces.Option {
+	return &callbackWorkersOption{
+		workers: workers,
+		timeout: timeout,
+	}
+}
+
+// panicLimitOption holds the number of panics after which a callback or a tag provider is disabled.
+type panicLimitOption struct {
+	limit int
+}
+
+// ApplyConfig sets the PanicLimit field of the provided config.Config.
+func (p *panicLimitOption) ApplyConfig(cfg *config.Config) {
+	cfg.PanicLimit = p.limit
+}
+
+// WithPanicLimit returns an Option disabling an observable callback or a tag provider once it panicked limit times,
+// 3 by default. The panics are always recovered, logged and counted by the go_metric_panics counter, instead of
+// crashing the goroutine collecting or recording the metrics; a negative limit never disables the panicking code.
+func WithPanicLimit(limit int) interfaces.Option {
+	return &panicLimitOption{
+		limit: limit,
+	}
+}
+
+// otlpEndpointOption holds the endpoint of the OpenTelemetry Collector and the interval of the exports.
+type otlpEndpointOption struct {
+	endpoint string
+	interval time.Duration
+}
+
+// ApplyConfig sets the Endpoint and Interval fields of the OTLP configuration of the provided config.Config.
+func (o *otlpEndpointOption) ApplyConfig(cfg *config.Config) {
+	if cfg.OTLP == nil {
+		cfg.OTLP = &config.OTLPCfg{}
+	}
+	cfg.OTLP.Endpoint = o.endpoint
+	cfg.OTLP.Interval = o.interval
+}
+
+// WithOTLPEndpoint returns an Option pushing the metrics of the config.MeterProviderTypeOTLPGrpc provider to the
+// OpenTelemetry Collector listening on endpoint, e.g. otel-collector:4317, every interval, one minute if not positive.
+// The connection is secured with TLS trusting the system roots, see WithOTLPTLS and WithOTLPInsecure.
+func WithOTLPEndpoint(endpoint string, interval time.Duration) interfaces.Option {
+	return &otlpEndpointOption{
+		endpoint: endpoint,
+		interval: interval,
+	}
+}
+
+// otlpTLSOption holds the TLS configuration of the connection to the OpenTelemetry Collector.
+type otlpTLSOption struct {
+	tls *tls.Config
+}
+
+// ApplyConfig sets the TLS field of the OTLP configuration of the provided config.Config.
+func (o *otlpTLSOption) ApplyConfig(cfg *config.Config) {
+	if cfg.OTLP == nil {
+		cfg.OTLP = &config.OTLPCfg{}
+	}
+	cfg.OTLP.TLS = o.tls
+}
+
+// WithOTLPTLS returns an Option securing the connection to the OpenTelemetry Collector with tlsConfig, e.g. to trust
+// a private CA or present a client certificate.
+func WithOTLPTLS(tlsConfig *tls.Config) interfaces.Option {
+	return &otlpTLSOption{
+		tls: tlsConfig,
+	}
+}
+
+// otlpInsecureOption represents an option to connect to the OpenTelemetry Collector without TLS.
+type otlpInsecureOption struct{}
+
+// ApplyConfig sets the Insecure flag of the OTLP configuration of the provided config.Config.
+func (o *otlpInsecureOption) ApplyConfig(cfg *config.Config) {
+	if cfg.OTLP == nil {
+		cfg.OTLP = &config.OTLPCfg{}
+	}
+	cfg.OTLP.Insecure = true
+}
+
+// WithOTLPInsecure returns an Option connecting to the OpenTelemetry Collector without TLS, e.g. to a sidecar
+// collector on localhost.
+func WithOTLPInsecure() interfaces.Option {
+	return &otlpInsecureOption{}
+}
+
+// otlpHeadersOption holds the headers sent to the OpenTelemetry Collector.
+type otlpHeadersOption struct {
+	headers map[string]string
+}
+
+// ApplyConfig adds the headers to the OTLP configuration of the provided config.Config.
+func (o *otlpHeadersOption) ApplyConfig(cfg *config.Config) {
+	if cfg.OTLP == nil {
+		cfg.OTLP = &config.OTLPCfg{}
+	}
+	if cfg.OTLP.Headers == nil {
+		cfg.OTLP.Headers = make(map[string]string, len(o.headers))
+	}
+	for key, value := range o.headers {
+		cfg.OTLP.Headers[key] = value
+	}
+}
+
+// WithOTLPHeaders returns an Option sending the headers with every export to the OpenTelemetry Collector, e.g. an
+// authorization token. Their values are not shown by Describe.
+func WithOTLPHeaders(headers map[string]string) interfaces.Option {
+	return &otlpHeadersOption{
+		headers: headers,
+	}
+}
+
+// statsDOption holds the address of the StatsD daemon and the interval between two sends.
+type statsDOption struct {
+	address       string
+	flushInterval time.Duration
+}
+
+// ApplyConfig sets the Address and FlushInterval fields of the StatsD configuration of the provided config.Config.
+func (o *statsDOption) ApplyConfig(cfg *config.Config) {
+	if cfg.StatsD == nil {
+		cfg.StatsD = &config.StatsDCfg{}
+	}
+	cfg.StatsD.Address = o.address
+	cfg.StatsD.FlushInterval = o.flushInterval
+}
+
+// WithStatsD returns an Option sending the measurements of the config.MeterProviderTypeStatsD and
+// config.MeterProviderTypeDogStatsD providers to the StatsD daemon or the Datadog agent listening on the UDP address,
+// e.g. localhost:8125, every flushInterval, one second if not positive.
+func WithStatsD(address string, flushInterval time.Duration) interfaces.Option {
+	return &statsDOption{
+		address:       address,
+		flushInterval: flushInterval,
+	}
+}
+
+// statsDPrefixOption holds the prefix of the StatsD metric names.
+type statsDPrefixOption struct {
+	prefix string
+}
+
+// ApplyConfig sets the Prefix field of the StatsD configuration of the provided config.Config.
+func (o *statsDPrefixOption) ApplyConfig(cfg *config.Config) {
+	if cfg.StatsD == nil {
+		cfg.StatsD = &config.StatsDCfg{}
+	}
+	cfg.StatsD.Prefix = o.prefix
+}
+
+// WithStatsDPrefix returns an Option prepending prefix and a dot to the names of the metrics sent to StatsD, e.g. the
+// name of the service.
+func WithStatsDPrefix(prefix string) interfaces.Option {
+	return &statsDPrefixOption{
+		prefix: prefix,
+	}
+}
+
+// statsDPacketSizeOption holds the maximum size of the StatsD packets.
+type statsDPacketSizeOption struct {
+	size int
+}
+
+// ApplyConfig sets the MaxPacketSize field of the StatsD configuration of the provided config.Config.
+func (o *statsDPacketSizeOption) ApplyConfig(cfg *config.Config) {
+	if cfg.StatsD == nil {
+		cfg.StatsD = &config.StatsDCfg{}
+	}
+	cfg.StatsD.MaxPacketSize = o.size
+}
+
+// WithStatsDPacketSize returns an Option bounding the size of the packets sent to StatsD, 1432 bytes by default to
+// fit the Ethernet MTU. A larger size, up to 8932 bytes on a jumbo frames network or 65507 bytes on the loopback
+// interface, sends fewer packets.
+func WithStatsDPacketSize(size int) interfaces.Option {
+	return &statsDPacketSizeOption{
+		size: size,
+	}
+}
+
+// dogStatsDDistributionsOption represents an option to send the histograms as DogStatsD distributions.
+type dogStatsDDistributionsOption struct{}
+
+// ApplyConfig sets the Distributions flag of the StatsD configuration of the provided config.Config.
+func (o *dogStatsDDistributionsOption) ApplyConfig(cfg *config.Config) {
+	if cfg.StatsD == nil {
+		cfg.StatsD = &config.StatsDCfg{}
+	}
+	cfg.StatsD.Distributions = true
+}
+
+// WithDogStatsDDistributions returns an Option sending the histograms of the config.MeterProviderTypeDogStatsD
+// provider as distributions, whose percentiles are computed by Datadog across all the hosts, instead of histograms
+// aggregated by every agent.
+func WithDogStatsDDistributions() interfaces.Option {
+	return &dogStatsDDistributionsOption{}
+}
+
+// graphiteOption holds the address of carbon and the interval between two pushes.
+type graphiteOption struct {
+	address  string
+	interval time.Duration
+}
+
+// ApplyConfig sets the Address and Interval fields of the Graphite configuration of the provided config.Config.
+func (o *graphiteOption) ApplyConfig(cfg *config.Config) {
+	if cfg.Graphite == nil {
+		cfg.Graphite = &config.GraphiteCfg{}
+	}
+	cfg.Graphite.Address = o.address
+	cfg.Graphite.Interval = o.interval
+}
+
+// WithGraphite returns an Option pushing the metrics of the config.MeterProviderTypeGraphite provider to the carbon
+// plaintext TCP address, e.g. localhost:2003, every interval, one minute if not positive. The counters are pushed as
+// totals, and the histograms as their count, sum, min and max.
+func WithGraphite(address string, interval time.Duration) interfaces.Option {
+	return &graphiteOption{
+		address:  address,
+		interval: interval,
+	}
+}
+
+// graphitePrefixOption holds the prefix of the Graphite metric paths.
+type graphitePrefixOption struct {
+	prefix string
+}
+
+// ApplyConfig sets the Prefix field of the Graphite configuration of the provided config.Config.
+func (o *graphitePrefixOption) ApplyConfig(cfg *config.Config) {
+	if cfg.Graphite == nil {
+		cfg.Graphite = &config.GraphiteCfg{}
+	}
+	cfg.Graphite.Prefix = o.prefix
+}
+
+// WithGraphitePrefix returns an Option prepending prefix and a dot to the paths of the metrics pushed to Graphite,
+// e.g. servers.checkout.
+func WithGraphitePrefix(prefix string) interfaces.Option {
+	return &graphitePrefixOption{
+		prefix: prefix,
+	}
+}
+
+// graphiteTagFormatOption holds the format of the tags in the Graphite metric paths.
+type graphiteTagFormatOption struct {
+	format config.GraphiteTagFormat
+}
+
+// ApplyConfig sets the TagFormat field of the Graphite configuration of the provided config.Config.
+func (o *graphiteTagFormatOption) ApplyConfig(cfg *config.Config) {
+	if cfg.Graphite == nil {
+		cfg.Graphite = &config.GraphiteCfg{}
+	}
+	cfg.Graphite.TagFormat = o.format
+}
+
+// WithGraphiteTagFormat returns an Option writing the tags of the series pushed to Graphite in format: as path
+// segments with config.GraphiteTagsPath, the default, or in the tagged format of Graphite 1.1 with
+// config.GraphiteTagsTagged.
+func WithGraphiteTagFormat(format config.GraphiteTagFormat) interfaces.Option {
+	return &graphiteTagFormatOption{
+		format: format,
+	}
+}
+
+// resourceTimeoutOption holds the time allowed to the detection of the resource.
+type resourceTimeoutOption struct {
+	timeout time.Duration
+}
+
+// ApplyConfig sets the ResourceTimeout field of the provided config.Config.
+func (r *resourceTimeoutOption) ApplyConfig(cfg *config.Config) {
+	cfg.ResourceTimeout = r.timeout
+}
+
+// WithResourceDetectionTimeout returns an Option bounding the detection of the resource of the meter, two seconds by
+// default. The detectors, such as those of the host and the container, run concurrently, and those not done in time
+// are skipped with an error log instead of stalling the startup.
+func WithResourceDetectionTimeout(timeout time.Duration) interfaces.Option {
+	return &resourceTimeoutOption{
+		timeout: timeout,
+	}
+}
+
 // infoLogOption allows customization of the info log write function within a configuration.
 // It holds a function that accepts a string message intended for informational logging.
 type infoLogOption struct {
@@ -189,6 +1059,95 @@ func WithErrorLogWrite(logFunc func(s string)) interfaces.Option {
 	}
 }
 
+// tagProviderOption holds a provider of tags computed at record time.
+type tagProviderOption struct {
+	provider interfaces.TagProvider
+}
+
+// ApplyConfig appends the provider to the TagProviders of the provided config.Config.
+func (t *tagProviderOption) ApplyConfig(cfg *config.Config) {
+	cfg.TagProviders = append(cfg.TagProviders, t.provider)
+}
+
+// WithTagProvider returns an Option attaching the tags computed by provider at record time to every measurement,
+// e.g. the current shard or configuration hash, without rebuilding the instruments. The tags of the instruments
+// take precedence over the provided ones on duplicate keys. A function can be used through config.TagProviderFunc.
+func WithTagProvider(provider interfaces.TagProvider) interfaces.Option {
+	return &tagProviderOption{
+		provider: provider,
+	}
+}
+
+// WithSpanAttributes returns an Option tagging the measurements recorded with a context carrying a span of the
+// OpenTelemetry SDK with the span attributes of the given keys, e.g. "rpc.method" recorded as the tag rpc_method.
+// Only attributes of bounded cardinality should be selected, see tag.FromSpan for custom selectors.
+func WithSpanAttributes(keys ...string) interfaces.Option {
+	return WithTagProvider(tag.FromSpan(tag.SpanKeys(keys...)))
+}
+
+// WithBaggageTags returns an Option tagging the measurements with the members of the OpenTelemetry baggage of their
+// context whose keys are in the allowlist, e.g. "tenant.tier" recorded as the tag tenant_tier, so that dimensions
+// injected upstream flow into the metrics. Only keys whose values are bounded should be allowed, see tag.FromBaggage.
+func WithBaggageTags(allowlist ...string) interfaces.Option {
+	return WithTagProvider(tag.FromBaggage(allowlist...))
+}
+
+// cardinalityLimitOption holds the number of distinct tag sets allowed per metric.
+type cardinalityLimitOption struct {
+	limit int
+}
+
+// ApplyConfig sets the CardinalityLimit field of the provided config.Config.
+func (c *cardinalityLimitOption) ApplyConfig(cfg *config.Config) {
+	cfg.CardinalityLimit = c.limit
+}
+
+// WithCardinalityLimit returns an Option that sets the number of distinct tag sets allowed per metric,
+// validate.DefaultCardinalityLimit if not positive. The validate provider reports the metrics exceeding it, the other
+// providers drop the measurements of their new series beyond it, none if not positive.
+func WithCardinalityLimit(limit int) interfaces.Option {
+	return &cardinalityLimitOption{
+		limit: limit,
+	}
+}
+
+// clockOption holds the clock of the meter.
+type clockOption struct {
+	clock clock.Clock
+}
+
+// ApplyConfig sets the Clock field of the provided config.Config.
+func (c *clockOption) ApplyConfig(cfg *config.Config) {
+	cfg.Clock = c.clock
+}
+
+// WithClock returns an Option that sets the clock driving the runtime and disk collectors, the push gateway and the
+// timing helpers of the instruments, e.g. a clock.Fake advanced by tests instead of sleeping.
+func WithClock(c clock.Clock) interfaces.Option {
+	return &clockOption{
+		clock: c,
+	}
+}
+
+// readerOption holds an additional reader of the meter provider.
+type readerOption struct {
+	reader sdkmetric.Reader
+}
+
+// ApplyConfig appends the reader to the Readers field of the provided config.Config.
+func (r *readerOption) ApplyConfig(cfg *config.Config) {
+	cfg.Readers = append(cfg.Readers, r.reader)
+}
+
+// WithReader returns an Option that registers reader on the meter provider next to the Prometheus exporter,
+// e.g. a sdkmetric.ManualReader collecting the measurements in tests or a sdkmetric.PeriodicReader exporting them
+// to another backend. A reader can only be registered on a single meter. It may be given several times.
+func WithReader(reader sdkmetric.Reader) interfaces.Option {
+	return &readerOption{
+		reader: reader,
+	}
+}
+
 // runtimeMetricsOption represents an option to enable the collection of runtime metrics.
 // It implements the interfaces.Option interface to apply configuration changes to a config.Config instance.
 type runtimeMetricsOption struct{}
@@ -202,3 +1161,375 @@ func (r *runtimeMetricsOption) ApplyConfig(cfg *config.Config) {
 func WithRuntimeMetricsCollector() interfaces.Option {
 	return &runtimeMetricsOption{}
 }
+
+// fdMetricsOption represents an option to enable the collection of file descriptor and TCP connection metrics.
+type fdMetricsOption struct{}
+
+// ApplyConfig sets the FDMetricsCollect flag to true in the provided config.Config instance.
+func (f *fdMetricsOption) ApplyConfig(cfg *config.Config) {
+	cfg.FDMetricsCollect = true
+}
+
+// WithFDMetricsCollector returns an Option that enables the collection, from /proc on Linux, of the number of open
+// file descriptors against their limit and of the TCP connections per state, to alert before running out of descriptors.
+func WithFDMetricsCollector() interfaces.Option {
+	return &fdMetricsOption{}
+}
+
+// diskUsageOption holds the paths whose disk usage is collected and the collection interval.
+type diskUsageOption struct {
+	interval time.Duration
+	paths    []string
+}
+
+// ApplyConfig appends the paths to the DiskUsagePaths of the provided config.Config and sets the DiskUsageInterval.
+func (d *diskUsageOption) ApplyConfig(cfg *config.Config) {
+	cfg.DiskUsagePaths = append(cfg.DiskUsagePaths, d.paths...)
+	cfg.DiskUsageInterval = d.interval
+}
+
+// WithDiskUsageCollector returns an Option that collects, every interval (30 seconds if not positive), the used and free
+// bytes and inodes of the filesystems holding the given paths, e.g. the data and log directories. Linux only.
+func WithDiskUsageCollector(interval time.Duration, paths ...string) interfaces.Option {
+	return &diskUsageOption{
+		interval: interval,
+		paths:    paths,
+	}
+}
+
+// uptimeOption holds the state file persisting the number of restarts of the process.
+type uptimeOption struct {
+	stateFile string
+}
+
+// ApplyConfig enables the UptimeCollect flag and sets the RestartStateFile of the provided config.Config.
+func (u *uptimeOption) ApplyConfig(cfg *config.Config) {
+	cfg.UptimeCollect = true
+	cfg.RestartStateFile = u.stateFile
+}
+
+// WithUptimeCollector returns an Option that exports the uptime and start time of the process.
+// When stateFile is not empty, the number of starts is persisted in that file and the restarts are exported
+// as the process_restarts gauge, which keeps growing across restarts so that restart storms are visible.
+func WithUptimeCollector(stateFile string) interfaces.Option {
+	return &uptimeOption{
+		stateFile: stateFile,
+	}
+}
+
+// logLevelOption holds the level of the SDK logging to apply to a configuration.
+type logLevelOption struct {
+	level config.LogLevel
+}
+
+// ApplyConfig sets the log level of the provided config.Config to the level stored in the logLevelOption instance.
+func (l *logLevelOption) ApplyConfig(cfg *config.Config) {
+	cfg.SetLogLevel(l.level)
+}
+
+// WithLogLevel returns an Option that sets the level of the SDK logging (debug, info or error).
+// The level can be changed afterward at runtime through Meter.SetLogLevel.
+func WithLogLevel(level config.LogLevel) interfaces.Option {
+	return &logLevelOption{
+		level: level,
+	}
+}
+
+// dropSummaryIntervalOption holds the interval at which the summary of dropped measurements is logged.
+type dropSummaryIntervalOption struct {
+	interval time.Duration
+}
+
+// ApplyConfig sets the DropSummaryInterval field of the provided config.Config.
+func (d *dropSummaryIntervalOption) ApplyConfig(cfg *config.Config) {
+	cfg.DropSummaryInterval = d.interval
+}
+
+// WithDropSummaryInterval returns an Option that sets the interval at which a summary of the dropped measurements
+// (nop fallback, validation failures, cardinality limits, disabled metrics) is logged, one minute by default.
+func WithDropSummaryInterval(interval time.Duration) interfaces.Option {
+	return &dropSummaryIntervalOption{
+		interval: interval,
+	}
+}
+
+// jitterOption holds the jitter fraction applied to the periods of the background loops.
+type jitterOption struct {
+	fraction float64
+}
+
+// ApplyConfig sets the TickerJitter field of the provided config.Config.
+func (j *jitterOption) ApplyConfig(cfg *config.Config) {
+	cfg.TickerJitter = j.fraction
+}
+
+// WithJitter returns an Option that randomizes the push gateway and runtime collector periods by up to
+// +/- fraction of the period (e.g. 0.1 for 10%), so fleets of identical services don't push at the same instant.
+func WithJitter(fraction float64) interfaces.Option {
+	return &jitterOption{
+		fraction: fraction,
+	}
+}
+
+// scrapeFilterOption holds the tenant filter of the metrics endpoint.
+type scrapeFilterOption struct {
+	filter config.ScrapeFilter
+	label  string
+}
+
+// ApplyConfig sets the ScrapeFilter and ScrapeTenantLabel fields of the provided config.Config.
+func (s *scrapeFilterOption) ApplyConfig(cfg *config.Config) {
+	cfg.ScrapeFilter = s.filter
+	cfg.ScrapeTenantLabel = s.label
+}
+
+// WithScrapeFilter returns an Option restricting every request to the metrics endpoint to the series whose label
+// holds the tenant resolved by filter from the request, config.DefaultScrapeTenantLabel if label is empty, so that a
+// shared exporter can be scraped by each tenant of a multi-tenant cluster. The push gateway exports every series.
+func WithScrapeFilter(filter config.ScrapeFilter, label string) interfaces.Option {
+	return &scrapeFilterOption{
+		filter: filter,
+		label:  label,
+	}
+}
+
+// featureGateOption holds the feature gate of the instruments.
+type featureGateOption struct {
+	gate config.FeatureGate
+}
+
+// ApplyConfig sets the FeatureGate field of the provided config.Config.
+func (f *featureGateOption) ApplyConfig(cfg *config.Config) {
+	cfg.FeatureGate = f.gate
+}
+
+// WithFeatureGate returns an Option gating the instruments behind a feature-flag system: gate is asked once per
+// metric whether its instruments record, and the metrics it gates off get no-op instruments.
+func WithFeatureGate(gate config.FeatureGate) interfaces.Option {
+	return &featureGateOption{
+		gate: gate,
+	}
+}
+
+// metricBudgetOption holds the instrument budget of a module.
+type metricBudgetOption struct {
+	module      string
+	instruments int
+}
+
+// ApplyConfig adds the budget of the module to the MetricBudgets field of the provided config.Config.
+func (b *metricBudgetOption) ApplyConfig(cfg *config.Config) {
+	if cfg.MetricBudgets == nil {
+		cfg.MetricBudgets = make(map[string]int)
+	}
+	cfg.MetricBudgets[b.module] = b.instruments
+}
+
+// WithMetricBudget returns an Option declaring the number of distinct metrics the code of a module, a package path
+// prefix such as github.com/acme/shop/payment, is expected to create. The modules exceeding their budget are logged
+// as errors, and their extra metrics are refused with WithStrictBudgets, keeping the scrape size predictable.
+// The usage of the budgets is returned by BudgetUsage.
+func WithMetricBudget(module string, instruments int) interfaces.Option {
+	return &metricBudgetOption{
+		module:      module,
+		instruments: instruments,
+	}
+}
+
+// strictBudgetsOption enables the strict mode of the instrument budgets.
+type strictBudgetsOption struct{}
+
+// ApplyConfig sets the StrictBudgets field of the provided config.Config.
+func (strictBudgetsOption) ApplyConfig(cfg *config.Config) {
+	cfg.StrictBudgets = true
+}
+
+// WithStrictBudgets returns an Option refusing the metrics making a module exceed the budget declared with
+// WithMetricBudget: their instruments are no-op.
+func WithStrictBudgets() interfaces.Option {
+	return strictBudgetsOption{}
+}
+
+// unusedWindowOption holds the window of the detection of the unused instruments.
+type unusedWindowOption struct {
+	window time.Duration
+}
+
+// ApplyConfig sets the UnusedWindow field of the provided config.Config.
+func (u *unusedWindowOption) ApplyConfig(cfg *config.Config) {
+	cfg.UnusedWindow = u.window
+}
+
+// WithUnusedDetection returns an Option tracking the metrics whose instruments were created but not recorded to
+// during window, so that dead instrumentation can be pruned. They are returned by UnusedInstruments and exposed by
+// the go_metric_unused_instruments gauge, tagged with the metric name.
+func WithUnusedDetection(window time.Duration) interfaces.Option {
+	return &unusedWindowOption{
+		window: window,
+	}
+}
+
+// valueReadbackOption enables the local tracking of the values of the series.
+type valueReadbackOption struct{}
+
+// ApplyConfig sets the ValueReadback field of the provided config.Config.
+func (valueReadbackOption) ApplyConfig(cfg *config.Config) {
+	cfg.ValueReadback = true
+}
+
+// WithValueReadback returns an Option tracking locally the value of every series of the counters, up-down counters
+// and gauges, read back through interfaces.Readable by control loops such as rate limiters or backpressure, so that
+// they consume the same numbers as the ones exported. It costs a lookup per measurement and the memory of a value per
+// series.
+func WithValueReadback() interfaces.Option {
+	return valueReadbackOption{}
+}
+
+// derivedMetricOption adds a derived metric.
+type derivedMetricOption struct {
+	metric config.DerivedMetric
+}
+
+// ApplyConfig appends the derived metric to the DerivedMetrics field of the provided config.Config.
+func (d *derivedMetricOption) ApplyConfig(cfg *config.Config) {
+	cfg.DerivedMetrics = append(cfg.DerivedMetrics, d.metric)
+}
+
+// WithDerivedMetric returns an Option exporting the gauge name computed at every collection from the values of other
+// metrics with expr, e.g. "http_errors / http_requests", instead of recording it next to them. Each metric of expr
+// stands for the sum of its series, grouped by the values of the tags by, one series of the gauge being exported per
+// group. The values of the series are tracked locally like with WithValueReadback, an invalid expr fails NewMeter
+// with an error wrapping config.ErrInvalidDerivedMetric.
+func WithDerivedMetric(name, desc, expr string, by ...string) interfaces.Option {
+	return &derivedMetricOption{
+		metric: config.DerivedMetric{Name: name, Desc: desc, Expr: expr, By: by},
+	}
+}
+
+// readinessGateOption enables the readiness gate of the health endpoint.
+type readinessGateOption struct{}
+
+// ApplyConfig sets the ReadinessGate field of the provided config.Config.
+func (readinessGateOption) ApplyConfig(cfg *config.Config) {
+	cfg.ReadinessGate = true
+}
+
+// WithReadinessGate returns an Option making the health endpoint report DOWN until the metrics are scraped or pushed
+// successfully for the first time, so that orchestrators hold the traffic of a service whose telemetry is misconfigured.
+func WithReadinessGate() interfaces.Option {
+	return readinessGateOption{}
+}
+
+// managementPortOption holds the port of the management endpoints.
+type managementPortOption struct {
+	port int
+}
+
+// ApplyConfig sets the ManagementPort field of the provided config.Config.
+func (m *managementPortOption) ApplyConfig(cfg *config.Config) {
+	cfg.ManagementPort = m.port
+}
+
+// WithManagementPort returns an Option serving the health check, configuration and pprof endpoints on port, the
+// Prometheus port only serving /metrics, so that the scrape and the operations endpoints can be exposed to different
+// security zones. The endpoints are all served on the Prometheus port when port is zero or the Prometheus port.
+func WithManagementPort(port int) interfaces.Option {
+	return &managementPortOption{
+		port: port,
+	}
+}
+
+// scrapeAllowlistOption holds the networks allowed to scrape the embedded server.
+type scrapeAllowlistOption struct {
+	cidrs []string
+}
+
+// ApplyConfig sets the ScrapeAllowlist field of the provided config.Config.
+func (s *scrapeAllowlistOption) ApplyConfig(cfg *config.Config) {
+	cfg.ScrapeAllowlist = s.cidrs
+}
+
+// WithScrapeAllowlist returns an Option restricting /metrics, /actuator/metrics-config and pprof on the embedded
+// server to the clients in the given networks, CIDRs such as 10.0.0.0/8 or single IP addresses, e.g. the subnets of
+// the Prometheus scrapers. The health check stays open to the probes of the orchestrator. Invalid entries make
+// NewMeter fail with config.ErrInvalidAllowlist.
+func WithScrapeAllowlist(cidrs ...string) interfaces.Option {
+	return &scrapeAllowlistOption{
+		cidrs: cidrs,
+	}
+}
+
+// httpServerOption tunes the embedded HTTP server.
+type httpServerOption struct {
+	apply func(cfg *config.HTTPServerCfg)
+}
+
+// ApplyConfig applies the tuning to the HTTPServer field of the provided config.Config.
+func (h *httpServerOption) ApplyConfig(cfg *config.Config) {
+	h.apply(&cfg.HTTPServer)
+}
+
+// WithH2C returns an Option making the embedded server accept HTTP/2 without TLS (h2c) next to HTTP/1.1, so that
+// high-frequency scrapers multiplex their scrapes on a single connection. It requires go1.24 or later.
+func WithH2C() interfaces.Option {
+	return &httpServerOption{apply: func(cfg *config.HTTPServerCfg) {
+		cfg.H2C = true
+	}}
+}
+
+// WithKeepAlive returns an Option tuning the keep-alive connections of the embedded server: idle bounds the time a
+// connection waits for the next scrape, the default of net/http if zero, and enabled false closes the connection
+// after every request.
+func WithKeepAlive(idle time.Duration, enabled bool) interfaces.Option {
+	return &httpServerOption{apply: func(cfg *config.HTTPServerCfg) {
+		cfg.IdleTimeout = idle
+		cfg.DisableKeepAlives = !enabled
+	}}
+}
+
+// WithReadHeaderTimeout returns an Option bounding the time the embedded server waits for the headers of a request.
+func WithReadHeaderTimeout(timeout time.Duration) interfaces.Option {
+	return &httpServerOption{apply: func(cfg *config.HTTPServerCfg) {
+		cfg.ReadHeaderTimeout = timeout
+	}}
+}
+
+// WithMaxConcurrentScrapes returns an Option bounding the scrapes of /metrics served at the same time, the others
+// waiting for a slot until they are canceled, so that many scrapers cannot pile up collections.
+func WithMaxConcurrentScrapes(n int) interfaces.Option {
+	return &httpServerOption{apply: func(cfg *config.HTTPServerCfg) {
+		cfg.MaxConcurrentScrapes = n
+	}}
+}
+
+// WithPortFallback returns an Option making the embedded server retry on the ports from `from` to `to` when the
+// Prometheus port is taken, e.g. by another replica on the same host, then on an ephemeral port chosen by the system
+// if ephemeral is true. A zero range only falls back to the ephemeral port. The port bound is returned by ListenAddr.
+func WithPortFallback(from, to int, ephemeral bool) interfaces.Option {
+	return &httpServerOption{apply: func(cfg *config.HTTPServerCfg) {
+		cfg.PortFallbackFrom = from
+		cfg.PortFallbackTo = to
+		cfg.EphemeralPortFallback = ephemeral
+	}}
+}
+
+// accessLogOption enables the access log of the embedded server.
+type accessLogOption struct {
+	hook func(entry config.AccessLogEntry)
+}
+
+// ApplyConfig sets the AccessLog and AccessLogHook fields of the provided config.Config.
+func (a *accessLogOption) ApplyConfig(cfg *config.Config) {
+	cfg.AccessLog = true
+	cfg.AccessLogHook = a.hook
+}
+
+// WithAccessLog returns an Option logging every request served by the embedded server, such as the scrapes, with its
+// source address, status, response size and duration through the info logger, and counting them in the
+// go_metric_http_requests, go_metric_http_request_duration and go_metric_http_response_size self-metrics.
+// hook, which may be nil, is called with every request as well.
+func WithAccessLog(hook func(entry config.AccessLogEntry)) interfaces.Option {
+	return &accessLogOption{
+		hook: hook,
+	}
+}
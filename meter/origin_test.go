@@ -0,0 +1,32 @@
+package meter
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentOrigins(t *testing.T) {
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithCreationAudit())
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	_, file, line, _ := runtime.Caller(0)
+	m.NewCounter("checkout_orders", "", "").IncrOne(context.Background())
+	m.NewCounter("checkout_orders", "", "").IncrOne(context.Background())
+
+	origins, ok := InstrumentOrigins(m)
+	require.True(t, ok)
+	require.Len(t, origins, 1, "the self-metrics have no origin")
+	assert.Equal(t, "checkout_orders", origins[0].Metric)
+	assert.Equal(t, file, origins[0].File)
+	assert.Equal(t, line+1, origins[0].Line, "the first creation is recorded")
+	assert.Contains(t, origins[0].Function, "TestInstrumentOrigins")
+
+	_, ok = InstrumentOrigins(MustNewMeter(WithProviderType(config.MeterProviderTypePrometheus)))
+	assert.False(t, ok)
+}
@@ -0,0 +1,25 @@
+package meter
+
+import (
+	"github.com/liangweijiang/go-metric/internal/meter/core"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// otelScopeName is the instrumentation scope of the instruments created on a wrapped provider.
+const otelScopeName = "github.com/liangweijiang/go-metric"
+
+// WrapOTelProvider returns a meter creating its instruments on provider, for applications configuring OpenTelemetry
+// elsewhere, e.g. with otel.GetMeterProvider(), to use the Counter and Histogram API of this package. The measurements
+// are exported by the readers of provider: the meter serves no endpoint, its GetHandler answers 404 Not Found, and
+// Flush forces provider to flush when it supports it. The options configure the tag providers, the histogram
+// boundaries, the clock and the logging; the base tags, provider, port and push gateway options are ignored, the
+// resource and the exporters being those of provider.
+func WrapOTelProvider(provider api.MeterProvider, options ...interfaces.Option) interfaces.Meter {
+	cfg := config.GetConfig()
+	for _, option := range options {
+		option.ApplyConfig(cfg)
+	}
+	return core.NewMeter(cfg, "otel", provider, provider.Meter(otelScopeName), core.NewRegistry(cfg, nil))
+}
@@ -0,0 +1,27 @@
+package meter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestWrapOTelProvider(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	m := WrapOTelProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	m.NewCounter("orders", "", "").AddTag("status", "paid").IncrOne(context.Background())
+	require.NoError(t, m.Flush(context.Background()))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	assert.Equal(t, otelScopeName, rm.ScopeMetrics[0].Scope.Name)
+	sum := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[float64])
+	assert.Equal(t, 1.0, sum.DataPoints[0].Value)
+	assert.Equal(t, attribute.NewSet(attribute.String("status", "paid")), sum.DataPoints[0].Attributes)
+}
@@ -0,0 +1,124 @@
+package meter
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// collector records the metric names and the authorization header of the exports it receives.
+type collector struct {
+	collectormetrics.UnimplementedMetricsServiceServer
+	mu            sync.Mutex
+	names         []string
+	authorization []string
+}
+
+func (c *collector) Export(ctx context.Context, req *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	md, _ := metadata.FromIncomingContext(ctx)
+	c.authorization = md.Get("authorization")
+	for _, rm := range req.GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				c.names = append(c.names, m.GetName())
+			}
+		}
+	}
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
+
+// serveCollector starts a collector on a local port and returns it with its address.
+func serveCollector(t *testing.T) (*collector, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	c := &collector{}
+	server := grpc.NewServer()
+	collectormetrics.RegisterMetricsServiceServer(server, c)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return c, listener.Addr().String()
+}
+
+// received returns the names of the metrics exported so far.
+func (c *collector) received() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.names...)
+}
+
+func TestOTLPMeter(t *testing.T) {
+	c, address := serveCollector(t)
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypeOTLPGrpc), WithOTLPEndpoint(address, 0),
+		WithOTLPInsecure(), WithOTLPHeaders(map[string]string{"authorization": "Bearer t0k3n"}))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	m.NewCounter("orders_total", "", "").IncrOne(context.Background())
+	require.NoError(t, m.Flush(context.Background()))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	assert.Contains(t, c.names, "orders_total")
+	assert.Equal(t, []string{"Bearer t0k3n"}, c.authorization)
+
+	_, err = NewMeter(WithProviderType(config.MeterProviderTypeOTLPGrpc), WithOTLPInsecure(), WithOTLPTLS(&tls.Config{}))
+	assert.ErrorIs(t, err, config.ErrInvalidOTLP)
+}
+
+func TestOTLPMeterDeferredStart(t *testing.T) {
+	c, address := serveCollector(t)
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypeOTLPGrpc),
+		WithOTLPEndpoint(address, 50*time.Millisecond), WithOTLPInsecure(), WithDeferredStart())
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	m.NewCounter("orders_total", "", "").IncrOne(context.Background())
+	time.Sleep(200 * time.Millisecond)
+	assert.Empty(t, c.received(), "nothing is pushed before the start")
+
+	m.WithRunning(true)
+	assert.Eventually(t, func() bool {
+		return slices.Contains(c.received(), "orders_total")
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestOTLPMeterLifecycle(t *testing.T) {
+	c, address := serveCollector(t)
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypeOTLPGrpc),
+		WithOTLPEndpoint(address, 50*time.Millisecond), WithOTLPInsecure())
+	require.NoError(t, err)
+	m.NewCounter("orders_total", "", "").IncrOne(context.Background())
+	assert.Eventually(t, func() bool {
+		return slices.Contains(c.received(), "orders_total")
+	}, 2*time.Second, 20*time.Millisecond)
+
+	m.WithRunning(false)
+	pushed := len(c.received())
+	time.Sleep(200 * time.Millisecond)
+	assert.Len(t, c.received(), pushed, "nothing is pushed once switched off")
+
+	m.WithRunning(true)
+	assert.Eventually(t, func() bool {
+		return len(c.received()) > pushed
+	}, 2*time.Second, 20*time.Millisecond, "the pushes resume once switched on")
+
+	require.NoError(t, Shutdown(context.Background(), m))
+	pushed = len(c.received())
+	m.WithRunning(true)
+	time.Sleep(200 * time.Millisecond)
+	assert.Len(t, c.received(), pushed, "nothing is pushed once shut down")
+	assert.NoError(t, Shutdown(context.Background(), m), "shutting down twice does nothing")
+}
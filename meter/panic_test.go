@@ -0,0 +1,42 @@
+package meter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	var provided int
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithPrometheusPort(0), WithPanicLimit(2),
+		WithTagProvider(config.TagProviderFunc(func(ctx context.Context) []attribute.KeyValue {
+			provided++
+			panic("no tenant")
+		})))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	var calls int
+	m.NewObservableGauge("broken_queue", "", "", func(ctx context.Context, o interfaces.Observer) error {
+		calls++
+		panic("nil queue")
+	})
+	for i := 0; i < 3; i++ {
+		m.NewCounter("orders_total", "", "").IncrOne(context.Background())
+	}
+
+	metertest.Scrape(t, m.GetHandler())
+	metertest.Scrape(t, m.GetHandler())
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`orders_total 3`,
+		`go_metric_panics_total{kind="callback",name="broken_queue"} 2`,
+		`go_metric_panics_total{kind="tag_provider",name="config.TagProviderFunc[0]"} 2`)
+	assert.Equal(t, 2, calls, "the callback is disabled after 2 panics")
+	assert.Equal(t, 2, provided, "the tag provider is disabled after 2 panics")
+}
@@ -0,0 +1,39 @@
+package meter
+
+import (
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"sync"
+)
+
+// ProviderFactory builds the meter of a provider registered with RegisterProvider from the configuration of NewMeter.
+type ProviderFactory func(cfg *config.Config) (interfaces.Meter, error)
+
+// providers holds the factories of the registered providers by their config.MeterProviderType.
+var providers sync.Map
+
+// RegisterProvider registers the factory of a provider shipped out of the SDK under typeName, typically from the init
+// function of its package, and returns the type selecting it with WithProviderType. NewMeter calls the factory with
+// the configuration, validated and with its local IP resolved. It returns an error wrapping
+// config.ErrDuplicateProvider if typeName is empty or taken, and config.ErrNilProviderFactory if factory is nil.
+func RegisterProvider(typeName string, factory ProviderFactory) (config.MeterProviderType, error) {
+	if factory == nil {
+		return 0, fmt.Errorf("%w: %q", config.ErrNilProviderFactory, typeName)
+	}
+	t, err := config.RegisterProviderType(typeName)
+	if err != nil {
+		return 0, err
+	}
+	providers.Store(t, factory)
+	return t, nil
+}
+
+// registeredProvider returns the factory of a provider registered with RegisterProvider.
+func registeredProvider(t config.MeterProviderType) (ProviderFactory, bool) {
+	factory, ok := providers.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return factory.(ProviderFactory), true
+}
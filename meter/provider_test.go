@@ -0,0 +1,65 @@
+package meter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/internal/meter/nop"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterProvider(t *testing.T) {
+	var built *config.Config
+	factory := func(cfg *config.Config) (interfaces.Meter, error) {
+		built = cfg
+		return nop.NewNopMeter(), nil
+	}
+	providerType, err := RegisterProvider("in-memory", factory)
+	require.NoError(t, err)
+	assert.Equal(t, "in-memory", providerType.String())
+
+	for _, tt := range []struct {
+		name     string
+		typeName string
+		factory  ProviderFactory
+		wantErr  error
+	}{
+		{name: "taken", typeName: "in-memory", factory: factory, wantErr: config.ErrDuplicateProvider},
+		{name: "builtin", typeName: "prometheus", factory: factory, wantErr: config.ErrDuplicateProvider},
+		{name: "empty", typeName: "", factory: factory, wantErr: config.ErrDuplicateProvider},
+		{name: "nil factory", typeName: "on-disk", wantErr: config.ErrNilProviderFactory},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RegisterProvider(tt.typeName, tt.factory)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+	_, err = config.ParseProviderType("on-disk")
+	assert.ErrorIs(t, err, config.ErrUnsupportedProvider, "a refused factory registers no type")
+
+	m, err := NewMeter(WithProviderType(providerType), WithLocalIP("10.0.0.7"))
+	require.NoError(t, err)
+	assert.NotNil(t, m)
+	require.NotNil(t, built)
+	assert.Equal(t, "10.0.0.7", built.LocalIP)
+}
+
+func TestWithProvider(t *testing.T) {
+	m, err := NewMeter(WithProvider("validate"))
+	require.NoError(t, err)
+	_, ok := Violations(m)
+	assert.True(t, ok)
+
+	_, err = NewMeter(WithProvider("carrier-pigeon"))
+	assert.ErrorIs(t, err, config.ErrUnsupportedProvider)
+
+	var file struct {
+		Provider config.MeterProviderType `json:"provider"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"provider": "prometheus"}`), &file))
+	assert.Equal(t, config.MeterProviderTypePrometheus, file.Provider)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"provider": "carrier-pigeon"}`), &file), config.ErrUnsupportedProvider)
+}
@@ -0,0 +1,81 @@
+package meter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/internal/meter/nop"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuarantinedMetrics(t *testing.T) {
+	var mu sync.Mutex
+	var accepted [][]byte
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if bytes.Contains(body, []byte("refunds_total")) {
+			http.Error(w, `pushed metrics are invalid or inconsistent with existing metrics: collected metric "refunds_total" was collected before with the same name and label values`, http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		accepted = append(accepted, body)
+		mu.Unlock()
+	}))
+	defer gateway.Close()
+
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithPrometheusPort(0),
+		WithPushGateway(gateway.URL, time.Hour), WithLocalIP("10.0.0.7"), WithDeferredStart())
+	require.NoError(t, err)
+	ctx := context.Background()
+	m.NewCounter("orders", "", "").IncrOne(ctx)
+	m.NewCounter("refunds", "", "").IncrOne(ctx)
+
+	require.NoError(t, m.Flush(ctx), "the rest of the metrics is pushed")
+	names, ok := QuarantinedMetrics(m)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"refunds_total"}, names)
+	mu.Lock()
+	require.Len(t, accepted, 1)
+	assert.Contains(t, string(accepted[0]), "orders_total")
+	mu.Unlock()
+
+	_, ok = QuarantinedMetrics(nop.NewNopMeter())
+	assert.False(t, ok)
+}
+
+func TestQuarantineTransientFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "ServerError", status: http.StatusServiceUnavailable},
+		{name: "TooManyRequests", status: http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				http.Error(w, `collected metric "refunds_total" could not be stored`, tt.status)
+			}))
+			defer gateway.Close()
+
+			m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithPrometheusPort(0),
+				WithPushGateway(gateway.URL, time.Hour), WithLocalIP("10.0.0.7"), WithDeferredStart())
+			require.NoError(t, err)
+			ctx := context.Background()
+			m.NewCounter("refunds", "", "").IncrOne(ctx)
+
+			assert.Error(t, m.Flush(ctx))
+			names, ok := QuarantinedMetrics(m)
+			assert.True(t, ok)
+			assert.Empty(t, names, "only the rejected metrics are quarantined")
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package meter
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"sync"
+	"time"
+)
+
+// ratioBucket counts the outcomes recorded during one slot of the window of a RollingRatio.
+type ratioBucket struct {
+	slot     int64
+	success  uint64
+	failures uint64
+}
+
+// RollingRatio counts the successes and failures of an operation in a ring of time buckets covering a rolling window,
+// and exports the ratio of failures over the window as a gauge. Its Ratio feeds in-process decisions, such as
+// opening a circuit breaker, with the number shown on the dashboards.
+type RollingRatio struct {
+	clock        clock.Clock
+	width        time.Duration
+	mu           sync.Mutex
+	buckets      []ratioBucket
+	registration interfaces.Registration
+}
+
+// NewRollingRatio creates a RollingRatio over window split in buckets time buckets, 10 if buckets is not positive,
+// exporting the ratio of failures as the gauge name of m tagged with tags. Call Close to stop exporting it.
+// Outcomes older than the window are forgotten bucket by bucket, the window sliding with the clock of m.
+func NewRollingRatio(m interfaces.Meter, name, desc string, window time.Duration, buckets int, tags map[string]string) *RollingRatio {
+	if buckets <= 0 {
+		buckets = 10
+	}
+	width := window / time.Duration(buckets)
+	if width <= 0 {
+		width = 1
+	}
+	r := &RollingRatio{
+		clock:   clock.From(m),
+		width:   width,
+		buckets: make([]ratioBucket, buckets),
+	}
+	r.registration = m.NewObservableGauge(name, desc, "", func(_ context.Context, o interfaces.Observer) error {
+		if ratio, total := r.Ratio(); total > 0 {
+			o.Observe(ratio, tags)
+		}
+		return nil
+	})
+	return r
+}
+
+// Record counts the outcome of an operation.
+func (r *RollingRatio) Record(success bool) {
+	slot := r.slot()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := &r.buckets[slot%int64(len(r.buckets))]
+	if b.slot != slot {
+		*b = ratioBucket{slot: slot}
+	}
+	if success {
+		b.success++
+	} else {
+		b.failures++
+	}
+}
+
+// RecordSuccess counts a successful operation.
+func (r *RollingRatio) RecordSuccess() {
+	r.Record(true)
+}
+
+// RecordFailure counts a failed operation.
+func (r *RollingRatio) RecordFailure() {
+	r.Record(false)
+}
+
+// RecordErr counts an operation failed if err is not nil, and returns err.
+func (r *RollingRatio) RecordErr(err error) error {
+	r.Record(err == nil)
+	return err
+}
+
+// Counts returns the successes and failures recorded during the window.
+func (r *RollingRatio) Counts() (success, failures uint64) {
+	slot := r.slot()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, b := range r.buckets {
+		if slot-b.slot < int64(len(r.buckets)) {
+			success += b.success
+			failures += b.failures
+		}
+	}
+	return success, failures
+}
+
+// Ratio returns the ratio of failures over the operations recorded during the window, and their number.
+// The ratio is 0 when no operation was recorded, decisions should require a minimal total.
+func (r *RollingRatio) Ratio() (ratio float64, total uint64) {
+	success, failures := r.Counts()
+	total = success + failures
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(failures) / float64(total), total
+}
+
+// Close stops exporting the gauge, the ratio can still be read.
+func (r *RollingRatio) Close() error {
+	return r.registration.Unregister()
+}
+
+// slot returns the index of the bucket of the current time since the epoch.
+func (r *RollingRatio) slot() int64 {
+	return r.clock.Now().UnixNano() / int64(r.width)
+}
@@ -0,0 +1,45 @@
+package meter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollingRatio(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithClock(fake))
+	require.NoError(t, err)
+
+	r := NewRollingRatio(m, "payment_error_ratio", "", time.Minute, 6, map[string]string{"dependency": "psp"})
+	ratio, total := r.Ratio()
+	assert.Zero(t, ratio)
+	assert.Zero(t, total)
+
+	r.RecordSuccess()
+	r.RecordSuccess()
+	r.RecordFailure()
+	fake.Advance(30 * time.Second)
+	_ = r.RecordErr(errors.New("declined"))
+	ratio, total = r.Ratio()
+	assert.Equal(t, 0.5, ratio)
+	assert.Equal(t, uint64(4), total)
+	metertest.ScrapeAndAssert(t, m.GetHandler(), `payment_error_ratio{dependency="psp"} 0.5`)
+
+	// the outcomes of the first bucket leave the window.
+	fake.Advance(40 * time.Second)
+	ratio, total = r.Ratio()
+	assert.Equal(t, 1.0, ratio)
+	assert.Equal(t, uint64(1), total)
+
+	fake.Advance(time.Minute)
+	_, total = r.Ratio()
+	assert.Zero(t, total)
+	require.NoError(t, r.Close())
+}
@@ -0,0 +1,44 @@
+package meter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueReadback(t *testing.T) {
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithValueReadback())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	m.NewCounter("requests", "", "").AddTag("route", "/a").Incr(ctx, 2)
+	m.NewCounter("requests", "", "").AddTag("route", "/a").Incr(ctx, 3)
+	m.NewCounter("requests", "", "").AddTag("route", "/b").IncrOne(ctx)
+	v, ok := m.NewCounter("requests", "", "").AddTag("route", "/a").(interfaces.Readable).Value(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, v)
+
+	m.NewUpDownCounter("inflight", "", "").IncrOne(ctx)
+	m.NewUpDownCounter("inflight", "", "").DecrOne(ctx)
+	m.NewUpDownCounter("inflight", "", "").IncrOne(ctx)
+	v, _ = m.NewUpDownCounter("inflight", "", "").(interfaces.Readable).Value(ctx)
+	assert.Equal(t, 1.0, v)
+
+	m.NewGauge("queue_depth", "", "").Update(ctx, 7)
+	m.NewGauge("queue_depth", "", "").Update(ctx, 4)
+	v, _ = m.NewGauge("queue_depth", "", "").(interfaces.Readable).Value(ctx)
+	assert.Equal(t, 4.0, v)
+
+	_, ok = m.NewGauge("queue_depth", "", "").AddTag("queue", "other").(interfaces.Readable).Value(ctx)
+	assert.False(t, ok)
+
+	plain, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	plain.NewGauge("queue_depth", "", "").Update(ctx, 4)
+	_, ok = plain.NewGauge("queue_depth", "", "").(interfaces.Readable).Value(ctx)
+	assert.False(t, ok)
+}
@@ -0,0 +1,49 @@
+package meter
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenames(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithPrometheusPort(0), WithClock(fake),
+		WithRenames(config.Renames{"http_requests": "http_server_requests", "rpc_seconds": "rpc_duration_seconds"}, time.Unix(0, 0).Add(time.Hour)))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		m.GetHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+		return recorder.Body.String()
+	}
+
+	m.NewCounter("http_requests", "", "").AddTag("method", "GET").IncrOne(context.Background())
+	m.NewCounter("http_server_requests", "", "").AddTag("method", "GET").IncrOne(context.Background())
+	body := scrape()
+	assert.Contains(t, body, `http_requests_total{method="GET"} 2`, "both names record both instruments")
+	assert.Contains(t, body, `http_server_requests_total{method="GET"} 2`)
+
+	m.NewHistogram("rpc_seconds", "", "s").Time(func() {
+		fake.Advance(time.Second)
+	})
+	body = scrape()
+	assert.Contains(t, body, "\nrpc_seconds_sum 1\n")
+	assert.Contains(t, body, "\nrpc_duration_seconds_sum 1\n")
+
+	fake.Advance(time.Hour)
+	m.NewGauge("http_requests", "", "").Update(context.Background(), 1)
+	body = scrape()
+	assert.Contains(t, body, "\nhttp_server_requests 1\n")
+	assert.NotContains(t, body, "\nhttp_requests 1\n", "the old name is no longer recorded after the transition")
+
+	_, err = NewMeter(WithRenames(config.Renames{"a": "b", "b": "c"}, time.Time{}))
+	assert.ErrorIs(t, err, config.ErrInvalidRename)
+}
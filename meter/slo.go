@@ -0,0 +1,141 @@
+package meter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Conventional names of the SLO instruments, the Prometheus exporter appends the _total suffix to the counters.
+// All of them are tagged with SLOTagName, the objective gauge is also tagged with SLOTagWindow, the window of the SLO
+// in its largest whole unit among days, hours, minutes and seconds, e.g. "30d", "1h", "90m" or "45s".
+const (
+	SLOGoodEventsMetric  = "slo_good_events"
+	SLOTotalEventsMetric = "slo_total_events"
+	SLOObjectiveMetric   = "slo_objective_ratio"
+	SLOTagName           = "slo"
+	SLOTagWindow         = "slo_window"
+)
+
+// ErrInvalidObjective is returned by NewSLO for an objective outside (0, 1).
+var ErrInvalidObjective = errors.New("slo objective must be within (0, 1)")
+
+// BurnRateWindow is a pair of windows of a multi-window burn-rate alert, firing when the burn rate over both the long
+// and the short window exceeds Factor.
+type BurnRateWindow struct {
+	Long   time.Duration
+	Short  time.Duration
+	Factor float64
+}
+
+// DefaultBurnRateWindows are the multi-window burn-rate alerts advised by the Google SRE workbook for a 30 days SLO:
+// 2% of the error budget burnt in 1h, 5% in 6h, 10% in 3d.
+var DefaultBurnRateWindows = []BurnRateWindow{
+	{Long: time.Hour, Short: 5 * time.Minute, Factor: 14.4},
+	{Long: 6 * time.Hour, Short: 30 * time.Minute, Factor: 6},
+	{Long: 3 * 24 * time.Hour, Short: 6 * time.Hour, Factor: 1},
+}
+
+// SLO maintains the good and total event counters of a service level objective under conventional names,
+// standardizing the inputs of multi-window burn-rate alerting. Measurements go to the global meter.
+type SLO struct {
+	name      string
+	objective float64
+	window    time.Duration
+}
+
+// NewSLO creates an SLO named name (e.g. "checkout_availability") aiming at objective (e.g. 0.999) over window (e.g. 30 days).
+// The name is used as the value of the SLOTagName tag, an objective outside (0, 1) returns ErrInvalidObjective.
+func NewSLO(name string, objective float64, window time.Duration) (*SLO, error) {
+	if !(objective > 0 && objective < 1) {
+		return nil, fmt.Errorf("%w: %s objective %v", ErrInvalidObjective, name, objective)
+	}
+	return &SLO{
+		name:      name,
+		objective: objective,
+		window:    window,
+	}, nil
+}
+
+// Record counts one event, good or not, and refreshes the objective gauge.
+func (s *SLO) Record(ctx context.Context, good bool) {
+	m := GetGlobalMeter()
+	if good {
+		m.NewCounter(SLOGoodEventsMetric, "good events of the service level objective", "").
+			WithTags(s.Tags()).IncrOne(ctx)
+	}
+	m.NewCounter(SLOTotalEventsMetric, "total events of the service level objective", "").
+		WithTags(s.Tags()).IncrOne(ctx)
+	m.NewGauge(SLOObjectiveMetric, "objective of the service level objective", "").
+		WithTags(s.Tags()).AddTag(SLOTagWindow, windowTag(s.window)).Update(ctx, s.objective)
+}
+
+// RecordGood counts one good event.
+func (s *SLO) RecordGood(ctx context.Context) {
+	s.Record(ctx, true)
+}
+
+// RecordBad counts one bad event.
+func (s *SLO) RecordBad(ctx context.Context) {
+	s.Record(ctx, false)
+}
+
+// Tags returns the tags identifying the SLO on its instruments.
+func (s *SLO) Tags() map[string]string {
+	return map[string]string{SLOTagName: s.name}
+}
+
+// ErrorBudget returns the ratio of bad events allowed by the objective, e.g. 0.001 for an objective of 0.999.
+func (s *SLO) ErrorBudget() float64 {
+	return 1 - s.objective
+}
+
+// ErrorRatioQuery returns the PromQL expression of the ratio of bad events over the given window.
+func (s *SLO) ErrorRatioQuery(window time.Duration) string {
+	selector := fmt.Sprintf(`{%s=%q}`, SLOTagName, s.name)
+	return fmt.Sprintf("1 - (sum(rate(%s_total%s[%s])) / sum(rate(%s_total%s[%s])))",
+		SLOGoodEventsMetric, selector, promDuration(window), SLOTotalEventsMetric, selector, promDuration(window))
+}
+
+// BurnRateQuery returns the PromQL expression of the burn rate over the given window,
+// 1 meaning the error budget is consumed exactly at the end of the SLO window.
+func (s *SLO) BurnRateQuery(window time.Duration) string {
+	return fmt.Sprintf("(%s) / %s", s.ErrorRatioQuery(window), strconv.FormatFloat(s.ErrorBudget(), 'g', -1, 64))
+}
+
+// AlertQueries returns one PromQL alert expression per burn-rate window, DefaultBurnRateWindows when none is given.
+func (s *SLO) AlertQueries(windows ...BurnRateWindow) []string {
+	if len(windows) == 0 {
+		windows = DefaultBurnRateWindows
+	}
+	queries := make([]string, 0, len(windows))
+	for _, w := range windows {
+		factor := strconv.FormatFloat(w.Factor, 'g', -1, 64)
+		queries = append(queries, fmt.Sprintf("(%s) > %s and (%s) > %s",
+			s.BurnRateQuery(w.Long), factor, s.BurnRateQuery(w.Short), factor))
+	}
+	return queries
+}
+
+// windowTag formats the window of an SLO as the value of SLOTagWindow, e.g. "30d" rather than "720h0m0s".
+func windowTag(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d > 0 && d%day == 0 {
+		return strconv.FormatInt(int64(d/day), 10) + "d"
+	}
+	return promDuration(d)
+}
+
+// promDuration formats d as a PromQL duration, e.g. "1h", "30m" or "90s".
+func promDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	default:
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	}
+}
@@ -0,0 +1,78 @@
+package meter
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSLO(t *testing.T) {
+	tests := []struct {
+		name      string
+		objective float64
+		wantErr   bool
+	}{
+		{name: "Valid", objective: 0.999},
+		{name: "Zero", objective: 0, wantErr: true},
+		{name: "Negative", objective: -0.5, wantErr: true},
+		{name: "One", objective: 1, wantErr: true},
+		{name: "AboveOne", objective: 99.9, wantErr: true},
+		{name: "NaN", objective: math.NaN(), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slo, err := NewSLO("checkout_availability", tt.objective, 30*24*time.Hour)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidObjective)
+				assert.Nil(t, slo)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, 0.001, slo.ErrorBudget(), 1e-9)
+		})
+	}
+}
+
+func TestSLORecord(t *testing.T) {
+	m := globalMeter(t)
+
+	slo, err := NewSLO("checkout_availability", 0.999, 30*24*time.Hour)
+	require.NoError(t, err)
+	ctx := context.Background()
+	slo.RecordGood(ctx)
+	slo.RecordBad(ctx)
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`slo_good_events_total{slo="checkout_availability"} 1`,
+		`slo_total_events_total{slo="checkout_availability"} 2`,
+		`slo_objective_ratio{slo="checkout_availability",slo_window="30d"} 0.999`,
+	)
+
+	slo, err = NewSLO("search_latency", 0.75, 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, `(1 - (sum(rate(slo_good_events_total{slo="search_latency"}[1h])) / `+
+		`sum(rate(slo_total_events_total{slo="search_latency"}[1h])))) / 0.25`, slo.BurnRateQuery(time.Hour))
+}
+
+func TestSLOWindowTag(t *testing.T) {
+	tests := []struct {
+		window time.Duration
+		want   string
+	}{
+		{window: 30 * 24 * time.Hour, want: "30d"},
+		{window: 36 * time.Hour, want: "36h"},
+		{window: time.Hour, want: "1h"},
+		{window: 90 * time.Minute, want: "90m"},
+		{window: 45 * time.Second, want: "45s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, windowTag(tt.window))
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package meter
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readPackets returns the packets received by conn until none arrives for 200 milliseconds.
+func readPackets(t *testing.T, conn net.PacketConn) []string {
+	var packets []string
+	buf := make([]byte, 65536)
+	for {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return packets
+		}
+		packets = append(packets, string(buf[:n]))
+	}
+}
+
+func TestStatsDMeter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypeStatsD), WithStatsD(conn.LocalAddr().String(), time.Hour),
+		WithStatsDPrefix("checkout"))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	ctx := context.Background()
+	m.NewCounter("orders_total", "", "").AddTag("method", "GET").AddTag("code", "200").Incr(ctx, 2)
+	m.NewUpDownCounter("inflight", "", "").DecrOne(ctx)
+	m.NewGauge("temperature", "", "").Update(ctx, -3.5)
+	m.NewHistogram("latency", "", "s").UpdateInMilliseconds(ctx, 12.5)
+	m.NewSizeHistogram("payload", "").Record(ctx, 512)
+	m.NewObservableGauge("queue_depth", "", "", func(_ context.Context, o interfaces.Observer) error {
+		o.Observe(7, map[string]string{"queue": "emails.high"})
+		return nil
+	})
+	require.NoError(t, m.Flush(ctx))
+
+	assert.Equal(t, []string{strings.Join([]string{
+		"checkout.orders_total.code.200.method.GET:2|c",
+		"checkout.inflight:-1|g",
+		"checkout.temperature:0|g",
+		"checkout.temperature:-3.5|g",
+		"checkout.latency:12.5|ms",
+		"checkout.payload:512|h",
+		"checkout.queue_depth.queue.emails_high:7|g",
+	}, "\n")}, readPackets(t, conn))
+
+	_, err = NewMeter(WithProviderType(config.MeterProviderTypeStatsD), WithStatsDPacketSize(70000))
+	assert.ErrorIs(t, err, config.ErrInvalidStatsD)
+}
+
+func TestStatsDMeterPacketSize(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypeStatsD), WithStatsD(conn.LocalAddr().String(), time.Hour),
+		WithStatsDPacketSize(64))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	for i := 0; i < 10; i++ {
+		m.NewCounter("requests_total", "", "").IncrOne(context.Background())
+	}
+	require.NoError(t, m.Flush(context.Background()))
+
+	packets := readPackets(t, conn)
+	assert.Len(t, packets, 4)
+	for _, packet := range packets {
+		assert.LessOrEqual(t, len(packet), 64)
+	}
+	assert.Equal(t, 10, strings.Count(strings.Join(packets, "\n"), "requests_total:1|c"))
+}
+
+func TestDogStatsDMeter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypeDogStatsD), WithStatsD(conn.LocalAddr().String(), time.Hour),
+		WithBaseTags(map[string]string{"env": "prod"}), WithDogStatsDDistributions())
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	ctx := context.Background()
+	m.NewCounter("orders_total", "", "").AddTag("method", "GET").Incr(ctx, 2)
+	m.NewUpDownCounter("inflight", "", "").DecrOne(ctx)
+	m.NewGauge("temperature", "", "").WithTags(map[string]string{"room": "a,b"}).Update(ctx, -3.5)
+	m.NewHistogram("latency", "", "s").Record(ctx, 0.25)
+	require.NoError(t, m.Flush(ctx))
+
+	assert.Equal(t, []string{strings.Join([]string{
+		"orders_total:2|c|#env:prod,method:GET",
+		"inflight:-1|c|#env:prod",
+		"temperature:-3.5|g|#env:prod,room:a_b",
+		"latency:0.25|d|#env:prod",
+	}, "\n")}, readPackets(t, conn))
+}
+
+func TestStatsDMeterCallbackPanic(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypeStatsD), WithStatsD(conn.LocalAddr().String(), time.Hour),
+		WithErrorLogWrite(func(string) {}))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	m.NewObservableGauge("broken", "", "", func(context.Context, interfaces.Observer) error {
+		panic("boom")
+	})
+	m.NewObservableGauge("queue_depth", "", "", func(_ context.Context, o interfaces.Observer) error {
+		o.Observe(7, nil)
+		return nil
+	})
+	err = m.Flush(context.Background())
+	assert.ErrorContains(t, err, "observable gauge broken: callback panicked: boom")
+	assert.Equal(t, []string{"queue_depth:7|g"}, readPackets(t, conn), "the other callbacks are observed")
+}
+
+func TestStatsDMeterRegistry(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypeStatsD), WithStatsD(conn.LocalAddr().String(), time.Hour),
+		WithRenames(config.Renames{"jobs": "jobs_done"}, time.Time{}),
+		WithFeatureGate(config.FeatureGateFunc(func(info config.InstrumentInfo) bool {
+			return info.Name != "experimental"
+		})))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	ctx := context.Background()
+	m.NewCounter("jobs", "", "").IncrOne(ctx)
+	m.NewCounter("experimental", "", "").IncrOne(ctx)
+	require.NoError(t, m.Flush(ctx))
+	assert.Equal(t, []string{"jobs_done:1|c\njobs:1|c"}, readPackets(t, conn), "renamed and gated like the other providers")
+}
+
+func TestStatsDMeterRestart(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypeStatsD),
+		WithStatsD(conn.LocalAddr().String(), 20*time.Millisecond))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	m.WithRunning(false)
+	m.WithRunning(true)
+	m.NewCounter("restarts_total", "", "").IncrOne(context.Background())
+	assert.Equal(t, []string{"restarts_total:1|c"}, readPackets(t, conn), "the flush loop runs after the restart")
+}
@@ -0,0 +1,217 @@
+package meter
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/internal/component"
+	"github.com/liangweijiang/go-metric/internal/meter/wrap"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Tag and metric of the tenant meters.
+const (
+	// TagTenant is the tag set by a TenantMeter on the measurements recorded to the shared meter.
+	TagTenant = config.DefaultScrapeTenantLabel
+	// TenantDroppedMetric counts per tenant the measurements dropped because the tenant exhausted its budget.
+	TenantDroppedMetric = "tenant_budget_dropped_measurements"
+	// DefaultTenantBudget is the default number of distinct series a tenant may record.
+	DefaultTenantBudget = 1000
+	// DefaultMaxTenants is the default number of tenants a TenantMeter keeps apart.
+	DefaultMaxTenants = 10000
+	// OverflowTenant is the tenant the tenants beyond the maximum number of tenants are recorded as.
+	OverflowTenant = "overflow"
+)
+
+// TenantOption configures a TenantMeter.
+type TenantOption func(t *TenantMeter)
+
+// WithTenantBudget sets the number of distinct series, i.e. metric names and tag sets, each tenant may record.
+// The measurements of the series beyond the budget are dropped and counted in TenantDroppedMetric.
+// A budget of zero or less disables the limit.
+func WithTenantBudget(series int) TenantOption {
+	return func(t *TenantMeter) {
+		t.budget = series
+	}
+}
+
+// WithMaxTenants bounds the number of tenants kept apart, DefaultMaxTenants by default, so that arbitrary tenant
+// names, e.g. taken from a request, cannot grow the TenantMeter without bound. The tenants used beyond the maximum
+// share the OverflowTenant meter and budget. A maximum of zero or less disables the limit.
+func WithMaxTenants(tenants int) TenantOption {
+	return func(t *TenantMeter) {
+		t.maxTenants = tenants
+	}
+}
+
+// WithTenantMeters isolates each tenant in its own meter created by newMeter on its first use, e.g. a Prometheus
+// meter with its own registry, served by TenantMeter.TenantHandler. The measurements recorded to an isolated meter
+// are not tagged with TagTenant. A tenant whose meter cannot be created falls back to the shared meter.
+func WithTenantMeters(newMeter func(tenant string) (interfaces.Meter, error)) TenantOption {
+	return func(t *TenantMeter) {
+		t.newMeter = newMeter
+	}
+}
+
+// TenantMeter partitions the instruments of a multi-tenant service per tenant. The meter of a tenant, returned by
+// For, tags every measurement with the tenant, overriding any TagTenant set by the caller, and drops the new series
+// once the tenant recorded its budget of distinct series, so that a noisy tenant cannot blow up the cardinality of
+// the others. A shared exporter can serve each tenant its own series with WithScrapeFilter. Switching off, disabling
+// or deprecating a metric through the meter of a tenant only applies to that tenant.
+// TenantMeter is safe for concurrent use.
+type TenantMeter struct {
+	shared     interfaces.Meter
+	budget     int
+	maxTenants int
+	newMeter   func(tenant string) (interfaces.Meter, error)
+	mu         sync.Mutex
+	tenants    map[string]*tenantMeter
+}
+
+// tenantMeter is the state of a tenant.
+type tenantMeter struct {
+	name         string
+	meter        interfaces.Meter
+	isolated     interfaces.Meter
+	mu           sync.Mutex
+	series       map[tenantSeries]struct{}
+	dropped      atomic.Uint64
+	registration interfaces.Registration
+}
+
+// tenantSeries identifies a series recorded by a tenant.
+type tenantSeries struct {
+	name string
+	tags attribute.Distinct
+}
+
+// NewTenantMeter creates a TenantMeter recording to shared, limiting each tenant to DefaultTenantBudget series
+// unless WithTenantBudget is given.
+func NewTenantMeter(shared interfaces.Meter, options ...TenantOption) *TenantMeter {
+	t := &TenantMeter{
+		shared:     shared,
+		budget:     DefaultTenantBudget,
+		maxTenants: DefaultMaxTenants,
+		tenants:    make(map[string]*tenantMeter),
+	}
+	for _, option := range options {
+		option(t)
+	}
+	return t
+}
+
+// For returns the meter of tenant, created on the first call. An empty tenant is recorded as unknown.
+// It must not be called from the callback of an observable gauge.
+func (t *TenantMeter) For(tenant string) interfaces.Meter {
+	return t.tenant(tenant).meter
+}
+
+// TenantHandler returns the handler of the isolated meter of tenant, and false if the tenant records to the shared
+// meter, whose handler exposes the series of every tenant.
+func (t *TenantMeter) TenantHandler(tenant string) (http.Handler, bool) {
+	tm := t.tenant(tenant)
+	if tm.isolated == nil {
+		return nil, false
+	}
+	return tm.isolated.GetHandler(), true
+}
+
+// Tenants returns the sorted names of the tenants used so far.
+func (t *TenantMeter) Tenants() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	names := make([]string, 0, len(t.tenants))
+	for name := range t.tenants {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Dropped returns the number of measurements of tenant dropped because of its budget.
+func (t *TenantMeter) Dropped(tenant string) uint64 {
+	return t.tenant(tenant).dropped.Load()
+}
+
+// tenant returns the state of tenant, creating it on the first call, or the state of the OverflowTenant once the
+// maximum number of tenants is reached.
+func (t *TenantMeter) tenant(tenant string) *tenantMeter {
+	if tenant == "" {
+		tenant = component.MissingTagValue
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tm, ok := t.tenants[tenant]; ok {
+		return tm
+	}
+	if t.maxTenants > 0 && len(t.tenants) >= t.maxTenants {
+		tenant = OverflowTenant
+		if tm, ok := t.tenants[tenant]; ok {
+			return tm
+		}
+	}
+	tm := &tenantMeter{
+		name:   tenant,
+		series: make(map[tenantSeries]struct{}),
+	}
+	inner := t.shared
+	if t.newMeter != nil {
+		isolated, err := t.newMeter(tenant)
+		if err == nil {
+			tm.isolated, inner = isolated, isolated
+		} else if c, ok := t.shared.(interface{ Config() *config.Config }); ok {
+			c.Config().WriteErrorOrNot("failed to create the meter of tenant " + tenant + ": " + err.Error())
+		}
+	}
+	hook := func(ctx context.Context, m *wrap.Measurement) bool {
+		return t.admit(tm, m)
+	}
+	if tm.isolated != nil {
+		tm.meter = wrap.New(inner, hook)
+	} else {
+		tm.meter = wrap.NewIsolated(inner, hook)
+	}
+	if t.budget > 0 {
+		tm.registration = inner.NewObservableGauge(TenantDroppedMetric, "measurements dropped because the tenant exhausted its series budget", config.UnitCount,
+			func(ctx context.Context, o interfaces.Observer) error {
+				o.Observe(float64(tm.dropped.Load()), map[string]string{TagTenant: tenant})
+				return nil
+			})
+	}
+	t.tenants[tenant] = tm
+	return tm
+}
+
+// admit tags a measurement of tm with the tenant when it records to the shared meter, and reports whether its series
+// fits in the budget of the tenant.
+func (t *TenantMeter) admit(tm *tenantMeter, m *wrap.Measurement) bool {
+	if tm.isolated == nil {
+		m.Tags = slices.DeleteFunc(m.Tags, func(kv attribute.KeyValue) bool {
+			return kv.Key == TagTenant
+		})
+		m.Tags = append(m.Tags, attribute.String(TagTenant, tm.name))
+	}
+	if t.budget <= 0 {
+		return true
+	}
+	set := attribute.NewSet(m.Tags...)
+	series := tenantSeries{
+		name: m.Name,
+		tags: set.Equivalent(),
+	}
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if _, ok := tm.series[series]; ok {
+		return true
+	}
+	if len(tm.series) >= t.budget {
+		tm.dropped.Add(1)
+		return false
+	}
+	tm.series[series] = struct{}{}
+	return true
+}
@@ -0,0 +1,110 @@
+package meter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantMeter(t *testing.T) {
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	tenants := NewTenantMeter(m, WithTenantBudget(2))
+
+	ctx := context.Background()
+	for _, route := range []string{"/a", "/b", "/c", "/a"} {
+		tenants.For("acme").NewCounter("tenant_requests", "requests", config.UnitCount).AddTag("route", route).IncrOne(ctx)
+	}
+	tenants.For("globex").NewCounter("tenant_requests", "requests", config.UnitCount).
+		AddTag("route", "/c").AddTag(TagTenant, "acme").IncrOne(ctx)
+
+	assert.Equal(t, []string{"acme", "globex"}, tenants.Tenants())
+	assert.Equal(t, uint64(1), tenants.Dropped("acme"))
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`tenant_requests_total{route="/a",tenant="acme"} 2`,
+		`tenant_requests_total{route="/b",tenant="acme"} 1`,
+		`tenant_requests_total{route="/c",tenant="globex"} 1`,
+		`tenant_budget_dropped_measurements{tenant="acme"} 1`,
+	)
+	assert.Len(t, metertest.Scrape(t, m.GetHandler())["tenant_requests_total"].GetMetric(), 3)
+	_, ok := tenants.TenantHandler("acme")
+	assert.False(t, ok)
+}
+
+func TestTenantMeterSwitches(t *testing.T) {
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	tenants := NewTenantMeter(m, WithMaxTenants(3))
+
+	ctx := context.Background()
+	tenants.For("acme").WithRunning(false)
+	tenants.For("globex").DisableMetric("tenant_switched")
+	tenants.For("initech").DeprecateMetric("tenant_switched", "")
+	for _, tenant := range []string{"acme", "globex", "initech", "umbrella", "hooli"} {
+		tenants.For(tenant).NewCounter("tenant_switched", "", "").IncrOne(ctx)
+	}
+
+	assert.Equal(t, []string{"acme", "globex", "initech", OverflowTenant}, tenants.Tenants())
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`tenant_switched_total{deprecated="true",tenant="initech"} 1`,
+		`tenant_switched_total{tenant="overflow"} 2`,
+	)
+	assert.Len(t, metertest.Scrape(t, m.GetHandler())["tenant_switched_total"].GetMetric(), 2,
+		"the switches of a tenant do not apply to the others")
+}
+
+func TestTenantMeterIsolated(t *testing.T) {
+	shared, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	tenants := NewTenantMeter(shared, WithTenantMeters(func(string) (interfaces.Meter, error) {
+		return NewMeter(WithProviderType(config.MeterProviderTypePrometheus))
+	}))
+
+	tenants.For("acme").NewCounter("tenant_jobs", "jobs", config.UnitCount).IncrOne(context.Background())
+	handler, ok := tenants.TenantHandler("acme")
+	require.True(t, ok)
+	metertest.ScrapeAndAssert(t, handler, `tenant_jobs_total 1`)
+	other, ok := tenants.TenantHandler("globex")
+	require.True(t, ok)
+	assert.NotContains(t, metertest.Scrape(t, other), "tenant_jobs_total")
+}
+
+func TestScrapeFilter(t *testing.T) {
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithScrapeFilter(func(r *http.Request) (string, error) {
+		token := r.Header.Get("Authorization")
+		if token == "" {
+			return "", errors.New("missing token")
+		}
+		return strings.TrimPrefix(token, "Bearer tenant-"), nil
+	}, ""))
+	require.NoError(t, err)
+	tenants := NewTenantMeter(m)
+	ctx := context.Background()
+	tenants.For("acme").NewCounter("scraped_requests", "requests", config.UnitCount).IncrOne(ctx)
+	tenants.For("globex").NewCounter("scraped_requests", "requests", config.UnitCount).IncrOne(ctx)
+
+	scrape := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		m.GetHandler().ServeHTTP(rec, req)
+		return rec
+	}
+	assert.Equal(t, http.StatusUnauthorized, scrape("").Code)
+	body := scrape("tenant-acme").Body.String()
+	assert.Contains(t, body, `scraped_requests_total{tenant="acme"} 1`)
+	assert.NotContains(t, body, "globex")
+	assert.NotContains(t, body, "target_info")
+	assert.Contains(t, scrape("tenant-").Body.String(), `scraped_requests_total{tenant="globex"} 1`)
+}
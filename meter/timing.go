@@ -0,0 +1,43 @@
+package meter
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/semconv"
+)
+
+// TagOutcome is the tag set by TimeFunc and TimeErr to OutcomeSuccess or OutcomeError depending on the returned error.
+const (
+	TagOutcome     = semconv.Outcome
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// TimeFunc calls f, records its duration to h tagged with its outcome, and returns its result, e.g.
+//
+//	user, err := meter.TimeFunc(m.NewHistogram("load_user", "", "s"), ctx, repo.LoadUser)
+//
+// It replaces the closures capturing the result around Histogram.Time. The duration is measured with the clock of h.
+func TimeFunc[T any](h interfaces.Histogram, ctx context.Context, f func(ctx context.Context) (T, error)) (T, error) {
+	start := clock.From(h).Now()
+	result, err := f(ctx)
+	h.AddTag(TagOutcome, outcome(err)).UpdateSine(ctx, start)
+	return result, err
+}
+
+// TimeErr calls f, records its duration to h tagged with its outcome, and returns its error.
+func TimeErr(h interfaces.Histogram, ctx context.Context, f func(ctx context.Context) error) error {
+	_, err := TimeFunc(h, ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, f(ctx)
+	})
+	return err
+}
+
+// outcome returns the value of the outcome tag for err.
+func outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
@@ -0,0 +1,69 @@
+package meter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeFuncWithFakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithClock(fake))
+	require.NoError(t, err)
+
+	h := m.NewHistogramWithBuckets("load_user", "", "s", []float64{1, 5})
+	user, err := TimeFunc(h, context.Background(), func(ctx context.Context) (string, error) {
+		fake.Advance(3 * time.Second)
+		return "gopher", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "gopher", user)
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`load_user_seconds_sum{outcome="success"} 3`,
+		`load_user_seconds_bucket{outcome="success",le="1"} 0`,
+		`load_user_seconds_bucket{outcome="success",le="5"} 1`,
+	)
+}
+
+func TestTimeErr(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected []string
+	}{
+		{
+			name:     "Success",
+			expected: []string{`save_user_seconds_sum{outcome="success"} 2`},
+		},
+		{
+			name:     "Error",
+			err:      errors.New("conflict"),
+			expected: []string{`save_user_seconds_sum{outcome="error"} 2`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := clock.NewFake(time.Now())
+			m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithClock(fake))
+			require.NoError(t, err)
+			defer m.WithRunning(false)
+
+			err = TimeErr(m.NewHistogramWithBuckets("save_user", "", "s", []float64{1, 5}), context.Background(),
+				func(ctx context.Context) error {
+					fake.Advance(2 * time.Second)
+					return tt.err
+				})
+			assert.Equal(t, tt.err, err)
+
+			metertest.ScrapeAndAssert(t, m.GetHandler(), tt.expected...)
+		})
+	}
+}
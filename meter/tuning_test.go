@@ -0,0 +1,40 @@
+package meter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketTuning(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithPrometheusPort(0),
+		WithClock(fake), WithBucketTuning(time.Hour))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	for i := 1; i <= 100; i++ {
+		m.NewHistogramWithBuckets("query_seconds", "", "s", []float64{1, 5, 10}).
+			Update(context.Background(), time.Duration(i)*time.Millisecond)
+	}
+	recommendations, ok := BucketRecommendations(m)
+	require.True(t, ok)
+	assert.Empty(t, recommendations, "the window has not ended")
+
+	fake.Advance(time.Hour)
+	recommendations, ok = BucketRecommendations(m)
+	require.True(t, ok)
+	require.Len(t, recommendations, 1)
+	assert.Equal(t, "query_seconds", recommendations[0].Metric)
+	assert.Equal(t, uint64(100), recommendations[0].Observations)
+	assert.Equal(t, 1, recommendations[0].UsedBuckets)
+	assert.InEpsilon(t, 0.05, recommendations[0].Recommended[2], 0.1, "the median")
+
+	_, ok = BucketRecommendations(MustNewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithPrometheusPort(0)))
+	assert.False(t, ok)
+}
@@ -0,0 +1,44 @@
+package meter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestUnitSuffixes(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithUnitSuffixes(), WithReader(reader),
+		WithHistogramExtrema())
+	require.NoError(t, err)
+	ctx := context.Background()
+	m.NewCounter("sent", "", "By").Incr(ctx, 512)
+	m.NewCounter("requests_total", "", "").IncrOne(ctx)
+	m.NewHistogram("latency_seconds", "", "s").UpdateInSeconds(ctx, 0.2)
+	m.NewGauge("queue_depth", "", "").Update(ctx, 3)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	var names []string
+	for _, sm := range rm.ScopeMetrics {
+		for _, metric := range sm.Metrics {
+			names = append(names, metric.Name)
+		}
+	}
+	assert.Subset(t, names, []string{"sent_bytes_total", "requests_total", "latency_seconds", "queue_depth",
+		"latency_min_seconds", "latency_max_seconds"})
+
+	// the Prometheus exporter does not append the suffixes twice.
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`sent_bytes_total 512`,
+		`requests_total 1`,
+		`latency_seconds_count 1`,
+		`queue_depth 3`,
+	)
+}
@@ -0,0 +1,38 @@
+package meter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/internal/meter/nop"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnusedInstruments(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus), WithClock(fake), WithUnusedDetection(time.Hour))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	m.NewCounter("orders_placed", "", config.UnitCount)
+	m.NewCounter("orders_paid", "", config.UnitCount).IncrOne(ctx)
+	fake.Advance(30 * time.Minute)
+	m.NewCounter("orders_paid", "", config.UnitCount).IncrOne(ctx)
+
+	unused, ok := UnusedInstruments(m)
+	require.True(t, ok)
+	assert.Empty(t, unused, "instruments are not reported before the window elapsed")
+
+	fake.Advance(45 * time.Minute)
+	unused, _ = UnusedInstruments(m)
+	assert.Equal(t, []string{"orders_placed"}, unused)
+	metertest.ScrapeAndAssert(t, m.GetHandler(), `go_metric_unused_instruments{metric="orders_placed"} 1`)
+
+	_, ok = UnusedInstruments(nop.NewNopMeter())
+	assert.False(t, ok)
+}
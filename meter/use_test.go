@@ -0,0 +1,42 @@
+package meter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestMeterUse(t *testing.T) {
+	m, err := NewMeter(WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+
+	ctx := context.Background()
+	counter := m.NewCounter("logins", "", "").AddTag("email", "jane@example.com")
+	m.Use(func(ctx context.Context, ms *interfaces.Measurement) bool {
+		for i, kv := range ms.Tags {
+			if kv.Key == "email" {
+				ms.Tags[i] = attribute.String("email", "redacted")
+			}
+		}
+		return true
+	})
+	counter.IncrOne(ctx)
+
+	m.Use(func(ctx context.Context, ms *interfaces.Measurement) bool {
+		return ms.Kind != interfaces.KindHistogram
+	})
+	m.NewCounter("logins", "", "").AddTag("email", "john@example.com").IncrOne(ctx)
+	m.NewHistogram("login_duration", "", "s").UpdateInSeconds(ctx, 0.2)
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(), `logins_total{email="redacted"} 2`)
+	for name := range metertest.Scrape(t, m.GetHandler()) {
+		assert.NotContains(t, name, "login_duration", "dropped by the second interceptor")
+	}
+}
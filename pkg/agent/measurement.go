@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Kind is the kind of instrument a Measurement is recorded to.
+type Kind string
+
+// The kinds of instruments supported by the agent.
+const (
+	KindCounter       Kind = "counter"
+	KindUpDownCounter Kind = "updowncounter"
+	KindGauge         Kind = "gauge"
+	KindHistogram     Kind = "histogram"
+)
+
+// ErrInvalidMeasurement is wrapped by the errors of the measurements rejected by the agent.
+var ErrInvalidMeasurement = errors.New("invalid measurement")
+
+// Measurement is a single measurement sent to the agent, encoded as one JSON object per line.
+// Value is the delta of counters, the value of gauges and the observation of histograms, in seconds for durations.
+// Buckets optionally sets the boundaries of a histogram, the default duration boundaries of the agent otherwise.
+type Measurement struct {
+	Kind    Kind              `json:"kind"`
+	Name    string            `json:"name"`
+	Desc    string            `json:"desc,omitempty"`
+	Unit    string            `json:"unit,omitempty"`
+	Value   float64           `json:"value"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Buckets []float64         `json:"buckets,omitempty"`
+}
+
+// Validate checks the measurement has a name, a supported kind and a finite value, and that a counter is not decreased.
+func (m *Measurement) Validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("%w: empty name", ErrInvalidMeasurement)
+	}
+	if math.IsNaN(m.Value) || math.IsInf(m.Value, 0) {
+		return fmt.Errorf("%w: %s has a non finite value", ErrInvalidMeasurement, m.Name)
+	}
+	switch m.Kind {
+	case KindCounter:
+		if m.Value < 0 {
+			return fmt.Errorf("%w: counter %s decreased", ErrInvalidMeasurement, m.Name)
+		}
+	case KindUpDownCounter, KindGauge, KindHistogram:
+	default:
+		return fmt.Errorf("%w: %s has unsupported kind %q", ErrInvalidMeasurement, m.Name, m.Kind)
+	}
+	return nil
+}
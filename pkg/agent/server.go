@@ -0,0 +1,188 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Names of the metrics the agent records about itself.
+const (
+	ReceivedMetric = "agent_received_measurements"
+	RejectedMetric = "agent_rejected_measurements"
+	TagTransport   = "transport"
+)
+
+// maxDatagramSize is the largest UDP payload, a datagram holds one or more measurements separated by new lines.
+const maxDatagramSize = 64 * 1024
+
+// maxRequestSize bounds the body of an HTTP ingestion request.
+const maxRequestSize = 4 << 20
+
+// Server is the ingestion side of the aggregating agent: a long-lived process receiving the measurements of
+// short-lived processes, e.g. batch jobs and CLI tools, over UDP or HTTP and aggregating them into its meter,
+// which exposes them to Prometheus like any other metric.
+//
+// A typical agent serves the meter handler and the ingestion endpoint on the same port:
+//
+//	m, _ := meter.NewMeter(meter.WithProviderType(config.MeterProviderTypePrometheus))
+//	s := agent.NewServer(m)
+//	go s.ListenUDP(ctx, ":8125")
+//	mux := http.NewServeMux()
+//	mux.Handle("/metrics", m.GetHandler())
+//	mux.Handle("/ingest", s)
+//	http.ListenAndServe(":9090", mux)
+type Server struct {
+	meter interfaces.Meter
+}
+
+// NewServer creates an agent aggregating the received measurements into m.
+func NewServer(m interfaces.Meter) *Server {
+	return &Server{
+		meter: m,
+	}
+}
+
+// Apply validates and records a measurement into the meter of the agent.
+func (s *Server) Apply(ctx context.Context, m *Measurement) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	switch m.Kind {
+	case KindCounter:
+		s.meter.NewCounter(m.Name, m.Desc, m.Unit).WithTags(m.Tags).Incr(ctx, m.Value)
+	case KindUpDownCounter:
+		s.meter.NewUpDownCounter(m.Name, m.Desc, m.Unit).WithTags(m.Tags).Update(ctx, m.Value)
+	case KindGauge:
+		s.meter.NewGauge(m.Name, m.Desc, m.Unit).WithTags(m.Tags).Update(ctx, m.Value)
+	case KindHistogram:
+		var h interfaces.Histogram
+		if len(m.Buckets) > 0 {
+			h = s.meter.NewHistogramWithBuckets(m.Name, m.Desc, m.Unit, m.Buckets)
+		} else {
+			h = s.meter.NewHistogram(m.Name, m.Desc, m.Unit)
+		}
+		h.WithTags(m.Tags).Record(ctx, m.Value)
+	}
+	return nil
+}
+
+// decode decodes and validates the measurements of r, one JSON object per line. The invalid lines are left out and
+// counted, the returned error reports the first of them.
+func decode(r io.Reader) ([]*Measurement, int, error) {
+	var (
+		measurements []*Measurement
+		invalid      int
+		first        error
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxDatagramSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		m := &Measurement{}
+		err := json.Unmarshal(line, m)
+		if err != nil {
+			err = fmt.Errorf("%w: %v", ErrInvalidMeasurement, err)
+		} else {
+			err = m.Validate()
+		}
+		if err != nil {
+			invalid++
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		measurements = append(measurements, m)
+	}
+	if err := scanner.Err(); err != nil && first == nil {
+		first = err
+	}
+	return measurements, invalid, first
+}
+
+// record applies the valid measurements received over the transport and counts them.
+func (s *Server) record(ctx context.Context, measurements []*Measurement, transport string) {
+	for _, m := range measurements {
+		_ = s.Apply(ctx, m)
+		s.meter.NewCounter(ReceivedMetric, "number of measurements received by the agent", "").
+			AddTag(TagTransport, transport).IncrOne(ctx)
+	}
+}
+
+// reject counts n measurements received over the transport and not applied.
+func (s *Server) reject(ctx context.Context, n int, transport string) {
+	if n == 0 {
+		return
+	}
+	s.meter.NewCounter(RejectedMetric, "number of measurements rejected by the agent", "").
+		AddTag(TagTransport, transport).Incr(ctx, float64(n))
+}
+
+// ingest applies the valid measurements of r, one JSON object per line.
+// Invalid lines are skipped and counted, the returned error reports the first of them.
+func (s *Server) ingest(ctx context.Context, r io.Reader, transport string) error {
+	measurements, invalid, err := decode(r)
+	s.record(ctx, measurements, transport)
+	s.reject(ctx, invalid, transport)
+	return err
+}
+
+// ServeHTTP ingests the measurements of a POST request body, one JSON object per line. The body is validated as a
+// whole before any measurement is applied, so that a client retrying a refused request does not count twice: it
+// answers 204 No Content when all measurements are accepted, 400 Bad Request with the first error otherwise, none of
+// the measurements being applied and all of them counted as rejected.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	measurements, invalid, err := decode(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		s.reject(r.Context(), len(measurements)+invalid, "http")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.record(r.Context(), measurements, "http")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeUDP ingests the datagrams received on pc until ctx is done, pc being closed then, or pc is closed.
+// Every datagram holds one or more measurements separated by new lines, invalid ones are counted and skipped.
+func (s *Server) ServeUDP(ctx context.Context, pc net.PacketConn) error {
+	stop := context.AfterFunc(ctx, func() {
+		_ = pc.Close()
+	})
+	defer stop()
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		_ = s.ingest(ctx, bytes.NewReader(buf[:n]), "udp")
+	}
+}
+
+// ListenUDP listens on the UDP address addr and serves it with ServeUDP until ctx is done.
+func (s *Server) ListenUDP(ctx context.Context, addr string) error {
+	pc, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	return s.ServeUDP(ctx, pc)
+}
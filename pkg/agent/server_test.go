@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, h http.Handler) string {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rec.Body.String()
+}
+
+func TestServer(t *testing.T) {
+	m, err := meter.NewMeter(meter.WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	s := NewServer(m)
+
+	body := `{"kind":"counter","name":"jobs_processed","value":3,"tags":{"job":"backup"}}
+{"kind":"counter","name":"jobs_processed","value":2,"tags":{"job":"backup"}}
+{"kind":"histogram","name":"job_duration","unit":"s","value":0.2,"buckets":[0.1,1]}
+`
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader(body)))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader(`{"kind":"counter","name":"x","value":-1}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// a batch with an invalid line is refused as a whole, its valid lines are not applied.
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader(
+		`{"kind":"counter","name":"jobs_processed","value":4,"tags":{"job":"backup"}}`+"\n"+`not json`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, scrape(t, m.GetHandler()), `agent_rejected_measurements_total{transport="http"} 3`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() { done <- s.ServeUDP(ctx, pc) }()
+	conn, err := net.Dial("udp", pc.LocalAddr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte(`{"kind":"gauge","name":"queue_size","value":7}` + "\n" + `not json`))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(scrape(t, m.GetHandler()), `agent_rejected_measurements_total{transport="udp"} 1`)
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	assert.NoError(t, <-done)
+
+	out := scrape(t, m.GetHandler())
+	assert.Contains(t, out, `jobs_processed_total{job="backup"} 5`)
+	assert.Contains(t, out, `job_duration_seconds_bucket{le="1"`)
+	assert.Contains(t, out, "queue_size 7")
+}
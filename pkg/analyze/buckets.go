@@ -0,0 +1,186 @@
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// binsPerOctave is the number of logarithmic bins of a Distribution between a value and its double, bounding the
+// relative error of its quantiles to about 4%.
+const binsPerOctave = 8
+
+// usedBucketShare is the share of the observations a bucket must hold to be counted as used.
+const usedBucketShare = 0.01
+
+// recommendedQuantiles are the quantiles of the observed values at which the recommended boundaries are placed.
+var recommendedQuantiles = []float64{0.1, 0.25, 0.5, 0.75, 0.9, 0.95, 0.99, 0.999}
+
+// Distribution accumulates the values recorded to a histogram in logarithmic bins, in constant memory per order of
+// magnitude, to recommend bucket boundaries fitting them. It is safe for concurrent use.
+type Distribution struct {
+	mu          sync.Mutex
+	bins        map[int]uint64
+	nonPositive uint64
+	count       uint64
+	min, max    float64
+}
+
+// NewDistribution creates an empty distribution.
+func NewDistribution() *Distribution {
+	return &Distribution{
+		bins: make(map[int]uint64),
+	}
+}
+
+// Observe accounts the value v, NaN being ignored.
+func (d *Distribution) Observe(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.count == 0 || v < d.min {
+		d.min = v
+	}
+	if d.count == 0 || v > d.max {
+		d.max = v
+	}
+	d.count++
+	if v <= 0 {
+		d.nonPositive++
+		return
+	}
+	d.bins[binOf(v)]++
+}
+
+// Count returns the number of values observed.
+func (d *Distribution) Count() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.count
+}
+
+// Quantile returns the estimated q-quantile of the observed values, 0 if none was observed.
+func (d *Distribution) Quantile(q float64) float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.count == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(d.count)))
+	if rank <= d.nonPositive {
+		return d.min
+	}
+	seen := d.nonPositive
+	for _, bin := range d.sortedBins() {
+		seen += d.bins[bin]
+		if seen >= rank {
+			return math.Min(math.Max(binValue(bin), d.min), d.max)
+		}
+	}
+	return d.max
+}
+
+// CountBelow returns the estimated number of observed values less than or equal to bound.
+func (d *Distribution) CountBelow(bound float64) uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var n uint64
+	if bound >= 0 {
+		n = d.nonPositive
+	}
+	for bin, count := range d.bins {
+		if binValue(bin) <= bound {
+			n += count
+		}
+	}
+	return n
+}
+
+// sortedBins returns the indexes of the non-empty bins in increasing order of their values.
+func (d *Distribution) sortedBins() []int {
+	bins := make([]int, 0, len(d.bins))
+	for bin := range d.bins {
+		bins = append(bins, bin)
+	}
+	sort.Ints(bins)
+	return bins
+}
+
+// binOf returns the index of the logarithmic bin holding the positive value v.
+func binOf(v float64) int {
+	return int(math.Floor(math.Log2(v) * binsPerOctave))
+}
+
+// binValue returns the value representing the bin, its geometric middle.
+func binValue(bin int) float64 {
+	return math.Exp2((float64(bin) + 0.5) / binsPerOctave)
+}
+
+// BucketRecommendation compares the bucket boundaries of a histogram with those fitting the values recorded to it
+// during an analysis window.
+type BucketRecommendation struct {
+	Metric       string    `json:"metric"`
+	Observations uint64    `json:"observations"`
+	Current      []float64 `json:"current"`
+	UsedBuckets  int       `json:"used_buckets"`
+	Recommended  []float64 `json:"recommended"`
+}
+
+// RecommendBuckets recommends to the histogram metric, whose boundaries are current, boundaries placed at the
+// quantiles from 10% to 99.9% of the values of d, rounded to two significant digits. The buckets of current, the
+// +Inf one included, holding at least 1% of the values are counted as used.
+func RecommendBuckets(metric string, current []float64, d *Distribution) BucketRecommendation {
+	r := BucketRecommendation{
+		Metric:       metric,
+		Observations: d.Count(),
+		Current:      current,
+	}
+	if r.Observations == 0 {
+		return r
+	}
+	var below uint64
+	for _, bound := range append(append([]float64(nil), current...), math.Inf(1)) {
+		n := d.CountBelow(bound)
+		if float64(n-below) >= usedBucketShare*float64(r.Observations) {
+			r.UsedBuckets++
+		}
+		below = n
+	}
+	for _, q := range recommendedQuantiles {
+		bound := roundSignificant(d.Quantile(q), 2)
+		if len(r.Recommended) == 0 || bound > r.Recommended[len(r.Recommended)-1] {
+			r.Recommended = append(r.Recommended, bound)
+		}
+	}
+	return r
+}
+
+// String describes the recommendation on a single line, e.g.
+// `latency_seconds: 1200 observations in 2 of 12 buckets, recommended [0.0012 0.0025 0.005]`.
+func (r BucketRecommendation) String() string {
+	return fmt.Sprintf("%s: %d observations in %d of %d buckets, recommended [%s]",
+		r.Metric, r.Observations, r.UsedBuckets, len(r.Current)+1, formatBounds(r.Recommended))
+}
+
+// roundSignificant rounds v to the given number of significant digits.
+func roundSignificant(v float64, digits int) float64 {
+	if v == 0 || math.IsInf(v, 0) {
+		return v
+	}
+	scale := math.Pow(10, float64(digits)-math.Ceil(math.Log10(math.Abs(v))))
+	return math.Round(v*scale) / scale
+}
+
+// formatBounds formats the boundaries separated by spaces.
+func formatBounds(bounds []float64) string {
+	parts := make([]string, len(bounds))
+	for i, bound := range bounds {
+		parts[i] = strconv.FormatFloat(bound, 'g', -1, 64)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,27 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecommendBuckets(t *testing.T) {
+	d := NewDistribution()
+	for i := 1; i <= 1000; i++ {
+		d.Observe(float64(i) / 1000)
+	}
+	assert.InEpsilon(t, 0.5, d.Quantile(0.5), 0.05)
+	assert.InEpsilon(t, 0.9, d.Quantile(0.9), 0.05)
+
+	r := RecommendBuckets("latency_seconds", []float64{5, 10, 30}, d)
+	assert.Equal(t, uint64(1000), r.Observations)
+	assert.Equal(t, 1, r.UsedBuckets, "every value falls in the first bucket")
+	require.Len(t, r.Recommended, 6, "the upper quantiles fall in the same bin")
+	assert.Equal(t, []float64{0.1, 0.26, 0.52, 0.74, 0.88, 0.96}, r.Recommended)
+	assert.Equal(t, "latency_seconds: 1000 observations in 1 of 4 buckets, recommended [0.1 0.26 0.52 0.74 0.88 0.96]",
+		r.String())
+
+	assert.Empty(t, RecommendBuckets("idle_seconds", []float64{1}, NewDistribution()).Recommended)
+}
@@ -0,0 +1,145 @@
+// Package analyze provides helpers computing statistics from the Prometheus text exposition of a meter,
+// useful for admin endpoints and for tests asserting latency SLOs.
+package analyze
+
+import (
+	"errors"
+	"fmt"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// Errors returned when a percentile cannot be computed from a snapshot.
+var (
+
+	// ErrMetricNotFound is returned when the snapshot has no family with the requested name.
+	ErrMetricNotFound = errors.New("metric not found")
+
+	// ErrNotHistogram is returned when the requested family is not a histogram.
+	ErrNotHistogram = errors.New("metric is not a histogram")
+
+	// ErrNoObservations is returned when the matching series have no observation.
+	ErrNoObservations = errors.New("no observations")
+)
+
+// Bucket is a cumulative histogram bucket: Count observations are less than or equal to UpperBound.
+type Bucket struct {
+	UpperBound float64
+	Count      float64
+}
+
+// Snapshot holds the metric families parsed from a Prometheus text exposition, indexed by family name.
+// Histogram families are named without the _bucket, _sum and _count suffixes, e.g. "http_duration_seconds".
+type Snapshot map[string]*dto.MetricFamily
+
+// Parse parses a Prometheus text exposition, such as the body returned by the handler of a meter.
+func Parse(r io.Reader) (Snapshot, error) {
+	var parser expfmt.TextParser
+	mfs, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, err
+	}
+	return mfs, nil
+}
+
+// Buckets returns the cumulative buckets of the histogram family metricName, summed over all the series whose labels
+// contain the given labels, a nil map matching every series. Buckets are sorted by upper bound and end with +Inf.
+func (s Snapshot) Buckets(metricName string, labels map[string]string) ([]Bucket, error) {
+	mf, ok := s[metricName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMetricNotFound, metricName)
+	}
+	if mf.GetType() != dto.MetricType_HISTOGRAM {
+		return nil, fmt.Errorf("%w: %s is a %s", ErrNotHistogram, metricName, mf.GetType())
+	}
+
+	counts := make(map[float64]float64)
+	for _, m := range mf.Metric {
+		if !matchLabels(m, labels) {
+			continue
+		}
+		h := m.GetHistogram()
+		hasInf := false
+		for _, b := range h.GetBucket() {
+			counts[b.GetUpperBound()] += float64(b.GetCumulativeCount())
+			hasInf = hasInf || math.IsInf(b.GetUpperBound(), 1)
+		}
+		if !hasInf {
+			counts[math.Inf(1)] += float64(h.GetSampleCount())
+		}
+	}
+
+	buckets := make([]Bucket, 0, len(counts))
+	for upperBound, count := range counts {
+		buckets = append(buckets, Bucket{UpperBound: upperBound, Count: count})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].UpperBound < buckets[j].UpperBound
+	})
+	return buckets, nil
+}
+
+// Percentile returns the approximate q-quantile (0 <= q <= 1) of the histogram family metricName over the series
+// matching labels, e.g. Percentile("http_duration_seconds", 0.99, map[string]string{"route": "/pay"}).
+func (s Snapshot) Percentile(metricName string, q float64, labels map[string]string) (float64, error) {
+	buckets, err := s.Buckets(metricName, labels)
+	if err != nil {
+		return 0, err
+	}
+	return Quantile(q, buckets)
+}
+
+// Quantile computes the approximate q-quantile of cumulative buckets sorted by upper bound, with the same linear
+// interpolation as the PromQL histogram_quantile function. The lower bound of the first bucket is 0 when its upper
+// bound is positive, and a quantile falling in the +Inf bucket returns the highest finite upper bound.
+func Quantile(q float64, buckets []Bucket) (float64, error) {
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		return 0, fmt.Errorf("invalid quantile %v, must be within [0, 1]", q)
+	}
+	if len(buckets) == 0 || buckets[len(buckets)-1].Count == 0 {
+		return 0, ErrNoObservations
+	}
+
+	rank := q * buckets[len(buckets)-1].Count
+	i := sort.Search(len(buckets), func(i int) bool {
+		return buckets[i].Count >= rank
+	})
+	if math.IsInf(buckets[i].UpperBound, 1) {
+		if i == 0 {
+			return 0, nil
+		}
+		return buckets[i-1].UpperBound, nil
+	}
+
+	lowerBound, lowerCount := 0.0, 0.0
+	if i > 0 {
+		lowerBound, lowerCount = buckets[i-1].UpperBound, buckets[i-1].Count
+	} else if buckets[0].UpperBound <= 0 {
+		return buckets[0].UpperBound, nil
+	}
+	upperBound, upperCount := buckets[i].UpperBound, buckets[i].Count
+	if upperCount == lowerCount {
+		return upperBound, nil
+	}
+	return lowerBound + (upperBound-lowerBound)*(rank-lowerCount)/(upperCount-lowerCount), nil
+}
+
+// matchLabels reports whether the labels of m contain all the given labels.
+func matchLabels(m *dto.Metric, labels map[string]string) bool {
+	if len(labels) == 0 {
+		return true
+	}
+	matched := 0
+	for _, label := range m.Label {
+		if value, ok := labels[label.GetName()]; ok {
+			if value != label.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
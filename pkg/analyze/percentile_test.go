@@ -0,0 +1,55 @@
+package analyze
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const snapshot = `# HELP http_duration_seconds request latency
+# TYPE http_duration_seconds histogram
+http_duration_seconds_bucket{route="/pay",le="0.1"} 50
+http_duration_seconds_bucket{route="/pay",le="0.5"} 90
+http_duration_seconds_bucket{route="/pay",le="1"} 100
+http_duration_seconds_bucket{route="/pay",le="+Inf"} 100
+http_duration_seconds_sum{route="/pay"} 20
+http_duration_seconds_count{route="/pay"} 100
+http_duration_seconds_bucket{route="/refund",le="0.1"} 0
+http_duration_seconds_bucket{route="/refund",le="0.5"} 0
+http_duration_seconds_bucket{route="/refund",le="1"} 0
+http_duration_seconds_bucket{route="/refund",le="+Inf"} 100
+http_duration_seconds_sum{route="/refund"} 300
+http_duration_seconds_count{route="/refund"} 100
+# TYPE requests_total counter
+requests_total 3
+`
+
+func TestSnapshotPercentile(t *testing.T) {
+	s, err := Parse(strings.NewReader(snapshot))
+	require.NoError(t, err)
+
+	p50, err := s.Percentile("http_duration_seconds", 0.5, map[string]string{"route": "/pay"})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.1, p50, 1e-9)
+
+	p95, err := s.Percentile("http_duration_seconds", 0.95, map[string]string{"route": "/pay"})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.75, p95, 1e-9)
+
+	p99, err := s.Percentile("http_duration_seconds", 0.99, map[string]string{"route": "/refund"})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, p99)
+
+	all, err := s.Percentile("http_duration_seconds", 0.25, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.1, all, 1e-9)
+
+	_, err = s.Percentile("requests_total", 0.5, nil)
+	assert.ErrorIs(t, err, ErrNotHistogram)
+	_, err = s.Percentile("missing", 0.5, nil)
+	assert.ErrorIs(t, err, ErrMetricNotFound)
+	_, err = s.Percentile("http_duration_seconds", 0.5, map[string]string{"route": "/none"})
+	assert.ErrorIs(t, err, ErrNoObservations)
+}
@@ -0,0 +1,78 @@
+// Package clock abstracts the time source of the SDK, so that the periodic collectors, the push gateway and the
+// timing helpers can be driven by a Fake clock in tests instead of sleeping:
+//
+//	fake := clock.NewFake(time.Now())
+//	m, _ := meter.NewMeter(meter.WithClock(fake), ...)
+//	fake.Advance(time.Minute)
+package clock
+
+import (
+	"time"
+)
+
+// Clock tells the time and creates timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+	// NewTimer creates a Timer sending the current time on its channel after d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the Clock counterpart of time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is sent when the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, it returns false if the timer already fired or was stopped.
+	Stop() bool
+	// Reset changes the timer to fire after d, it returns true if the timer was active.
+	Reset(d time.Duration) bool
+}
+
+// Clocked is implemented by the meters and instruments configured with a Clock.
+type Clocked interface {
+	Clock() Clock
+}
+
+// Real is the Clock of the time package.
+var Real Clock = realClock{}
+
+// From returns the clock of v if it implements Clocked, Real otherwise.
+// Code measuring durations recorded to an instrument takes its start time from the clock of the instrument or meter.
+func From(v any) Clock {
+	if clocked, ok := v.(Clocked); ok {
+		if c := clocked.Clock(); c != nil {
+			return c
+		}
+	}
+	return Real
+}
+
+// realClock is the Clock of the time package.
+type realClock struct{}
+
+// Now returns time.Now().
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since returns time.Since(t).
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// NewTimer wraps time.NewTimer(d).
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+// realTimer is a Timer wrapping a time.Timer.
+type realTimer struct {
+	*time.Timer
+}
+
+// C returns the channel of the time.Timer.
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
@@ -0,0 +1,126 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves forward when Advance is called, firing the timers which are due.
+// It is safe for concurrent use.
+type Fake struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFake creates a Fake clock set to now.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the time of the clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the time of the clock elapsed since t.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// NewTimer creates a Timer firing when the clock is advanced by d or more.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{
+		clock: f,
+		ch:    make(chan time.Time, 1),
+	}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by d and fires the timers which are due, in the order of their deadlines.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	for {
+		var next *fakeTimer
+		for _, t := range f.timers {
+			if !t.deadline.After(f.now) && (next == nil || t.deadline.Before(next.deadline)) {
+				next = t
+			}
+		}
+		if next == nil {
+			return
+		}
+		f.remove(next)
+		select {
+		case next.ch <- f.now:
+		default:
+		}
+	}
+}
+
+// BlockUntil blocks until at least n timers are waiting, so that a test advances the clock only once the goroutines
+// it drives have armed their timers.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.timers) < n {
+		f.cond.Wait()
+	}
+}
+
+// remove removes t from the waiting timers and reports whether it was waiting, f.mu must be held.
+func (f *Fake) remove(t *fakeTimer) bool {
+	for i, waiting := range f.timers {
+		if waiting == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTimer is a Timer of a Fake clock.
+type fakeTimer struct {
+	clock    *Fake
+	ch       chan time.Time
+	deadline time.Time
+}
+
+// C returns the channel on which the time is sent when the timer fires.
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop prevents the timer from firing.
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+// Reset changes the timer to fire when the clock is advanced by d or more from now.
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	active := f.remove(t)
+	t.deadline = f.now.Add(d)
+	if d <= 0 {
+		select {
+		case t.ch <- f.now:
+		default:
+		}
+		return active
+	}
+	f.timers = append(f.timers, t)
+	f.cond.Broadcast()
+	return active
+}
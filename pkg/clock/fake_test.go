@@ -0,0 +1,63 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+	timer := f.NewTimer(time.Minute)
+
+	f.Advance(59 * time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+	assert.Equal(t, 59*time.Second, f.Since(start))
+
+	f.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-timer.C())
+	assert.False(t, timer.Stop())
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Stop())
+	f.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+}
+
+func TestFakeBlockUntil(t *testing.T) {
+	f := NewFake(time.Now())
+	fired := make(chan struct{})
+	go func() {
+		timer := f.NewTimer(time.Second)
+		<-timer.C()
+		close(fired)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Second)
+	<-fired
+}
+
+func TestFrom(t *testing.T) {
+	assert.Equal(t, Real, From(nil))
+	f := NewFake(time.Now())
+	assert.Equal(t, Clock(f), From(clocked{f}))
+}
+
+type clocked struct {
+	c Clock
+}
+
+func (c clocked) Clock() Clock {
+	return c.c
+}
@@ -0,0 +1,175 @@
+package components
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/internal/component"
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"sync"
+	"time"
+)
+
+// Names and tags of the metrics of the cache component, see interfaces.CacheComponent.
+const (
+	CacheRequestsMetric  = "cache_requests"
+	CacheEvictionsMetric = "cache_evictions"
+	CacheEntriesMetric   = "cache_entries"
+	TagCache             = component.TagCache
+	TagCacheResult       = component.TagResult
+	TagEvictionReason    = component.TagReason
+	CacheResultHit       = component.CacheResultHit
+	CacheResultMiss      = component.CacheResultMiss
+	EvictionReasonPolicy = "policy"
+)
+
+// CacheStats is a snapshot of the cumulative statistics of a cache library.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   float64
+}
+
+// defaultCacheWatchInterval is the interval at which WatchCache polls the statistics when none is given.
+const defaultCacheWatchInterval = 15 * time.Second
+
+// WatchCache reports the statistics of a cache library under the standardized names of the cache component: the
+// number of entries returned by stats is observed at every collection of the global meter, and every interval,
+// 15 seconds if not positive, the growth of its hits, misses and evictions is added to the counters, the evictions
+// with the reason "policy". stats is typically one of the adapters, e.g.
+//
+//	components.WatchCache("sessions", 0, func() components.CacheStats { return components.FromRistretto(cache.Metrics) })
+//
+// The returned Registration stops the reporting.
+func WatchCache(name string, interval time.Duration, stats func() CacheStats) interfaces.Registration {
+	if interval <= 0 {
+		interval = defaultCacheWatchInterval
+	}
+	w := &cacheWatcher{
+		name:   name,
+		stats:  stats,
+		last:   stats(),
+		stopCh: make(chan struct{}),
+	}
+	w.gauge = meter.GetGlobalMeter().Components().Cache.ObserveSize(name, func() float64 {
+		return stats().Entries
+	})
+	go w.poll(clock.From(meter.GetGlobalMeter()), interval)
+	return w
+}
+
+// cacheWatcher turns the cumulative statistics of a cache into the increments of the counters of the component.
+// The counters are not recorded by the callback of the gauge, the meter not allowing instruments to be created while
+// it collects.
+type cacheWatcher struct {
+	name     string
+	stats    func() CacheStats
+	last     CacheStats
+	gauge    interfaces.Registration
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// poll records the growth of the statistics every interval until the watcher is unregistered.
+func (w *cacheWatcher) poll(c clock.Clock, interval time.Duration) {
+	timer := c.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-timer.C():
+			w.record()
+			timer.Reset(interval)
+		}
+	}
+}
+
+// record records the growth of the statistics since the previous call.
+func (w *cacheWatcher) record() {
+	current := w.stats()
+	ctx := context.Background()
+	cache := meter.GetGlobalMeter().Components().Cache
+	if delta := growth(w.last.Hits, current.Hits); delta > 0 {
+		cache.Requests(w.name, CacheResultHit).Incr(ctx, delta)
+	}
+	if delta := growth(w.last.Misses, current.Misses); delta > 0 {
+		cache.Requests(w.name, CacheResultMiss).Incr(ctx, delta)
+	}
+	if delta := growth(w.last.Evictions, current.Evictions); delta > 0 {
+		component.NewCounter(globalMeter, component.CacheEvictions, w.name, EvictionReasonPolicy).Incr(ctx, delta)
+	}
+	w.last = current
+}
+
+// Unregister stops polling the statistics and observing the entries.
+func (w *cacheWatcher) Unregister() error {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+	return w.gauge.Unregister()
+}
+
+// growth returns the increase of a cumulative statistic, 0 when it was reset.
+func growth(last, current uint64) float64 {
+	if current < last {
+		return 0
+	}
+	return float64(current - last)
+}
+
+// RistrettoMetrics is implemented by the *ristretto.Metrics of github.com/dgraph-io/ristretto, the Metrics field of
+// a cache created with Config.Metrics set.
+type RistrettoMetrics interface {
+	Hits() uint64
+	Misses() uint64
+	KeysAdded() uint64
+	KeysEvicted() uint64
+}
+
+// FromRistretto adapts the metrics of a ristretto cache, the number of entries being the keys added minus the keys
+// evicted.
+func FromRistretto(m RistrettoMetrics) CacheStats {
+	added, evicted := m.KeysAdded(), m.KeysEvicted()
+	return CacheStats{
+		Hits:      m.Hits(),
+		Misses:    m.Misses(),
+		Evictions: evicted,
+		Entries:   growth(evicted, added),
+	}
+}
+
+// FromBigCache adapts the statistics of a github.com/allegro/bigcache cache, given its Stats() and Len(), e.g.
+// components.FromBigCache(cache.Stats(), cache.Len()). Its structure matches bigcache.Stats, which bigcache does not
+// report evictions with: count them with Components().Cache.RecordEviction in its OnRemoveWithReason callback.
+func FromBigCache(stats struct {
+	Hits       int64 `json:"hits"`
+	Misses     int64 `json:"misses"`
+	DelHits    int64 `json:"delete_hits"`
+	DelMisses  int64 `json:"delete_misses"`
+	Collisions int64 `json:"collisions"`
+}, length int) CacheStats {
+	return CacheStats{
+		Hits:    uint64(max(stats.Hits, 0)),
+		Misses:  uint64(max(stats.Misses, 0)),
+		Entries: float64(length),
+	}
+}
+
+// FromGroupCache adapts the statistics of a github.com/golang/groupcache cache, given one of the CacheStats of a
+// group, e.g. components.FromGroupCache(group.CacheStats(groupcache.MainCache)), whose structure it matches.
+func FromGroupCache(stats struct {
+	Bytes     int64
+	Items     int64
+	Gets      int64
+	Hits      int64
+	Evictions int64
+}) CacheStats {
+	return CacheStats{
+		Hits:      uint64(max(stats.Hits, 0)),
+		Misses:    uint64(max(stats.Gets-stats.Hits, 0)),
+		Evictions: uint64(max(stats.Evictions, 0)),
+		Entries:   float64(stats.Items),
+	}
+}
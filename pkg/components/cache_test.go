@@ -0,0 +1,43 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/liangweijiang/go-metric/pkg/metertest/fixture"
+	"github.com/stretchr/testify/assert"
+)
+
+// groupCacheStats has the structure of groupcache.CacheStats.
+type groupCacheStats struct {
+	Bytes     int64
+	Items     int64
+	Gets      int64
+	Hits      int64
+	Evictions int64
+}
+
+func TestWatchCache(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	m := fixture.Global(t, meter.WithClock(fake))
+
+	stats := groupCacheStats{Gets: 10, Hits: 8, Items: 5}
+	registration := WatchCache("users", time.Minute, func() CacheStats { return FromGroupCache(stats) })
+	defer func() {
+		assert.NoError(t, registration.Unregister())
+	}()
+
+	fake.BlockUntil(1)
+	stats = groupCacheStats{Gets: 20, Hits: 15, Items: 7, Evictions: 1}
+	fake.Advance(time.Minute)
+	fake.BlockUntil(1)
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`cache_entries{cache="users"} 7`,
+		`cache_requests_total{cache="users",result="hit"} 7`,
+		`cache_requests_total{cache="users",result="miss"} 3`,
+		`cache_evictions_total{cache="users",reason="policy"} 1`,
+	)
+}
@@ -0,0 +1,13 @@
+package components
+
+import (
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"time"
+)
+
+// now returns the time of the clock of the global meter, from which the durations recorded by the components are
+// measured so that they follow a fake clock configured with meter.WithClock.
+func now() time.Time {
+	return clock.From(meter.GetGlobalMeter()).Now()
+}
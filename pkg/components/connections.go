@@ -0,0 +1,96 @@
+package components
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Names and tags of the metrics recorded by ConnTracker, all tagged with TagConnKind.
+// ConnectionMessagesMetric is also tagged with TagDirection, DirectionIn or DirectionOut.
+const (
+	ConnectionsActiveMetric  = "connections_active"
+	ConnectionLifetimeMetric = "connection_lifetime"
+	ConnectionMessagesMetric = "connection_messages"
+	TagConnKind              = "kind"
+	TagDirection             = "direction"
+	DirectionIn              = "in"
+	DirectionOut             = "out"
+)
+
+// ConnectionLifetimeBoundaries are the boundaries, in seconds, of the lifetime histogram of long-lived connections,
+// from 1s to 24h.
+var ConnectionLifetimeBoundaries = []float64{1, 5, 30, 60, 300, 900, 1800, 3600, 7200, 21600, 43200, 86400}
+
+// ConnTracker tracks long-lived connections of one kind, such as websockets or server-sent event streams:
+// the number of active connections as an observable gauge, the lifetime of closed connections and the messages exchanged.
+// The gauge is registered to the global meter when the first connection is opened.
+type ConnTracker struct {
+	kind   string
+	active int64
+	once   sync.Once
+}
+
+// NewConnTracker creates a tracker of the connections of the given kind, e.g. "websocket" or "sse".
+func NewConnTracker(kind string) *ConnTracker {
+	return &ConnTracker{
+		kind: kind,
+	}
+}
+
+// Active returns the number of connections currently open.
+func (t *ConnTracker) Active() int64 {
+	return atomic.LoadInt64(&t.active)
+}
+
+// Open accounts a new connection and returns the handle to use for its messages and closing.
+func (t *ConnTracker) Open(_ context.Context) *Conn {
+	t.once.Do(func() {
+		meter.GetGlobalMeter().NewObservableGauge(ConnectionsActiveMetric, "number of open long-lived connections", "",
+			func(_ context.Context, o interfaces.Observer) error {
+				o.Observe(float64(t.Active()), map[string]string{TagConnKind: t.kind})
+				return nil
+			})
+	})
+	atomic.AddInt64(&t.active, 1)
+	return &Conn{
+		tracker: t,
+		start:   now(),
+	}
+}
+
+// Conn is the handle of a tracked connection returned by ConnTracker.Open.
+type Conn struct {
+	tracker *ConnTracker
+	start   time.Time
+	closed  int32
+}
+
+// MessageIn counts a message received on the connection.
+func (c *Conn) MessageIn(ctx context.Context) {
+	c.message(ctx, DirectionIn)
+}
+
+// MessageOut counts a message sent on the connection.
+func (c *Conn) MessageOut(ctx context.Context) {
+	c.message(ctx, DirectionOut)
+}
+
+// message counts a message in the given direction.
+func (c *Conn) message(ctx context.Context, direction string) {
+	meter.GetGlobalMeter().NewCounter(ConnectionMessagesMetric, "number of messages exchanged on long-lived connections", "").
+		AddTag(TagConnKind, c.tracker.kind).AddTag(TagDirection, direction).IncrOne(ctx)
+}
+
+// Close accounts the end of the connection and records its lifetime, only the first call has an effect.
+func (c *Conn) Close(ctx context.Context) {
+	if !atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		return
+	}
+	atomic.AddInt64(&c.tracker.active, -1)
+	meter.GetGlobalMeter().NewHistogramWithBuckets(ConnectionLifetimeMetric, "lifetime of closed long-lived connections",
+		"s", ConnectionLifetimeBoundaries).AddTag(TagConnKind, c.tracker.kind).UpdateSine(ctx, c.start)
+}
@@ -0,0 +1,93 @@
+package components
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/liangweijiang/go-metric/pkg/metertest/fixture"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnTracker(t *testing.T) {
+	tests := []struct {
+		name       string
+		opens      int
+		closes     int
+		closeTwice bool
+		in, out    int
+		wantActive int64
+		expected   []string
+	}{
+		{
+			name:       "Open",
+			opens:      2,
+			wantActive: 2,
+			expected:   []string{`connections_active{kind="websocket"} 2`},
+		},
+		{
+			name:       "Closed",
+			opens:      2,
+			closes:     1,
+			wantActive: 1,
+			expected: []string{
+				`connections_active{kind="websocket"} 1`,
+				`connection_lifetime_seconds_bucket{kind="websocket",le="60"} 0`,
+				`connection_lifetime_seconds_bucket{kind="websocket",le="300"} 1`,
+			},
+		},
+		{
+			name:       "ClosedTwice",
+			opens:      1,
+			closes:     1,
+			closeTwice: true,
+			expected: []string{
+				`connections_active{kind="websocket"} 0`,
+				`connection_lifetime_seconds_count{kind="websocket"} 1`,
+			},
+		},
+		{
+			name:       "Messages",
+			opens:      1,
+			in:         2,
+			out:        1,
+			wantActive: 1,
+			expected: []string{
+				`connection_messages_total{direction="in",kind="websocket"} 2`,
+				`connection_messages_total{direction="out",kind="websocket"} 1`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := clock.NewFake(time.Now())
+			m := fixture.Global(t, meter.WithClock(fake))
+			ctx := context.Background()
+
+			tracker := NewConnTracker("websocket")
+			var conns []*Conn
+			for i := 0; i < tt.opens; i++ {
+				conns = append(conns, tracker.Open(ctx))
+			}
+			for i := 0; i < tt.in; i++ {
+				conns[0].MessageIn(ctx)
+			}
+			for i := 0; i < tt.out; i++ {
+				conns[0].MessageOut(ctx)
+			}
+			fake.Advance(90 * time.Second)
+			for _, conn := range conns[:tt.closes] {
+				conn.Close(ctx)
+				if tt.closeTwice {
+					conn.Close(ctx)
+				}
+			}
+
+			assert.Equal(t, tt.wantActive, tracker.Active())
+			metertest.ScrapeAndAssert(t, m.GetHandler(), tt.expected...)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/semconv"
+	"net/http"
+)
+
+// Names and tags of the metrics recorded by TrackDeadline.
+// DeadlineRemainingMetric is a histogram, in seconds, of the time left before the deadline when an operation completes;
+// ContextErrorsMetric counts the operations whose context ended, tagged with TagReason ReasonTimeout or ReasonCanceled.
+const (
+	DeadlineRemainingMetric = "context_deadline_remaining"
+	ContextErrorsMetric     = "context_errors"
+	TagOperation            = semconv.Operation
+	TagReason               = "reason"
+	ReasonTimeout           = "timeout"
+	ReasonCanceled          = "canceled"
+)
+
+// TrackDeadline starts tracking an operation bound to ctx and returns the function to call when it completes,
+// typically `defer components.TrackDeadline(ctx, "load_user")()`.
+// On completion it records the time left before the deadline of ctx, if it has one, and counts the context
+// timeout or cancellation, if ctx is done.
+func TrackDeadline(ctx context.Context, operation string) func() {
+	return func() {
+		m := meter.GetGlobalMeter()
+		if deadline, ok := ctx.Deadline(); ok {
+			remaining := deadline.Sub(now())
+			if remaining < 0 {
+				remaining = 0
+			}
+			m.NewHistogramWithBuckets(DeadlineRemainingMetric, "time left before the context deadline on completion",
+				"s", config.BucketsHTTPServer).AddTag(TagOperation, operation).Update(context.WithoutCancel(ctx), remaining)
+		}
+		ObserveContextError(ctx, operation, ctx.Err())
+	}
+}
+
+// ObserveContextError counts err if it is a context timeout or cancellation, e.g. the error returned by a client call.
+// Other errors, and nil, are ignored.
+func ObserveContextError(ctx context.Context, operation string, err error) {
+	var reason string
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		reason = ReasonTimeout
+	case errors.Is(err, context.Canceled):
+		reason = ReasonCanceled
+	default:
+		return
+	}
+	// the context of the operation is done, record with a context that is not.
+	meter.GetGlobalMeter().NewCounter(ContextErrorsMetric, "number of operations whose context timed out or was canceled", "").
+		AddTag(TagOperation, operation).AddTag(TagReason, reason).IncrOne(context.WithoutCancel(ctx))
+}
+
+// DeadlineMetrics is a middleware tracking the deadline of the request context with TrackDeadline,
+// the operation being the route of the request.
+func DeadlineMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			TrackDeadline(r.Context(), route(r))()
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,96 @@
+package components
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/liangweijiang/go-metric/pkg/metertest/fixture"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackDeadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      func() (context.Context, context.CancelFunc)
+		expected []string
+		absent   string
+	}{
+		{
+			name: "WithinDeadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), time.Hour)
+			},
+			expected: []string{`context_deadline_remaining_seconds_bucket{operation="load_user",le="10"} 0`},
+			absent:   ContextErrorsMetric,
+		},
+		{
+			name: "TimedOut",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			},
+			expected: []string{
+				`context_deadline_remaining_seconds_bucket{operation="load_user",le="0.005"} 1`,
+				`context_errors_total{operation="load_user",reason="timeout"} 1`,
+			},
+		},
+		{
+			name: "Canceled",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			expected: []string{`context_errors_total{operation="load_user",reason="canceled"} 1`},
+			absent:   DeadlineRemainingMetric,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := fixture.Global(t)
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			TrackDeadline(ctx, "load_user")()
+
+			metertest.ScrapeAndAssert(t, m.GetHandler(), tt.expected...)
+			if tt.absent != "" {
+				assert.NotContains(t, metertest.Snapshot(t, m.GetHandler()), tt.absent)
+			}
+		})
+	}
+}
+
+func TestTrackDeadlineClock(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	m := fixture.Global(t, meter.WithClock(fake))
+	ctx, cancel := context.WithDeadline(context.Background(), fake.Now().Add(time.Hour))
+	defer cancel()
+
+	done := TrackDeadline(ctx, "load_user")
+	fake.Advance(time.Hour - 300*time.Millisecond)
+	done()
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`context_deadline_remaining_seconds_bucket{operation="load_user",le="0.25"} 0`,
+		`context_deadline_remaining_seconds_bucket{operation="load_user",le="0.5"} 1`)
+}
+
+func TestDeadlineMetrics(t *testing.T) {
+	m := fixture.Global(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders/{id}", func(http.ResponseWriter, *http.Request) {})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	DeadlineMetrics(mux).ServeHTTP(httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/orders/42", nil).WithContext(ctx))
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`context_deadline_remaining_seconds_count{operation="GET /orders/{id}"} 1`)
+}
@@ -0,0 +1,150 @@
+package components
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"net"
+	"strings"
+	"time"
+)
+
+// Names and tags of the metrics recorded by Dialer and Resolver, all tagged with TagHostClass.
+// The durations, in seconds, are tagged with TagOutcome, OutcomeSuccess or OutcomeError.
+const (
+	DNSLookupDurationMetric = "dns_lookup_duration"
+	DNSLookupFailuresMetric = "dns_lookup_failures"
+	DialDurationMetric      = "dial_duration"
+	DialFailuresMetric      = "dial_failures"
+	TagHostClass            = "host_class"
+	TagNetwork              = "network"
+	TagOutcome              = meter.TagOutcome
+	OutcomeSuccess          = meter.OutcomeSuccess
+	OutcomeError            = meter.OutcomeError
+)
+
+// Host classes returned by DefaultHostClass.
+const (
+	HostClassLoopback = "loopback"
+	HostClassPrivate  = "private"
+	HostClassInternal = "internal"
+	HostClassExternal = "external"
+)
+
+// HostClassifier maps a target host to a class of bounded cardinality, used as the host_class tag.
+type HostClassifier func(host string) string
+
+// internalSuffixes are the domain suffixes resolved inside the cluster or the company network.
+var internalSuffixes = []string{".svc", ".cluster.local", ".internal", ".local", ".lan", ".consul"}
+
+// DefaultHostClass classifies loopback hosts, private IPs, cluster or company internal names (single label names and
+// names under .svc, .cluster.local, .internal, .local, .lan or .consul) and the remaining external hosts.
+func DefaultHostClass(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" {
+		return HostClassLoopback
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		switch {
+		case ip.IsLoopback():
+			return HostClassLoopback
+		case ip.IsPrivate(), ip.IsLinkLocalUnicast():
+			return HostClassPrivate
+		default:
+			return HostClassExternal
+		}
+	}
+	if !strings.Contains(host, ".") {
+		return HostClassInternal
+	}
+	for _, suffix := range internalSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return HostClassInternal
+		}
+	}
+	return HostClassExternal
+}
+
+// Dialer wraps a net.Dialer, recording the dial latency and failures per host class.
+// Its DialContext method fits http.Transport.DialContext.
+type Dialer struct {
+	dialer   *net.Dialer
+	classify HostClassifier
+}
+
+// NewDialer wraps d, a zero net.Dialer if nil, classifying hosts with classify, DefaultHostClass if nil.
+func NewDialer(d *net.Dialer, classify HostClassifier) *Dialer {
+	if d == nil {
+		d = &net.Dialer{}
+	}
+	if classify == nil {
+		classify = DefaultHostClass
+	}
+	return &Dialer{
+		dialer:   d,
+		classify: classify,
+	}
+}
+
+// DialContext connects to address on the named network, recording the latency and the failure if any.
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	start := now()
+	conn, err := d.dialer.DialContext(ctx, network, address)
+	host, _, splitErr := net.SplitHostPort(address)
+	if splitErr != nil {
+		host = address
+	}
+	tags := map[string]string{TagHostClass: d.classify(host), TagNetwork: network}
+	observeNetCall(ctx, DialDurationMetric, DialFailuresMetric, start, err, tags)
+	return conn, err
+}
+
+// Resolver wraps a net.Resolver, recording the lookup latency and failures per host class.
+type Resolver struct {
+	resolver *net.Resolver
+	classify HostClassifier
+}
+
+// NewResolver wraps r, net.DefaultResolver if nil, classifying hosts with classify, DefaultHostClass if nil.
+func NewResolver(r *net.Resolver, classify HostClassifier) *Resolver {
+	if r == nil {
+		r = net.DefaultResolver
+	}
+	if classify == nil {
+		classify = DefaultHostClass
+	}
+	return &Resolver{
+		resolver: r,
+		classify: classify,
+	}
+}
+
+// LookupHost looks up the addresses of host, recording the latency and the failure if any.
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	start := now()
+	addrs, err := r.resolver.LookupHost(ctx, host)
+	observeNetCall(ctx, DNSLookupDurationMetric, DNSLookupFailuresMetric, start, err,
+		map[string]string{TagHostClass: r.classify(host)})
+	return addrs, err
+}
+
+// LookupIPAddr looks up the IP addresses of host, recording the latency and the failure if any.
+func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	start := now()
+	addrs, err := r.resolver.LookupIPAddr(ctx, host)
+	observeNetCall(ctx, DNSLookupDurationMetric, DNSLookupFailuresMetric, start, err,
+		map[string]string{TagHostClass: r.classify(host)})
+	return addrs, err
+}
+
+// observeNetCall records the duration of a call started at start with its outcome, and counts its failure.
+func observeNetCall(ctx context.Context, durationMetric, failuresMetric string, start time.Time, err error, tags map[string]string) {
+	m := meter.GetGlobalMeter()
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+		m.NewCounter(failuresMetric, "number of failed network calls", "").WithTags(tags).IncrOne(ctx)
+	}
+	m.NewHistogramWithBuckets(durationMetric, "duration of network calls", "s", config.BucketsDB).
+		WithTags(tags).AddTag(TagOutcome, outcome).UpdateSine(ctx, start)
+}
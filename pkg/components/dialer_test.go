@@ -0,0 +1,137 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/liangweijiang/go-metric/pkg/metertest/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultHostClass(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "localhost", want: HostClassLoopback},
+		{host: "127.0.0.1", want: HostClassLoopback},
+		{host: "::1", want: HostClassLoopback},
+		{host: "10.0.0.7", want: HostClassPrivate},
+		{host: "169.254.1.1", want: HostClassPrivate},
+		{host: "8.8.8.8", want: HostClassExternal},
+		{host: "redis", want: HostClassInternal},
+		{host: "orders.default.svc.cluster.local", want: HostClassInternal},
+		{host: "Vault.Consul.", want: HostClassInternal},
+		{host: "api.example.com", want: HostClassExternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, tt.want, DefaultHostClass(tt.host))
+		})
+	}
+}
+
+func TestDialer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	refused := closed.Addr().String()
+	_ = closed.Close()
+
+	tests := []struct {
+		name     string
+		address  string
+		wantErr  bool
+		expected []string
+	}{
+		{
+			name:    "Success",
+			address: l.Addr().String(),
+			expected: []string{
+				`dial_duration_seconds_count{host_class="loopback",network="tcp",outcome="success"} 1`,
+			},
+		},
+		{
+			name:    "Refused",
+			address: refused,
+			wantErr: true,
+			expected: []string{
+				`dial_duration_seconds_count{host_class="loopback",network="tcp",outcome="error"} 1`,
+				`dial_failures_total{host_class="loopback",network="tcp"} 1`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := fixture.Global(t)
+
+			conn, err := NewDialer(nil, nil).DialContext(context.Background(), "tcp", tt.address)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				_ = conn.Close()
+			}
+
+			metertest.ScrapeAndAssert(t, m.GetHandler(), tt.expected...)
+		})
+	}
+}
+
+func TestResolver(t *testing.T) {
+	// the resolver fails every query sent to a DNS server, the IP literals are resolved without query.
+	offline := &net.Resolver{
+		PreferGo: true,
+		Dial: func(context.Context, string, string) (net.Conn, error) {
+			return nil, errors.New("offline")
+		},
+	}
+	tests := []struct {
+		name     string
+		host     string
+		wantErr  bool
+		expected []string
+	}{
+		{
+			name: "Success",
+			host: "10.0.0.7",
+			expected: []string{
+				`dns_lookup_duration_seconds_count{host_class="private",outcome="success"} 2`,
+			},
+		},
+		{
+			name:    "Failure",
+			host:    "api.example.com",
+			wantErr: true,
+			expected: []string{
+				`dns_lookup_duration_seconds_count{host_class="external",outcome="error"} 2`,
+				`dns_lookup_failures_total{host_class="external"} 2`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := fixture.Global(t)
+			resolver := NewResolver(offline, nil)
+			ctx := context.Background()
+
+			_, hostErr := resolver.LookupHost(ctx, tt.host)
+			_, ipErr := resolver.LookupIPAddr(ctx, tt.host)
+			if tt.wantErr {
+				assert.Error(t, hostErr)
+				assert.Error(t, ipErr)
+			} else {
+				assert.NoError(t, hostErr)
+				assert.NoError(t, ipErr)
+			}
+
+			metertest.ScrapeAndAssert(t, m.GetHandler(), tt.expected...)
+		})
+	}
+}
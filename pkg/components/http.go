@@ -0,0 +1,89 @@
+package components
+
+import (
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/semconv"
+	"net/http"
+	"strconv"
+)
+
+// Names and tags of the metrics recorded by RequestMetrics.
+const (
+	HTTPServerRequestsMetric = "http_server_requests"
+	HTTPServerDurationMetric = "http_server_duration"
+	TagRoute                 = semconv.Route
+	TagMethod                = semconv.Method
+	TagStatus                = semconv.Status
+)
+
+// UnknownRoute is the route tag of the requests not served by a http.ServeMux, whose URL paths would make the
+// cardinality of the metrics unbounded.
+const UnknownRoute = "unknown"
+
+// route returns the pattern matched by http.ServeMux for r, UnknownRoute if none.
+func route(r *http.Request) string {
+	if r.Pattern == "" {
+		return UnknownRoute
+	}
+	return r.Pattern
+}
+
+// statusWriter captures the status code written by a handler.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code before writing it.
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records an implicit 200 status if none was written.
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Status returns the written status code, 200 if the handler wrote nothing.
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// RequestMetrics is a middleware injecting a Recorder in the request context. When the request completes,
+// it counts the request, records its duration and flushes the Recorder, all with the same route, method and status tags
+// plus the tags set on the Recorder by the handler. The route is the pattern matched by http.ServeMux,
+// or UnknownRoute when the handler is not served by a ServeMux.
+func RequestMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := now()
+		recorder := NewRecorder()
+		sw := &statusWriter{ResponseWriter: w}
+		r = r.WithContext(ContextWithRecorder(r.Context(), recorder))
+		next.ServeHTTP(sw, r)
+
+		recorder.SetTag(TagRoute, route(r))
+		recorder.SetTag(TagMethod, r.Method)
+		recorder.SetTag(TagStatus, strconv.Itoa(sw.Status()))
+		recorder.Incr(HTTPServerRequestsMetric, 1)
+
+		m := meter.GetGlobalMeter()
+		recorder.Flush(r.Context(), m)
+		m.NewHistogramWithBuckets(HTTPServerDurationMetric, "duration of the handled http requests", "s", config.BucketsHTTPServer).
+			WithTags(recorder.Tags()).UpdateSine(r.Context(), start)
+	})
+}
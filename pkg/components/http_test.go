@@ -0,0 +1,54 @@
+package components
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/liangweijiang/go-metric/pkg/metertest/fixture"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  func(next http.Handler) http.Handler
+		target   string
+		expected []string
+	}{
+		{
+			name: "ServeMux",
+			handler: func(next http.Handler) http.Handler {
+				mux := http.NewServeMux()
+				mux.Handle("GET /orders/{id}", next)
+				return mux
+			},
+			target: "/orders/42",
+			expected: []string{
+				`http_server_requests_total{method="GET",route="GET /orders/{id}",status="201",tier="gold"} 1`,
+				`http_server_duration_seconds_count{method="GET",route="GET /orders/{id}",status="201",tier="gold"} 1`,
+			},
+		},
+		{
+			name:    "WithoutServeMux",
+			handler: func(next http.Handler) http.Handler { return next },
+			target:  "/orders/42",
+			expected: []string{
+				`http_server_requests_total{method="GET",route="unknown",status="201",tier="gold"} 1`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := fixture.Global(t)
+
+			h := RequestMetrics(tt.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				RecorderFromContext(r.Context()).SetTag("tier", "gold")
+				w.WriteHeader(http.StatusCreated)
+			})))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			metertest.ScrapeAndAssert(t, m.GetHandler(), tt.expected...)
+		})
+	}
+}
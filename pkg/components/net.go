@@ -0,0 +1,143 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"github.com/liangweijiang/go-metric/meter"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// Names and tags of the metrics recorded by the net wrappers, all tagged with TagListener.
+// NetBytesMetric is tagged with TagDirection, NetErrorsMetric with TagNetOp (accept, read, write or close).
+const (
+	NetConnectionsAcceptedMetric = "net_connections_accepted"
+	NetConnectionDurationMetric  = "net_connection_duration"
+	NetBytesMetric               = "net_bytes"
+	NetErrorsMetric              = "net_errors"
+	TagListener                  = "listener"
+	TagNetOp                     = "op"
+)
+
+// WrapListener returns a net.Listener counting the accepted connections and accept errors of l under the listener tag name.
+// The accepted connections are wrapped with WrapConn.
+func WrapListener(l net.Listener, name string) net.Listener {
+	return &listener{
+		Listener: l,
+		name:     name,
+	}
+}
+
+// listener is the instrumented net.Listener returned by WrapListener.
+type listener struct {
+	net.Listener
+	name string
+}
+
+// Accept waits for the next connection, counts it and wraps it with WrapConn.
+func (l *listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		countNetError(l.name, "accept", err)
+		return nil, err
+	}
+	meter.GetGlobalMeter().NewCounter(NetConnectionsAcceptedMetric, "number of accepted connections", "").
+		AddTag(TagListener, l.name).IncrOne(context.Background())
+	return WrapConn(conn, l.name), nil
+}
+
+// WrapConn returns a net.Conn recording the bytes read and written, the errors and, once closed,
+// the duration of c under the listener tag name. It can wrap outbound connections as well.
+func WrapConn(c net.Conn, name string) net.Conn {
+	return &conn{
+		Conn:  c,
+		name:  name,
+		start: now(),
+	}
+}
+
+// conn is the instrumented net.Conn returned by WrapConn.
+type conn struct {
+	net.Conn
+	name   string
+	start  time.Time
+	closed int32
+}
+
+// Read reads from the connection and records the bytes read.
+func (c *conn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	countNetBytes(c.name, DirectionIn, n)
+	countNetError(c.name, "read", err)
+	return n, err
+}
+
+// Write writes to the connection and records the bytes written.
+func (c *conn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	countNetBytes(c.name, DirectionOut, n)
+	countNetError(c.name, "write", err)
+	return n, err
+}
+
+// Close closes the connection and records its duration, only the first call is recorded.
+func (c *conn) Close() error {
+	err := c.Conn.Close()
+	if atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		meter.GetGlobalMeter().NewHistogramWithBuckets(NetConnectionDurationMetric, "duration of closed connections",
+			"s", ConnectionLifetimeBoundaries).AddTag(TagListener, c.name).UpdateSine(context.Background(), c.start)
+		countNetError(c.name, "close", err)
+	}
+	return err
+}
+
+// WrapPacketConn returns a net.PacketConn, e.g. a UDP socket, recording the bytes read and written and the errors
+// of pc under the listener tag name.
+func WrapPacketConn(pc net.PacketConn, name string) net.PacketConn {
+	return &packetConn{
+		PacketConn: pc,
+		name:       name,
+	}
+}
+
+// packetConn is the instrumented net.PacketConn returned by WrapPacketConn.
+type packetConn struct {
+	net.PacketConn
+	name string
+}
+
+// ReadFrom reads a packet and records its size.
+func (c *packetConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	n, addr, err := c.PacketConn.ReadFrom(p)
+	countNetBytes(c.name, DirectionIn, n)
+	countNetError(c.name, "read", err)
+	return n, addr, err
+}
+
+// WriteTo writes a packet and records its size.
+func (c *packetConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	n, err := c.PacketConn.WriteTo(p, addr)
+	countNetBytes(c.name, DirectionOut, n)
+	countNetError(c.name, "write", err)
+	return n, err
+}
+
+// countNetBytes adds n bytes to the bytes counter in the given direction, nothing is recorded for 0 bytes.
+func countNetBytes(name, direction string, n int) {
+	if n <= 0 {
+		return
+	}
+	meter.GetGlobalMeter().NewCounter(NetBytesMetric, "number of bytes transferred on connections", "By").
+		AddTag(TagListener, name).AddTag(TagDirection, direction).Incr(context.Background(), float64(n))
+}
+
+// countNetError counts err for the given operation, the end of stream and the use of a closed connection are not errors.
+func countNetError(name, op string, err error) {
+	if err == nil || errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		return
+	}
+	meter.GetGlobalMeter().NewCounter(NetErrorsMetric, "number of connection errors", "").
+		AddTag(TagListener, name).AddTag(TagNetOp, op).IncrOne(context.Background())
+}
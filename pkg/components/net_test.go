@@ -0,0 +1,105 @@
+package components
+
+import (
+	"net"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/liangweijiang/go-metric/pkg/metertest/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapConn(t *testing.T) {
+	tests := []struct {
+		name     string
+		use      func(c, peer net.Conn)
+		expected []string
+	}{
+		{
+			name: "Write",
+			use: func(c, peer net.Conn) {
+				go func() { _, _ = peer.Read(make([]byte, 16)) }()
+				_, _ = c.Write([]byte("hello"))
+			},
+			expected: []string{`net_bytes_total{direction="out",listener="api"} 5`},
+		},
+		{
+			name: "Read",
+			use: func(c, peer net.Conn) {
+				go func() { _, _ = peer.Write([]byte("hey")) }()
+				_, _ = c.Read(make([]byte, 16))
+			},
+			expected: []string{`net_bytes_total{direction="in",listener="api"} 3`},
+		},
+		{
+			name: "WriteToClosedPeer",
+			use: func(c, peer net.Conn) {
+				_ = peer.Close()
+				_, _ = c.Write([]byte("hello"))
+			},
+			expected: []string{`net_errors_total{listener="api",op="write"} 1`},
+		},
+		{
+			name: "ClosedTwice",
+			use: func(c, _ net.Conn) {
+				_ = c.Close()
+				_ = c.Close()
+			},
+			expected: []string{`net_connection_duration_seconds_count{listener="api"} 1`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := fixture.Global(t)
+			c, peer := net.Pipe()
+			defer peer.Close()
+
+			tt.use(WrapConn(c, "api"), peer)
+
+			metertest.ScrapeAndAssert(t, m.GetHandler(), tt.expected...)
+		})
+	}
+}
+
+func TestWrapListener(t *testing.T) {
+	m := fixture.Global(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	wrapped := WrapListener(l, "api")
+	client, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+	accepted, err := wrapped.Accept()
+	require.NoError(t, err)
+	assert.IsType(t, &conn{}, accepted, "the accepted connections are wrapped")
+	_ = accepted.Close()
+	_ = wrapped.Close()
+	_, err = wrapped.Accept()
+	assert.ErrorIs(t, err, net.ErrClosed)
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`net_connections_accepted_total{listener="api"} 1`,
+		`net_connection_duration_seconds_count{listener="api"} 1`)
+	assert.NotContains(t, metertest.Snapshot(t, m.GetHandler()), NetErrorsMetric,
+		"accepting on a closed listener is not an error")
+}
+
+func TestWrapPacketConn(t *testing.T) {
+	m := fixture.Global(t)
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	wrapped := WrapPacketConn(pc, "statsd")
+	defer wrapped.Close()
+	_, err = wrapped.WriteTo([]byte("ping"), pc.LocalAddr())
+	require.NoError(t, err)
+	n, _, err := wrapped.ReadFrom(make([]byte, 16))
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`net_bytes_total{direction="out",listener="statsd"} 4`,
+		`net_bytes_total{direction="in",listener="statsd"} 4`)
+}
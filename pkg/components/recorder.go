@@ -0,0 +1,131 @@
+// Package components provides middlewares and helpers instrumenting common components with the global meter.
+package components
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// recorderKey is the context key of the Recorder injected by RequestMetrics.
+type recorderKey struct{}
+
+// Recorder accumulates the measurements made while handling a request and records them once, at completion,
+// with tags shared by all of them (route, method, status...). It avoids repeating the tags at every call site
+// and counting the same request twice. A Recorder is safe for concurrent use.
+type Recorder struct {
+	mu       sync.Mutex
+	tags     map[string]string
+	counters map[string]float64
+	observed map[string][]float64
+	flushed  bool
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		tags:     make(map[string]string),
+		counters: make(map[string]float64),
+		observed: make(map[string][]float64),
+	}
+}
+
+// ContextWithRecorder returns a copy of ctx carrying r.
+func ContextWithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// RecorderFromContext returns the Recorder carried by ctx, or nil if there is none.
+// All the methods of Recorder accept a nil receiver and do nothing, so callers don't need to check the result.
+func RecorderFromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// SetTag sets a tag shared by all the measurements of the recorder.
+func (r *Recorder) SetTag(key, value string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tags[key] = value
+}
+
+// Incr adds delta to the counter metricName, the counter is recorded once with the sum at completion.
+func (r *Recorder) Incr(metricName string, delta float64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[metricName] += delta
+}
+
+// Observe adds a duration to the histogram metricName, in seconds.
+func (r *Recorder) Observe(metricName string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observed[metricName] = append(r.observed[metricName], d.Seconds())
+}
+
+// Tags returns a copy of the shared tags.
+func (r *Recorder) Tags() map[string]string {
+	tags := make(map[string]string)
+	if r == nil {
+		return tags
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, v := range r.tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+// Flush records the accumulated measurements to m with the shared tags, as they are when it is called. Only the first
+// call records, the following ones do nothing, so a request is never counted twice.
+func (r *Recorder) Flush(ctx context.Context, m interfaces.Meter) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	if r.flushed {
+		r.mu.Unlock()
+		return
+	}
+	r.flushed = true
+	tags := maps.Clone(r.tags)
+	counters := maps.Clone(r.counters)
+	observed := make(map[string][]float64, len(r.observed))
+	for name, values := range r.observed {
+		observed[name] = slices.Clone(values)
+	}
+	r.mu.Unlock()
+
+	for _, name := range sortedKeys(counters) {
+		m.NewCounter(name, "", "").WithTags(tags).Incr(ctx, counters[name])
+	}
+	for _, name := range sortedKeys(observed) {
+		for _, v := range observed[name] {
+			m.NewHistogram(name, "", "s").WithTags(tags).UpdateInSeconds(ctx, v)
+		}
+	}
+}
+
+// sortedKeys returns the keys of m in lexical order, so measurements are recorded in a deterministic order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,77 @@
+package components
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	m, err := meter.NewMeter(meter.WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	r := NewRecorder()
+	r.SetTag("route", "/pay")
+	r.Incr("payments", 2)
+	r.Incr("payments", 1)
+	r.Observe("payment_latency", 250*time.Millisecond)
+	r.Flush(ctx, m)
+	r.Incr("payments", 5)
+	r.Flush(ctx, m)
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`payments_total{route="/pay"} 3`,
+		`payment_latency_seconds_sum{route="/pay"} 0.25`,
+	)
+	assert.Equal(t, map[string]string{"route": "/pay"}, r.Tags())
+
+	var nilRecorder *Recorder
+	assert.NotPanics(t, func() {
+		nilRecorder.Incr("payments", 1)
+		nilRecorder.Flush(ctx, m)
+	})
+}
+
+func TestRecorderConcurrentFlush(t *testing.T) {
+	m, err := meter.NewMeter(meter.WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+	ctx := context.Background()
+	r := NewRecorder()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Incr("jobs", 1)
+				r.Observe("job_latency", time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+	// the recorder is flushed by several goroutines at once, the measurements are recorded exactly once.
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Flush(ctx, m)
+		}()
+	}
+	wg.Wait()
+	r.Incr("jobs", 1)
+	r.Flush(ctx, m)
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`jobs_total 800`,
+		`job_latency_seconds_count 800`,
+	)
+}
@@ -0,0 +1,103 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/liangweijiang/go-metric/meter"
+	"net/http"
+)
+
+// PanicsMetric is the counter incremented for every recovered panic, exported as panics_total and tagged with TagHandler.
+const (
+	PanicsMetric = "panics"
+	TagHandler   = "handler"
+)
+
+// ErrPanic is wrapped by the error returned by a function wrapped with WrapFunc when it panicked and the panic was swallowed.
+var ErrPanic = errors.New("recovered panic")
+
+// recoverConfig holds the behavior of RecoverAndCount and WrapFunc.
+type recoverConfig struct {
+	handler string
+	repanic bool
+}
+
+// RecoverOption configures RecoverAndCount and WrapFunc.
+type RecoverOption func(cfg *recoverConfig)
+
+// WithHandlerName sets the value of the handler tag, by default the route of the request for RecoverAndCount
+// and the name given to WrapFunc.
+func WithHandlerName(name string) RecoverOption {
+	return func(cfg *recoverConfig) {
+		cfg.handler = name
+	}
+}
+
+// WithRepanic makes the recovered panic be raised again once counted, instead of being swallowed.
+func WithRepanic() RecoverOption {
+	return func(cfg *recoverConfig) {
+		cfg.repanic = true
+	}
+}
+
+// newRecoverConfig applies opts to the default configuration: panics are swallowed and tagged with handler.
+func newRecoverConfig(handler string, opts []RecoverOption) *recoverConfig {
+	cfg := &recoverConfig{handler: handler}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// countPanic increments the panics counter of the global meter for the given handler.
+func countPanic(ctx context.Context, handler string) {
+	meter.GetGlobalMeter().NewCounter(PanicsMetric, "number of recovered panics", "").
+		AddTag(TagHandler, handler).IncrOne(ctx)
+}
+
+// RecoverAndCount is a middleware recovering the panics of next and counting them in the panics counter.
+// A swallowed panic answers 500 Internal Server Error if nothing was written yet,
+// with WithRepanic the panic is raised again and handled by the server as usual.
+func RecoverAndCount(next http.Handler, opts ...RecoverOption) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			cfg := newRecoverConfig(route(r), opts)
+			countPanic(r.Context(), cfg.handler)
+			// http.ErrAbortHandler is the sentinel used to abort a response on purpose, it must reach the server.
+			if cfg.repanic || v == http.ErrAbortHandler {
+				panic(v)
+			}
+			if sw.status == 0 {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(sw, r)
+	})
+}
+
+// WrapFunc returns a function calling f, recovering and counting its panics under the handler tag name.
+// A swallowed panic makes the function return the zero value of T and an error wrapping ErrPanic.
+func WrapFunc[T any](name string, f func(ctx context.Context) (T, error), opts ...RecoverOption) func(ctx context.Context) (T, error) {
+	cfg := newRecoverConfig(name, opts)
+	return func(ctx context.Context) (result T, err error) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			countPanic(ctx, cfg.handler)
+			if cfg.repanic {
+				panic(v)
+			}
+			var zero T
+			result, err = zero, fmt.Errorf("%w in %s: %v", ErrPanic, cfg.handler, v)
+		}()
+		return f(ctx)
+	}
+}
@@ -0,0 +1,65 @@
+package components
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/liangweijiang/go-metric/pkg/metertest/fixture"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapFunc(t *testing.T) {
+	m := fixture.Global(t)
+	f := WrapFunc("load_user", func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+	got, err := f(context.Background())
+	assert.Equal(t, 0, got)
+	assert.ErrorIs(t, err, ErrPanic)
+
+	repanic := WrapFunc("load_user", func(ctx context.Context) (int, error) {
+		panic("boom")
+	}, WithRepanic())
+	assert.PanicsWithValue(t, "boom", func() { _, _ = repanic(context.Background()) })
+
+	ok := WrapFunc("load_user", func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	got, err = ok(context.Background())
+	assert.Equal(t, 42, got)
+	assert.NoError(t, err)
+
+	renamed := WrapFunc("load_user", func(ctx context.Context) (int, error) {
+		panic("boom")
+	}, WithHandlerName("users"))
+	_, _ = renamed(context.Background())
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`panics_total{handler="load_user"} 2`,
+		`panics_total{handler="users"} 1`)
+}
+
+func TestRecoverAndCount(t *testing.T) {
+	m := fixture.Global(t)
+	mux := http.NewServeMux()
+	mux.Handle("GET /pay", RecoverAndCount(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pay", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	h := RecoverAndCount(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil))
+	}, "the aborted responses reach the server")
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`panics_total{handler="GET /pay"} 1`,
+		`panics_total{handler="`+UnknownRoute+`"} 1`)
+}
@@ -0,0 +1,54 @@
+package components
+
+import (
+	"github.com/liangweijiang/go-metric/internal/component"
+	"github.com/liangweijiang/go-metric/internal/metrics/nop"
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+)
+
+// MissingTagValue is the value of the predefined tags of a component given no value.
+const MissingTagValue = component.MissingTagValue
+
+// globalMeter returns the global meter, to which the component instruments of this package record.
+func globalMeter() interfaces.BaseMeter {
+	return meter.GetGlobalMeter()
+}
+
+// ValidateSpec checks that spec follows the naming policy of the components, it is meant to be called by the tests
+// of the packages declaring specs.
+func ValidateSpec(spec interfaces.ComponentSpec) error {
+	return component.Validate(spec)
+}
+
+// Histogram returns the histogram of spec recorded to the global meter with the values of the predefined tags of
+// spec, in order, e.g.
+//
+//	h, err := components.Histogram(spec, "GET", "/pay")
+//
+// The metric name and the tag keys are fixed by spec: the histogram has no method adding other dimensions. An invalid
+// spec, see ValidateSpec, yields the error and a histogram recording nothing.
+func Histogram(spec interfaces.ComponentSpec, values ...string) (interfaces.ComponentHistogram, error) {
+	if err := component.Validate(spec); err != nil {
+		return nop.ComponentHistogram, err
+	}
+	return component.NewHistogram(globalMeter, spec, values...), nil
+}
+
+// Counter returns the counter of spec recorded to the global meter with the values of the predefined tags of spec.
+// An invalid spec yields the error and a counter recording nothing.
+func Counter(spec interfaces.ComponentSpec, values ...string) (interfaces.ComponentCounter, error) {
+	if err := component.Validate(spec); err != nil {
+		return nop.Counter, err
+	}
+	return component.NewCounter(globalMeter, spec, values...), nil
+}
+
+// UpDownCounter returns the up-down counter of spec recorded to the global meter with the values of the predefined
+// tags of spec. An invalid spec yields the error and an up-down counter recording nothing.
+func UpDownCounter(spec interfaces.ComponentSpec, values ...string) (interfaces.ComponentUpDownCounter, error) {
+	if err := component.Validate(spec); err != nil {
+		return nop.UpDownCounter, err
+	}
+	return component.NewUpDownCounter(globalMeter, spec, values...), nil
+}
@@ -0,0 +1,42 @@
+package components
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/internal/component"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecInstruments(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    interfaces.ComponentSpec
+		wantErr bool
+	}{
+		{name: "valid", spec: interfaces.ComponentSpec{Name: "jobs", Tags: []string{"queue"}}},
+		{name: "reserved suffix", spec: interfaces.ComponentSpec{Name: "jobs_total"}, wantErr: true},
+		{name: "duplicate tag", spec: interfaces.ComponentSpec{Name: "jobs", Tags: []string{"queue", "queue"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, errHistogram := Histogram(tt.spec, "default")
+			c, errCounter := Counter(tt.spec, "default")
+			u, errUpDown := UpDownCounter(tt.spec, "default")
+			for _, err := range []error{errHistogram, errCounter, errUpDown} {
+				if tt.wantErr {
+					assert.ErrorIs(t, err, component.ErrInvalidSpec)
+				} else {
+					assert.NoError(t, err)
+				}
+			}
+			assert.NotNil(t, h)
+			assert.NotNil(t, c)
+			assert.NotNil(t, u)
+			h.UpdateInSeconds(context.Background())
+			c.IncrOne(context.Background())
+			u.IncrOne(context.Background())
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package components
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"io"
+	"log"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Names and tags of the TLS metrics.
+// TLSCertificateExpiryMetric is an observable gauge of the seconds left before the expiry of every watched certificate,
+// negative once expired, tagged with TagCertSource and TagCertSubject.
+// TLSHandshakeErrorsMetric counts the failed handshakes, tagged with TagEndpoint and TagReason.
+const (
+	TLSCertificateExpiryMetric = "tls_certificate_expiry"
+	TLSHandshakeErrorsMetric   = "tls_handshake_errors"
+	TagCertSource              = "source"
+	TagCertSubject             = "subject"
+	TagEndpoint                = "endpoint"
+)
+
+// Reasons of the failed handshakes counted by CountTLSHandshakeError.
+const (
+	ReasonCertExpired       = "certificate_expired"
+	ReasonUnknownAuthority  = "unknown_authority"
+	ReasonHostnameMismatch  = "hostname_mismatch"
+	ReasonBadCertificate    = "bad_certificate"
+	ReasonProtocolMismatch  = "protocol_mismatch"
+	ReasonConnectionAborted = "connection_aborted"
+	ReasonOther             = "other"
+)
+
+// CertExpiryCollector exports the seconds left before the expiry of certificates read from PEM files or tls.Config.
+// Files are read again at every collection, so that rotated certificates are picked up.
+type CertExpiryCollector struct {
+	mu      sync.Mutex
+	files   []string
+	configs map[string]*tls.Config
+}
+
+// NewCertExpiryCollector creates a collector of the certificates in the given PEM files, more can be added
+// with AddFile and AddConfig before calling Register.
+func NewCertExpiryCollector(files ...string) *CertExpiryCollector {
+	return &CertExpiryCollector{
+		files:   files,
+		configs: make(map[string]*tls.Config),
+	}
+}
+
+// AddFile watches the certificates of a PEM file, tagged with the path as source.
+func (c *CertExpiryCollector) AddFile(path string) *CertExpiryCollector {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files = append(c.files, path)
+	return c
+}
+
+// AddConfig watches the leaf certificates of cfg, tagged with name as source.
+func (c *CertExpiryCollector) AddConfig(name string, cfg *tls.Config) *CertExpiryCollector {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.configs[name] = cfg
+	return c
+}
+
+// Register registers the expiry gauge to the global meter, the returned Registration stops the collection.
+func (c *CertExpiryCollector) Register() interfaces.Registration {
+	return meter.GetGlobalMeter().NewObservableGauge(TLSCertificateExpiryMetric,
+		"seconds left before the expiry of the certificate", "s", c.observe)
+}
+
+// observe reports the expiry of every watched certificate, the files that cannot be read are reported as an error.
+func (c *CertExpiryCollector) observe(_ context.Context, o interfaces.Observer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	at := now()
+	report := func(source string, cert *x509.Certificate) {
+		o.Observe(cert.NotAfter.Sub(at).Seconds(), map[string]string{
+			TagCertSource:  source,
+			TagCertSubject: certSubject(cert),
+		})
+	}
+	var errs []error
+	for _, path := range c.files {
+		certs, err := readCertificates(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, cert := range certs {
+			report(path, cert)
+		}
+	}
+	for name, cfg := range c.configs {
+		for _, pair := range cfg.Certificates {
+			cert, err := leafCertificate(pair)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("certificate of %s: %w", name, err))
+				continue
+			}
+			report(name, cert)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// readCertificates parses all the certificates of a PEM file.
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("certificate of %s: %w", path, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate in %s", path)
+	}
+	return certs, nil
+}
+
+// leafCertificate returns the parsed leaf of a certificate chain.
+func leafCertificate(pair tls.Certificate) (*x509.Certificate, error) {
+	if pair.Leaf != nil {
+		return pair.Leaf, nil
+	}
+	if len(pair.Certificate) == 0 {
+		return nil, errors.New("empty certificate chain")
+	}
+	return x509.ParseCertificate(pair.Certificate[0])
+}
+
+// certSubject returns the common name of the certificate, or its full subject when it has none.
+func certSubject(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	return cert.Subject.String()
+}
+
+// CountTLSHandshakeError counts err as a failed handshake of the given endpoint, e.g. the error returned by
+// tls.Conn.HandshakeContext or by a client request. Nil is ignored.
+func CountTLSHandshakeError(ctx context.Context, endpoint string, err error) {
+	if err == nil {
+		return
+	}
+	countTLSHandshakeError(ctx, endpoint, tlsErrorReason(err))
+}
+
+// countTLSHandshakeError increments the handshake errors counter of the global meter.
+func countTLSHandshakeError(ctx context.Context, endpoint, reason string) {
+	meter.GetGlobalMeter().NewCounter(TLSHandshakeErrorsMetric, "number of failed TLS handshakes", "").
+		AddTag(TagEndpoint, endpoint).AddTag(TagReason, reason).IncrOne(ctx)
+}
+
+// TLS alerts, RFC 8446 section 6, classified by alertReason.
+const (
+	alertHandshakeFailure       = 40
+	alertBadCertificate         = 42
+	alertUnsupportedCertificate = 43
+	alertCertificateExpired     = 45
+	alertUnknownCA              = 48
+	alertProtocolVersion        = 70
+	alertInsufficientSecurity   = 71
+	alertCertificateRequired    = 116
+)
+
+// tlsErrorReason classifies a handshake error from its type: the certificate verification errors of crypto/x509,
+// the alerts sent or received and the records of another protocol of crypto/tls, and the connections closed early.
+func tlsErrorReason(err error) string {
+	var invalid x509.CertificateInvalidError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var alertErr tls.AlertError
+	var recordHeader tls.RecordHeaderError
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+		return ReasonCertExpired
+	case errors.As(err, &unknownAuthority):
+		return ReasonUnknownAuthority
+	case errors.As(err, &hostname):
+		return ReasonHostnameMismatch
+	case errors.As(err, &alertErr):
+		return alertReason(uint8(alertErr))
+	case errors.As(err, &opErr) && (opErr.Op == "remote error" || opErr.Op == "local error") &&
+		reflect.ValueOf(opErr.Err).Kind() == reflect.Uint8:
+		// crypto/tls reports the alerts of TCP connections as net.OpError wrapping its unexported alert type, a uint8.
+		return alertReason(uint8(reflect.ValueOf(opErr.Err).Uint()))
+	case errors.As(err, &recordHeader):
+		return ReasonProtocolMismatch
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE):
+		return ReasonConnectionAborted
+	default:
+		return ReasonOther
+	}
+}
+
+// alertReason classifies a TLS alert.
+func alertReason(alert uint8) string {
+	switch alert {
+	case alertCertificateExpired:
+		return ReasonCertExpired
+	case alertUnknownCA:
+		return ReasonUnknownAuthority
+	case alertBadCertificate, alertUnsupportedCertificate, alertCertificateRequired:
+		return ReasonBadCertificate
+	case alertHandshakeFailure, alertProtocolVersion, alertInsufficientSecurity:
+		return ReasonProtocolMismatch
+	default:
+		return ReasonOther
+	}
+}
+
+// tlsMessageReason classifies a handshake error from its message, which is all http.Server reports.
+func tlsMessageReason(msg string) string {
+	switch {
+	case strings.Contains(msg, "expired"):
+		return ReasonCertExpired
+	case strings.Contains(msg, "unknown authority"), strings.Contains(msg, "unknown certificate authority"):
+		return ReasonUnknownAuthority
+	case strings.Contains(msg, "not valid for"), strings.Contains(msg, "doesn't contain any IP SANs"):
+		return ReasonHostnameMismatch
+	case strings.Contains(msg, "bad certificate"), strings.Contains(msg, "certificate required"):
+		return ReasonBadCertificate
+	case strings.Contains(msg, "protocol version"), strings.Contains(msg, "no cipher suite"),
+		strings.Contains(msg, "first record does not look like a TLS handshake"):
+		return ReasonProtocolMismatch
+	case strings.Contains(msg, "EOF"), strings.Contains(msg, "connection reset"), strings.Contains(msg, "broken pipe"):
+		return ReasonConnectionAborted
+	default:
+		return ReasonOther
+	}
+}
+
+// TLSHandshakeErrorLog returns a logger to set as http.Server.ErrorLog, counting the TLS handshake errors
+// the server logs under the given endpoint. All lines are written to out, os.Stderr if nil.
+func TLSHandshakeErrorLog(endpoint string, out io.Writer) *log.Logger {
+	if out == nil {
+		out = os.Stderr
+	}
+	return log.New(&handshakeErrorWriter{
+		endpoint: endpoint,
+		out:      out,
+	}, "", log.LstdFlags)
+}
+
+// handshakeErrorWriter counts the handshake error lines of the http.Server log before writing them.
+type handshakeErrorWriter struct {
+	endpoint string
+	out      io.Writer
+}
+
+// Write counts p if it is a handshake error and writes it to the underlying writer.
+func (w *handshakeErrorWriter) Write(p []byte) (int, error) {
+	msg := string(p)
+	if i := strings.Index(msg, "TLS handshake error"); i >= 0 {
+		countTLSHandshakeError(context.Background(), w.endpoint, tlsMessageReason(msg[i:]))
+	}
+	return w.out.Write(p)
+}
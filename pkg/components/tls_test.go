@@ -0,0 +1,154 @@
+package components
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/liangweijiang/go-metric/pkg/metertest/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCertificate returns a self-signed certificate of the common name expiring at notAfter, and its DER encoding.
+func newCertificate(t *testing.T, commonName string, notAfter time.Time) (*x509.Certificate, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, der
+}
+
+func TestCertExpiryCollector(t *testing.T) {
+	fake := clock.NewFake(time.Now().Truncate(time.Second))
+	m := fixture.Global(t, meter.WithClock(fake))
+
+	_, der := newCertificate(t, "api.local", fake.Now().Add(time.Hour))
+	path := filepath.Join(t.TempDir(), "api.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	leaf, _ := newCertificate(t, "grpc.local", fake.Now().Add(-time.Minute))
+
+	registration := NewCertExpiryCollector(path).
+		AddConfig("grpc", &tls.Config{Certificates: []tls.Certificate{{Leaf: leaf}}}).
+		Register()
+	defer func() {
+		assert.NoError(t, registration.Unregister())
+	}()
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`tls_certificate_expiry_seconds{source="`+path+`",subject="api.local"} 3600`,
+		`tls_certificate_expiry_seconds{source="grpc",subject="grpc.local"} -60`,
+	)
+}
+
+func TestTLSHandshakeErrors(t *testing.T) {
+	cert, _ := newCertificate(t, "a.local", time.Now().Add(time.Hour))
+	tests := []struct {
+		name   string
+		err    error
+		reason string
+	}{
+		{
+			name:   "Expired",
+			err:    &tls.CertificateVerificationError{Err: x509.CertificateInvalidError{Cert: cert, Reason: x509.Expired}},
+			reason: ReasonCertExpired,
+		},
+		{name: "ExpiredInMessageOnly", err: errors.New("session token expired"), reason: ReasonOther},
+		{name: "UnknownAuthority", err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{Cert: cert}}, reason: ReasonUnknownAuthority},
+		{name: "HostnameMismatch", err: x509.HostnameError{Certificate: cert, Host: "b.local"}, reason: ReasonHostnameMismatch},
+		{name: "BadCertificate", err: fmt.Errorf("handshake: %w", tls.AlertError(42)), reason: ReasonBadCertificate},
+		{name: "ProtocolMismatch", err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}, reason: ReasonProtocolMismatch},
+		{name: "ConnectionAborted", err: fmt.Errorf("read: %w", io.EOF), reason: ReasonConnectionAborted},
+		{name: "Other", err: errors.New("tls: internal error"), reason: ReasonOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := fixture.Global(t)
+
+			CountTLSHandshakeError(context.Background(), "api", tt.err)
+			CountTLSHandshakeError(context.Background(), "api", nil)
+
+			metertest.ScrapeAndAssert(t, m.GetHandler(),
+				`tls_handshake_errors_total{endpoint="api",reason="`+tt.reason+`"} 1`)
+		})
+	}
+}
+
+func TestTLSHandshakeErrorsOfConnections(t *testing.T) {
+	m := fixture.Global(t)
+	_, der := newCertificate(t, "api.local", time.Now().Add(time.Hour))
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	// the certificate of the server is not signed by the key, only its verification by the client matters here.
+	serverConfig := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan error, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			done <- err
+			return
+		}
+		defer conn.Close()
+		done <- tls.Server(conn, serverConfig).HandshakeContext(context.Background())
+	}()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	clientErr := tls.Client(conn, &tls.Config{ServerName: "api.local"}).HandshakeContext(context.Background())
+	_ = conn.Close()
+	serverErr := <-done
+	require.Error(t, clientErr)
+	require.Error(t, serverErr)
+
+	CountTLSHandshakeError(context.Background(), "client", clientErr)
+	CountTLSHandshakeError(context.Background(), "server", serverErr)
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`tls_handshake_errors_total{endpoint="client",reason="unknown_authority"} 1`,
+		`tls_handshake_errors_total{endpoint="server",reason="bad_certificate"} 1`)
+}
+
+func TestTLSHandshakeErrorLog(t *testing.T) {
+	m := fixture.Global(t)
+
+	var out bytes.Buffer
+	logger := TLSHandshakeErrorLog("api", &out)
+	logger.Print("http: TLS handshake error from 10.0.0.7:51234: remote error: tls: bad certificate")
+	logger.Print("http: panic serving 10.0.0.7:51234: boom")
+
+	assert.Contains(t, out.String(), "TLS handshake error", "the lines are written to out")
+	assert.Contains(t, out.String(), "panic serving")
+	metertest.ScrapeAndAssert(t, m.GetHandler(),
+		`tls_handshake_errors_total{endpoint="api",reason="bad_certificate"} 1`)
+}
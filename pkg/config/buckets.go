@@ -0,0 +1,37 @@
+package config
+
+// Units of the histograms created with dedicated boundaries, following the UCUM notation used by OpenTelemetry.
+const (
+
+	// UnitBytes is the unit of the histograms created by NewSizeHistogram, exported with the _bytes suffix.
+	UnitBytes = "By"
+
+	// UnitCount is the unit of the histograms created by NewCountHistogram, an annotation without suffix.
+	UnitCount = "{count}"
+)
+
+// DefaultSizeBoundaries are the histogram boundaries, in bytes, used by NewSizeHistogram.
+// They grow by powers of four from 64B to 1GiB, covering payloads from small RPC messages to large uploads.
+var DefaultSizeBoundaries = []float64{
+	64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10,
+	1 << 20, 4 << 20, 16 << 20, 64 << 20, 256 << 20, 1 << 30,
+}
+
+// DefaultCountBoundaries are the histogram boundaries used by NewCountHistogram, powers of two from 1 to 16384,
+// fitting batch sizes, retries, queue depths or items per request.
+var DefaultCountBoundaries = []float64{1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024, 2048, 4096, 8192, 16384}
+
+// Preset duration boundaries, in seconds, for the most common kinds of operations.
+// They can be applied to all histograms with WithHistogramBoundaries, or to a single one with NewHistogramWithBuckets.
+var (
+
+	// BucketsHTTPServer fits the latency of HTTP/RPC request handling, from 5ms to 10s,
+	// they are the boundaries advised by the OpenTelemetry semantic conventions for http.server.request.duration.
+	BucketsHTTPServer = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+	// BucketsDB fits the latency of database queries, from 1ms to 30s to keep slow queries and timeouts visible.
+	BucketsDB = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+	// BucketsCacheFast fits the latency of in-memory or nearby caches such as redis, from 100µs to 100ms.
+	BucketsCacheFast = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1}
+)
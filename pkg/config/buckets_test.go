@@ -0,0 +1,29 @@
+package config
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketPresets(t *testing.T) {
+	tests := []struct {
+		name        string
+		boundaries  []float64
+		first, last float64
+		wantLen     int
+	}{
+		{name: "BucketsHTTPServer", boundaries: BucketsHTTPServer, first: 0.005, last: 10, wantLen: 14},
+		{name: "BucketsDB", boundaries: BucketsDB, first: 0.001, last: 30, wantLen: 14},
+		{name: "BucketsCacheFast", boundaries: BucketsCacheFast, first: 0.0001, last: 0.1, wantLen: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, tt.boundaries, tt.wantLen)
+			assert.True(t, sort.Float64sAreSorted(tt.boundaries), "the boundaries are increasing")
+			assert.Equal(t, tt.first, tt.boundaries[0])
+			assert.Equal(t, tt.last, tt.boundaries[len(tt.boundaries)-1])
+		})
+	}
+}
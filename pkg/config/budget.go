@@ -0,0 +1,15 @@
+package config
+
+// BudgetUsage is the number of distinct metrics created by the code of a module against the budget it declared,
+// Refused counting the metrics refused in strict mode.
+type BudgetUsage struct {
+	Module      string
+	Budget      int
+	Instruments int
+	Refused     int
+}
+
+// Exceeded reports whether the module created, or tried to create in strict mode, more metrics than its budget.
+func (u BudgetUsage) Exceeded() bool {
+	return u.Instruments > u.Budget || u.Refused > 0
+}
@@ -0,0 +1,38 @@
+package config
+
+import "strconv"
+
+// NameCollisionPolicy decides what happens when an instrument is created with the name of a metric created before
+// with another kind, unit or description.
+type NameCollisionPolicy int
+
+const (
+	// NameCollisionIgnore leaves the instruments to the OpenTelemetry SDK, which logs a warning and may export
+	// conflicting series. It is the default policy.
+	NameCollisionIgnore NameCollisionPolicy = iota
+	// NameCollisionMerge creates the instrument with the unit and description of the metric created first, so that
+	// their measurements are merged in a single metric. Instruments of another kind are refused like with
+	// NameCollisionError.
+	NameCollisionMerge
+	// NameCollisionSuffix creates the instrument under the name suffixed with its unit, or with its kind when the units
+	// are the same, e.g. latency_ms next to latency. A collision on the description alone is merged.
+	NameCollisionSuffix
+	// NameCollisionError refuses the instrument, which falls back to a no-op, and logs an error.
+	NameCollisionError
+)
+
+// String returns the name of the policy, e.g. "merge".
+func (p NameCollisionPolicy) String() string {
+	switch p {
+	case NameCollisionIgnore:
+		return "ignore"
+	case NameCollisionMerge:
+		return "merge"
+	case NameCollisionSuffix:
+		return "suffix"
+	case NameCollisionError:
+		return "error"
+	default:
+		return "unknown(" + strconv.Itoa(int(p)) + ")"
+	}
+}
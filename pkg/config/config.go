@@ -1,8 +1,13 @@
 package config
 
 import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/clock"
 	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"net/http"
 	"os"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,35 +26,370 @@ const (
 	MeterEnvDev MeterEnv = "dev"
 )
 
+// LogLevel controls which messages of the SDK logging are written, messages below the level are discarded.
+type LogLevel int32
+
+const (
+
+	// LogLevelDebug writes debug, info and error messages.
+	LogLevelDebug LogLevel = iota - 1
+
+	// LogLevelInfo writes info and error messages. It is the default level.
+	LogLevelInfo
+
+	// LogLevelError writes error messages only.
+	LogLevelError
+)
+
+// defaultFinalPushTimeout is the default time allowed to the last push performed when the push gateway server is stopped.
+const defaultFinalPushTimeout = time.Second * 5
+
+// defaultDropSummaryInterval is the default interval at which the summary of dropped measurements is logged.
+const defaultDropSummaryInterval = time.Minute
+
+// defaultResourceTimeout is the default time allowed to the detection of the resource of the meter.
+const defaultResourceTimeout = 2 * time.Second
+
+// defaultCallbackTimeout is the default time allowed to an observable callback run by the callback workers.
+const defaultCallbackTimeout = time.Second
+
+// defaultPanicLimit is the default number of panics after which an observable callback or a tag provider is disabled.
+const defaultPanicLimit = 3
+
+// defaultDiskUsageInterval is the default interval at which the usage of the watched paths is collected.
+const defaultDiskUsageInterval = 30 * time.Second
+
 type MeterProviderType int
 
 const (
 	MeterProviderTypePrometheus MeterProviderType = iota + 1
+	// MeterProviderTypeValidate is a dry-run provider exporting nothing and reporting the violations of the naming,
+	// tagging and cardinality rules, for CI integration tests. It is used in development environments as well.
+	MeterProviderTypeValidate
+	// MeterProviderTypeOTLPGrpc pushes the metrics to an OpenTelemetry Collector with the OTLP gRPC exporter, see
+	// OTLPCfg, instead of being scraped.
+	MeterProviderTypeOTLPGrpc
+	// MeterProviderTypeStatsD sends the measurements as StatsD lines over UDP, see StatsDCfg, to a statsd daemon or a
+	// telegraf agent instead of being scraped.
+	MeterProviderTypeStatsD
+	// MeterProviderTypeDogStatsD sends the measurements as DogStatsD lines over UDP, see StatsDCfg, to a Datadog agent,
+	// with their tags as Datadog tags.
+	MeterProviderTypeDogStatsD
+	// MeterProviderTypeGraphite pushes the metrics as Graphite plaintext lines over TCP, see GraphiteCfg, to carbon,
+	// for the legacy monitoring stacks.
+	MeterProviderTypeGraphite
 )
 
+// lastBuiltinProviderType is the last provider type of the SDK.
+const lastBuiltinProviderType = MeterProviderTypeGraphite
+
+// PushGatewayCfg holds the settings of the push gateway integration.
+// FinalPushTimeout bounds the best-effort push performed on shutdown, five seconds if not set.
+// ProbeTimeout enables a connectivity probe of the gateway when the meter is created if it is positive.
+// IsLeader, when set, is asked before every push and only the replica it elects pushes, avoiding duplicate series.
 type PushGatewayCfg struct {
-	GatewayAddress string
-	PushPeriod     time.Duration
+	GatewayAddress   string
+	PushPeriod       time.Duration
+	FinalPushTimeout time.Duration
+	ProbeTimeout     time.Duration
+	IsLeader         func(ctx context.Context) bool
+	Resolution       time.Duration
+	AggregateLabels  []string
+}
+
+// Enabled reports whether a push gateway address is configured.
+func (p *PushGatewayCfg) Enabled() bool {
+	return p != nil && p.GatewayAddress != ""
+}
+
+// ShouldPush reports whether this replica pushes, that is when no IsLeader hook is configured or it is the leader.
+func (p *PushGatewayCfg) ShouldPush(ctx context.Context) bool {
+	return p.IsLeader == nil || p.IsLeader(ctx)
+}
+
+// GetFinalPushTimeout returns the time allowed to the last push on shutdown, falling back to the default if not set.
+func (p *PushGatewayCfg) GetFinalPushTimeout() time.Duration {
+	if p.FinalPushTimeout <= 0 {
+		return defaultFinalPushTimeout
+	}
+	return p.FinalPushTimeout
+}
+
+// HTTPServerCfg tunes the embedded HTTP server for high-frequency scraping, e.g. every second by many scrapers.
+// H2C serves HTTP/2 without TLS next to HTTP/1.1, IdleTimeout bounds the keep-alive connections waiting for the next
+// scrape, DisableKeepAlives closes the connection after every request, ReadHeaderTimeout bounds the reading of the
+// request headers, and MaxConcurrentScrapes bounds the scrapes of /metrics served at the same time, the others
+// waiting for a slot. Zero values keep the defaults of net/http and do not bound the scrapes.
+// When the Prometheus port is taken, the server retries on the ports from PortFallbackFrom to PortFallbackTo, if any,
+// then on an ephemeral port chosen by the system with EphemeralPortFallback, the address bound being returned by
+// ListenAddr.
+type HTTPServerCfg struct {
+	H2C                   bool
+	IdleTimeout           time.Duration
+	DisableKeepAlives     bool
+	ReadHeaderTimeout     time.Duration
+	MaxConcurrentScrapes  int
+	PortFallbackFrom      int
+	PortFallbackTo        int
+	EphemeralPortFallback bool
 }
 
+// AccessLogEntry describes a request served by the embedded HTTP server, e.g. a scrape of /metrics. Route is the
+// pattern of the route that served the request, empty if no route matched it, Path the raw path requested.
+type AccessLogEntry struct {
+	Method     string
+	Path       string
+	Route      string
+	RemoteAddr string
+	Status     int
+	Size       int64
+	Duration   time.Duration
+}
+
+// TagProvider computes tags at record time, e.g. the current shard or the hash of the current configuration,
+// so that dynamic values are attached to every measurement without rebuilding the instruments.
+// It is called for every measurement and must be fast and safe for concurrent use.
+type TagProvider interface {
+	Tags(ctx context.Context) []attribute.KeyValue
+}
+
+// TagProviderFunc is a function implementing TagProvider.
+type TagProviderFunc func(ctx context.Context) []attribute.KeyValue
+
+// Tags calls f.
+func (f TagProviderFunc) Tags(ctx context.Context) []attribute.KeyValue {
+	return f(ctx)
+}
+
+// DefaultScrapeTenantLabel is the label matched against the tenant of a scrape request when no other is configured.
+const DefaultScrapeTenantLabel = "tenant"
+
+// ScrapeFilter resolves the tenant of a request to the metrics endpoint from its authentication, e.g. a bearer token,
+// so that the endpoint returns only the series labeled with this tenant. An error rejects the request as unauthorized,
+// an empty tenant without error serves every series, e.g. to the operators of the platform.
+type ScrapeFilter func(r *http.Request) (tenant string, err error)
+
 // Config holds the configuration parameters for setting up metrics reporting, including port details, environment settings, meter provider types, push gateway configurations, histogram boundaries, base tags for metrics, and optional log output functions.
 type Config struct {
 	PrometheusPort        int
+	ManagementPort        int
+	ScrapeAllowlist       []string
+	HTTPServer            HTTPServerCfg
+	AccessLog             bool
+	AccessLogHook         func(entry AccessLogEntry)
 	LocalIP               string
+	LocalIPInterfaces     []string
 	Env                   MeterEnv
 	MeterProvider         MeterProviderType
+	ProviderName          string
 	PushGateway           *PushGatewayCfg
+	ReportMetric          *ReportMetricCfg
 	RuntimeMetricsCollect bool
+	FDMetricsCollect      bool
+	DiskUsagePaths        []string
+	DiskUsageInterval     time.Duration
+	UptimeCollect         bool
+	RestartStateFile      string
 	HistogramBoundaries   []float64
+	NativeHistograms      bool
+	CreatedTimestamps     bool
+	DeltaBuckets          bool
+	HistogramExtrema      bool
+	BucketTuningWindow    time.Duration
+	UnitCoercion          bool
+	CreationAudit         bool
+	Dictionary            Dictionary
+	Renames               Renames
+	RenameUntil           time.Time
+	NameCollisionPolicy   NameCollisionPolicy
+	UnitSuffixes          bool
 	BaseTags              map[string]string
+	InstanceTagsPull      InstanceTagPlacement
+	InstanceTagsPush      InstanceTagPlacement
+	TagProviders          []TagProvider
+	CardinalityLimit      int
 	InfoLogWrite          func(s string)
 	ErrorLogWrite         func(s string)
+	DropSummaryInterval   time.Duration
+	Context               context.Context
+	TickerJitter          float64
+	Clock                 clock.Clock
+	Readers               []sdkmetric.Reader
+	ScrapeFilter          ScrapeFilter
+	ScrapeTenantLabel     string
+	FeatureGate           FeatureGate
+	MetricBudgets         map[string]int
+	StrictBudgets         bool
+	UnusedWindow          time.Duration
+	ValueReadback         bool
+	DerivedMetrics        []DerivedMetric
+	ReadinessGate         bool
+	LazyInit              bool
+	DeferStart            bool
+	Serverless            bool
+	SignalDump            bool
+	SignalDumpPath        string
+	Export                *ExportCfg
+	OTLP                  *OTLPCfg
+	StatsD                *StatsDCfg
+	Graphite              *GraphiteCfg
+	CallbackWorkers       int
+	CallbackTimeout       time.Duration
+	PanicLimit            int
+	ResourceTimeout       time.Duration
+	logLevel              int32
+	pushPeriod            int64
+	cardinalityLimit      int64
+	exported              int32
+	listenAddr            atomic.Value
 }
 
 func GetConfig() *Config {
 	return new(Config)
 }
 
+// SetLogLevel changes the level of the SDK logging, it is safe to be called at runtime.
+func (c *Config) SetLogLevel(level LogLevel) {
+	atomic.StoreInt32(&c.logLevel, int32(level))
+}
+
+// GetLogLevel returns the current level of the SDK logging.
+func (c *Config) GetLogLevel() LogLevel {
+	return LogLevel(atomic.LoadInt32(&c.logLevel))
+}
+
+// SetPushPeriod overrides the period of the push gateway at runtime, the next push is scheduled with it.
+func (c *Config) SetPushPeriod(period time.Duration) {
+	atomic.StoreInt64(&c.pushPeriod, int64(period))
+}
+
+// GetPushPeriod returns the period of the push gateway set by SetPushPeriod, the configured PushPeriod otherwise.
+func (c *Config) GetPushPeriod() time.Duration {
+	if period := time.Duration(atomic.LoadInt64(&c.pushPeriod)); period > 0 {
+		return period
+	}
+	if c.PushGateway == nil {
+		return 0
+	}
+	return c.PushGateway.PushPeriod
+}
+
+// SetCardinalityLimit overrides the number of distinct tag sets allowed per metric at runtime.
+func (c *Config) SetCardinalityLimit(limit int) {
+	atomic.StoreInt64(&c.cardinalityLimit, int64(limit))
+}
+
+// GetCardinalityLimit returns the limit set by SetCardinalityLimit, the configured CardinalityLimit otherwise.
+func (c *Config) GetCardinalityLimit() int {
+	if limit := int(atomic.LoadInt64(&c.cardinalityLimit)); limit > 0 {
+		return limit
+	}
+	return c.CardinalityLimit
+}
+
+// SeparateManagementPort reports whether the health check, configuration and profiling endpoints are served on a
+// management port distinct from the port of the metrics.
+func (c *Config) SeparateManagementPort() bool {
+	return c.ManagementPort > 0 && c.ManagementPort != c.PrometheusPort
+}
+
+// SetListenAddr records the address the metrics server is bound to, the configured port or its fallback.
+func (c *Config) SetListenAddr(addr string) {
+	c.listenAddr.Store(addr)
+}
+
+// ListenAddr returns the address the metrics server is bound to, e.g. [::]:9464, empty while it is not listening.
+func (c *Config) ListenAddr() string {
+	addr, _ := c.listenAddr.Load().(string)
+	return addr
+}
+
+// MarkExported records that the metrics were exported successfully, scraped or pushed, at least once.
+func (c *Config) MarkExported() {
+	atomic.StoreInt32(&c.exported, 1)
+}
+
+// Ready reports whether the health endpoint reports UP: always unless ReadinessGate is set, in which case the metrics
+// must have been exported successfully at least once.
+func (c *Config) Ready() bool {
+	return !c.ReadinessGate || atomic.LoadInt32(&c.exported) == 1
+}
+
+// GetDropSummaryInterval returns the interval at which the summary of dropped measurements is logged,
+// falling back to one minute if none is configured.
+func (c *Config) GetDropSummaryInterval() time.Duration {
+	if c.DropSummaryInterval <= 0 {
+		return defaultDropSummaryInterval
+	}
+	return c.DropSummaryInterval
+}
+
+// GetDiskUsageInterval returns the interval at which the usage of the watched paths is collected,
+// falling back to 30 seconds if none is configured.
+func (c *Config) GetDiskUsageInterval() time.Duration {
+	if c.DiskUsageInterval <= 0 {
+		return defaultDiskUsageInterval
+	}
+	return c.DiskUsageInterval
+}
+
+// GetClock returns the clock of the periodic collectors, the push gateway and the timing helpers,
+// clock.Real if none is configured.
+func (c *Config) GetClock() clock.Clock {
+	if c.Clock == nil {
+		return clock.Real
+	}
+	return c.Clock
+}
+
+// GetScrapeTenantLabel returns the label holding the tenant of the series filtered by the ScrapeFilter,
+// DefaultScrapeTenantLabel if none is configured.
+func (c *Config) GetScrapeTenantLabel() string {
+	if c.ScrapeTenantLabel == "" {
+		return DefaultScrapeTenantLabel
+	}
+	return c.ScrapeTenantLabel
+}
+
+// GetResourceDetectionTimeout returns the time allowed to the detection of the resource of the meter, falling back to
+// the default if not set.
+func (c *Config) GetResourceDetectionTimeout() time.Duration {
+	if c.ResourceTimeout <= 0 {
+		return defaultResourceTimeout
+	}
+	return c.ResourceTimeout
+}
+
+// GetCallbackTimeout returns the time allowed to an observable callback run by the callback workers, falling back to
+// the default if not set.
+func (c *Config) GetCallbackTimeout() time.Duration {
+	if c.CallbackTimeout <= 0 {
+		return defaultCallbackTimeout
+	}
+	return c.CallbackTimeout
+}
+
+// GetPanicLimit returns the number of panics after which an observable callback or a tag provider is disabled,
+// falling back to the default if not set, and 0, never disabling them, if negative.
+func (c *Config) GetPanicLimit() int {
+	if c.PanicLimit < 0 {
+		return 0
+	}
+	if c.PanicLimit == 0 {
+		return defaultPanicLimit
+	}
+	return c.PanicLimit
+}
+
+// GetContext returns the context bounding the lifetime of the background loops of the meter,
+// context.Background() if none is configured.
+func (c *Config) GetContext() context.Context {
+	if c.Context == nil {
+		return context.Background()
+	}
+	return c.Context
+}
+
 // WriteErrorOrNot logs an error message either to a custom error log function defined in Config or to stdout if not set.
 // It prefixes the message with "[go-metrics][error]:" when writing to stdout.
 //
@@ -75,6 +415,9 @@ func (c *Config) WriteErrorOrNot(s string) {
 // Returns:
 // None
 func (c *Config) WriteInfoOrNot(s string) {
+	if c.GetLogLevel() > LogLevelInfo {
+		return
+	}
 	if c.InfoLogWrite == nil {
 		_, _ = os.Stdout.WriteString("[go-metrics][info]: " + s + "\n")
 	} else {
@@ -82,6 +425,19 @@ func (c *Config) WriteInfoOrNot(s string) {
 	}
 }
 
+// WriteDebugOrNot logs a debug message through the same sink as WriteInfoOrNot, only when the log level is LogLevelDebug.
+// It prefixes the message with "[go-metrics][debug]:" when writing to stdout.
+func (c *Config) WriteDebugOrNot(s string) {
+	if c.GetLogLevel() > LogLevelDebug {
+		return
+	}
+	if c.InfoLogWrite == nil {
+		_, _ = os.Stdout.WriteString("[go-metrics][debug]: " + s + "\n")
+	} else {
+		c.InfoLogWrite("[go-metrics][debug] " + s)
+	}
+}
+
 // WithBaseTags creates a slice of attribute.KeyValue from the BaseTags map in the Config.
 // Each key-value pair in the BaseTags map is converted into an attribute.KeyValue.
 // This function is useful for populating common tags across metrics or traces.
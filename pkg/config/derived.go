@@ -0,0 +1,14 @@
+package config
+
+// DerivedMetric defines a gauge computed at collection time from the values of other metrics, e.g. the error ratio
+// errors / requests. Expr combines metric names and numbers with + - * / and parentheses, every metric standing for
+// the sum of its series, or of its series with the same values of the tags listed in By, one gauge series being
+// exported per combination of these values. Derived metrics require the values of the series to be tracked, see
+// ValueReadback.
+type DerivedMetric struct {
+	Name string
+	Desc string
+	Unit string
+	Expr string
+	By   []string
+}
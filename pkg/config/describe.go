@@ -0,0 +1,349 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String returns the name of the provider type, e.g. "prometheus".
+func (t MeterProviderType) String() string {
+	switch t {
+	case MeterProviderTypePrometheus:
+		return "prometheus"
+	case MeterProviderTypeValidate:
+		return "validate"
+	case MeterProviderTypeOTLPGrpc:
+		return "otlp"
+	case MeterProviderTypeStatsD:
+		return "statsd"
+	case MeterProviderTypeDogStatsD:
+		return "dogstatsd"
+	case MeterProviderTypeGraphite:
+		return "graphite"
+	default:
+		if name, ok := registeredProviderName(t); ok {
+			return name
+		}
+		return "unknown(" + strconv.Itoa(int(t)) + ")"
+	}
+}
+
+// String returns the name of the log level, e.g. "info".
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelError:
+		return "error"
+	default:
+		return "unknown(" + strconv.Itoa(int(l)) + ")"
+	}
+}
+
+// Description is the effective configuration of a meter, without secrets, as served by the diagnostics endpoint.
+// Durations are formatted by time.Duration.String and the hooks are only reported as set or not.
+type Description struct {
+	Provider            string                  `json:"provider"`
+	Env                 MeterEnv                `json:"env"`
+	PrometheusPort      int                     `json:"prometheus_port"`
+	ManagementPort      int                     `json:"management_port,omitempty"`
+	ScrapeAllowlist     []string                `json:"scrape_allowlist,omitempty"`
+	HTTPServer          HTTPServerDescription   `json:"http_server"`
+	AccessLog           bool                    `json:"access_log"`
+	LocalIP             string                  `json:"local_ip"`
+	LocalIPInterfaces   []string                `json:"local_ip_interfaces,omitempty"`
+	PushGateway         *PushGatewayDescription `json:"push_gateway,omitempty"`
+	ReportMetric        *ReportDescription      `json:"report_metric,omitempty"`
+	Export              *ExportDescription      `json:"export,omitempty"`
+	OTLP                *OTLPDescription        `json:"otlp,omitempty"`
+	StatsD              *StatsDDescription      `json:"statsd,omitempty"`
+	Graphite            *GraphiteDescription    `json:"graphite,omitempty"`
+	HistogramBoundaries []float64               `json:"histogram_boundaries"`
+	NativeHistograms    bool                    `json:"native_histograms"`
+	CreatedTimestamps   bool                    `json:"created_timestamps"`
+	DeltaBuckets        bool                    `json:"delta_buckets"`
+	HistogramExtrema    bool                    `json:"histogram_extrema"`
+	BucketTuningWindow  string                  `json:"bucket_tuning_window,omitempty"`
+	UnitCoercion        bool                    `json:"unit_coercion"`
+	CreationAudit       bool                    `json:"creation_audit"`
+	DictionaryMetrics   int                     `json:"dictionary_metrics,omitempty"`
+	Renames             map[string]string       `json:"renames,omitempty"`
+	RenameUntil         string                  `json:"rename_until,omitempty"`
+	NameCollisionPolicy string                  `json:"name_collision_policy"`
+	UnitSuffixes        bool                    `json:"unit_suffixes"`
+	RuntimeMetrics      bool                    `json:"runtime_metrics"`
+	FDMetrics           bool                    `json:"fd_metrics"`
+	UptimeMetrics       bool                    `json:"uptime_metrics"`
+	DiskUsagePaths      []string                `json:"disk_usage_paths,omitempty"`
+	DiskUsageInterval   string                  `json:"disk_usage_interval"`
+	BaseTags            map[string]string       `json:"base_tags"`
+	InstanceTagsPull    string                  `json:"instance_tags_pull"`
+	InstanceTagsPush    string                  `json:"instance_tags_push"`
+	TagProviders        []string                `json:"tag_providers"`
+	CardinalityLimit    int                     `json:"cardinality_limit"`
+	DropSummaryInterval string                  `json:"drop_summary_interval"`
+	TickerJitter        float64                 `json:"ticker_jitter"`
+	LogLevel            string                  `json:"log_level"`
+	Readers             int                     `json:"readers"`
+	ScrapeFilter        bool                    `json:"scrape_filter"`
+	ScrapeTenantLabel   string                  `json:"scrape_tenant_label,omitempty"`
+	FeatureGate         bool                    `json:"feature_gate"`
+	MetricBudgets       map[string]int          `json:"metric_budgets,omitempty"`
+	StrictBudgets       bool                    `json:"strict_budgets"`
+	UnusedWindow        string                  `json:"unused_window,omitempty"`
+	ReadinessGate       bool                    `json:"readiness_gate"`
+	LazyInit            bool                    `json:"lazy_init"`
+	DeferStart          bool                    `json:"defer_start"`
+	Serverless          bool                    `json:"serverless"`
+	SignalDump          string                  `json:"signal_dump,omitempty"`
+	ResourceTimeout     string                  `json:"resource_timeout"`
+	CallbackWorkers     int                     `json:"callback_workers,omitempty"`
+	CallbackTimeout     string                  `json:"callback_timeout,omitempty"`
+	PanicLimit          int                     `json:"panic_limit"`
+	ValueReadback       bool                    `json:"value_readback"`
+	DerivedMetrics      []string                `json:"derived_metrics,omitempty"`
+}
+
+// HTTPServerDescription is the tuning of the embedded HTTP server.
+type HTTPServerDescription struct {
+	H2C                  bool   `json:"h2c"`
+	IdleTimeout          string `json:"idle_timeout"`
+	KeepAlives           bool   `json:"keep_alives"`
+	ReadHeaderTimeout    string `json:"read_header_timeout"`
+	MaxConcurrentScrapes int    `json:"max_concurrent_scrapes"`
+	PortFallback         string `json:"port_fallback,omitempty"`
+	ListenAddr           string `json:"listen_addr,omitempty"`
+}
+
+// PushGatewayDescription is the effective push gateway configuration, the credentials of the address being redacted.
+type PushGatewayDescription struct {
+	Address          string   `json:"address"`
+	Period           string   `json:"period"`
+	FinalPushTimeout string   `json:"final_push_timeout"`
+	ProbeTimeout     string   `json:"probe_timeout"`
+	LeaderElection   bool     `json:"leader_election"`
+	Resolution       string   `json:"resolution,omitempty"`
+	AggregateLabels  []string `json:"aggregate_labels,omitempty"`
+}
+
+// ReportDescription is the effective report metric configuration, the credentials of the address being redacted.
+type ReportDescription struct {
+	Address  string `json:"address"`
+	Service  string `json:"service,omitempty"`
+	Interval string `json:"interval"`
+	Timeout  string `json:"timeout"`
+}
+
+// ExportDescription is the effective configuration of the pipeline of the push exporters.
+type ExportDescription struct {
+	BatchSize      int    `json:"batch_size"`
+	QueueSize      int    `json:"queue_size"`
+	Compression    string `json:"compression,omitempty"`
+	MaxAttempts    int    `json:"max_attempts"`
+	InitialBackoff string `json:"initial_backoff"`
+	MaxBackoff     string `json:"max_backoff"`
+}
+
+// OTLPDescription is the OTLP exporter, with the names of its headers but not their values.
+type OTLPDescription struct {
+	Endpoint string   `json:"endpoint,omitempty"`
+	Insecure bool     `json:"insecure"`
+	TLS      bool     `json:"tls"`
+	Headers  []string `json:"headers,omitempty"`
+	Interval string   `json:"interval"`
+	Timeout  string   `json:"timeout"`
+}
+
+// StatsDDescription is the StatsD client.
+type StatsDDescription struct {
+	Address       string `json:"address"`
+	Prefix        string `json:"prefix,omitempty"`
+	FlushInterval string `json:"flush_interval"`
+	MaxPacketSize int    `json:"max_packet_size"`
+	Distributions bool   `json:"distributions"`
+}
+
+// GraphiteDescription is the Graphite exporter.
+type GraphiteDescription struct {
+	Address   string `json:"address"`
+	Prefix    string `json:"prefix,omitempty"`
+	Interval  string `json:"interval"`
+	Timeout   string `json:"timeout"`
+	TagFormat string `json:"tag_format"`
+}
+
+// Describe returns the effective configuration, with the defaults applied and the secrets redacted.
+func (c *Config) Describe() Description {
+	d := Description{
+		Provider:        c.MeterProvider.String(),
+		Env:             c.Env,
+		PrometheusPort:  c.PrometheusPort,
+		ManagementPort:  c.ManagementPort,
+		ScrapeAllowlist: c.ScrapeAllowlist,
+		HTTPServer: HTTPServerDescription{
+			H2C:                  c.HTTPServer.H2C,
+			IdleTimeout:          c.HTTPServer.IdleTimeout.String(),
+			KeepAlives:           !c.HTTPServer.DisableKeepAlives,
+			ReadHeaderTimeout:    c.HTTPServer.ReadHeaderTimeout.String(),
+			MaxConcurrentScrapes: c.HTTPServer.MaxConcurrentScrapes,
+			PortFallback:         c.HTTPServer.describePortFallback(),
+			ListenAddr:           c.ListenAddr(),
+		},
+		LocalIP:             c.LocalIP,
+		LocalIPInterfaces:   c.LocalIPInterfaces,
+		HistogramBoundaries: c.HistogramBoundaries,
+		NativeHistograms:    c.NativeHistograms,
+		CreatedTimestamps:   c.CreatedTimestamps,
+		DeltaBuckets:        c.DeltaBuckets,
+		HistogramExtrema:    c.HistogramExtrema,
+		UnitCoercion:        c.UnitCoercion,
+		CreationAudit:       c.CreationAudit,
+		DictionaryMetrics:   len(c.Dictionary),
+		Renames:             c.Renames,
+		NameCollisionPolicy: c.NameCollisionPolicy.String(),
+		UnitSuffixes:        c.UnitSuffixes,
+		RuntimeMetrics:      c.RuntimeMetricsCollect,
+		FDMetrics:           c.FDMetricsCollect,
+		UptimeMetrics:       c.UptimeCollect,
+		DiskUsagePaths:      c.DiskUsagePaths,
+		DiskUsageInterval:   c.GetDiskUsageInterval().String(),
+		BaseTags:            c.BaseTags,
+		InstanceTagsPull:    c.InstanceTagsPull.String(),
+		InstanceTagsPush:    c.InstanceTagsPush.String(),
+		TagProviders:        make([]string, 0, len(c.TagProviders)),
+		CardinalityLimit:    c.GetCardinalityLimit(),
+		DropSummaryInterval: c.GetDropSummaryInterval().String(),
+		TickerJitter:        c.TickerJitter,
+		LogLevel:            c.GetLogLevel().String(),
+		Readers:             len(c.Readers),
+		ScrapeFilter:        c.ScrapeFilter != nil,
+		FeatureGate:         c.FeatureGate != nil,
+		MetricBudgets:       c.MetricBudgets,
+		StrictBudgets:       c.StrictBudgets,
+		ReadinessGate:       c.ReadinessGate,
+		LazyInit:            c.LazyInit,
+		DeferStart:          c.DeferStart,
+		Serverless:          c.Serverless,
+		SignalDump:          c.SignalDumpTarget(),
+		ResourceTimeout:     c.GetResourceDetectionTimeout().String(),
+		CallbackWorkers:     c.CallbackWorkers,
+		PanicLimit:          c.GetPanicLimit(),
+		ValueReadback:       c.ValueReadback,
+	}
+	if c.ScrapeFilter != nil {
+		d.ScrapeTenantLabel = c.GetScrapeTenantLabel()
+	}
+	if c.UnusedWindow > 0 {
+		d.UnusedWindow = c.UnusedWindow.String()
+	}
+	if c.BucketTuningWindow > 0 {
+		d.BucketTuningWindow = c.BucketTuningWindow.String()
+	}
+	if !c.RenameUntil.IsZero() {
+		d.RenameUntil = c.RenameUntil.Format(time.RFC3339)
+	}
+	for _, derived := range c.DerivedMetrics {
+		d.DerivedMetrics = append(d.DerivedMetrics, derived.Name+" = "+derived.Expr)
+	}
+	for _, provider := range c.TagProviders {
+		d.TagProviders = append(d.TagProviders, fmt.Sprintf("%T", provider))
+	}
+	sort.Strings(d.TagProviders)
+	if c.PushGateway.Enabled() {
+		d.PushGateway = &PushGatewayDescription{
+			Address:          c.PushGateway.RedactedAddress(),
+			Period:           c.GetPushPeriod().String(),
+			FinalPushTimeout: c.PushGateway.GetFinalPushTimeout().String(),
+			ProbeTimeout:     c.PushGateway.ProbeTimeout.String(),
+			LeaderElection:   c.PushGateway.IsLeader != nil,
+			AggregateLabels:  c.PushGateway.AggregateLabels,
+		}
+		if c.PushGateway.Resolution > 0 {
+			d.PushGateway.Resolution = c.PushGateway.Resolution.String()
+		}
+	}
+	if c.ReportMetric.Enabled() {
+		d.ReportMetric = &ReportDescription{
+			Address:  c.ReportMetric.RedactedAddress(),
+			Service:  c.ReportMetric.Service,
+			Interval: c.ReportMetric.Interval.String(),
+			Timeout:  c.ReportMetric.GetTimeout().String(),
+		}
+	}
+	if c.CallbackWorkers > 0 {
+		d.CallbackTimeout = c.GetCallbackTimeout().String()
+	}
+	if c.OTLP != nil {
+		d.OTLP = &OTLPDescription{
+			Endpoint: c.OTLP.Endpoint,
+			Insecure: c.OTLP.Insecure,
+			TLS:      c.OTLP.TLS != nil,
+			Interval: c.OTLP.GetInterval().String(),
+			Timeout:  c.OTLP.GetTimeout().String(),
+		}
+		for key := range c.OTLP.Headers {
+			d.OTLP.Headers = append(d.OTLP.Headers, key)
+		}
+		sort.Strings(d.OTLP.Headers)
+	}
+	if c.StatsD != nil {
+		d.StatsD = &StatsDDescription{
+			Address:       c.StatsD.GetAddress(),
+			Prefix:        c.StatsD.Prefix,
+			FlushInterval: c.StatsD.GetFlushInterval().String(),
+			MaxPacketSize: c.StatsD.GetMaxPacketSize(),
+			Distributions: c.StatsD.Distributions,
+		}
+	}
+	if c.Graphite != nil {
+		d.Graphite = &GraphiteDescription{
+			Address:   c.Graphite.GetAddress(),
+			Prefix:    c.Graphite.Prefix,
+			Interval:  c.Graphite.GetInterval().String(),
+			Timeout:   c.Graphite.GetTimeout().String(),
+			TagFormat: c.Graphite.GetTagFormat().String(),
+		}
+	}
+	if c.Export != nil {
+		d.Export = &ExportDescription{
+			BatchSize:      c.Export.BatchSize,
+			QueueSize:      c.Export.QueueSize,
+			Compression:    string(c.Export.Compression),
+			MaxAttempts:    c.Export.GetMaxAttempts(),
+			InitialBackoff: c.Export.Backoff(1).String(),
+			MaxBackoff:     c.Export.Backoff(math.MaxInt).String(),
+		}
+	}
+	return d
+}
+
+// redactURL hides the password and the query of a URL, which may carry credentials.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "<invalid>"
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "redacted"
+	}
+	return u.Redacted()
+}
+
+// describePortFallback describes the ports tried when the Prometheus port is taken, empty without fallback.
+func (h HTTPServerCfg) describePortFallback() string {
+	var fallbacks []string
+	if h.PortFallbackFrom > 0 {
+		fallbacks = append(fallbacks, fmt.Sprintf("%d-%d", h.PortFallbackFrom, h.PortFallbackTo))
+	}
+	if h.EphemeralPortFallback {
+		fallbacks = append(fallbacks, "ephemeral")
+	}
+	return strings.Join(fallbacks, ",")
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+)
+
+// ErrInvalidDictionary is returned when a metrics dictionary cannot be parsed.
+var ErrInvalidDictionary = errors.New("invalid metrics dictionary")
+
+// MetricDefinition is the entry of a metric in a Dictionary: what it measures and the team owning it.
+type MetricDefinition struct {
+	Description string `yaml:"description"`
+	Owner       string `yaml:"owner"`
+}
+
+// Help returns the description of the metric, followed by its owner if any, e.g.
+// "Orders placed. Owner: checkout-team."
+func (d MetricDefinition) Help() string {
+	if d.Owner == "" {
+		return d.Description
+	}
+	if d.Description == "" {
+		return "Owner: " + d.Owner + "."
+	}
+	return d.Description + " Owner: " + d.Owner + "."
+}
+
+// Dictionary is the central list of the metrics of an organization, by name. The instruments created with an empty
+// description are described from it, and the metrics missing from it are reported, see ParseDictionary for its file.
+type Dictionary map[string]MetricDefinition
+
+// dictionaryFile is the layout of a dictionary file.
+type dictionaryFile struct {
+	Metrics Dictionary `yaml:"metrics"`
+}
+
+// ParseDictionary parses a YAML metrics dictionary, listing the metrics under the metrics key:
+//
+//	metrics:
+//	  orders_total:
+//	    description: Orders placed.
+//	    owner: checkout-team
+func ParseDictionary(data []byte) (Dictionary, error) {
+	var file dictionaryFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDictionary, err)
+	}
+	if file.Metrics == nil {
+		return Dictionary{}, nil
+	}
+	return file.Metrics, nil
+}
+
+// LoadDictionary reads and parses the YAML metrics dictionary at path, see ParseDictionary.
+func LoadDictionary(path string) (Dictionary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDictionary(data)
+}
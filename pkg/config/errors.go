@@ -0,0 +1,174 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/derive"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// Errors returned when the configuration of a meter cannot be applied.
+// They are wrapped with the offending value, callers should compare them with errors.Is.
+var (
+
+	// ErrInvalidPort is returned when the Prometheus port is outside the range of valid TCP ports.
+	ErrInvalidPort = errors.New("invalid prometheus port")
+
+	// ErrUnsupportedProvider is returned when the meter provider type is not known by the SDK.
+	ErrUnsupportedProvider = errors.New("unsupported meter provider type")
+
+	// ErrDuplicateProvider is returned when a provider is registered under an empty name or the name of another one.
+	ErrDuplicateProvider = errors.New("duplicate meter provider")
+
+	// ErrNilProviderFactory is returned when a provider is registered without a factory.
+	ErrNilProviderFactory = errors.New("nil meter provider factory")
+
+	// ErrExporterInit is returned when the exporter backing the meter provider fails to initialize.
+	ErrExporterInit = errors.New("failed to initialize exporter")
+
+	// ErrInvalidGatewayAddress is returned when the push gateway address is not a valid http(s) URL.
+	ErrInvalidGatewayAddress = errors.New("invalid push gateway address")
+
+	// ErrInvalidPushPeriod is returned when the push gateway period is not positive.
+	ErrInvalidPushPeriod = errors.New("invalid push gateway period")
+
+	// ErrGatewayUnreachable is returned when the initial connectivity probe of the push gateway fails.
+	ErrGatewayUnreachable = errors.New("push gateway unreachable")
+
+	// ErrInvalidDerivedMetric is returned when the expression of a derived metric cannot be parsed.
+	ErrInvalidDerivedMetric = errors.New("invalid derived metric")
+
+	// ErrInvalidReportAddress is returned when the address of the metadata service is not a valid http(s) URL.
+	ErrInvalidReportAddress = errors.New("invalid report metric address")
+
+	// ErrInvalidAllowlist is returned when an entry of the scrape allowlist is neither a CIDR nor an IP address.
+	ErrInvalidAllowlist = errors.New("invalid scrape allowlist")
+)
+
+// Validate checks the configuration before a meter is built from it.
+// It returns an error wrapping one of the exported error variables of this package describing the first failure found.
+// The push gateway settings given without a gateway address are refused rather than silently not pushing.
+func (c *Config) Validate() error {
+	if c.PrometheusPort < 0 || c.PrometheusPort > 65535 {
+		return fmt.Errorf("%w: %d", ErrInvalidPort, c.PrometheusPort)
+	}
+	if c.ManagementPort < 0 || c.ManagementPort > 65535 {
+		return fmt.Errorf("%w: management port %d", ErrInvalidPort, c.ManagementPort)
+	}
+	if from, to := c.HTTPServer.PortFallbackFrom, c.HTTPServer.PortFallbackTo; from != 0 || to != 0 {
+		if from <= 0 || to > 65535 || from > to {
+			return fmt.Errorf("%w: fallback port range %d-%d", ErrInvalidPort, from, to)
+		}
+	}
+	switch c.MeterProvider {
+	case 0, MeterProviderTypePrometheus, MeterProviderTypeValidate, MeterProviderTypeOTLPGrpc, MeterProviderTypeStatsD,
+		MeterProviderTypeDogStatsD, MeterProviderTypeGraphite:
+	default:
+		if _, ok := registeredProviderName(c.MeterProvider); !ok {
+			return fmt.Errorf("%w: %d", ErrUnsupportedProvider, c.MeterProvider)
+		}
+	}
+	if c.PushGateway != nil {
+		if err := c.PushGateway.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.OTLP != nil {
+		if err := c.OTLP.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.StatsD != nil {
+		if err := c.StatsD.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Graphite != nil {
+		if err := c.Graphite.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Export != nil {
+		if err := c.Export.Validate(); err != nil {
+			return err
+		}
+		if c.MeterProvider == MeterProviderTypeOTLPGrpc {
+			if err := c.Export.validateOTLP(); err != nil {
+				return err
+			}
+		}
+	}
+	if c.ReportMetric.Enabled() {
+		if err := c.ReportMetric.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := c.Renames.Validate(); err != nil {
+		return err
+	}
+	if _, err := c.ParseScrapeAllowlist(); err != nil {
+		return err
+	}
+	for _, d := range c.DerivedMetrics {
+		if _, err := derive.Parse(d.Expr); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidDerivedMetric, d.Name, err)
+		}
+	}
+	return nil
+}
+
+// ParseScrapeAllowlist parses the entries of the scrape allowlist, CIDRs such as 10.0.0.0/8 or single IP addresses.
+func (c *Config) ParseScrapeAllowlist() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(c.ScrapeAllowlist))
+	for _, entry := range c.ScrapeAllowlist {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAllowlist, entry)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Validate checks that the gateway address is set and is an http(s) URL with a host and without query or fragment,
+// the scheme may be omitted like in push.New, and that the push period is positive.
+func (p *PushGatewayCfg) Validate() error {
+	if p.GatewayAddress == "" {
+		return fmt.Errorf("%w: the push gateway settings require a gateway address", ErrInvalidGatewayAddress)
+	}
+	if _, err := p.GatewayURL(); err != nil {
+		return err
+	}
+	if p.PushPeriod <= 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidPushPeriod, p.PushPeriod)
+	}
+	return nil
+}
+
+// GatewayURL parses the gateway address, defaulting the scheme to http when it is omitted.
+func (p *PushGatewayCfg) GatewayURL() (*url.URL, error) {
+	address := p.GatewayAddress
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrInvalidGatewayAddress, p.GatewayAddress, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: %q: unsupported scheme %q", ErrInvalidGatewayAddress, p.GatewayAddress, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%w: %q: missing host", ErrInvalidGatewayAddress, p.GatewayAddress)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("%w: %q: query and fragment are not allowed", ErrInvalidGatewayAddress, p.GatewayAddress)
+	}
+	return u, nil
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidExport is returned when the batching, the queue, the compression or the retry policy of the push
+// exporters is invalid.
+var ErrInvalidExport = errors.New("invalid export configuration")
+
+// Compression is the encoding of the bodies sent by the push exporters.
+type Compression string
+
+// Compressions of the bodies sent by the push exporters, set as their Content-Encoding.
+const (
+	CompressionNone Compression = ""
+	CompressionGzip Compression = "gzip"
+)
+
+// Default retry policy of the push exporters.
+const (
+	DefaultRetryInitialBackoff = 100 * time.Millisecond
+	DefaultRetryMaxBackoff     = 5 * time.Second
+)
+
+// RetryPolicy is the policy of the push exporters retrying the requests failing with a network error, a 429 or a 5xx
+// status: up to MaxAttempts attempts, one if not set, waiting between them a backoff starting at InitialBackoff and
+// doubling up to MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// ExportCfg configures the pipeline of the push exporters, such as the push gateway: the number of metric families
+// sent per request, BatchSize, all of them if not set, the number of exports waiting to be sent while a previous one
+// is sent or retried, QueueSize, none if not set, the exports being sent by the push loop itself, the compression of
+// the bodies and the retry policy. The exports not fitting in the queue are dropped and counted.
+type ExportCfg struct {
+	BatchSize   int
+	QueueSize   int
+	Compression Compression
+	Retry       RetryPolicy
+}
+
+// GetMaxAttempts returns the number of attempts of a request, one if not set.
+func (e *ExportCfg) GetMaxAttempts() int {
+	if e == nil || e.Retry.MaxAttempts <= 0 {
+		return 1
+	}
+	return e.Retry.MaxAttempts
+}
+
+// Backoff returns the time waited before the given retry, counted from one, doubling from the initial backoff up to
+// the maximum one.
+func (e *ExportCfg) Backoff(retry int) time.Duration {
+	backoff, limit := e.Retry.InitialBackoff, e.Retry.MaxBackoff
+	if backoff <= 0 {
+		backoff = DefaultRetryInitialBackoff
+	}
+	if limit <= 0 {
+		limit = DefaultRetryMaxBackoff
+	}
+	for i := 1; i < retry && backoff < limit; i++ {
+		backoff *= 2
+	}
+	return min(backoff, limit)
+}
+
+// Validate checks that the sizes and the backoffs are not negative and the compression is supported.
+func (e *ExportCfg) Validate() error {
+	if e.BatchSize < 0 || e.QueueSize < 0 {
+		return fmt.Errorf("%w: batch size %d, queue size %d", ErrInvalidExport, e.BatchSize, e.QueueSize)
+	}
+	if e.Retry.MaxAttempts < 0 || e.Retry.InitialBackoff < 0 || e.Retry.MaxBackoff < 0 {
+		return fmt.Errorf("%w: retry policy %+v", ErrInvalidExport, e.Retry)
+	}
+	switch e.Compression {
+	case CompressionNone, CompressionGzip:
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported compression %q", ErrInvalidExport, e.Compression)
+	}
+}
+
+// validateOTLP checks that the settings are supported by the OTLP exporter, which sends every export in a single
+// request, without queueing it.
+func (e *ExportCfg) validateOTLP() error {
+	if e.BatchSize > 0 || e.QueueSize > 0 {
+		return fmt.Errorf("%w: the otlp exporter does not batch nor queue the exports", ErrInvalidExport)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package config
+
+// InstrumentInfo describes an instrument being created, for the FeatureGate deciding whether it records.
+// BaseTags are the base tags of the meter, e.g. the name of the service, and Instance its local IP,
+// so that a feature-flag system can roll an instrument out per service or to a percentage of the instances.
+type InstrumentInfo struct {
+	Name     string
+	Kind     string
+	Unit     string
+	BaseTags map[string]string
+	Instance string
+}
+
+// FeatureGate gates expensive instruments, e.g. high-resolution histograms, behind a feature-flag system.
+// It is evaluated once per metric name, when the first instrument of the metric is created, and the answer is cached
+// for the lifetime of the meter: the instruments of a gated-off metric are no-op.
+type FeatureGate interface {
+	InstrumentEnabled(info InstrumentInfo) bool
+}
+
+// FeatureGateFunc is a function implementing FeatureGate.
+type FeatureGateFunc func(info InstrumentInfo) bool
+
+// InstrumentEnabled calls f.
+func (f FeatureGateFunc) InstrumentEnabled(info InstrumentInfo) bool {
+	return f(info)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidGraphite is returned when the settings of the Graphite exporter are invalid.
+var ErrInvalidGraphite = errors.New("invalid graphite configuration")
+
+// Default settings of the Graphite exporter. 2003 is the plaintext port of carbon.
+const (
+	defaultGraphiteAddress  = "127.0.0.1:2003"
+	defaultGraphiteInterval = time.Minute
+	defaultGraphiteTimeout  = 10 * time.Second
+)
+
+// GraphiteTagFormat is the way the tags of a series are written in its Graphite metric path.
+type GraphiteTagFormat int
+
+const (
+	// GraphiteTagsPath appends the tags to the metric path as key.value segments sorted by key, e.g.
+	// checkout.http_requests.method.GET, for the Graphite servers without tag support. It is the default format.
+	GraphiteTagsPath GraphiteTagFormat = iota
+	// GraphiteTagsTagged writes the tags in the tagged format of Graphite 1.1, e.g.
+	// checkout.http_requests;method=GET, so that they can be queried with seriesByTag.
+	GraphiteTagsTagged
+)
+
+// String returns the name of the tag format, e.g. "path".
+func (f GraphiteTagFormat) String() string {
+	switch f {
+	case GraphiteTagsPath:
+		return "path"
+	case GraphiteTagsTagged:
+		return "tagged"
+	default:
+		return "unknown(" + strconv.Itoa(int(f)) + ")"
+	}
+}
+
+// GraphiteCfg holds the settings of the exporter of the MeterProviderTypeGraphite provider, pushing the metrics every
+// Interval, one minute if not set, as plaintext lines to the carbon TCP Address, 127.0.0.1:2003 if empty, each push
+// bounded by Timeout, ten seconds if not set. Prefix is prepended to the metric paths, separated by a dot, and the
+// tags are written according to TagFormat.
+type GraphiteCfg struct {
+	Address   string
+	Prefix    string
+	Interval  time.Duration
+	Timeout   time.Duration
+	TagFormat GraphiteTagFormat
+}
+
+// GetAddress returns the address of carbon, falling back to the default if not set.
+func (g *GraphiteCfg) GetAddress() string {
+	if g == nil || g.Address == "" {
+		return defaultGraphiteAddress
+	}
+	return g.Address
+}
+
+// GetInterval returns the interval between two pushes, falling back to the default if not set.
+func (g *GraphiteCfg) GetInterval() time.Duration {
+	if g == nil || g.Interval <= 0 {
+		return defaultGraphiteInterval
+	}
+	return g.Interval
+}
+
+// GetTimeout returns the time allowed to a push, falling back to the default if not set.
+func (g *GraphiteCfg) GetTimeout() time.Duration {
+	if g == nil || g.Timeout <= 0 {
+		return defaultGraphiteTimeout
+	}
+	return g.Timeout
+}
+
+// GetTagFormat returns the format of the tags, GraphiteTagsPath if not set.
+func (g *GraphiteCfg) GetTagFormat() GraphiteTagFormat {
+	if g == nil {
+		return GraphiteTagsPath
+	}
+	return g.TagFormat
+}
+
+// Validate checks that the durations are not negative and that the tag format is known.
+func (g *GraphiteCfg) Validate() error {
+	if g.Interval < 0 || g.Timeout < 0 {
+		return fmt.Errorf("%w: negative interval %s or timeout %s", ErrInvalidGraphite, g.Interval, g.Timeout)
+	}
+	if g.TagFormat != GraphiteTagsPath && g.TagFormat != GraphiteTagsTagged {
+		return fmt.Errorf("%w: tag format %s", ErrInvalidGraphite, g.TagFormat)
+	}
+	return nil
+}
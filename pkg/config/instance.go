@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// Keys of the tags identifying the instance of the application, injected with the instance tag placements.
+const (
+	InstanceTagHostname = "hostname"
+	InstanceTagPID      = "pid"
+	InstanceTagIP       = "ip"
+)
+
+// InstanceTagPlacement decides where the tags identifying the instance of the application are injected.
+type InstanceTagPlacement int
+
+const (
+	// InstanceTagsNone does not inject the instance tags. It is the default placement.
+	InstanceTagsNone InstanceTagPlacement = iota
+	// InstanceTagsResource adds the instance tags to the resource, exported once per meter, e.g. by the target_info
+	// metric of Prometheus. It suits the pull mode, where the scraper labels every series with its target already.
+	// The resource is shared by every exporter of the meter.
+	InstanceTagsResource
+	// InstanceTagsLabels adds the instance tags as constant labels to every series, which is required in push mode
+	// where the series of the instances pushing to the same gateway are not told apart otherwise.
+	InstanceTagsLabels
+)
+
+// String returns the name of the placement, e.g. "labels".
+func (p InstanceTagPlacement) String() string {
+	switch p {
+	case InstanceTagsNone:
+		return "none"
+	case InstanceTagsResource:
+		return "resource"
+	case InstanceTagsLabels:
+		return "labels"
+	default:
+		return "unknown(" + strconv.Itoa(int(p)) + ")"
+	}
+}
+
+// InstanceTags returns the tags identifying the instance of the application: its hostname, its pid and its LocalIP.
+// The hostname and the IP are omitted when they are unknown.
+func (c *Config) InstanceTags() map[string]string {
+	tags := map[string]string{InstanceTagPID: strconv.Itoa(os.Getpid())}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		tags[InstanceTagHostname] = hostname
+	}
+	if c.LocalIP != "" {
+		tags[InstanceTagIP] = c.LocalIP
+	}
+	return tags
+}
+
+// InstanceTagsInResource reports whether the instance tags are added to the resource, in the pull or the push mode.
+func (c *Config) InstanceTagsInResource() bool {
+	return c.InstanceTagsPull == InstanceTagsResource || c.InstanceTagsPush == InstanceTagsResource
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"github.com/liangweijiang/go-metric/pkg/utils"
+	"os"
+)
+
+// LocalIPEnv is the environment variable overriding the detected local IP, e.g. set to the pod IP by the downward API
+// of Kubernetes.
+const LocalIPEnv = "GO_METRIC_LOCAL_IP"
+
+// NeedsLocalIP reports whether the local IP is used by the meter: in the instance tags, as the job of the push
+// gateway, in the scrape endpoint reported to the metadata service or as the instance of the feature gate.
+func (c *Config) NeedsLocalIP() bool {
+	return c.InstanceTagsPull != InstanceTagsNone || c.InstanceTagsPush != InstanceTagsNone ||
+		c.PushGateway != nil || c.ReportMetric != nil || c.FeatureGate != nil
+}
+
+// ResolveLocalIP sets LocalIP when it is not configured: to the value of the LocalIPEnv environment variable if set,
+// otherwise to the first address of the host which is neither loopback nor link-local, looked up first on the
+// interfaces of LocalIPInterfaces in order, IPv4 being preferred. The failure of the detection is logged and leaves
+// LocalIP empty.
+func (c *Config) ResolveLocalIP() {
+	if c.LocalIP != "" {
+		return
+	}
+	if ip := os.Getenv(LocalIPEnv); ip != "" {
+		c.LocalIP = ip
+		return
+	}
+	ip, err := utils.LocalIP(c.LocalIPInterfaces...)
+	if err != nil {
+		c.WriteErrorOrNot("failed to detect local ip: " + err.Error())
+		return
+	}
+	c.LocalIP = ip
+	c.WriteDebugOrNot("detected local ip " + ip)
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveLocalIP(t *testing.T) {
+	t.Setenv(LocalIPEnv, "10.1.2.3")
+	cfg := &Config{LocalIP: "192.0.2.1"}
+	cfg.ResolveLocalIP()
+	assert.Equal(t, "192.0.2.1", cfg.LocalIP, "the configured ip is kept")
+
+	cfg = &Config{}
+	cfg.ResolveLocalIP()
+	assert.Equal(t, "10.1.2.3", cfg.LocalIP)
+}
+
+func TestNeedsLocalIP(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  *Config
+		want bool
+	}{
+		{name: "default", cfg: &Config{}},
+		{name: "instance tags on pull", cfg: &Config{InstanceTagsPull: InstanceTagsLabels}, want: true},
+		{name: "instance tags on push", cfg: &Config{InstanceTagsPush: InstanceTagsResource}, want: true},
+		{name: "push gateway", cfg: &Config{PushGateway: &PushGatewayCfg{}}, want: true},
+		{name: "report", cfg: &Config{ReportMetric: &ReportMetricCfg{}}, want: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, c.cfg.NeedsLocalIP())
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package config
+
+import "strconv"
+
+// InstrumentOrigin is where the first instrument of a metric was created: the function and the file:line of the first
+// caller outside of the SDK.
+type InstrumentOrigin struct {
+	Metric   string `json:"metric"`
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Location returns the file:line of the origin.
+func (o InstrumentOrigin) Location() string {
+	return o.File + ":" + strconv.Itoa(o.Line)
+}
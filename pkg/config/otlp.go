@@ -0,0 +1,59 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidOTLP is returned when the settings of the OTLP exporter are invalid.
+var ErrInvalidOTLP = errors.New("invalid otlp configuration")
+
+// Default settings of the OTLP exporter, those of the OpenTelemetry specification.
+const (
+	defaultOTLPInterval = time.Minute
+	defaultOTLPTimeout  = 30 * time.Second
+)
+
+// OTLPCfg holds the settings of the OTLP gRPC exporter of the MeterProviderTypeOTLPGrpc provider, pushing the metrics
+// to an OpenTelemetry Collector every Interval, one minute if not set, each export bounded by Timeout, 30 seconds if
+// not set. Endpoint is the host:port of the collector, the OTEL_EXPORTER_OTLP_ENDPOINT environment variable or
+// localhost:4317 if empty. The connection is secured with TLS, the system roots being trusted if TLS is nil, unless
+// Insecure is set. Headers are sent with every export, e.g. an authorization token.
+type OTLPCfg struct {
+	Endpoint string
+	Insecure bool
+	TLS      *tls.Config
+	Headers  map[string]string
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// GetInterval returns the interval between two exports, falling back to the default if not set.
+func (o *OTLPCfg) GetInterval() time.Duration {
+	if o == nil || o.Interval <= 0 {
+		return defaultOTLPInterval
+	}
+	return o.Interval
+}
+
+// GetTimeout returns the time allowed to an export, falling back to the default if not set.
+func (o *OTLPCfg) GetTimeout() time.Duration {
+	if o == nil || o.Timeout <= 0 {
+		return defaultOTLPTimeout
+	}
+	return o.Timeout
+}
+
+// Validate checks that the connection is not both insecure and configured with TLS, and that the durations are not
+// negative.
+func (o *OTLPCfg) Validate() error {
+	if o.Insecure && o.TLS != nil {
+		return fmt.Errorf("%w: insecure connection configured with TLS", ErrInvalidOTLP)
+	}
+	if o.Interval < 0 || o.Timeout < 0 {
+		return fmt.Errorf("%w: negative interval %s or timeout %s", ErrInvalidOTLP, o.Interval, o.Timeout)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+)
+
+// firstRegisteredProviderType is the first type given to the providers registered with RegisterProviderType, far from
+// the types of the providers of the SDK.
+const firstRegisteredProviderType MeterProviderType = 1000
+
+// providerTypes holds the names of the provider types registered out of the SDK.
+var providerTypes = struct {
+	sync.RWMutex
+	names  map[MeterProviderType]string
+	byName map[string]MeterProviderType
+	next   MeterProviderType
+}{
+	names:  make(map[MeterProviderType]string),
+	byName: make(map[string]MeterProviderType),
+	next:   firstRegisteredProviderType,
+}
+
+// providerAliases are the other names the providers of the SDK are parsed from.
+var providerAliases = map[string]MeterProviderType{
+	"otlp_grpc": MeterProviderTypeOTLPGrpc,
+}
+
+// RegisterProviderType allocates a MeterProviderType to the provider named name, for the providers shipped out of the
+// SDK, see meter.RegisterProvider. It returns an error wrapping ErrDuplicateProvider if the name is taken.
+func RegisterProviderType(name string) (MeterProviderType, error) {
+	if name == "" {
+		return 0, fmt.Errorf("%w: empty name", ErrDuplicateProvider)
+	}
+	providerTypes.Lock()
+	defer providerTypes.Unlock()
+	if _, ok := providerTypes.byName[name]; ok || isBuiltinProvider(name) {
+		return 0, fmt.Errorf("%w: %q", ErrDuplicateProvider, name)
+	}
+	t := providerTypes.next
+	providerTypes.next++
+	providerTypes.names[t] = name
+	providerTypes.byName[name] = t
+	return t, nil
+}
+
+// isBuiltinProvider reports whether name is the name, or an alias, of a provider of the SDK.
+func isBuiltinProvider(name string) bool {
+	if _, ok := providerAliases[name]; ok {
+		return true
+	}
+	for t := MeterProviderTypePrometheus; t <= lastBuiltinProviderType; t++ {
+		if t.String() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// registeredProviderName returns the name of a provider type registered with RegisterProviderType.
+func registeredProviderName(t MeterProviderType) (string, bool) {
+	providerTypes.RLock()
+	defer providerTypes.RUnlock()
+	name, ok := providerTypes.names[t]
+	return name, ok
+}
+
+// ParseProviderType returns the type of the provider named name, e.g. "prometheus", "otlp" or the name of a provider
+// registered with RegisterProviderType, or an error wrapping ErrUnsupportedProvider.
+func ParseProviderType(name string) (MeterProviderType, error) {
+	for t := MeterProviderTypePrometheus; t <= lastBuiltinProviderType; t++ {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	if t, ok := providerAliases[name]; ok {
+		return t, nil
+	}
+	providerTypes.RLock()
+	defer providerTypes.RUnlock()
+	if t, ok := providerTypes.byName[name]; ok {
+		return t, nil
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnsupportedProvider, name)
+}
+
+// MarshalText returns the name of the provider type, so that it is written by name in configuration files.
+func (t MeterProviderType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText parses the name of a provider type, so that configuration files select the provider by name.
+func (t *MeterProviderType) UnmarshalText(text []byte) error {
+	parsed, err := ParseProviderType(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// ResolveProviderName sets MeterProvider to the type of the provider named ProviderName, if set, see
+// ParseProviderType. It returns an error wrapping ErrUnsupportedProvider when no provider has this name.
+func (c *Config) ResolveProviderName() error {
+	if c.ProviderName == "" {
+		return nil
+	}
+	t, err := ParseProviderType(c.ProviderName)
+	if err != nil {
+		return err
+	}
+	c.MeterProvider = t
+	return nil
+}
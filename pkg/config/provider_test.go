@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProviderType(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected MeterProviderType
+	}{
+		{name: "prometheus", expected: MeterProviderTypePrometheus},
+		{name: "validate", expected: MeterProviderTypeValidate},
+		{name: "otlp", expected: MeterProviderTypeOTLPGrpc},
+		{name: "otlp_grpc", expected: MeterProviderTypeOTLPGrpc},
+		{name: "statsd", expected: MeterProviderTypeStatsD},
+		{name: "dogstatsd", expected: MeterProviderTypeDogStatsD},
+		{name: "graphite", expected: MeterProviderTypeGraphite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseProviderType(tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, parsed)
+
+			roundTrip, err := ParseProviderType(parsed.String())
+			require.NoError(t, err)
+			assert.Equal(t, parsed, roundTrip)
+		})
+	}
+
+	_, err := ParseProviderType("carrier-pigeon")
+	assert.ErrorIs(t, err, ErrUnsupportedProvider)
+	_, err = RegisterProviderType("otlp_grpc")
+	assert.ErrorIs(t, err, ErrDuplicateProvider, "the aliases are taken")
+}
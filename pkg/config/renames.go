@@ -0,0 +1,31 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidRename is returned when a metric is renamed to an empty name, to itself or to a renamed metric.
+var ErrInvalidRename = errors.New("invalid metric rename")
+
+// Renames maps the old names of renamed metrics to their new names. During the transition period, see
+// Config.RenameUntil, the measurements are recorded under both names, so that the dashboards and alerts can be migrated
+// from the old to the new series without a gap in the data.
+type Renames map[string]string
+
+// Validate checks that the names are not empty, that no metric is renamed to itself and that the renames are not
+// chained, a new name being renamed in turn.
+func (r Renames) Validate() error {
+	for from, to := range r {
+		if from == "" || to == "" {
+			return fmt.Errorf("%w: %q to %q", ErrInvalidRename, from, to)
+		}
+		if from == to {
+			return fmt.Errorf("%w: %s to itself", ErrInvalidRename, from)
+		}
+		if next, ok := r[to]; ok {
+			return fmt.Errorf("%w: %s to %s, renamed to %s", ErrInvalidRename, from, to, next)
+		}
+	}
+	return nil
+}
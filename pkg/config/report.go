@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+)
+
+// defaultReportTimeout is the default time allowed to a request to the metadata service.
+const defaultReportTimeout = time.Second * 5
+
+// ReportMetricCfg holds the settings of the report metric integration, which announces the scrape endpoint of the
+// meter to a central metadata service when the meter starts and withdraws it when the meter stops, so that the
+// scrape targets are discovered without a static configuration.
+// The announcement is POSTed as JSON to Address, and DELETEd with the same body on withdrawal. Interval re-announces
+// the endpoint periodically, e.g. for the services expiring the announcements not renewed, it is announced once if
+// Interval is not positive. Timeout bounds every request, five seconds if not set. Service names the application in
+// the announcement.
+type ReportMetricCfg struct {
+	Address  string
+	Service  string
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// Announcement is the body of the requests announcing and withdrawing the scrape endpoint of a meter.
+// Instance is the IP:port the metrics are scraped from, Path the path of the metrics endpoint.
+type Announcement struct {
+	Service  string            `json:"service,omitempty"`
+	Instance string            `json:"instance"`
+	Path     string            `json:"path"`
+	Env      MeterEnv          `json:"env,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// Enabled reports whether a metadata service address is configured.
+func (r *ReportMetricCfg) Enabled() bool {
+	return r != nil && r.Address != ""
+}
+
+// GetTimeout returns the time allowed to a request to the metadata service, falling back to the default if not set.
+func (r *ReportMetricCfg) GetTimeout() time.Duration {
+	if r.Timeout <= 0 {
+		return defaultReportTimeout
+	}
+	return r.Timeout
+}
+
+// Validate checks that the address of the metadata service is an http(s) URL with a host.
+func (r *ReportMetricCfg) Validate() error {
+	u, err := url.Parse(r.Address)
+	if err != nil {
+		return fmt.Errorf("%w: %q: %v", ErrInvalidReportAddress, r.Address, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: %q: unsupported scheme %q", ErrInvalidReportAddress, r.Address, u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%w: %q: missing host", ErrInvalidReportAddress, r.Address)
+	}
+	return nil
+}
+
+// RedactedAddress returns the address of the metadata service, its password and query being hidden.
+func (r *ReportMetricCfg) RedactedAddress() string {
+	return redactURL(r.Address)
+}
+
+// ScrapeEndpoint returns the IP:port the metrics are scraped from: the address the metrics server is bound to, its
+// host being replaced by LocalIP when the server listens on every interface. ok is false while the server is not
+// listening, or when it listens on every interface and LocalIP is not set.
+func (c *Config) ScrapeEndpoint() (string, bool) {
+	host, port, err := net.SplitHostPort(c.ListenAddr())
+	if err != nil {
+		return "", false
+	}
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		if c.LocalIP == "" {
+			return "", false
+		}
+		host = c.LocalIP
+	}
+	return net.JoinHostPort(host, port), true
+}
@@ -0,0 +1,45 @@
+package config
+
+// Kinds of the servers exporting the metrics of a meter.
+const (
+	ServerKindMetrics     = "metrics"
+	ServerKindManagement  = "management"
+	ServerKindPushGateway = "push_gateway"
+	ServerKindReport      = "report"
+	ServerKindSignalDump  = "signal_dump"
+)
+
+// ServerState describes a server exporting the metrics of a meter: its kind, the address it is bound to, or the
+// redacted address of the gateway it pushes to, and whether it is running.
+type ServerState struct {
+	Kind    string `json:"kind"`
+	Addr    string `json:"addr"`
+	Running bool   `json:"running"`
+}
+
+// ServerInfo describes where the metrics of a meter are exposed, so that applications can log it or register it
+// with a discovery service. Exporters lists the exporter types, e.g. prometheus, push_gateway and the type of every
+// configured reader.
+type ServerInfo struct {
+	Provider  string        `json:"provider"`
+	Running   bool          `json:"running"`
+	Exporters []string      `json:"exporters"`
+	Servers   []ServerState `json:"servers"`
+}
+
+// RedactedAddress returns the gateway address, its password and query, which may carry credentials, being hidden.
+func (p *PushGatewayCfg) RedactedAddress() string {
+	return redactURL(p.GatewayAddress)
+}
+
+// SignalDumpTarget returns where the snapshot is dumped on SIGUSR1, the configured file or stderr, empty when the
+// dump is not enabled.
+func (c *Config) SignalDumpTarget() string {
+	if !c.SignalDump {
+		return ""
+	}
+	if c.SignalDumpPath == "" {
+		return "stderr"
+	}
+	return c.SignalDumpPath
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidStatsD is returned when the settings of the StatsD client are invalid.
+var ErrInvalidStatsD = errors.New("invalid statsd configuration")
+
+// Default settings of the StatsD client. 1432 bytes fit a packet in the Ethernet MTU with the IP and UDP headers.
+const (
+	defaultStatsDAddress       = "127.0.0.1:8125"
+	defaultStatsDFlushInterval = time.Second
+	defaultStatsDPacketSize    = 1432
+	maxStatsDPacketSize        = 65507
+)
+
+// StatsDCfg holds the settings of the StatsD client of the MeterProviderTypeStatsD and MeterProviderTypeDogStatsD
+// providers, sending the measurements as StatsD lines to the UDP Address, 127.0.0.1:8125 if empty, e.g. a statsd
+// daemon, a telegraf agent or a Datadog agent. The lines are buffered in packets of at most MaxPacketSize bytes, 1432
+// if not set, sent when full and every FlushInterval, one second if not set. Prefix is prepended to the metric names,
+// separated by a dot. Distributions makes the DogStatsD provider send the histograms as distributions, aggregated
+// across the hosts by Datadog, instead of histograms aggregated by every agent.
+type StatsDCfg struct {
+	Address       string
+	Prefix        string
+	FlushInterval time.Duration
+	MaxPacketSize int
+	Distributions bool
+}
+
+// GetAddress returns the address of the StatsD daemon, falling back to the default if not set.
+func (s *StatsDCfg) GetAddress() string {
+	if s == nil || s.Address == "" {
+		return defaultStatsDAddress
+	}
+	return s.Address
+}
+
+// GetFlushInterval returns the interval between two sends of the buffered lines, falling back to the default if not
+// set.
+func (s *StatsDCfg) GetFlushInterval() time.Duration {
+	if s == nil || s.FlushInterval <= 0 {
+		return defaultStatsDFlushInterval
+	}
+	return s.FlushInterval
+}
+
+// GetMaxPacketSize returns the maximum size of a packet, falling back to the default if not set.
+func (s *StatsDCfg) GetMaxPacketSize() int {
+	if s == nil || s.MaxPacketSize <= 0 {
+		return defaultStatsDPacketSize
+	}
+	return s.MaxPacketSize
+}
+
+// Validate checks that the flush interval is not negative and that the packets fit a UDP datagram.
+func (s *StatsDCfg) Validate() error {
+	if s.FlushInterval < 0 {
+		return fmt.Errorf("%w: negative flush interval %s", ErrInvalidStatsD, s.FlushInterval)
+	}
+	if s.MaxPacketSize < 0 || s.MaxPacketSize > maxStatsDPacketSize {
+		return fmt.Errorf("%w: packet size %d out of range 1-%d", ErrInvalidStatsD, s.MaxPacketSize, maxStatsDPacketSize)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+// Package derive parses and evaluates the expressions of derived metrics, e.g. errors / requests, computed from the
+// values of other metrics at collection time.
+package derive
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidExpression is returned when an expression cannot be parsed.
+var ErrInvalidExpression = errors.New("invalid expression")
+
+// Expr is a parsed expression combining metric names and numbers with + - * / and parentheses,
+// e.g. (http_errors + rpc_errors) / http_requests * 100.
+type Expr struct {
+	source  string
+	root    node
+	metrics []string
+}
+
+// node is a node of the syntax tree of an expression.
+type node interface {
+	eval(value func(metric string) float64) float64
+}
+
+// number is a constant of an expression.
+type number float64
+
+func (n number) eval(func(string) float64) float64 {
+	return float64(n)
+}
+
+// metric is a reference to the value of a metric.
+type metric string
+
+func (m metric) eval(value func(string) float64) float64 {
+	return value(string(m))
+}
+
+// negate is the unary minus.
+type negate struct {
+	operand node
+}
+
+func (n negate) eval(value func(string) float64) float64 {
+	return -n.operand.eval(value)
+}
+
+// binary is an arithmetic operation on two operands.
+type binary struct {
+	op          byte
+	left, right node
+}
+
+func (b binary) eval(value func(string) float64) float64 {
+	l, r := b.left.eval(value), b.right.eval(value)
+	switch b.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	default:
+		return l / r
+	}
+}
+
+// Parse parses an expression, the error wraps ErrInvalidExpression.
+func Parse(expr string) (*Expr, error) {
+	p := &parser{src: expr, seen: make(map[string]bool)}
+	root, err := p.parseSum()
+	if err == nil && p.skipSpaces() < len(p.src) {
+		err = p.errorf("unexpected %q", p.src[p.pos])
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(p.metrics) == 0 {
+		return nil, fmt.Errorf("%w: %q references no metric", ErrInvalidExpression, expr)
+	}
+	return &Expr{source: expr, root: root, metrics: p.metrics}, nil
+}
+
+// Metrics returns the names of the metrics referenced by the expression, in order of first reference.
+func (e *Expr) Metrics() []string {
+	return e.metrics
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression with the values of the metrics returned by value.
+// ok is false when the result is not a finite number, e.g. on a division by zero.
+func (e *Expr) Eval(value func(metric string) float64) (v float64, ok bool) {
+	v = e.root.eval(value)
+	return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// parser is a recursive descent parser of expressions.
+type parser struct {
+	src     string
+	pos     int
+	metrics []string
+	seen    map[string]bool
+}
+
+// parseSum parses a sum of terms: term (('+' | '-') term)*.
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek('+') || p.peek('-') {
+		op := p.src[p.pos]
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseProduct parses a product of factors: factor (('*' | '/') factor)*.
+func (p *parser) parseProduct() (node, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek('*') || p.peek('/') {
+		op := p.src[p.pos]
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseFactor parses a number, a metric name, a negated factor or a parenthesized sum.
+func (p *parser) parseFactor() (node, error) {
+	if p.skipSpaces() == len(p.src) {
+		return nil, p.errorf("unexpected end")
+	}
+	c := rune(p.src[p.pos])
+	switch {
+	case c == '-':
+		p.pos++
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return negate{operand: operand}, nil
+	case c == '(':
+		p.pos++
+		inner, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(')') {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return inner, nil
+	case unicode.IsDigit(c) || c == '.':
+		start := p.pos
+		for p.pos < len(p.src) && (unicode.IsDigit(rune(p.src[p.pos])) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", p.src[start:p.pos])
+		}
+		return number(v), nil
+	case c == '_' || c == ':' || unicode.IsLetter(c):
+		start := p.pos
+		for p.pos < len(p.src) && isNameChar(rune(p.src[p.pos])) {
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		if !p.seen[name] {
+			p.seen[name] = true
+			p.metrics = append(p.metrics, name)
+		}
+		return metric(name), nil
+	default:
+		return nil, p.errorf("unexpected %q", c)
+	}
+}
+
+// peek skips the spaces and reports whether the next character is c.
+func (p *parser) peek(c byte) bool {
+	return p.skipSpaces() < len(p.src) && p.src[p.pos] == c
+}
+
+// skipSpaces skips the spaces and returns the position of the next character.
+func (p *parser) skipSpaces() int {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	return p.pos
+}
+
+// errorf returns an error wrapping ErrInvalidExpression at the current position.
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %q at %d: %s", ErrInvalidExpression, p.src, p.pos, fmt.Sprintf(format, args...))
+}
+
+// isNameChar reports whether c may appear in a metric name.
+func isNameChar(c rune) bool {
+	return c == '_' || c == ':' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
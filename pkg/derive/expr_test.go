@@ -0,0 +1,44 @@
+package derive
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	values := map[string]float64{"errors": 3, "requests": 12, "timeouts": 1}
+	value := func(metric string) float64 { return values[metric] }
+	tests := []struct {
+		expr    string
+		want    float64
+		metrics []string
+	}{
+		{expr: "errors / requests", want: 0.25, metrics: []string{"errors", "requests"}},
+		{expr: "(errors + timeouts) / requests * 100", want: 100.0 / 3, metrics: []string{"errors", "timeouts", "requests"}},
+		{expr: "1 - errors/requests", want: 0.75, metrics: []string{"errors", "requests"}},
+		{expr: "-errors + requests - errors", want: 6, metrics: []string{"errors", "requests"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.metrics, e.Metrics())
+			v, ok := e.Eval(value)
+			assert.True(t, ok)
+			assert.InDelta(t, tt.want, v, 1e-9)
+		})
+	}
+
+	e, err := Parse("errors / missing")
+	require.NoError(t, err)
+	_, ok := e.Eval(value)
+	assert.False(t, ok, "division by zero")
+
+	for _, invalid := range []string{"", "errors /", "(errors", "errors requests", "1 + 2", "errors % 2"} {
+		_, err := Parse(invalid)
+		assert.True(t, errors.Is(err, ErrInvalidExpression), invalid)
+	}
+}
@@ -0,0 +1,263 @@
+package faas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// RuntimeAPIEnv is the environment variable holding the address of the Lambda runtime API.
+const RuntimeAPIEnv = "AWS_LAMBDA_RUNTIME_API"
+
+// Lambda extension events.
+const (
+	EventInvoke   = "INVOKE"
+	EventShutdown = "SHUTDOWN"
+)
+
+// ErrNotLambda is returned by Start when the runtime API of Lambda is not available.
+var ErrNotLambda = errors.New("not running in AWS Lambda: " + RuntimeAPIEnv + " is not set")
+
+// event is an event of the Lambda extensions API.
+type event struct {
+	EventType  string `json:"eventType"`
+	DeadlineMs int64  `json:"deadlineMs"`
+	RequestID  string `json:"requestId"`
+}
+
+// ExtensionOption configures an Extension.
+type ExtensionOption func(e *Extension)
+
+// WithExtensionName sets the name the extension registers with, go-metric by default.
+func WithExtensionName(name string) ExtensionOption {
+	return func(e *Extension) {
+		e.name = name
+	}
+}
+
+// WithRuntimeAPI sets the address of the runtime API, read from RuntimeAPIEnv by default.
+func WithRuntimeAPI(address string) ExtensionOption {
+	return func(e *Extension) {
+		e.api = address
+	}
+}
+
+// WithExtensionErrorHandler sets the function called with the errors of the extension, which are otherwise ignored.
+func WithExtensionErrorHandler(onError func(err error)) ExtensionOption {
+	return func(e *Extension) {
+		e.onError = onError
+	}
+}
+
+// Extension is an internal Lambda extension flushing the meter at the end of every invocation, after the function
+// returned its response and before the environment is frozen, which Lambda delays until the extension asks for the
+// next event. The function tells the end of an invocation with Done, or runs it with Invoke.
+type Extension struct {
+	meter   interfaces.Meter
+	name    string
+	api     string
+	client  *http.Client
+	onError func(err error)
+	done    chan struct{}
+	mu      sync.Mutex
+	id      string
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+// NewExtension creates an extension flushing m. It does nothing until Start is called.
+func NewExtension(m interfaces.Meter, options ...ExtensionOption) *Extension {
+	e := &Extension{
+		meter:  m,
+		name:   "go-metric",
+		api:    os.Getenv(RuntimeAPIEnv),
+		client: &http.Client{},
+		done:   make(chan struct{}, 1),
+	}
+	for _, option := range options {
+		option(e)
+	}
+	return e
+}
+
+// Start registers the extension for the invocation events, then waits for them until Stop is called or the
+// environment shuts down. It must be called during the initialization of the function, before its first invocation.
+// Calling Start on a started extension does nothing.
+func (e *Extension) Start(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopCh != nil {
+		return nil
+	}
+	if e.api == "" {
+		return ErrNotLambda
+	}
+	id, err := e.register(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to register lambda extension: %w", err)
+	}
+	e.id = id
+	e.stopCh, e.doneCh = make(chan struct{}), make(chan struct{})
+	go e.loop(e.stopCh, e.doneCh)
+	return nil
+}
+
+// Stop stops waiting for the events. The pending invocation, if any, is not flushed.
+func (e *Extension) Stop() {
+	e.mu.Lock()
+	stopCh, doneCh := e.stopCh, e.doneCh
+	e.stopCh, e.doneCh = nil, nil
+	e.mu.Unlock()
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+}
+
+// Done tells the extension the current invocation ended, its metrics are flushed before the environment is frozen.
+func (e *Extension) Done() {
+	select {
+	case e.done <- struct{}{}:
+	default:
+	}
+}
+
+// Invoke calls fn and tells the extension the invocation ended, returning the error of fn without waiting for the
+// flush.
+func (e *Extension) Invoke(ctx context.Context, fn func(ctx context.Context) error) error {
+	defer e.Done()
+	return fn(ctx)
+}
+
+// register registers the extension for the INVOKE events and returns its identifier.
+func (e *Extension) register(ctx context.Context) (string, error) {
+	body, err := json.Marshal(map[string][]string{"events": {EventInvoke}})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url("register"), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Lambda-Extension-Name", e.name)
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("register returned status %d", resp.StatusCode)
+	}
+	return resp.Header.Get("Lambda-Extension-Identifier"), nil
+}
+
+// loop asks for the next event and handles it until stopCh is closed or the environment shuts down.
+func (e *Extension) loop(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for {
+		ev, err := e.next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			e.fail(err)
+			// the runtime API is local, retry after a short pause rather than spinning.
+			select {
+			case <-stopCh:
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+			continue
+		}
+		switch ev.EventType {
+		case EventInvoke:
+			e.awaitInvocation(ctx, ev)
+		case EventShutdown:
+			e.flush(ctx, time.Time{})
+			return
+		}
+	}
+}
+
+// next asks the runtime API for the next event, which also lets Lambda freeze the environment.
+func (e *Extension) next(ctx context.Context) (event, error) {
+	var ev event
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.url("event/next"), nil)
+	if err != nil {
+		return ev, err
+	}
+	req.Header.Set("Lambda-Extension-Identifier", e.id)
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return ev, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ev, fmt.Errorf("next event returned status %d", resp.StatusCode)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&ev)
+	return ev, err
+}
+
+// awaitInvocation waits until the function tells the end of the invocation of ev, or its deadline, then flushes.
+func (e *Extension) awaitInvocation(ctx context.Context, ev event) {
+	var deadline time.Time
+	var timeout <-chan time.Time
+	if ev.DeadlineMs > 0 {
+		deadline = time.UnixMilli(ev.DeadlineMs)
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-e.done:
+	case <-timeout:
+		e.fail(fmt.Errorf("invocation %s reached its deadline without ending", ev.RequestID))
+	case <-ctx.Done():
+		return
+	}
+	e.flush(ctx, deadline)
+}
+
+// flush flushes the meter within DefaultFlushTimeout, or deadline if sooner.
+func (e *Extension) flush(ctx context.Context, deadline time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultFlushTimeout)
+	defer cancel()
+	if !deadline.IsZero() {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadline(ctx, deadline)
+		defer cancelDeadline()
+	}
+	if err := e.meter.Flush(ctx); err != nil {
+		e.fail(err)
+	}
+}
+
+// fail calls the error handler, if any.
+func (e *Extension) fail(err error) {
+	if e.onError != nil {
+		e.onError(err)
+	}
+}
+
+// url returns the URL of the extensions API path.
+func (e *Extension) url(path string) string {
+	return "http://" + e.api + "/2020-01-01/extension/" + path
+}
@@ -0,0 +1,30 @@
+// Package faas exports the metrics of functions frozen between their invocations, e.g. on AWS Lambda, where the
+// metrics recorded by an invocation are lost if they are not exported before the function returns. The meter is
+// created with meter.WithServerless, then flushed at the end of every invocation, either by the function itself with
+// Invoke, or after the response is sent by a Lambda extension.
+package faas
+
+import (
+	"context"
+	"errors"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"time"
+)
+
+// DefaultFlushTimeout is the default time allowed to the flush at the end of an invocation.
+const DefaultFlushTimeout = 2 * time.Second
+
+// Invoke calls fn, then flushes m within DefaultFlushTimeout so that the metrics recorded by the invocation are
+// exported before the function is frozen. The errors of fn and of the flush are joined.
+func Invoke(ctx context.Context, m interfaces.Meter, fn func(ctx context.Context) error) error {
+	err := fn(ctx)
+	return errors.Join(err, EndInvocation(ctx, m))
+}
+
+// EndInvocation flushes m within DefaultFlushTimeout, or the deadline of ctx if sooner, e.g. deferred by the handler
+// of the function.
+func EndInvocation(ctx context.Context, m interfaces.Meter) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultFlushTimeout)
+	defer cancel()
+	return m.Flush(ctx)
+}
@@ -0,0 +1,98 @@
+package faas_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/faas"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gateway records the bodies pushed to it.
+type gateway struct {
+	mu     sync.Mutex
+	pushes []string
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	g.mu.Lock()
+	g.pushes = append(g.pushes, string(body))
+	g.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (g *gateway) count() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.pushes)
+}
+
+func newServerlessMeter(t *testing.T) (interfaces.Meter, *gateway) {
+	g := &gateway{}
+	server := httptest.NewServer(g)
+	t.Cleanup(server.Close)
+	m, err := meter.NewMeter(meter.WithProviderType(config.MeterProviderTypePrometheus), meter.WithServerless(),
+		meter.WithPushGateway(server.URL, time.Minute))
+	require.NoError(t, err)
+	return m, g
+}
+
+func TestInvoke(t *testing.T) {
+	m, g := newServerlessMeter(t)
+	_, listening := meter.ListenAddr(m)
+	assert.False(t, listening, "no server is scraped from in serverless mode")
+	assert.Zero(t, g.count(), "nothing is pushed before the end of the invocation")
+
+	err := faas.Invoke(context.Background(), m, func(ctx context.Context) error {
+		m.NewCounter("invocations", "", "").IncrOne(ctx)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, g.count())
+}
+
+func TestExtension(t *testing.T) {
+	m, g := newServerlessMeter(t)
+	events := make(chan string, 2)
+	events <- faas.EventInvoke
+	events <- faas.EventShutdown
+	runtimeAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/register"):
+			assert.Equal(t, "go-metric", r.Header.Get("Lambda-Extension-Name"))
+			w.Header().Set("Lambda-Extension-Identifier", "ext-1")
+		case strings.HasSuffix(r.URL.Path, "/event/next"):
+			assert.Equal(t, "ext-1", r.Header.Get("Lambda-Extension-Identifier"))
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"eventType":  <-events,
+				"deadlineMs": time.Now().Add(time.Minute).UnixMilli(),
+				"requestId":  "req-1",
+			})
+		}
+	}))
+	defer runtimeAPI.Close()
+
+	ext := faas.NewExtension(m, faas.WithRuntimeAPI(strings.TrimPrefix(runtimeAPI.URL, "http://")))
+	require.NoError(t, ext.Start(context.Background()))
+	require.NoError(t, ext.Invoke(context.Background(), func(ctx context.Context) error {
+		m.NewCounter("invocations", "", "").IncrOne(ctx)
+		return nil
+	}))
+	assert.Eventually(t, func() bool { return g.count() == 2 }, 5*time.Second, 10*time.Millisecond,
+		"flushed at the end of the invocation and at the shutdown")
+	ext.Stop()
+
+	assert.ErrorIs(t, faas.NewExtension(m, faas.WithRuntimeAPI("")).Start(context.Background()), faas.ErrNotLambda)
+}
@@ -5,13 +5,23 @@ import (
 	"time"
 )
 
+// ComponentSpec 定义中间件指标的命名规范：固定的指标名称、描述、单位、直方图分桶以及预定义的标签键。
+// 组件指标按 Tags 的顺序提供标签值，不能添加临时维度，保证同一中间件在所有服务中的指标一致
+type ComponentSpec struct {
+	Name    string
+	Desc    string
+	Unit    string
+	Buckets []float64
+	Tags    []string
+}
+
 // ComponentHistogram 用来记录中间件的直方图
 type ComponentHistogram interface {
 	// Update 记录一段时间耗时
 	Update(ctx context.Context, d time.Duration)
-	// UpdateInSeconds 记录一段单位秒时间耗时
+	// UpdateInSeconds 记录从直方图创建开始的耗时，单位秒
 	UpdateInSeconds(ctx context.Context)
-	// UpdateInMilliseconds 记录一段单位毫秒时间耗时
+	// UpdateInMilliseconds 记录从直方图创建开始的耗时，单位毫秒，与其他耗时一样换算为秒记录
 	UpdateInMilliseconds(ctx context.Context)
 	// UpdateSine 记录从某个时间开始的耗时
 	UpdateSine(ctx context.Context, start time.Time)
@@ -19,13 +29,79 @@ type ComponentHistogram interface {
 
 // ComponentCounter 计数器
 type ComponentCounter interface {
+	// Incr 增加 delta，delta 不能为负数
 	Incr(ctx context.Context, delta float64)
+	// IncrOne 加一
 	IncrOne(ctx context.Context)
 }
 
 // ComponentUpDownCounter 增减计数器可以增加和减少
 type ComponentUpDownCounter interface {
+	// Update 增加 delta，可以为负数
 	Update(ctx context.Context, delta float64)
+	// IncrOne 加一
 	IncrOne(ctx context.Context)
+	// DecrOne 减一
 	DecrOne(ctx context.Context)
 }
+
+// Components 中间件埋点方法，指标名称和标签由组件固定，中间件包只依赖这些组件而不是直接创建指标
+type Components struct {
+	HTTPServer HTTPServerComponent
+	HTTPClient HTTPClientComponent
+	DB         DBComponent
+	Cache      CacheComponent
+	MQ         MQComponent
+}
+
+// HTTPServerComponent HTTP服务端埋点，route 为路由模板而不是原始路径
+type HTTPServerComponent interface {
+	// Requests 请求数
+	Requests(method, route, status string) ComponentCounter
+	// Duration 请求耗时
+	Duration(method, route, status string) ComponentHistogram
+	// InFlight 正在处理的请求数
+	InFlight(method, route string) ComponentUpDownCounter
+}
+
+// HTTPClientComponent HTTP客户端埋点，peerService 为被调用服务的逻辑名称
+type HTTPClientComponent interface {
+	// Requests 请求数
+	Requests(peerService, method, status string) ComponentCounter
+	// Duration 请求耗时
+	Duration(peerService, method, status string) ComponentHistogram
+}
+
+// DBComponent 数据库埋点，system 为数据库类型例如 mysql，operation 为操作例如 select
+type DBComponent interface {
+	// Queries 查询数，outcome 为 success 或 error
+	Queries(system, operation, outcome string) ComponentCounter
+	// Duration 查询耗时
+	Duration(system, operation, outcome string) ComponentHistogram
+}
+
+// CacheComponent 缓存埋点，cache 为缓存名称
+type CacheComponent interface {
+	// Requests 缓存请求数，result 为 hit 或 miss
+	Requests(cache, result string) ComponentCounter
+	// Duration 缓存操作耗时
+	Duration(cache, operation string) ComponentHistogram
+	// RecordHit 记录一次命中
+	RecordHit(ctx context.Context, cache string)
+	// RecordMiss 记录一次未命中
+	RecordMiss(ctx context.Context, cache string)
+	// RecordEviction 记录一次淘汰，reason 为淘汰原因例如 expired、capacity
+	RecordEviction(ctx context.Context, cache, reason string)
+	// ObserveSize 注册缓存条目数的异步gauge，每次采集时调用 size
+	ObserveSize(cache string, size func() float64) Registration
+}
+
+// MQComponent 消息队列埋点
+type MQComponent interface {
+	// Published 发布的消息数，outcome 为 success 或 error
+	Published(topic, outcome string) ComponentCounter
+	// Consumed 消费的消息数
+	Consumed(topic, outcome string) ComponentCounter
+	// ProcessDuration 消息处理耗时
+	ProcessDuration(topic, outcome string) ComponentHistogram
+}
@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 测量所属的指标类型
+const (
+	KindCounter       = "counter"
+	KindUpDownCounter = "updowncounter"
+	KindGauge         = "gauge"
+	KindHistogram     = "histogram"
+)
+
+// Measurement 经过拦截器的一次测量，直方图的时长单位为秒
+type Measurement struct {
+	Kind  string
+	Name  string
+	Tags  []attribute.KeyValue
+	Value float64
+}
+
+// Interceptor 在测量到达后端之前观察、就地修改测量，返回false丢弃该测量，Tags 为测量最终的标签，包括 TagProvider 计算的标签。
+// 修改测量的名称无效；异步gauge的测量在采集时经过拦截器，拦截器不能创建指标
+type Interceptor func(ctx context.Context, m *Measurement) bool
@@ -1,6 +1,14 @@
 package interfaces
 
-import "net/http"
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"net/http"
+	"time"
+)
+
+// TagProvider computes tags at record time, see config.TagProvider.
+type TagProvider = config.TagProvider
 
 // BaseMeter defines an interface for creating and managing metric instruments like counters, up-down counters, gauges, and histograms.
 // It also allows controlling the SDKS's running state and provides an HTTP handler for metric exposition.
@@ -9,17 +17,56 @@ type BaseMeter interface {
 	GetHandler() http.Handler
 	// WithRunning 设置为false，SDK切换为空实现，关闭指标的收集功能
 	WithRunning(on bool)
+	// DisableMetric 运行时关闭指定名称的指标，之后的记录会被丢弃，无需重新部署
+	DisableMetric(metricName string)
+	// EnableMetric 恢复被 DisableMetric 关闭的指标
+	EnableMetric(metricName string)
+	// DeprecateMetric 将指标标记为废弃，replacement 为替代的指标名，可以为空。之后的序列带上 deprecated="true" 标签，
+	// 仍在使用该指标的包会被记录日志并通过自监控指标上报，便于有组织地迁移指标
+	DeprecateMetric(metricName, replacement string)
+	// Use 添加测量拦截器，之后记录的每个测量（包括之前创建的指标和标准组件的测量）按添加顺序经过拦截器后才到达后端，
+	// 拦截器可以观察、修改或丢弃测量，例如统一的标签脱敏或采样策略
+	Use(interceptor Interceptor)
+	// SetLogLevel 运行时调整SDK日志级别
+	SetLogLevel(level config.LogLevel)
+	// Flush 立即导出/推送所有已记录的指标，用于进程退出前或者 preStop 钩子
+	Flush(ctx context.Context) error
 	NewCounter(metricName, desc, unit string) Counter
 	NewUpDownCounter(metricName, desc, unit string) UpDownCounter
 	NewGauge(metricName, desc, unit string) Gauge
 	NewHistogram(metricName, desc, unit string) Histogram
+	// NewObservableGauge 创建异步gauge，每次采集时调用 callback 上报当前值
+	NewObservableGauge(metricName, desc, unit string, callback ObservableCallback) Registration
+	// NewHistogramWithBuckets 创建使用指定分桶的直方图，例如 config.BucketsHTTPServer、config.BucketsDB、config.BucketsCacheFast
+	NewHistogramWithBuckets(metricName, desc, unit string, buckets []float64) Histogram
+	// NewSizeHistogram 创建单位为字节的直方图，使用 config.DefaultSizeBoundaries 分桶，通过 Record 记录
+	NewSizeHistogram(metricName, desc string) Histogram
+	// NewCountHistogram 创建计数直方图，使用2的幂次分桶 config.DefaultCountBoundaries，通过 Record 记录
+	NewCountHistogram(metricName, desc string) Histogram
 }
 
 // Meter extends the BaseMeter interface, adding the capability to retrieve the components
 // associated with the middleware for observability purposes, such as monitoring and distributed tracing.
 type Meter interface {
 	BaseMeter
-	// Components() Components // 返回中间件埋点方法
+	// Components 返回中间件埋点方法
+	Components() Components
+}
+
+// Reloadable is implemented by the meters whose settings can be changed at runtime without being rebuilt,
+// e.g. by a remote configuration controller.
+type Reloadable interface {
+	// SetPushPeriod 运行时修改推送网关的推送周期，从下一次推送开始生效
+	SetPushPeriod(period time.Duration)
+	// SetCardinalityLimit 运行时修改每个指标允许的标签组合数
+	SetCardinalityLimit(limit int)
+}
+
+// Shutdowner is implemented by the meters holding connections to their backends, e.g. the meters pushing their
+// metrics, which must be closed once the meter is no longer used.
+type Shutdowner interface {
+	// Shutdown 停止推送并最后推送一次已记录的指标，然后关闭与后端的连接，之后不再导出任何指标
+	Shutdown(ctx context.Context) error
 }
 
 // MeterServer defines an interface for a metric server that can start and stop its service.
@@ -27,4 +74,8 @@ type Meter interface {
 type MeterServer interface {
 	Start()
 	Stop()
+	// Flush 立即导出一次指标，拉模式的服务可以直接返回nil
+	Flush(ctx context.Context) error
+	// State 返回服务的类型、监听或推送的地址以及运行状态
+	State() config.ServerState
 }
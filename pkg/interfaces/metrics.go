@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"go.opentelemetry.io/otel/attribute"
 	"time"
 )
 
@@ -46,6 +47,8 @@ type Histogram interface {
 	UpdateSine(ctx context.Context, start time.Time)
 	// Time 记录函数执行的耗时
 	Time(f func())
+	// Record 记录一个原始值，用于字节数、数量等非耗时的直方图
+	Record(ctx context.Context, v float64)
 	// AddTag 单次增加一组tag
 	// 不能以 __ 双下划线开头, 否则会自动转义，(^[a-zA-Z_][a-zA-Z0-9_]*$)
 	AddTag(key string, value string) Histogram
@@ -65,3 +68,32 @@ type Gauge interface {
 	// 不能以 __ 双下划线开头, 否则会自动转义，(^[a-zA-Z_][a-zA-Z0-9_]*$)
 	WithTags(tags map[string]string) Gauge
 }
+
+// Attributed is implemented by the instruments of type T accepting typed tags, as the instruments of the SDK do, see
+// tag.Add for the instruments which may not.
+type Attributed[T any] interface {
+	// AddAttributes 增加带类型的tag，数值与布尔值保留其类型，例如 tag.Key("status").Int(200)
+	AddAttributes(attrs ...attribute.KeyValue) T
+}
+
+// Readable is implemented by the Counter, UpDownCounter and Gauge of the meters reading values back, see
+// meter.WithValueReadback, so that adaptive systems such as rate limiters consume the numbers being exported.
+type Readable interface {
+	// Value 返回与本实例标签相同的序列在本地记录的值，Counter 与 UpDownCounter 为累计值，Gauge 为最后一次记录的值；
+	// 未开启回读或该序列尚未记录时 ok 为 false
+	Value(ctx context.Context) (v float64, ok bool)
+}
+
+// Observer is passed to the callbacks of observable instruments to report the current values.
+type Observer interface {
+	// Observe 上报一个观测值，tags 为该观测值的标签
+	Observe(v float64, tags map[string]string)
+}
+
+// ObservableCallback is called at every collection to report the current values of an observable instrument.
+type ObservableCallback func(ctx context.Context, o Observer) error
+
+// Registration is returned by the creation of an observable instrument, it stops the callback when unregistered.
+type Registration interface {
+	Unregister() error
+}
@@ -0,0 +1,109 @@
+// Package lint checks scraped metrics against the Prometheus naming conventions enforced by promtool check metrics,
+// so that applications fail their CI on the metrics promtool would reject, e.g.
+//
+//	snapshot := metertest.Scrape(t, m.GetHandler())
+//	for _, v := range lint.Lint(snapshot) {
+//		t.Error(v)
+//	}
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/analyze"
+	"github.com/liangweijiang/go-metric/pkg/validate"
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint/validations"
+	dto "github.com/prometheus/client_model/go"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Rules reported in the violations, next to the ones of the validate package.
+const (
+	RuleHelp          = "help"
+	RuleUnit          = "unit"
+	RuleCounter       = "counter_suffix"
+	RuleReservedName  = "reserved_name"
+	RuleMetricName    = "metric_name"
+	RuleLabelName     = "label_name"
+	RuleDuplicateName = "duplicate_series"
+)
+
+// check is a promtool validation of a family reported under a rule.
+type check struct {
+	rule     string
+	validate func(mf *dto.MetricFamily) []error
+}
+
+// checks are the validations of promtool check metrics, with its camelCase check split between the metric and the
+// label names.
+var checks = []check{
+	{RuleHelp, validations.LintHelp},
+	{RuleUnit, validations.LintMetricUnits},
+	{RuleCounter, validations.LintCounter},
+	{RuleReservedName, validations.LintHistogramSummaryReserved},
+	{RuleMetricName, validations.LintMetricTypeInName},
+	{RuleMetricName, validations.LintReservedChars},
+	{RuleMetricName, lintMetricNameCase},
+	{RuleUnit, validations.LintUnitAbbreviations},
+	{RuleDuplicateName, validations.LintDuplicateMetric},
+	{RuleLabelName, lintLabelNames},
+}
+
+// camelCase matches the names written in camelCase.
+var camelCase = regexp.MustCompile(`[a-z][A-Z]`)
+
+// Lint returns the violations of the families of snapshot, sorted by metric name: the missing help, the units which
+// are not base units or are abbreviated, the counters without _total suffix, and the metric and label names breaking
+// the naming conventions. A family breaking a rule several times is reported once per rule and message.
+func Lint(snapshot analyze.Snapshot) []validate.Violation {
+	names := make([]string, 0, len(snapshot))
+	for name := range snapshot {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var violations []validate.Violation
+	for _, name := range names {
+		mf := snapshot[name]
+		seen := make(map[string]struct{})
+		for _, c := range checks {
+			for _, err := range c.validate(mf) {
+				key := c.rule + "\x00" + err.Error()
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				violations = append(violations, validate.Violation{Metric: name, Rule: c.rule, Message: err.Error()})
+			}
+		}
+	}
+	return violations
+}
+
+// lintMetricNameCase detects the metric names written in camelCase.
+func lintMetricNameCase(mf *dto.MetricFamily) []error {
+	if camelCase.MatchString(mf.GetName()) {
+		return []error{errors.New("metric names should be written in 'snake_case' not 'camelCase'")}
+	}
+	return nil
+}
+
+// lintLabelNames detects the label names written in camelCase or starting with __, which is reserved to the labels
+// internal to Prometheus. The label names reserved to histograms and summaries are reported by RuleReservedName.
+func lintLabelNames(mf *dto.MetricFamily) []error {
+	var problems []error
+	for _, m := range mf.GetMetric() {
+		for _, l := range m.GetLabel() {
+			name := l.GetName()
+			if camelCase.MatchString(name) {
+				problems = append(problems, errors.New("label names should be written in 'snake_case' not 'camelCase'"))
+			}
+			if strings.HasPrefix(name, "__") {
+				problems = append(problems, fmt.Errorf("label name %q must not start with '__'", name))
+			}
+		}
+	}
+	return problems
+}
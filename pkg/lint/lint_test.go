@@ -0,0 +1,36 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/pkg/analyze"
+	"github.com/liangweijiang/go-metric/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exposition = `# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{route="/a"} 3
+http_requests_total{route="/b"} 1
+# TYPE queue_latency_milliseconds gauge
+queue_latency_milliseconds 12
+# HELP cache_hits Hits of the cache.
+# TYPE cache_hits counter
+cache_hits{cacheName="users",le="1"} 7
+cache_hits{cacheName="orders",le="2"} 2
+`
+
+func TestLint(t *testing.T) {
+	snapshot, err := analyze.Parse(strings.NewReader(exposition))
+	require.NoError(t, err)
+
+	assert.Equal(t, []validate.Violation{
+		{Metric: "cache_hits", Rule: RuleCounter, Message: `counter metrics should have "_total" suffix`},
+		{Metric: "cache_hits", Rule: RuleReservedName, Message: `non-histogram metrics should not have "le" label`},
+		{Metric: "cache_hits", Rule: RuleLabelName, Message: "label names should be written in 'snake_case' not 'camelCase'"},
+		{Metric: "queue_latency_milliseconds", Rule: RuleHelp, Message: "no help text"},
+		{Metric: "queue_latency_milliseconds", Rule: RuleUnit, Message: `use base unit "seconds" instead of "milliseconds"`},
+	}, Lint(snapshot))
+}
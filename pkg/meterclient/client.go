@@ -0,0 +1,430 @@
+package meterclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/liangweijiang/go-metric/internal/component"
+	"github.com/liangweijiang/go-metric/pkg/agent"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// _ is a blank identifier used for type assertion to ensure that *Client implements the interfaces.Meter interface.
+var _ interfaces.Meter = (*Client)(nil)
+
+// Sizes of the batches of measurements, a batch is sent once it would exceed them.
+// UDP batches stay below the usual MTU to avoid fragmentation.
+const (
+	udpBatchSize  = 1400
+	httpBatchSize = 1 << 20
+)
+
+// httpTimeout bounds the requests sent to the HTTP ingestion endpoint of the agent.
+const httpTimeout = 5 * time.Second
+
+// DefaultFlushInterval is the interval at which the client flushes the pending measurements when no other is given.
+const DefaultFlushInterval = 10 * time.Second
+
+// DialOption configures a Client created by Dial.
+type DialOption func(c *Client)
+
+// WithFlushInterval returns a DialOption flushing the pending measurements every interval instead of
+// DefaultFlushInterval, never if interval is not positive.
+func WithFlushInterval(interval time.Duration) DialOption {
+	return func(c *Client) {
+		c.flushInterval = interval
+	}
+}
+
+// Client implements interfaces.Meter by sending the measurements to an aggregating agent (see package agent),
+// so that short-lived processes such as batch jobs and CLI tools are instrumented like services.
+// Measurements are batched and sent when a batch is full, every flush interval, on Flush and on Close, which must be
+// called before exiting. Observable gauges are observed on every Flush. The batches are sent without holding the lock
+// of the client, so that the measurements recorded meanwhile do not wait for the network.
+type Client struct {
+	conn       net.Conn
+	url        string
+	httpClient *http.Client
+	batchSize  int
+	running    int32
+	disabled   sync.Map
+	deprecated sync.Map
+	useMu      sync.Mutex
+	chain      atomic.Pointer[[]interfaces.Interceptor]
+	mu         sync.Mutex
+	buf        bytes.Buffer
+	err        error
+	gauges     map[int]*observer
+	nextID     int
+
+	flushInterval time.Duration
+	done          chan struct{}
+	stopped       chan struct{}
+	closeOnce     sync.Once
+}
+
+// Dial connects to the agent at addr, either http://host:port/path for its HTTP ingestion endpoint,
+// or udp://host:port or host:port for its UDP listener, and starts flushing the measurements periodically.
+func Dial(addr string, options ...DialOption) (*Client, error) {
+	c := &Client{
+		running:       1,
+		gauges:        make(map[int]*observer),
+		flushInterval: DefaultFlushInterval,
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	switch {
+	case strings.HasPrefix(addr, "http://"), strings.HasPrefix(addr, "https://"):
+		c.url = addr
+		c.httpClient = &http.Client{Timeout: httpTimeout}
+		c.batchSize = httpBatchSize
+	default:
+		conn, err := net.Dial("udp", strings.TrimPrefix(addr, "udp://"))
+		if err != nil {
+			return nil, fmt.Errorf("dial agent %s: %w", addr, err)
+		}
+		c.conn = conn
+		c.batchSize = udpBatchSize
+	}
+	go c.flushLoop()
+	return c, nil
+}
+
+// flushLoop flushes the pending measurements every flush interval until the client is closed. The errors are kept to
+// be returned by the next Flush.
+func (c *Client) flushLoop() {
+	defer close(c.stopped)
+	if c.flushInterval <= 0 {
+		<-c.done
+		return
+	}
+	ticker := time.NewTicker(c.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+			if err := c.Flush(ctx); err != nil {
+				c.mu.Lock()
+				c.err = errors.Join(c.err, err)
+				c.mu.Unlock()
+			}
+			cancel()
+		}
+	}
+}
+
+// Close stops the periodic flushes, flushes the pending measurements and releases the connection to the agent.
+// Calling it again does nothing.
+func (c *Client) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		<-c.stopped
+		err = c.Flush(context.Background())
+		if c.conn != nil {
+			err = errors.Join(err, c.conn.Close())
+		}
+	})
+	return err
+}
+
+// GetHandler returns a handler answering 404 Not Found, the metrics are exposed by the agent.
+func (c *Client) GetHandler() http.Handler {
+	return http.NotFoundHandler()
+}
+
+// WithRunning switches the sending of measurements on or off.
+func (c *Client) WithRunning(on bool) {
+	if on {
+		atomic.StoreInt32(&c.running, 1)
+		return
+	}
+	atomic.StoreInt32(&c.running, 0)
+}
+
+// DisableMetric drops the later measurements of the metric with the given name.
+func (c *Client) DisableMetric(metricName string) {
+	c.disabled.Store(metricName, struct{}{})
+}
+
+// EnableMetric resumes sending the measurements of a metric disabled with DisableMetric.
+func (c *Client) EnableMetric(metricName string) {
+	c.disabled.Delete(metricName)
+}
+
+// DeprecateMetric sends the later measurements of the metric with the given name with the deprecated="true" tag.
+func (c *Client) DeprecateMetric(metricName, _ string) {
+	c.deprecated.Store(metricName, struct{}{})
+}
+
+// Use appends interceptor to the interceptors of the measurements, which are sent as modified by them.
+func (c *Client) Use(interceptor interfaces.Interceptor) {
+	c.useMu.Lock()
+	defer c.useMu.Unlock()
+	var chain []interfaces.Interceptor
+	if current := c.chain.Load(); current != nil {
+		chain = append(chain, *current...)
+	}
+	chain = append(chain, interceptor)
+	c.chain.Store(&chain)
+}
+
+// SetLogLevel does nothing, the client does not log.
+func (c *Client) SetLogLevel(_ config.LogLevel) {}
+
+// Flush observes the observable gauges and sends the pending measurements to the agent.
+// It returns the errors of the batches sent since the previous Flush as well.
+func (c *Client) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	gauges := make([]*observer, 0, len(c.gauges))
+	for _, g := range c.gauges {
+		gauges = append(gauges, g)
+	}
+	c.mu.Unlock()
+
+	var errs []error
+	for _, g := range gauges {
+		observed := *g
+		observed.ctx = ctx
+		if err := g.callback(ctx, &observed); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	c.mu.Lock()
+	batch := c.takeLocked()
+	errs = append(errs, c.err)
+	c.err = nil
+	c.mu.Unlock()
+	errs = append(errs, c.send(ctx, batch))
+	return errors.Join(errs...)
+}
+
+// NewCounter creates a Counter sent to the agent.
+func (c *Client) NewCounter(metricName, desc, unit string) interfaces.Counter {
+	return &counter{instrument: c.newInstrument(agent.KindCounter, metricName, desc, unit, nil)}
+}
+
+// NewUpDownCounter creates an UpDownCounter sent to the agent.
+func (c *Client) NewUpDownCounter(metricName, desc, unit string) interfaces.UpDownCounter {
+	return &upDownCounter{instrument: c.newInstrument(agent.KindUpDownCounter, metricName, desc, unit, nil)}
+}
+
+// NewGauge creates a Gauge sent to the agent.
+func (c *Client) NewGauge(metricName, desc, unit string) interfaces.Gauge {
+	return &gauge{instrument: c.newInstrument(agent.KindGauge, metricName, desc, unit, nil)}
+}
+
+// NewHistogram creates a Histogram sent to the agent, using the boundaries configured on the agent.
+func (c *Client) NewHistogram(metricName, desc, unit string) interfaces.Histogram {
+	return &histogram{instrument: c.newInstrument(agent.KindHistogram, metricName, desc, unit, nil)}
+}
+
+// NewHistogramWithBuckets creates a Histogram sent to the agent with the given boundaries.
+func (c *Client) NewHistogramWithBuckets(metricName, desc, unit string, buckets []float64) interfaces.Histogram {
+	return &histogram{instrument: c.newInstrument(agent.KindHistogram, metricName, desc, unit, buckets)}
+}
+
+// NewSizeHistogram creates a Histogram in bytes sent to the agent, using config.DefaultSizeBoundaries.
+func (c *Client) NewSizeHistogram(metricName, desc string) interfaces.Histogram {
+	return c.NewHistogramWithBuckets(metricName, desc, config.UnitBytes, config.DefaultSizeBoundaries)
+}
+
+// NewCountHistogram creates a count Histogram sent to the agent, using config.DefaultCountBoundaries.
+func (c *Client) NewCountHistogram(metricName, desc string) interfaces.Histogram {
+	return c.NewHistogramWithBuckets(metricName, desc, config.UnitCount, config.DefaultCountBoundaries)
+}
+
+// NewObservableGauge registers callback, called on every Flush to send the current values as gauges.
+func (c *Client) NewObservableGauge(metricName, desc, unit string, callback interfaces.ObservableCallback) interfaces.Registration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.nextID
+	c.nextID++
+	c.gauges[id] = &observer{
+		client:   c,
+		name:     metricName,
+		desc:     desc,
+		unit:     unit,
+		callback: callback,
+	}
+	return &registration{
+		client: c,
+		id:     id,
+	}
+}
+
+// newInstrument creates the common part of the instruments.
+func (c *Client) newInstrument(kind agent.Kind, name, desc, unit string, buckets []float64) instrument {
+	return instrument{
+		client: c,
+		measurement: agent.Measurement{
+			Kind:    kind,
+			Name:    name,
+			Desc:    desc,
+			Unit:    unit,
+			Buckets: buckets,
+		},
+	}
+}
+
+// record appends m, as modified by the interceptors, to the current batch, sending the batch first if m would not fit
+// in it. Nothing is recorded when the client is switched off, the metric disabled or an interceptor drops m.
+func (c *Client) record(ctx context.Context, m agent.Measurement) {
+	if atomic.LoadInt32(&c.running) == 0 {
+		return
+	}
+	if _, disabled := c.disabled.Load(m.Name); disabled {
+		return
+	}
+	if _, deprecated := c.deprecated.Load(m.Name); deprecated {
+		tags := make(map[string]string, len(m.Tags)+1)
+		for k, v := range m.Tags {
+			tags[k] = v
+		}
+		tags["deprecated"] = "true"
+		m.Tags = tags
+	}
+	if !c.intercept(ctx, &m) {
+		return
+	}
+	line, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	var batch []byte
+	if c.buf.Len() > 0 && c.buf.Len()+len(line)+1 > c.batchSize {
+		batch = c.takeLocked()
+	}
+	c.buf.Write(line)
+	c.buf.WriteByte('\n')
+	c.mu.Unlock()
+	if err := c.send(context.Background(), batch); err != nil {
+		c.mu.Lock()
+		c.err = errors.Join(c.err, err)
+		c.mu.Unlock()
+	}
+}
+
+// intercept gives m to the interceptors, setting its tags and value to those they leave, and returns false if one of
+// them drops it. The tags are sent as strings, the wire protocol only carrying strings.
+func (c *Client) intercept(ctx context.Context, m *agent.Measurement) bool {
+	chain := c.chain.Load()
+	if chain == nil {
+		return true
+	}
+	measurement := &interfaces.Measurement{
+		Kind:  string(m.Kind),
+		Name:  m.Name,
+		Tags:  make([]attribute.KeyValue, 0, len(m.Tags)),
+		Value: m.Value,
+	}
+	for k, v := range m.Tags {
+		measurement.Tags = append(measurement.Tags, attribute.String(k, v))
+	}
+	for _, interceptor := range *chain {
+		if !interceptor(ctx, measurement) {
+			return false
+		}
+	}
+	m.Tags = make(map[string]string, len(measurement.Tags))
+	for _, kv := range measurement.Tags {
+		m.Tags[string(kv.Key)] = kv.Value.Emit()
+	}
+	m.Value = measurement.Value
+	return true
+}
+
+// takeLocked returns a copy of the current batch and resets it, c.mu must be held.
+func (c *Client) takeLocked() []byte {
+	if c.buf.Len() == 0 {
+		return nil
+	}
+	batch := bytes.Clone(c.buf.Bytes())
+	c.buf.Reset()
+	return batch
+}
+
+// send sends batch to the agent, c.mu must not be held.
+func (c *Client) send(ctx context.Context, batch []byte) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	if c.conn != nil {
+		_, err := c.conn.Write(batch)
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(batch))
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("agent %s returned status %d", c.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// observer is an observable gauge of the client, it sends the values reported by its callback.
+type observer struct {
+	client   *Client
+	ctx      context.Context
+	name     string
+	desc     string
+	unit     string
+	callback interfaces.ObservableCallback
+}
+
+// Observe records v as a gauge measurement with the given tags.
+func (o *observer) Observe(v float64, tags map[string]string) {
+	o.client.record(o.ctx, agent.Measurement{
+		Kind:  agent.KindGauge,
+		Name:  o.name,
+		Desc:  o.desc,
+		Unit:  o.unit,
+		Value: v,
+		Tags:  tags,
+	})
+}
+
+// registration removes the callback of an observable gauge from the client.
+type registration struct {
+	client *Client
+	id     int
+}
+
+// Unregister stops calling the callback on Flush.
+func (r *registration) Unregister() error {
+	r.client.mu.Lock()
+	defer r.client.mu.Unlock()
+	delete(r.client.gauges, r.id)
+	return nil
+}
+
+// Components returns the standard components sending their measurements to the agent.
+func (c *Client) Components() interfaces.Components {
+	return component.NewComponents(func() interfaces.BaseMeter {
+		return c
+	})
+}
@@ -0,0 +1,130 @@
+package meterclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/agent"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestClientHTTP(t *testing.T) {
+	m, err := meter.NewMeter(meter.WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	srv := httptest.NewServer(agent.NewServer(m))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	require.NoError(t, err)
+	ctx := context.Background()
+	c.NewCounter("files_copied", "", "").AddTag("job", "sync").Incr(ctx, 3)
+	c.NewSizeHistogram("file_size", "").Record(ctx, 100)
+	c.DisableMetric("ignored")
+	c.NewGauge("ignored", "", "").Update(ctx, 1)
+	c.NewObservableGauge("progress", "", "", func(_ context.Context, o interfaces.Observer) error {
+		o.Observe(0.5, nil)
+		return nil
+	})
+	require.NoError(t, c.Close())
+
+	rec := httptest.NewRecorder()
+	m.GetHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := rec.Body.String()
+	assert.Contains(t, out, `files_copied_total{job="sync"} 3`)
+	assert.Contains(t, out, `file_size_bytes_count 1`)
+	assert.Contains(t, out, `progress 0.5`)
+	assert.NotContains(t, out, `ignored`)
+}
+
+func TestClientUse(t *testing.T) {
+	m, err := meter.NewMeter(meter.WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	srv := httptest.NewServer(agent.NewServer(m))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	require.NoError(t, err)
+	c.Use(func(_ context.Context, ms *interfaces.Measurement) bool {
+		for i, kv := range ms.Tags {
+			if kv.Key == "user" {
+				ms.Tags[i] = attribute.String("user", "redacted")
+			}
+		}
+		return ms.Name != "dropped"
+	})
+	ctx := context.Background()
+	c.NewCounter("logins", "", "").AddTag("user", "jane").IncrOne(ctx)
+	c.NewGauge("dropped", "", "").Update(ctx, 1)
+	require.NoError(t, c.Close())
+
+	rec := httptest.NewRecorder()
+	m.GetHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := rec.Body.String()
+	assert.Contains(t, out, `logins_total{user="redacted"} 1`)
+	assert.NotContains(t, out, `dropped`)
+}
+
+func TestClientFlushInterval(t *testing.T) {
+	m, err := meter.NewMeter(meter.WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	srv := httptest.NewServer(agent.NewServer(m))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL, WithFlushInterval(20*time.Millisecond))
+	require.NoError(t, err)
+	defer c.Close()
+	c.NewCounter("files_copied", "", "").IncrOne(context.Background())
+
+	assert.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		m.GetHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return strings.Contains(rec.Body.String(), "files_copied_total 1")
+	}, 2*time.Second, 20*time.Millisecond, "the measurements are flushed without calling Flush")
+}
+
+func TestClientSendOutsideLock(t *testing.T) {
+	release := make(chan struct{})
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			<-release
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c, err := Dial(srv.URL, WithFlushInterval(0))
+	require.NoError(t, err)
+	defer c.Close()
+	c.batchSize = 1
+	ctx := context.Background()
+	counter := c.NewCounter("files_copied", "", "")
+	counter.IncrOne(ctx)
+	// the second measurement sends the first one, whose request blocks until released.
+	go counter.IncrOne(ctx)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&requests) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	recorded := make(chan struct{})
+	go func() {
+		counter.IncrOne(ctx)
+		close(recorded)
+	}()
+	select {
+	case <-recorded:
+	case <-time.After(time.Second):
+		t.Fatal("recording waited for the batch being sent")
+	}
+}
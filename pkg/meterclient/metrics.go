@@ -0,0 +1,198 @@
+package meterclient
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/agent"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"time"
+)
+
+// instrument holds the client and the measurement template shared by the instruments.
+type instrument struct {
+	client      *Client
+	measurement agent.Measurement
+}
+
+// addTag sets a tag of the measurement template.
+func (i *instrument) addTag(key, value string) {
+	if i.measurement.Tags == nil {
+		i.measurement.Tags = make(map[string]string)
+	}
+	i.measurement.Tags[key] = value
+}
+
+// withTags sets the tags of the measurement template.
+func (i *instrument) withTags(tags map[string]string) {
+	for k, v := range tags {
+		i.addTag(k, v)
+	}
+}
+
+// addAttributes sets typed attributes as tags of the measurement template, the wire protocol only carries strings.
+func (i *instrument) addAttributes(attrs []attribute.KeyValue) {
+	for _, kv := range attrs {
+		i.addTag(string(kv.Key), kv.Value.Emit())
+	}
+}
+
+// record sends a measurement of value v.
+func (i *instrument) record(ctx context.Context, v float64) {
+	m := i.measurement
+	m.Value = v
+	i.client.record(ctx, m)
+}
+
+// counter is the interfaces.Counter of the client.
+type counter struct {
+	instrument
+}
+
+// Incr adds delta to the counter.
+func (c *counter) Incr(ctx context.Context, delta float64) {
+	c.record(ctx, delta)
+}
+
+// IncrOne adds one to the counter.
+func (c *counter) IncrOne(ctx context.Context) {
+	c.Incr(ctx, 1)
+}
+
+// AddTag adds a tag to the counter.
+func (c *counter) AddTag(key string, value string) interfaces.Counter {
+	c.addTag(key, value)
+	return c
+}
+
+// WithTags adds the tags of the map to the counter.
+func (c *counter) WithTags(tags map[string]string) interfaces.Counter {
+	c.withTags(tags)
+	return c
+}
+
+// AddAttributes adds typed attributes to the counter, sent as strings.
+func (c *counter) AddAttributes(attrs ...attribute.KeyValue) interfaces.Counter {
+	c.addAttributes(attrs)
+	return c
+}
+
+// upDownCounter is the interfaces.UpDownCounter of the client.
+type upDownCounter struct {
+	instrument
+}
+
+// Update adds delta, possibly negative, to the counter.
+func (c *upDownCounter) Update(ctx context.Context, delta float64) {
+	c.record(ctx, delta)
+}
+
+// IncrOne adds one to the counter.
+func (c *upDownCounter) IncrOne(ctx context.Context) {
+	c.Update(ctx, 1)
+}
+
+// DecrOne subtracts one from the counter.
+func (c *upDownCounter) DecrOne(ctx context.Context) {
+	c.Update(ctx, -1)
+}
+
+// AddTag adds a tag to the counter.
+func (c *upDownCounter) AddTag(key string, value string) interfaces.UpDownCounter {
+	c.addTag(key, value)
+	return c
+}
+
+// WithTags adds the tags of the map to the counter.
+func (c *upDownCounter) WithTags(tags map[string]string) interfaces.UpDownCounter {
+	c.withTags(tags)
+	return c
+}
+
+// AddAttributes adds typed attributes to the counter, sent as strings.
+func (c *upDownCounter) AddAttributes(attrs ...attribute.KeyValue) interfaces.UpDownCounter {
+	c.addAttributes(attrs)
+	return c
+}
+
+// gauge is the interfaces.Gauge of the client.
+type gauge struct {
+	instrument
+}
+
+// Update sets the gauge to v.
+func (g *gauge) Update(ctx context.Context, v float64) {
+	g.record(ctx, v)
+}
+
+// AddTag adds a tag to the gauge.
+func (g *gauge) AddTag(key string, value string) interfaces.Gauge {
+	g.addTag(key, value)
+	return g
+}
+
+// WithTags adds the tags of the map to the gauge.
+func (g *gauge) WithTags(tags map[string]string) interfaces.Gauge {
+	g.withTags(tags)
+	return g
+}
+
+// AddAttributes adds typed attributes to the gauge, sent as strings.
+func (g *gauge) AddAttributes(attrs ...attribute.KeyValue) interfaces.Gauge {
+	g.addAttributes(attrs)
+	return g
+}
+
+// histogram is the interfaces.Histogram of the client, durations are sent in seconds.
+type histogram struct {
+	instrument
+}
+
+// Update records a duration.
+func (h *histogram) Update(ctx context.Context, d time.Duration) {
+	h.UpdateInSeconds(ctx, d.Seconds())
+}
+
+// UpdateInSeconds records a duration in seconds.
+func (h *histogram) UpdateInSeconds(ctx context.Context, s float64) {
+	h.Record(ctx, s)
+}
+
+// UpdateInMilliseconds records a duration in milliseconds.
+func (h *histogram) UpdateInMilliseconds(ctx context.Context, m float64) {
+	h.UpdateInSeconds(ctx, m/1000)
+}
+
+// UpdateSine records the time elapsed since start.
+func (h *histogram) UpdateSine(ctx context.Context, start time.Time) {
+	h.Update(ctx, time.Since(start))
+}
+
+// Time records the duration of f.
+func (h *histogram) Time(f func()) {
+	start := time.Now()
+	f()
+	h.UpdateSine(context.Background(), start)
+}
+
+// Record records the raw value v.
+func (h *histogram) Record(ctx context.Context, v float64) {
+	h.record(ctx, v)
+}
+
+// AddTag adds a tag to the histogram.
+func (h *histogram) AddTag(key string, value string) interfaces.Histogram {
+	h.addTag(key, value)
+	return h
+}
+
+// WithTags adds the tags of the map to the histogram.
+func (h *histogram) WithTags(tags map[string]string) interfaces.Histogram {
+	h.withTags(tags)
+	return h
+}
+
+// AddAttributes adds typed attributes to the histogram, sent as strings.
+func (h *histogram) AddAttributes(attrs ...attribute.KeyValue) interfaces.Histogram {
+	h.addAttributes(attrs)
+	return h
+}
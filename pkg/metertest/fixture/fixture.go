@@ -0,0 +1,116 @@
+// Package fixture boots a full PrometheusMeter behind an httptest server for end-to-end tests, scraping the meter
+// over HTTP as Prometheus does. It is the reference harness for the integration tests of the services:
+//
+//	func TestCheckout(t *testing.T) {
+//		f := fixture.Start(t)
+//		checkout(f.Meter)
+//		f.Assert(t, `orders_total{status="paid"} 1`)
+//	}
+package fixture
+
+import (
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// MetricsPath is the path on which the fixture serves the handler of the meter.
+const MetricsPath = "/metrics"
+
+// Fixture is a running PrometheusMeter served on an ephemeral port of the loopback interface.
+type Fixture struct {
+	// Meter is the meter under test, the measurements recorded to it are exposed on URL.
+	Meter interfaces.Meter
+	// Server is the httptest server exposing the meter.
+	Server *httptest.Server
+	// URL is the address of the metrics endpoint, e.g. http://127.0.0.1:41234/metrics.
+	URL string
+}
+
+// Start creates a PrometheusMeter configured with options, starts it and serves its handler on an ephemeral port.
+// The meter is stopped and the server closed when the test ends. The provider is always Prometheus, and the meter
+// does not listen on its own port: the httptest server stands for it.
+func Start(t testing.TB, options ...interfaces.Option) *Fixture {
+	t.Helper()
+	options = append(options, meter.WithProviderType(config.MeterProviderTypePrometheus), meter.WithPrometheusPort(0))
+	m, err := meter.NewMeter(options...)
+	if err != nil {
+		t.Fatalf("create meter: %v", err)
+	}
+	m.WithRunning(true)
+
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, m.GetHandler())
+	server := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		server.Close()
+		m.WithRunning(false)
+	})
+	return &Fixture{
+		Meter:  m,
+		Server: server,
+		URL:    server.URL + MetricsPath,
+	}
+}
+
+// Global creates a PrometheusMeter configured with options and installs it as the global meter, for the tests of the
+// code recording to the global meter. The previous global meter is restored and the meter stopped when the test ends.
+func Global(t testing.TB, options ...interfaces.Option) interfaces.Meter {
+	t.Helper()
+	options = append(options, meter.WithProviderType(config.MeterProviderTypePrometheus))
+	m, err := meter.NewMeter(options...)
+	if err != nil {
+		t.Fatalf("create meter: %v", err)
+	}
+	previous := meter.GetGlobalMeter()
+	meter.SetGlobalMeter(m)
+	t.Cleanup(func() {
+		meter.SetGlobalMeter(previous)
+		m.WithRunning(false)
+	})
+	return m
+}
+
+// Scrape gets the metrics endpoint over HTTP and returns the exposition, failing t on error.
+func (f *Fixture) Scrape(t testing.TB) string {
+	t.Helper()
+	resp, err := f.Server.Client().Get(f.URL)
+	if err != nil {
+		t.Fatalf("scrape %s: %v", f.URL, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read scrape: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("scrape returned status %d: %s", resp.StatusCode, body)
+	}
+	return string(body)
+}
+
+// Assert scrapes the metrics endpoint over HTTP and asserts on the series like metertest.ScrapeAndAssert.
+func (f *Fixture) Assert(t testing.TB, expected ...string) bool {
+	t.Helper()
+	body := f.Scrape(t)
+	return metertest.ScrapeAndAssert(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}), expected...)
+}
+
+// AssertGolden scrapes the metrics endpoint over HTTP and compares its schema, the series without their values,
+// with the golden file at path like metertest.AssertGolden.
+func (f *Fixture) AssertGolden(t testing.TB, path string) bool {
+	t.Helper()
+	body := f.Scrape(t)
+	return metertest.AssertGolden(t, metertest.SchemaSnapshot(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, body)
+	})), path)
+}
@@ -0,0 +1,39 @@
+package fixture
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixture(t *testing.T) {
+	f := Start(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		f.Meter.NewCounter("orders", "orders placed", "").AddTag("status", "paid").IncrOne(ctx)
+	}
+	f.Meter.NewCounter("orders", "orders placed", "").AddTag("status", "failed").IncrOne(ctx)
+
+	assert.Contains(t, f.Scrape(t), `orders_total{status="paid"} 3`)
+	assert.True(t, f.Assert(t, `orders_total{status="failed"} 1`, `orders_total{status="paid"} 3`))
+
+	path := filepath.Join(t.TempDir(), "orders.golden")
+	t.Setenv(metertest.UpdateGoldenEnv, "1")
+	assert.True(t, f.AssertGolden(t, path))
+}
+
+func TestGlobal(t *testing.T) {
+	previous := meter.GetGlobalMeter()
+	t.Run("Installed", func(t *testing.T) {
+		m := Global(t)
+		assert.Same(t, m, meter.GetGlobalMeter())
+		meter.GetGlobalMeter().NewCounter("orders", "", "").IncrOne(context.Background())
+		metertest.ScrapeAndAssert(t, m.GetHandler(), `orders_total 1`)
+	})
+	assert.Same(t, previous, meter.GetGlobalMeter(), "the global meter is restored when the test ends")
+}
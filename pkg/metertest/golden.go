@@ -0,0 +1,129 @@
+package metertest
+
+import (
+	"fmt"
+	dto "github.com/prometheus/client_model/go"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv is the environment variable which, set to a non-empty value, makes AssertGolden rewrite the golden
+// files with the current snapshots instead of comparing them, e.g. UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// targetInfoFamily is the family of the resource attributes, which depend on the host and are left out of snapshots.
+const targetInfoFamily = "target_info"
+
+// Snapshot scrapes handler and returns a normalized snapshot of the exposition: the families are sorted by name,
+// each preceded by its TYPE line, the series are sorted, the HELP lines, timestamps and target_info are left out.
+// The snapshot is stable across runs recording the same measurements.
+func Snapshot(t testing.TB, handler http.Handler) string {
+	t.Helper()
+	return snapshot(Scrape(t, handler), true)
+}
+
+// SchemaSnapshot is like Snapshot without the values, listing the series only, so that the golden file of a service
+// catches renamed and removed metrics, tags and buckets whatever the measurements recorded by the test.
+func SchemaSnapshot(t testing.TB, handler http.Handler) string {
+	t.Helper()
+	return snapshot(Scrape(t, handler), false)
+}
+
+// snapshot formats the families, with or without the values.
+func snapshot(families map[string]*dto.MetricFamily, values bool) string {
+	names := make([]string, 0, len(families))
+	for name := range families {
+		if name != targetInfoFamily {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		mf := families[name]
+		_, _ = fmt.Fprintf(&b, "# TYPE %s %s\n", name, strings.ToLower(mf.GetType().String()))
+		var lines []string
+		for _, s := range flatten(map[string]*dto.MetricFamily{name: mf}) {
+			line := s.String()
+			if !values {
+				line = line[:strings.LastIndexByte(line, ' ')]
+			}
+			lines = append(lines, line)
+		}
+		sort.Strings(lines)
+		lines = dedupe(lines)
+		for _, line := range lines {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// dedupe removes the adjacent duplicates of sorted lines.
+func dedupe(lines []string) []string {
+	out := lines[:0]
+	for i, line := range lines {
+		if i == 0 || line != lines[i-1] {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// AssertGolden compares got, typically a Snapshot or a SchemaSnapshot, with the content of the golden file at path,
+// reporting a diff of the lines on mismatch. When the UPDATE_GOLDEN environment variable is set, it writes got to
+// the file instead, creating its directory.
+func AssertGolden(t testing.TB, got, path string) bool {
+	t.Helper()
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return true
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("read golden file, run the test with %s=1 to create it: %v", UpdateGoldenEnv, err)
+		return false
+	}
+	if string(want) == got {
+		return true
+	}
+	t.Errorf("snapshot differs from golden file %s (-golden +got), run the test with %s=1 to update it:\n%s",
+		path, UpdateGoldenEnv, diffLines(string(want), got))
+	return false
+}
+
+// diffLines lists the lines of want missing from got, prefixed with -, and the lines of got missing from want,
+// prefixed with +. Snapshots being sorted, a set difference is enough to point out renamed and removed series.
+func diffLines(want, got string) string {
+	count := make(map[string]int)
+	for _, line := range strings.Split(want, "\n") {
+		count[line]++
+	}
+	var added []string
+	for _, line := range strings.Split(got, "\n") {
+		if count[line] > 0 {
+			count[line]--
+			continue
+		}
+		added = append(added, "+"+line)
+	}
+	var removed []string
+	for _, line := range strings.Split(want, "\n") {
+		if count[line] > 0 {
+			count[line]--
+			removed = append(removed, "-"+line)
+		}
+	}
+	return strings.Join(append(removed, added...), "\n")
+}
@@ -0,0 +1,41 @@
+package metertest
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGolden(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("# HELP target_info resource\n# TYPE target_info gauge\ntarget_info{host=\"a\"} 1\n" + exposition))
+	})
+
+	assert.Equal(t, `# TYPE latency_seconds histogram
+latency_seconds_bucket{le="+Inf"}
+latency_seconds_bucket{le="0.1"}
+latency_seconds_bucket{le="1"}
+latency_seconds_count
+latency_seconds_sum
+# TYPE requests_total counter
+requests_total{service="pay",status="200"}
+requests_total{service="pay",status="500"}
+`, SchemaSnapshot(t, handler))
+
+	path := filepath.Join(t.TempDir(), "testdata", "metrics.golden")
+	t.Setenv(UpdateGoldenEnv, "1")
+	assert.True(t, AssertGolden(t, Snapshot(t, handler), path))
+	t.Setenv(UpdateGoldenEnv, "")
+	assert.True(t, AssertGolden(t, Snapshot(t, handler), path))
+
+	require.NoError(t, os.WriteFile(path, []byte("# TYPE requests_total counter\nrequests{status=\"200\"} 3\n"), 0o644))
+	r := &recorder{TB: t}
+	assert.False(t, AssertGolden(r, "# TYPE requests_total counter\n", path))
+	if assert.Len(t, r.errors, 1) {
+		assert.Contains(t, r.errors[0], `-requests{status="200"} 3`)
+	}
+}
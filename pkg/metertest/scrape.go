@@ -0,0 +1,200 @@
+// Package metertest provides test helpers scraping the handler of a meter and asserting on the exposed series:
+//
+//	metertest.ScrapeAndAssert(t, m.GetHandler(), `requests_total{status="200"} 3`, `latency_seconds_count 1`)
+package metertest
+
+import (
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/analyze"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// sample is a single series of an exposition, histograms and summaries being flattened into their _bucket, _sum and
+// _count series as in the text format.
+type sample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// String formats the sample as a line of the text format.
+func (s sample) String() string {
+	keys := make([]string, 0, len(s.labels))
+	for k := range s.labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, s.labels[k]))
+	}
+	if len(pairs) == 0 {
+		return fmt.Sprintf("%s %v", s.name, s.value)
+	}
+	return fmt.Sprintf("%s{%s} %v", s.name, strings.Join(pairs, ","), s.value)
+}
+
+// Scrape gets the exposition of handler, e.g. the GetHandler of a meter, and parses it, failing t on error.
+func Scrape(t testing.TB, handler http.Handler) analyze.Snapshot {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scrape returned status %d: %s", rec.Code, rec.Body.String())
+	}
+	snapshot, err := analyze.Parse(rec.Body)
+	if err != nil {
+		t.Fatalf("parse scrape: %v", err)
+	}
+	return snapshot
+}
+
+// ScrapeAndAssert scrapes handler and asserts that every expected line of the text format, e.g.
+// `requests_total{status="200"} 3`, matches a scraped series. A series matches when it has the same name and value
+// and its labels contain the expected labels, so that the order of the series, extra series and extra labels such as
+// the base tags are tolerated. Histograms are asserted through their _bucket, _sum and _count series.
+// It reports every mismatch and returns whether all the lines matched.
+func ScrapeAndAssert(t testing.TB, handler http.Handler, expected ...string) bool {
+	t.Helper()
+	samples := flatten(Scrape(t, handler))
+	ok := true
+	for _, line := range expected {
+		want, err := parseLine(line)
+		if err != nil {
+			t.Errorf("invalid expected series %q: %v", line, err)
+			ok = false
+			continue
+		}
+		var candidates []string
+		matched := false
+		for _, got := range samples {
+			if got.name != want.name {
+				continue
+			}
+			candidates = append(candidates, got.String())
+			if containsLabels(got.labels, want.labels) && sameValue(got.value, want.value) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		ok = false
+		if len(candidates) == 0 {
+			t.Errorf("series %s not found in scrape", line)
+		} else {
+			t.Errorf("series %s not found in scrape, got:\n\t%s", line, strings.Join(candidates, "\n\t"))
+		}
+	}
+	return ok
+}
+
+// parseLine parses a single line of the text format.
+func parseLine(line string) (sample, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(strings.TrimSpace(line) + "\n"))
+	if err != nil {
+		return sample{}, err
+	}
+	samples := flatten(families)
+	if len(samples) != 1 {
+		return sample{}, fmt.Errorf("expected one series, got %d", len(samples))
+	}
+	return samples[0], nil
+}
+
+// flatten lists the series of the families as they appear in the text format.
+func flatten(families map[string]*dto.MetricFamily) []sample {
+	var samples []sample
+	for name, mf := range families {
+		for _, m := range mf.Metric {
+			labels := make(map[string]string, len(m.Label))
+			for _, label := range m.Label {
+				labels[label.GetName()] = label.GetValue()
+			}
+			with := func(key, value string) map[string]string {
+				l := make(map[string]string, len(labels)+1)
+				for k, v := range labels {
+					l[k] = v
+				}
+				l[key] = value
+				return l
+			}
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				samples = append(samples, sample{name, labels, m.GetCounter().GetValue()})
+			case dto.MetricType_GAUGE:
+				samples = append(samples, sample{name, labels, m.GetGauge().GetValue()})
+			case dto.MetricType_UNTYPED:
+				samples = append(samples, sample{name, labels, m.GetUntyped().GetValue()})
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				hasInf := false
+				for _, b := range h.GetBucket() {
+					le := with("le", formatBound(b.GetUpperBound()))
+					samples = append(samples, sample{name + "_bucket", le, float64(b.GetCumulativeCount())})
+					hasInf = hasInf || math.IsInf(b.GetUpperBound(), 1)
+				}
+				if !hasInf {
+					samples = append(samples, sample{name + "_bucket", with("le", "+Inf"), float64(h.GetSampleCount())})
+				}
+				samples = append(samples,
+					sample{name + "_sum", labels, h.GetSampleSum()},
+					sample{name + "_count", labels, float64(h.GetSampleCount())})
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					samples = append(samples, sample{name, with("quantile", formatBound(q.GetQuantile())), q.GetValue()})
+				}
+				samples = append(samples,
+					sample{name + "_sum", labels, s.GetSampleSum()},
+					sample{name + "_count", labels, float64(s.GetSampleCount())})
+			}
+		}
+	}
+	return samples
+}
+
+// formatBound formats a bucket bound or a quantile as in the text format.
+func formatBound(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// containsLabels reports whether got contains the labels of want, le and quantile being compared as numbers.
+func containsLabels(got, want map[string]string) bool {
+	for k, v := range want {
+		value, ok := got[k]
+		if !ok {
+			return false
+		}
+		if value == v {
+			continue
+		}
+		if k != "le" && k != "quantile" {
+			return false
+		}
+		a, errA := strconv.ParseFloat(value, 64)
+		b, errB := strconv.ParseFloat(v, 64)
+		if errA != nil || errB != nil || a != b {
+			return false
+		}
+	}
+	return true
+}
+
+// sameValue compares two sample values, NaN being equal to NaN.
+func sameValue(a, b float64) bool {
+	return a == b || math.IsNaN(a) && math.IsNaN(b)
+}
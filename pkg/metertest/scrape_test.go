@@ -0,0 +1,53 @@
+package metertest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const exposition = `# TYPE requests_total counter
+requests_total{service="pay",status="500"} 1
+requests_total{service="pay",status="200"} 3
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 2
+latency_seconds_sum 0.6
+latency_seconds_count 2
+`
+
+// recorder is a testing.TB recording the reported failures.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestScrapeAndAssert(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(exposition))
+	})
+
+	assert.True(t, ScrapeAndAssert(t, handler,
+		`latency_seconds_count 2`,
+		`latency_seconds_bucket{le="1.0"} 2`,
+		`requests_total{status="200"} 3`,
+		`requests_total{service="pay",status="500"} 1`,
+	))
+
+	r := &recorder{TB: t}
+	assert.False(t, ScrapeAndAssert(r, handler, `requests_total{status="200"} 4`, `missing_total 1`, `{`))
+	if assert.Len(t, r.errors, 3) {
+		assert.Contains(t, r.errors[0], `requests_total{service="pay",status="200"} 3`)
+		assert.Contains(t, r.errors[1], "not found")
+		assert.Contains(t, r.errors[2], "invalid expected series")
+	}
+}
@@ -0,0 +1,122 @@
+package metrictool
+
+import (
+	"github.com/liangweijiang/go-metric/pkg/analyze"
+	"github.com/liangweijiang/go-metric/pkg/validate"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/attribute"
+	"sort"
+	"strings"
+)
+
+// Diff lists the series of b missing from a, as Added, and the series of a missing from b, as Removed.
+type Diff struct {
+	Added   []string
+	Removed []string
+}
+
+// Empty reports whether both snapshots have the same series.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// DiffSnapshots compares the series of two snapshots, e.g. scraped before and after a deployment, ignoring the values.
+func DiffSnapshots(a, b analyze.Snapshot) Diff {
+	before, after := Series(a), Series(b)
+	return Diff{
+		Added:   missing(after, before),
+		Removed: missing(before, after),
+	}
+}
+
+// missing returns the elements of the sorted slice a absent from the sorted slice b.
+func missing(a, b []string) []string {
+	var out []string
+	j := 0
+	for _, s := range a {
+		for j < len(b) && b[j] < s {
+			j++
+		}
+		if j == len(b) || b[j] != s {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// FamilyCardinality is the number of series of a metric family and the number of distinct values of each label.
+type FamilyCardinality struct {
+	Name   string
+	Series int
+	Labels map[string]int
+}
+
+// Cardinality returns the cardinality of every family of a snapshot, the largest first.
+func Cardinality(s analyze.Snapshot) []FamilyCardinality {
+	families := make([]FamilyCardinality, 0, len(s))
+	for name, mf := range s {
+		values := make(map[string]map[string]struct{})
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if values[l.GetName()] == nil {
+					values[l.GetName()] = make(map[string]struct{})
+				}
+				values[l.GetName()][l.GetValue()] = struct{}{}
+			}
+		}
+		labels := make(map[string]int, len(values))
+		for label, distinct := range values {
+			labels[label] = len(distinct)
+		}
+		families = append(families, FamilyCardinality{
+			Name:   name,
+			Series: len(mf.GetMetric()),
+			Labels: labels,
+		})
+	}
+	sort.Slice(families, func(i, j int) bool {
+		if families[i].Series != families[j].Series {
+			return families[i].Series > families[j].Series
+		}
+		return families[i].Name < families[j].Name
+	})
+	return families
+}
+
+// kinds maps the types of the exposition to the instrument kinds of the validator.
+var kinds = map[dto.MetricType]string{
+	dto.MetricType_COUNTER:   "counter",
+	dto.MetricType_GAUGE:     "gauge",
+	dto.MetricType_HISTOGRAM: "histogram",
+	dto.MetricType_SUMMARY:   "summary",
+	dto.MetricType_UNTYPED:   "untyped",
+}
+
+// Lint checks the names and labels of the families of a snapshot against the rules of the validate provider,
+// reporting the families with more than limit series, validate.DefaultCardinalityLimit if limit is not positive.
+// The _total suffix of the counters, added by the exposition, is not reported.
+func Lint(s analyze.Snapshot, limit int) []validate.Violation {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	validator := validate.NewValidator(limit, nil)
+	for _, name := range names {
+		mf := s[name]
+		metric := name
+		if mf.GetType() == dto.MetricType_COUNTER {
+			metric = strings.TrimSuffix(name, "_total")
+		}
+		validator.CheckInstrument(kinds[mf.GetType()], metric, mf.GetUnit(), nil)
+		for _, m := range mf.GetMetric() {
+			tags := make([]attribute.KeyValue, 0, len(m.GetLabel()))
+			for _, l := range m.GetLabel() {
+				tags = append(tags, attribute.String(l.GetName(), l.GetValue()))
+			}
+			validator.CheckRecord(metric, tags, 0, false)
+		}
+	}
+	return validator.Violations()
+}
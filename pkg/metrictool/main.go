@@ -0,0 +1,184 @@
+package metrictool
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Exit codes of Main.
+const (
+	ExitOK       = 0
+	ExitFindings = 1
+	ExitUsage    = 2
+)
+
+// usage is the help of the command.
+const usage = `usage: go-metric <command> [flags] <target>...
+
+A target is an http(s) URL, a file holding a Prometheus text exposition, or - for stdin.
+
+commands:
+  scrape [-timeout d] [-o file] <url>   scrape a target and print or save its exposition
+  diff <before> <after>                 list the series added and removed between two targets
+  cardinality [-top n] <target>         count the series of every family and the values of every label
+  lint [-limit n] <target>              check the names and labels against the conventions of the SDK
+`
+
+// Main runs the go-metric command with the arguments following the program name and returns its exit code:
+// ExitFindings when diff finds differences or lint finds violations, ExitUsage on invalid arguments.
+func Main(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(stderr, usage)
+		return ExitUsage
+	}
+	fs := flag.NewFlagSet("go-metric "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout of the scrapes")
+	var run func(ctx context.Context, targets []string) (int, error)
+	switch args[0] {
+	case "scrape":
+		output := fs.String("o", "", "file the exposition is written to, stdout if empty")
+		run = func(ctx context.Context, targets []string) (int, error) {
+			return scrape(ctx, targets, *output, stdout, stderr)
+		}
+	case "diff":
+		run = func(ctx context.Context, targets []string) (int, error) {
+			return diff(ctx, targets, stdin, stdout)
+		}
+	case "cardinality":
+		top := fs.Int("top", 20, "number of families printed, all if not positive")
+		run = func(ctx context.Context, targets []string) (int, error) {
+			return cardinality(ctx, targets, *top, stdin, stdout)
+		}
+	case "lint":
+		limit := fs.Int("limit", 0, "number of series of a family beyond which it is reported")
+		run = func(ctx context.Context, targets []string) (int, error) {
+			return lint(ctx, targets, *limit, stdin, stdout)
+		}
+	case "help", "-h", "-help", "--help":
+		_, _ = fmt.Fprint(stdout, usage)
+		return ExitOK
+	default:
+		_, _ = fmt.Fprintf(stderr, "unknown command %q\n%s", args[0], usage)
+		return ExitUsage
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return ExitUsage
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	code, err := run(ctx, fs.Args())
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, "go-metric "+args[0]+": "+err.Error())
+	}
+	return code
+}
+
+// errTargets is returned for a wrong number of targets.
+var errTargets = errors.New("wrong number of targets")
+
+// scrape writes the exposition of the target to output and a summary to stderr.
+func scrape(ctx context.Context, targets []string, output string, stdout, stderr io.Writer) (int, error) {
+	if len(targets) != 1 {
+		return ExitUsage, errTargets
+	}
+	body, err := Fetch(ctx, nil, targets[0])
+	if err != nil {
+		return ExitFindings, err
+	}
+	snapshot, err := Load(ctx, nil, "-", strings.NewReader(body))
+	if err != nil {
+		return ExitFindings, err
+	}
+	if output == "" {
+		_, err = io.WriteString(stdout, body)
+	} else {
+		err = os.WriteFile(output, []byte(body), 0o644)
+	}
+	if err != nil {
+		return ExitFindings, err
+	}
+	_, _ = fmt.Fprintf(stderr, "%d families, %d series\n", len(snapshot), len(Series(snapshot)))
+	return ExitOK, nil
+}
+
+// diff prints the series added and removed between the targets.
+func diff(ctx context.Context, targets []string, stdin io.Reader, stdout io.Writer) (int, error) {
+	if len(targets) != 2 {
+		return ExitUsage, errTargets
+	}
+	before, err := Load(ctx, nil, targets[0], stdin)
+	if err != nil {
+		return ExitFindings, err
+	}
+	after, err := Load(ctx, nil, targets[1], stdin)
+	if err != nil {
+		return ExitFindings, err
+	}
+	d := DiffSnapshots(before, after)
+	for _, s := range d.Removed {
+		_, _ = fmt.Fprintln(stdout, "- "+s)
+	}
+	for _, s := range d.Added {
+		_, _ = fmt.Fprintln(stdout, "+ "+s)
+	}
+	if !d.Empty() {
+		return ExitFindings, nil
+	}
+	return ExitOK, nil
+}
+
+// cardinality prints the cardinality of the top families of the target.
+func cardinality(ctx context.Context, targets []string, top int, stdin io.Reader, stdout io.Writer) (int, error) {
+	if len(targets) != 1 {
+		return ExitUsage, errTargets
+	}
+	snapshot, err := Load(ctx, nil, targets[0], stdin)
+	if err != nil {
+		return ExitFindings, err
+	}
+	families := Cardinality(snapshot)
+	total := 0
+	for _, f := range families {
+		total += f.Series
+	}
+	if top > 0 && len(families) > top {
+		families = families[:top]
+	}
+	for _, f := range families {
+		labels := make([]string, 0, len(f.Labels))
+		for label, values := range f.Labels {
+			labels = append(labels, fmt.Sprintf("%s=%d", label, values))
+		}
+		sort.Strings(labels)
+		_, _ = fmt.Fprintf(stdout, "%8d  %s  %s\n", f.Series, f.Name, strings.Join(labels, " "))
+	}
+	_, _ = fmt.Fprintf(stdout, "%8d  total\n", total)
+	return ExitOK, nil
+}
+
+// lint prints the violations found in the target.
+func lint(ctx context.Context, targets []string, limit int, stdin io.Reader, stdout io.Writer) (int, error) {
+	if len(targets) != 1 {
+		return ExitUsage, errTargets
+	}
+	snapshot, err := Load(ctx, nil, targets[0], stdin)
+	if err != nil {
+		return ExitFindings, err
+	}
+	violations := Lint(snapshot, limit)
+	for _, v := range violations {
+		_, _ = fmt.Fprintln(stdout, v.String())
+	}
+	if len(violations) > 0 {
+		return ExitFindings, nil
+	}
+	return ExitOK, nil
+}
@@ -0,0 +1,69 @@
+package metrictool
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const before = `# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/users"} 3
+http_requests_total{method="POST",route="/users"} 1
+# TYPE queue_depth gauge
+queue_depth 4
+`
+
+const after = `# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/users"} 7
+http_requests_total{method="GET",route="/orders"} 2
+# TYPE RequestsInFlight gauge
+RequestsInFlight{statusCode="200",status_code="200"} 1
+`
+
+func TestCommands(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(before))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	beforePath, afterPath := filepath.Join(dir, "before.prom"), filepath.Join(dir, "after.prom")
+	require.NoError(t, os.WriteFile(afterPath, []byte(after), 0o644))
+
+	run := func(args ...string) (int, string) {
+		var stdout, stderr bytes.Buffer
+		code := Main(args, strings.NewReader(after), &stdout, &stderr)
+		return code, stdout.String()
+	}
+
+	code, _ := run("scrape", "-o", beforePath, server.URL)
+	require.Equal(t, ExitOK, code)
+
+	code, out := run("diff", beforePath, afterPath)
+	assert.Equal(t, ExitFindings, code)
+	assert.Equal(t, `- http_requests_total{method="POST",route="/users"}
+- queue_depth
++ RequestsInFlight{statusCode="200",status_code="200"}
++ http_requests_total{method="GET",route="/orders"}
+`, out)
+
+	code, out = run("cardinality", "-top", "1", beforePath)
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, "       2  http_requests_total  method=2 route=1\n       3  total\n", out)
+
+	code, out = run("lint", "-")
+	assert.Equal(t, ExitFindings, code)
+	assert.Contains(t, out, "RequestsInFlight: near_duplicate_tag_key")
+	assert.NotContains(t, out, "http_requests")
+
+	code, _ = run("diff", beforePath)
+	assert.Equal(t, ExitUsage, code)
+	code, _ = run("publish")
+	assert.Equal(t, ExitUsage, code)
+}
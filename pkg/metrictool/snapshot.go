@@ -0,0 +1,86 @@
+// Package metrictool implements the go-metric command, helping operators adopting the SDK: it scrapes a target,
+// diffs two snapshots, estimates the cardinality of the metrics and lints their names and labels against the
+// conventions of the SDK. Every subcommand is available as a function for the programs embedding the tool.
+package metrictool
+
+import (
+	"context"
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/analyze"
+	dto "github.com/prometheus/client_model/go"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Load reads the Prometheus text exposition of target: an http(s) URL scraped with client, http.DefaultClient if nil,
+// "-" for stdin, or the path of a file, e.g. a snapshot saved by the scrape subcommand.
+func Load(ctx context.Context, client *http.Client, target string, stdin io.Reader) (analyze.Snapshot, error) {
+	switch {
+	case target == "-":
+		return analyze.Parse(stdin)
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		body, err := Fetch(ctx, client, target)
+		if err != nil {
+			return nil, err
+		}
+		return analyze.Parse(strings.NewReader(body))
+	default:
+		f, err := os.Open(target)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return analyze.Parse(f)
+	}
+}
+
+// Fetch scrapes url in the Prometheus text format and returns the exposition.
+func Fetch(ctx context.Context, client *http.Client, url string) (string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/plain;version=0.0.4")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d scraping %s", resp.StatusCode, url)
+	}
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
+
+// Series returns the identifiers of the series of a snapshot, e.g. http_requests_total{method="GET"}, sorted.
+// Histograms and summaries are identified by their family name and labels, without their buckets or quantiles.
+func Series(s analyze.Snapshot) []string {
+	var series []string
+	for name, mf := range s {
+		for _, m := range mf.GetMetric() {
+			series = append(series, seriesID(name, m))
+		}
+	}
+	sort.Strings(series)
+	return series
+}
+
+// seriesID formats the name of the family followed by the sorted labels of the series.
+func seriesID(name string, m *dto.Metric) string {
+	labels := make([]string, 0, len(m.GetLabel()))
+	for _, l := range m.GetLabel() {
+		labels = append(labels, fmt.Sprintf("%s=%q", l.GetName(), l.GetValue()))
+	}
+	if len(labels) == 0 {
+		return name
+	}
+	sort.Strings(labels)
+	return name + "{" + strings.Join(labels, ",") + "}"
+}
@@ -0,0 +1,238 @@
+// Package redact provides the policy redacting the sensitive values of the tags, such as emails, tokens or IP
+// addresses, before they are exported, for compliance-sensitive deployments. The policy is applied to every
+// measurement as an interceptor of the meter:
+//
+//	m.Use(redact.NewPolicy().Interceptor())
+//
+// The interceptor sees the final tags of the measurements, those of the tag providers included. The base tags are
+// set once on the resource of the meter, not on the measurements, and so are not redacted: they must not hold
+// sensitive values.
+package redact
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"net"
+	"regexp"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Action is what a policy does with a tag value matched by a rule.
+type Action int
+
+const (
+	// ActionHash replaces the value by the first 16 hexadecimal digits of its HMAC-SHA256, see WithHashKey, so that
+	// the series of distinct values stay distinct without exposing them.
+	ActionHash Action = iota
+	// ActionDrop replaces the value by Redacted, merging the series of all the values matched.
+	ActionDrop
+)
+
+// Redacted is the value of the tags whose value was dropped.
+const Redacted = "redacted"
+
+// maxCachedValues bounds the number of tag values whose redaction is cached by a policy.
+const maxCachedValues = 10000
+
+// Rule matches the sensitive values of the string tags with the given keys, of every tag if Keys is empty.
+type Rule struct {
+	Name   string
+	Keys   []string
+	Match  func(value string) bool
+	Action Action
+}
+
+// Pattern returns a rule matching the values in which re finds a match, e.g. an internal account number.
+func Pattern(name string, re *regexp.Regexp, action Action) Rule {
+	return Rule{
+		Name:   name,
+		Match:  re.MatchString,
+		Action: action,
+	}
+}
+
+// emailPattern matches an email address within a value.
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// Emails returns a rule matching the values holding an email address.
+func Emails(action Action) Rule {
+	return Pattern("email", emailPattern, action)
+}
+
+// tokenPattern matches the bearer tokens and the JSON Web Tokens within a value.
+var tokenPattern = regexp.MustCompile(`(?i)\bbearer\s+\S+|\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.`)
+
+// Tokens returns a rule matching the values holding a bearer token or a JSON Web Token, or looking like a secret:
+// at least 32 characters of the base64 alphabet mixing letters and digits, e.g. an API key.
+func Tokens(action Action) Rule {
+	return Rule{
+		Name: "token",
+		Match: func(value string) bool {
+			return tokenPattern.MatchString(value) || looksLikeSecret(value)
+		},
+		Action: action,
+	}
+}
+
+// looksLikeSecret reports whether value is made of at least 32 characters of the base64 alphabet, letters and digits
+// mixed.
+func looksLikeSecret(value string) bool {
+	if len(value) < 32 {
+		return false
+	}
+	var letters, digits bool
+	for _, c := range value {
+		switch {
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			letters = true
+		case c >= '0' && c <= '9':
+			digits = true
+		case c == '+' || c == '/' || c == '=' || c == '-' || c == '_':
+		default:
+			return false
+		}
+	}
+	return letters && digits
+}
+
+// IPs returns a rule matching the values which are an IPv4 or IPv6 address, with or without a port.
+func IPs(action Action) Rule {
+	return Rule{
+		Name: "ip",
+		Match: func(value string) bool {
+			if host, _, err := net.SplitHostPort(value); err == nil {
+				value = host
+			}
+			return net.ParseIP(value) != nil
+		},
+		Action: action,
+	}
+}
+
+// DefaultRules hashes the emails and the IP addresses and drops the tokens.
+func DefaultRules() []Rule {
+	return []Rule{Emails(ActionHash), Tokens(ActionDrop), IPs(ActionHash)}
+}
+
+// Option configures a Policy.
+type Option func(p *Policy)
+
+// WithRules replaces the default rules of the policy.
+func WithRules(rules ...Rule) Option {
+	return func(p *Policy) {
+		p.rules = rules
+	}
+}
+
+// WithHashKey keys the hashes of the values, so that they cannot be reversed by hashing the candidate values, e.g.
+// the emails of known users. The hashes are not keyed by default.
+func WithHashKey(key []byte) Option {
+	return func(p *Policy) {
+		p.key = key
+	}
+}
+
+// Policy redacts the values of the string tags matched by its rules, the first rule matching a value applies.
+type Policy struct {
+	rules    []Rule
+	key      []byte
+	cache    sync.Map
+	cached   atomic.Int64
+	redacted atomic.Uint64
+}
+
+// NewPolicy creates a policy applying DefaultRules unless configured otherwise.
+func NewPolicy(options ...Option) *Policy {
+	p := &Policy{rules: DefaultRules()}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Interceptor returns the interceptor redacting the tags of every measurement.
+func (p *Policy) Interceptor() interfaces.Interceptor {
+	return func(_ context.Context, m *interfaces.Measurement) bool {
+		m.Tags = p.RedactTags(m.Tags)
+		return true
+	}
+}
+
+// RedactTags returns the tags with their sensitive values redacted, tags itself when none is.
+func (p *Policy) RedactTags(tags []attribute.KeyValue) []attribute.KeyValue {
+	var redacted []attribute.KeyValue
+	for i, kv := range tags {
+		if kv.Value.Type() != attribute.STRING {
+			continue
+		}
+		value, ok := p.Redact(string(kv.Key), kv.Value.AsString())
+		if !ok {
+			continue
+		}
+		if redacted == nil {
+			redacted = slices.Clone(tags)
+		}
+		redacted[i] = attribute.String(string(kv.Key), value)
+	}
+	if redacted == nil {
+		return tags
+	}
+	return redacted
+}
+
+// Redact returns the redacted value of the tag with the given key, and whether a rule matched it.
+func (p *Policy) Redact(key, value string) (string, bool) {
+	cacheKey := key + "\x00" + value
+	if cached, ok := p.cache.Load(cacheKey); ok {
+		return p.result(value, cached.(*redaction))
+	}
+	r := &redaction{}
+	for _, rule := range p.rules {
+		if (len(rule.Keys) == 0 || slices.Contains(rule.Keys, key)) && rule.Match(value) {
+			r.matched, r.value = true, p.apply(rule.Action, value)
+			break
+		}
+	}
+	if p.cached.Load() < maxCachedValues {
+		if _, loaded := p.cache.LoadOrStore(cacheKey, r); !loaded {
+			p.cached.Add(1)
+		}
+	}
+	return p.result(value, r)
+}
+
+// RedactedCount returns the number of tag values redacted so far.
+func (p *Policy) RedactedCount() uint64 {
+	return p.redacted.Load()
+}
+
+// redaction is the cached redaction of a tag value.
+type redaction struct {
+	matched bool
+	value   string
+}
+
+// result returns the value to record for the redaction of value.
+func (p *Policy) result(value string, r *redaction) (string, bool) {
+	if !r.matched {
+		return value, false
+	}
+	p.redacted.Add(1)
+	return r.value, true
+}
+
+// apply returns the value replacing value for the action.
+func (p *Policy) apply(action Action, value string) string {
+	if action == ActionDrop {
+		return Redacted
+	}
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
@@ -0,0 +1,65 @@
+package redact
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestPolicy(t *testing.T) {
+	p := NewPolicy(WithHashKey([]byte("k3y")))
+	m := &interfaces.Measurement{Tags: []attribute.KeyValue{
+		attribute.String("user", "jane@example.com"),
+		attribute.String("auth", "Bearer abc.def"),
+		attribute.String("peer", "10.0.0.7:443"),
+		attribute.String("route", "/orders"),
+		attribute.Int("status", 200),
+	}}
+	tags := m.Tags
+	assert.True(t, p.Interceptor()(context.Background(), m))
+
+	user := m.Tags[0].Value
+	assert.Len(t, user.AsString(), 16)
+	assert.NotEqual(t, "jane@example.com", user.AsString())
+	assert.Equal(t, attribute.String("auth", Redacted), m.Tags[1])
+	assert.NotEqual(t, "10.0.0.7:443", m.Tags[2].Value.AsString())
+	assert.Equal(t, tags[3:], m.Tags[3:])
+	assert.Equal(t, "jane@example.com", tags[0].Value.AsString(), "the tags of the instrument are not modified")
+	assert.Equal(t, uint64(3), p.RedactedCount())
+
+	hashed, _ := p.Redact("user", "jane@example.com")
+	assert.Equal(t, user.AsString(), hashed, "the hash is stable")
+	other, _ := NewPolicy().Redact("user", "jane@example.com")
+	assert.NotEqual(t, hashed, other, "the hash depends on the key")
+	_, ok := p.Redact("build", "6f1c2e9a4b7d8e3f0a5c6b2d1e4f7a8b")
+	assert.True(t, ok, "looks like a secret")
+	_, ok = p.Redact("handler", "get_user_profile_settings_by_identifier")
+	assert.False(t, ok)
+
+	account := NewPolicy(WithRules(Rule{Name: "account", Keys: []string{"account"}, Action: ActionDrop,
+		Match: regexp.MustCompile(`^ACC-\d+$`).MatchString}))
+	assert.Equal(t, []attribute.KeyValue{attribute.String("account", Redacted), attribute.String("ref", "ACC-42")},
+		account.RedactTags([]attribute.KeyValue{attribute.String("account", "ACC-42"), attribute.String("ref", "ACC-42")}))
+}
+
+func TestPolicyTagProvider(t *testing.T) {
+	m, err := meter.NewMeter(meter.WithProviderType(config.MeterProviderTypePrometheus),
+		meter.WithTagProvider(config.TagProviderFunc(func(context.Context) []attribute.KeyValue {
+			return []attribute.KeyValue{attribute.String("caller", "10.0.0.7")}
+		})))
+	require.NoError(t, err)
+	defer m.WithRunning(false)
+	m.Use(NewPolicy(WithRules(IPs(ActionDrop))).Interceptor())
+
+	m.NewCounter("requests", "", "").IncrOne(context.Background())
+
+	metertest.ScrapeAndAssert(t, m.GetHandler(), `requests_total{caller="redacted"} 1`)
+}
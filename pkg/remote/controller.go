@@ -0,0 +1,230 @@
+// Package remote applies metric controls published by a remote configuration endpoint to a running meter,
+// e.g. muting a metric exploding in cardinality without redeploying the service.
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/clock"
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is the default period at which the directives are polled.
+const DefaultInterval = 30 * time.Second
+
+// ErrNotReloadable is returned by Apply when the directives change a setting the meter cannot change at runtime.
+var ErrNotReloadable = errors.New("meter settings cannot be changed at runtime")
+
+// Directives are the metric controls published by the configuration endpoint. Zero values leave the settings unchanged,
+// except DisableMetrics: the metrics disabled by previous directives and no longer listed are enabled again.
+type Directives struct {
+	DisableMetrics   []string `json:"disable_metrics"`
+	PushPeriod       string   `json:"push_period"`
+	CardinalityLimit int      `json:"cardinality_limit"`
+}
+
+// Source fetches the current directives.
+type Source interface {
+	Fetch(ctx context.Context) (Directives, error)
+}
+
+// SourceFunc is a function implementing Source, e.g. reading a key of etcd or consul and decoding it with
+// json.Unmarshal.
+type SourceFunc func(ctx context.Context) (Directives, error)
+
+// Fetch calls f.
+func (f SourceFunc) Fetch(ctx context.Context) (Directives, error) {
+	return f(ctx)
+}
+
+// httpSource fetches the directives as a JSON document served over HTTP.
+type httpSource struct {
+	url    string
+	client *http.Client
+}
+
+// HTTPSource returns a Source getting the directives as a JSON document from url with client,
+// http.DefaultClient if nil.
+func HTTPSource(url string, client *http.Client) Source {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpSource{
+		url:    url,
+		client: client,
+	}
+}
+
+// Fetch gets and decodes the directives.
+func (s *httpSource) Fetch(ctx context.Context) (Directives, error) {
+	var d Directives
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return d, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return d, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return d, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, s.url)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&d)
+	return d, err
+}
+
+// Option configures a Controller.
+type Option func(c *Controller)
+
+// WithInterval sets the period at which the directives are polled, DefaultInterval if not positive.
+func WithInterval(interval time.Duration) Option {
+	return func(c *Controller) {
+		c.interval = interval
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the polls, which are otherwise ignored.
+func WithErrorHandler(onError func(err error)) Option {
+	return func(c *Controller) {
+		c.onError = onError
+	}
+}
+
+// Controller polls a Source and applies the directives to a meter through its runtime API: DisableMetric and
+// EnableMetric, and interfaces.Reloadable for the push period and the cardinality limit.
+type Controller struct {
+	meter    interfaces.Meter
+	source   Source
+	interval time.Duration
+	onError  func(err error)
+	mu       sync.Mutex
+	disabled map[string]struct{}
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewController creates a controller applying the directives of source to m. It does nothing until Start is called.
+func NewController(m interfaces.Meter, source Source, options ...Option) *Controller {
+	c := &Controller{
+		meter:    m,
+		source:   source,
+		disabled: make(map[string]struct{}),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if c.interval <= 0 {
+		c.interval = DefaultInterval
+	}
+	return c
+}
+
+// Start polls the directives immediately, then every interval until Stop is called. Calling Start on a started
+// controller does nothing.
+func (c *Controller) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopCh != nil {
+		return
+	}
+	c.stopCh, c.doneCh = make(chan struct{}), make(chan struct{})
+	go c.poll(clock.From(c.meter), c.stopCh, c.doneCh)
+}
+
+// Stop stops polling, the applied directives stay in effect.
+func (c *Controller) Stop() {
+	c.mu.Lock()
+	stopCh, doneCh := c.stopCh, c.doneCh
+	c.stopCh, c.doneCh = nil, nil
+	c.mu.Unlock()
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+}
+
+// poll fetches and applies the directives every interval until stopCh is closed.
+func (c *Controller) poll(clk clock.Clock, stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	timer := clk.NewTimer(c.interval)
+	defer timer.Stop()
+	for {
+		if err := c.Poll(ctx); err != nil && c.onError != nil && ctx.Err() == nil {
+			c.onError(err)
+		}
+		select {
+		case <-stopCh:
+			return
+		case <-timer.C():
+			timer.Reset(c.interval)
+		}
+	}
+}
+
+// Poll fetches the directives once and applies them.
+func (c *Controller) Poll(ctx context.Context) error {
+	d, err := c.source.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch metric directives: %w", err)
+	}
+	return c.Apply(d)
+}
+
+// Apply applies the directives to the meter. Every valid directive is applied, the errors of the others are joined.
+func (c *Controller) Apply(d Directives) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	disabled := make(map[string]struct{}, len(d.DisableMetrics))
+	for _, name := range d.DisableMetrics {
+		disabled[name] = struct{}{}
+		if _, ok := c.disabled[name]; !ok {
+			c.meter.DisableMetric(name)
+		}
+	}
+	for name := range c.disabled {
+		if _, ok := disabled[name]; !ok {
+			c.meter.EnableMetric(name)
+		}
+	}
+	c.disabled = disabled
+
+	if d.PushPeriod == "" && d.CardinalityLimit <= 0 {
+		return nil
+	}
+	reloadable, ok := c.meter.(interfaces.Reloadable)
+	if !ok {
+		return ErrNotReloadable
+	}
+	var errs []error
+	if d.PushPeriod != "" {
+		period, err := time.ParseDuration(d.PushPeriod)
+		if err == nil && period <= 0 {
+			err = errors.New("must be positive")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid push period %q: %w", d.PushPeriod, err))
+		} else {
+			reloadable.SetPushPeriod(period)
+		}
+	}
+	if d.CardinalityLimit > 0 {
+		reloadable.SetCardinalityLimit(d.CardinalityLimit)
+	}
+	return errors.Join(errs...)
+}
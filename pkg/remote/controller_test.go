@@ -0,0 +1,55 @@
+package remote_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liangweijiang/go-metric/meter"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"github.com/liangweijiang/go-metric/pkg/metertest"
+	"github.com/liangweijiang/go-metric/pkg/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController(t *testing.T) {
+	m, err := meter.NewMeter(meter.WithProviderType(config.MeterProviderTypePrometheus))
+	require.NoError(t, err)
+	var directives atomic.Value
+	directives.Store(remote.Directives{DisableMetrics: []string{"remote_noisy"}, PushPeriod: "5s", CardinalityLimit: 50})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(directives.Load())
+	}))
+	defer server.Close()
+
+	controller := remote.NewController(m, remote.HTTPSource(server.URL, nil))
+	ctx := context.Background()
+	require.NoError(t, controller.Poll(ctx))
+	cfg := m.(interface{ Config() *config.Config }).Config()
+	assert.Equal(t, 5*time.Second, cfg.GetPushPeriod())
+	assert.Equal(t, 50, cfg.GetCardinalityLimit())
+
+	m.NewCounter("remote_noisy", "noisy", config.UnitCount).IncrOne(ctx)
+	m.NewCounter("remote_quiet", "quiet", config.UnitCount).IncrOne(ctx)
+	snapshot := metertest.Scrape(t, m.GetHandler())
+	assert.NotContains(t, snapshot, "remote_noisy_total")
+	assert.Contains(t, snapshot, "remote_quiet_total")
+
+	directives.Store(remote.Directives{PushPeriod: "soon"})
+	assert.Error(t, controller.Poll(ctx))
+	m.NewCounter("remote_noisy", "noisy", config.UnitCount).IncrOne(ctx)
+	metertest.ScrapeAndAssert(t, m.GetHandler(), `remote_noisy_total 1`)
+	assert.Equal(t, 5*time.Second, cfg.GetPushPeriod())
+
+	directives.Store(remote.Directives{CardinalityLimit: 1})
+	require.NoError(t, controller.Poll(ctx))
+	m.NewCounter("remote_routes", "routes", config.UnitCount).AddTag("route", "/a").IncrOne(ctx)
+	m.NewCounter("remote_routes", "routes", config.UnitCount).AddTag("route", "/b").IncrOne(ctx)
+	metertest.ScrapeAndAssert(t, m.GetHandler(), `remote_routes_total{route="/a"} 1`)
+	assert.Len(t, metertest.Scrape(t, m.GetHandler())["remote_routes_total"].GetMetric(), 1)
+}
@@ -0,0 +1,42 @@
+package semconv
+
+import (
+	"strings"
+	"sync"
+)
+
+// KeyChecker detects the tag keys that nearly duplicate each other, such as statusCode and status_code,
+// which split the same dimension across series. It is safe for concurrent use.
+type KeyChecker struct {
+	checked    sync.Map
+	normalized sync.Map
+}
+
+// NewKeyChecker creates a KeyChecker that has seen no key yet.
+func NewKeyChecker() *KeyChecker {
+	return &KeyChecker{}
+}
+
+// Check records key and returns the key seen before it nearly duplicates, if any.
+// A conflict is only reported the first time key is checked.
+func (c *KeyChecker) Check(key string) (string, bool) {
+	if _, checked := c.checked.LoadOrStore(key, struct{}{}); checked {
+		return "", false
+	}
+	first, loaded := c.normalized.LoadOrStore(Normalize(key), key)
+	if !loaded || first.(string) == key {
+		return "", false
+	}
+	return first.(string), true
+}
+
+// Normalize returns the form of key shared by its near-duplicates: lower case, without '_', '-' and '.' separators.
+func Normalize(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', '.':
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
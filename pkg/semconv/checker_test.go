@@ -0,0 +1,25 @@
+package semconv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyChecker(t *testing.T) {
+	c := NewKeyChecker()
+
+	_, conflict := c.Check("status_code")
+	assert.False(t, conflict)
+	_, conflict = c.Check("status_code")
+	assert.False(t, conflict)
+
+	first, conflict := c.Check("statusCode")
+	assert.True(t, conflict)
+	assert.Equal(t, "status_code", first)
+	_, conflict = c.Check("statusCode")
+	assert.False(t, conflict, "a conflict is reported once")
+
+	_, conflict = c.Check("status")
+	assert.False(t, conflict)
+}
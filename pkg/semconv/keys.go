@@ -0,0 +1,21 @@
+// Package semconv defines the standardized tag keys shared by the instrumentation, so that the same dimension has
+// the same name on every metric, and a checker of the keys that nearly duplicate each other.
+package semconv
+
+// Standardized tag keys.
+const (
+	// Status is the status of a request, e.g. the HTTP status code or a gRPC code.
+	Status = "status"
+	// Method is the method of a request, e.g. the HTTP method or the RPC method.
+	Method = "method"
+	// Route is the route template of a request, e.g. /users/{id}, never the raw path.
+	Route = "route"
+	// PeerService is the logical name of the remote service called.
+	PeerService = "peer_service"
+	// ErrorType is the class of an error, e.g. timeout or not_found, never the error message.
+	ErrorType = "error_type"
+	// Outcome is the outcome of an operation, success or error.
+	Outcome = "outcome"
+	// Operation is the name of an operation.
+	Operation = "operation"
+)
@@ -0,0 +1,58 @@
+package semconv
+
+import "strings"
+
+// CounterSuffix is the suffix of the names of the Prometheus counters.
+const CounterSuffix = "_total"
+
+// unitSuffixes maps the UCUM units to the suffixes appended to the metric names, the same ones as the OpenTelemetry
+// Prometheus exporter so that the scraped names do not change with the suffixes appended at the creation.
+var unitSuffixes = map[string]string{
+	"d":    "_days",
+	"h":    "_hours",
+	"min":  "_minutes",
+	"s":    "_seconds",
+	"ms":   "_milliseconds",
+	"us":   "_microseconds",
+	"ns":   "_nanoseconds",
+	"By":   "_bytes",
+	"KiBy": "_kibibytes",
+	"MiBy": "_mebibytes",
+	"GiBy": "_gibibytes",
+	"TiBy": "_tibibytes",
+	"KBy":  "_kilobytes",
+	"MBy":  "_megabytes",
+	"GBy":  "_gigabytes",
+	"TBy":  "_terabytes",
+	"m":    "_meters",
+	"V":    "_volts",
+	"A":    "_amperes",
+	"J":    "_joules",
+	"W":    "_watts",
+	"g":    "_grams",
+	"Cel":  "_celsius",
+	"Hz":   "_hertz",
+	"1":    "_ratio",
+	"%":    "_percent",
+}
+
+// UnitSuffix returns the suffix of the names of the metrics in unit, such as _seconds for s, if unit has one.
+func UnitSuffix(unit string) (string, bool) {
+	suffix, ok := unitSuffixes[unit]
+	return suffix, ok
+}
+
+// AppendUnitSuffix returns name ending with the suffix of unit and, for a counter, with _total after it, following
+// the Prometheus naming conventions. The suffixes name already ends with are not appended twice.
+func AppendUnitSuffix(name, unit string, counter bool) string {
+	if counter {
+		name = strings.TrimSuffix(name, CounterSuffix)
+	}
+	if suffix, ok := unitSuffixes[unit]; ok && !strings.HasSuffix(name, suffix) {
+		name += suffix
+	}
+	if counter {
+		name += CounterSuffix
+	}
+	return name
+}
@@ -0,0 +1,44 @@
+package tag
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// FromBaggage returns a config.TagProvider tagging the measurements with the members of the OpenTelemetry baggage of
+// their context whose keys are allowed, e.g. FromBaggage("tenant.tier") to carry the tier injected by an upstream
+// service, recorded as the tag tenant_tier. The characters not allowed in tag keys are replaced by underscores.
+// Baggage is set by the callers: only the keys whose values are bounded must be allowed, since every value becomes
+// a series. The members missing from the baggage are left out.
+func FromBaggage(allowed ...string) config.TagProvider {
+	keys := make(map[string]string, len(allowed))
+	for _, key := range allowed {
+		keys[key] = sanitizeKey(key)
+	}
+	return config.TagProviderFunc(func(ctx context.Context) []attribute.KeyValue {
+		bag := baggage.FromContext(ctx)
+		if bag.Len() == 0 {
+			return nil
+		}
+		var tags []attribute.KeyValue
+		for key, tagKey := range keys {
+			if member := bag.Member(key); member.Key() != "" {
+				tags = append(tags, attribute.String(tagKey, member.Value()))
+			}
+		}
+		return tags
+	})
+}
+
+// sanitizeKey replaces the characters not allowed in tag keys by underscores.
+func sanitizeKey(key string) string {
+	b := []byte(key)
+	for i, c := range b {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,21 @@
+package tag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestFromBaggage(t *testing.T) {
+	provider := FromBaggage("tenant.tier", "region")
+	assert.Empty(t, provider.Tags(context.Background()))
+
+	bag, err := baggage.Parse("tenant.tier=gold,user.id=42")
+	require.NoError(t, err)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("tenant_tier", "gold")}, provider.Tags(ctx))
+}
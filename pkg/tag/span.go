@@ -0,0 +1,50 @@
+package tag
+
+import (
+	"context"
+	"github.com/liangweijiang/go-metric/pkg/config"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+// SpanSelector chooses the attributes of the current span copied to the measurements, returning the tag to record,
+// e.g. with a renamed key, and false to leave the attribute out.
+type SpanSelector func(kv attribute.KeyValue) (attribute.KeyValue, bool)
+
+// SpanKeys returns a SpanSelector copying the span attributes with the given keys, e.g. "rpc.method", the dots being
+// replaced by underscores to form valid tag keys.
+func SpanKeys(keys ...string) SpanSelector {
+	allowed := make(map[attribute.Key]attribute.Key, len(keys))
+	for _, key := range keys {
+		allowed[attribute.Key(key)] = attribute.Key(strings.ReplaceAll(key, ".", "_"))
+	}
+	return func(kv attribute.KeyValue) (attribute.KeyValue, bool) {
+		key, ok := allowed[kv.Key]
+		if !ok {
+			return kv, false
+		}
+		return attribute.KeyValue{Key: key, Value: kv.Value}, true
+	}
+}
+
+// FromSpan returns a config.TagProvider tagging the measurements recorded with a context carrying a span with the
+// attributes of the span chosen by selector. Only the spans of the OpenTelemetry SDK expose their attributes, the
+// measurements recorded without such a span get no tag. As every copied attribute becomes a metric dimension, the
+// selector must only keep attributes of bounded cardinality: a method name rather than a request id.
+func FromSpan(selector SpanSelector) config.TagProvider {
+	return config.TagProviderFunc(func(ctx context.Context) []attribute.KeyValue {
+		span, ok := trace.SpanFromContext(ctx).(sdktrace.ReadOnlySpan)
+		if !ok {
+			return nil
+		}
+		var tags []attribute.KeyValue
+		for _, kv := range span.Attributes() {
+			if selected, ok := selector(kv); ok {
+				tags = append(tags, selected)
+			}
+		}
+		return tags
+	})
+}
@@ -0,0 +1,21 @@
+package tag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFromSpan(t *testing.T) {
+	provider := FromSpan(SpanKeys("rpc.method"))
+	assert.Empty(t, provider.Tags(context.Background()))
+
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "call",
+		trace.WithAttributes(attribute.String("rpc.method", "GetUser"), attribute.String("request.id", "42")))
+	defer span.End()
+	assert.Equal(t, []attribute.KeyValue{attribute.String("rpc_method", "GetUser")}, provider.Tags(ctx))
+}
@@ -0,0 +1,105 @@
+// Package tag builds typed tags for the instruments, so that numeric and boolean tag values keep their type instead
+// of being formatted with fmt.Sprintf at the call sites:
+//
+//	tag.Add(m.NewCounter("requests", "", ""), tag.Key("status").Int(200), tag.Key("method").String("GET"))
+package tag
+
+import (
+	"github.com/liangweijiang/go-metric/pkg/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"reflect"
+)
+
+// Tagged is the set of the instruments of type T, which add tags to themselves.
+type Tagged[T any] interface {
+	AddTag(key string, value string) T
+}
+
+// Add adds the typed tags attrs to instrument, keeping their type if it implements interfaces.Attributed, as the
+// instruments of the SDK do, and formatted as strings with AddTag otherwise, e.g. by an instrument implemented out of
+// the SDK.
+func Add[T Tagged[T]](instrument T, attrs ...attribute.KeyValue) T {
+	if attributed, ok := any(instrument).(interfaces.Attributed[T]); ok {
+		return attributed.AddAttributes(attrs...)
+	}
+	for _, kv := range attrs {
+		instrument = instrument.AddTag(string(kv.Key), kv.Value.Emit())
+	}
+	return instrument
+}
+
+// AddInt adds the integer tag key=value to instrument, see Add.
+func AddInt[T Tagged[T]](instrument T, key string, value int) T {
+	return Add(instrument, attribute.Int(key, value))
+}
+
+// AddBool adds the boolean tag key=value to instrument, see Add.
+func AddBool[T Tagged[T]](instrument T, key string, value bool) T {
+	return Add(instrument, attribute.Bool(key, value))
+}
+
+// AddFloat adds the floating point tag key=value to instrument, see Add.
+func AddFloat[T Tagged[T]](instrument T, key string, value float64) T {
+	return Add(instrument, attribute.Float64(key, value))
+}
+
+// Key returns the typed key of a tag, whose String, Int, Int64, Float64 and Bool methods build the tag with its value.
+func Key(name string) attribute.Key {
+	return attribute.Key(name)
+}
+
+// Value is the set of the types a tag value built by Of can have.
+type Value interface {
+	~string | ~bool | ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~float32 | ~float64
+}
+
+// Of builds the tag name=v keeping the type of v, integers being widened to int64 and floats to float64, e.g.
+// tag.Of("retries", retries) whatever the integer type of retries.
+func Of[T Value](name string, v T) attribute.KeyValue {
+	switch value := any(v).(type) {
+	case string:
+		return attribute.String(name, value)
+	case bool:
+		return attribute.Bool(name, value)
+	case int:
+		return attribute.Int(name, value)
+	case int8:
+		return attribute.Int64(name, int64(value))
+	case int16:
+		return attribute.Int64(name, int64(value))
+	case int32:
+		return attribute.Int64(name, int64(value))
+	case int64:
+		return attribute.Int64(name, value)
+	case uint8:
+		return attribute.Int64(name, int64(value))
+	case uint16:
+		return attribute.Int64(name, int64(value))
+	case uint32:
+		return attribute.Int64(name, int64(value))
+	case float32:
+		return attribute.Float64(name, float64(value))
+	case float64:
+		return attribute.Float64(name, value)
+	default:
+		// named types, e.g. type Status int, are converted through their underlying type.
+		return ofNamed(name, v)
+	}
+}
+
+// ofNamed builds the tag of a value whose type is defined on one of the types of Value, from its underlying value.
+func ofNamed(name string, v any) attribute.KeyValue {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return attribute.Bool(name, rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return attribute.Int64(name, rv.Int())
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return attribute.Int64(name, int64(rv.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return attribute.Float64(name, rv.Float())
+	default:
+		return attribute.String(name, rv.String())
+	}
+}
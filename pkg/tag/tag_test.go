@@ -0,0 +1,85 @@
+package tag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type status int
+
+func TestOf(t *testing.T) {
+	assert.Equal(t, attribute.Int("status", 200), Key("status").Int(200))
+	assert.Equal(t, attribute.String("method", "GET"), Of("method", "GET"))
+	assert.Equal(t, attribute.Bool("cached", true), Of("cached", true))
+	assert.Equal(t, attribute.Int64("retries", 3), Of("retries", int32(3)))
+	assert.Equal(t, attribute.Int64("size", 7), Of("size", uint16(7)))
+	assert.Equal(t, attribute.Float64("ratio", 0.5), Of("ratio", float32(0.5)))
+	assert.Equal(t, attribute.Int64("status", 404), Of("status", status(404)))
+}
+
+// tagged is an instrument accepting the tags as strings only.
+type tagged struct {
+	tags []attribute.KeyValue
+}
+
+func (t *tagged) AddTag(key string, value string) *tagged {
+	t.tags = append(t.tags, attribute.String(key, value))
+	return t
+}
+
+// attributed is an instrument accepting typed tags.
+type attributed struct {
+	tagged
+}
+
+func (a *attributed) AddTag(key string, value string) *attributed {
+	a.tagged.AddTag(key, value)
+	return a
+}
+
+func (a *attributed) AddAttributes(attrs ...attribute.KeyValue) *attributed {
+	a.tags = append(a.tags, attrs...)
+	return a
+}
+
+func TestAdd(t *testing.T) {
+	attrs := []attribute.KeyValue{Key("status").Int(200), Key("cached").Bool(true)}
+	tests := []struct {
+		name string
+		add  func() []attribute.KeyValue
+		want []attribute.KeyValue
+	}{
+		{
+			name: "typed",
+			add:  func() []attribute.KeyValue { return Add(&attributed{}, attrs...).tags },
+			want: attrs,
+		},
+		{
+			name: "strings",
+			add:  func() []attribute.KeyValue { return Add(&tagged{}, attrs...).tags },
+			want: []attribute.KeyValue{attribute.String("status", "200"), attribute.String("cached", "true")},
+		},
+		{
+			name: "int",
+			add:  func() []attribute.KeyValue { return AddInt(&attributed{}, "status", 200).tags },
+			want: []attribute.KeyValue{attribute.Int("status", 200)},
+		},
+		{
+			name: "bool",
+			add:  func() []attribute.KeyValue { return AddBool(&attributed{}, "cached", true).tags },
+			want: []attribute.KeyValue{attribute.Bool("cached", true)},
+		},
+		{
+			name: "float",
+			add:  func() []attribute.KeyValue { return AddFloat(&tagged{}, "ratio", 0.5).tags },
+			want: []attribute.KeyValue{attribute.String("ratio", "0.5")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.add())
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"errors"
+	"net"
+)
+
+// ErrNoLocalIP 没有找到可用的本机IP时返回
+var ErrNoLocalIP = errors.New("no non-loopback local ip found")
+
+// netInterface 是探测本机IP所需的网卡信息
+type netInterface struct {
+	name  string
+	addrs []net.IP
+}
+
+// LocalIP 探测本机的IP地址，跳过未启用的网卡、回环地址与链路本地地址，IPv4优先于IPv6
+// preferred 为网卡名称的优先级列表，例如 eth0、en0，依次在这些网卡上查找，都没有时再取第一个可用的网卡
+func LocalIP(preferred ...string) (string, error) {
+	interfaces, err := systemInterfaces()
+	if err != nil {
+		return "", err
+	}
+	return pickLocalIP(interfaces, preferred)
+}
+
+// systemInterfaces 返回本机已启用且非回环网卡的地址
+func systemInterfaces() ([]netInterface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	var interfaces []netInterface
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		ni := netInterface{name: iface.Name}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				ni.addrs = append(ni.addrs, ipNet.IP)
+			}
+		}
+		interfaces = append(interfaces, ni)
+	}
+	return interfaces, nil
+}
+
+// pickLocalIP 按网卡优先级列表选择IP，同一优先级内IPv4优先
+func pickLocalIP(interfaces []netInterface, preferred []string) (string, error) {
+	for _, name := range preferred {
+		for _, iface := range interfaces {
+			if iface.name != name {
+				continue
+			}
+			if ip, ok := pickAddr(iface.addrs); ok {
+				return ip, nil
+			}
+		}
+	}
+	var all []net.IP
+	for _, iface := range interfaces {
+		all = append(all, iface.addrs...)
+	}
+	if ip, ok := pickAddr(all); ok {
+		return ip, nil
+	}
+	return "", ErrNoLocalIP
+}
+
+// pickAddr 返回第一个可用的IPv4地址，没有时返回第一个可用的IPv6地址
+func pickAddr(addrs []net.IP) (string, bool) {
+	var v6 net.IP
+	for _, ip := range addrs {
+		if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() || ip.IsMulticast() {
+			continue
+		}
+		if ip.To4() != nil {
+			return ip.String(), true
+		}
+		if v6 == nil {
+			v6 = ip
+		}
+	}
+	if v6 != nil {
+		return v6.String(), true
+	}
+	return "", false
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestPickLocalIP(t *testing.T) {
+	interfaces := []netInterface{
+		{name: "docker0", addrs: []net.IP{net.ParseIP("172.17.0.1")}},
+		{name: "eth0", addrs: []net.IP{net.ParseIP("fe80::1"), net.ParseIP("2001:db8::7"), net.ParseIP("10.0.0.7")}},
+		{name: "eth1", addrs: []net.IP{net.ParseIP("fe80::2"), net.ParseIP("2001:db8::8")}},
+	}
+	cases := []struct {
+		preferred []string
+		want      string
+	}{
+		{nil, "172.17.0.1"},
+		{[]string{"eth0"}, "10.0.0.7"},
+		{[]string{"eth1", "eth0"}, "2001:db8::8"},
+		{[]string{"wlan0"}, "172.17.0.1"},
+	}
+	for _, c := range cases {
+		if got, err := pickLocalIP(interfaces, c.preferred); err != nil || got != c.want {
+			t.Errorf("pickLocalIP(%v) = %q, %v; want %q", c.preferred, got, err, c.want)
+		}
+	}
+
+	linkLocal := []netInterface{{name: "eth0", addrs: []net.IP{net.ParseIP("fe80::1"), net.ParseIP("127.0.0.1")}}}
+	if _, err := pickLocalIP(linkLocal, nil); !errors.Is(err, ErrNoLocalIP) {
+		t.Errorf("pickLocalIP(link-local only) error = %v; want %v", err, ErrNoLocalIP)
+	}
+}
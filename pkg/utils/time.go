@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Jitter 在周期 d 上增加 [-fraction*d, +fraction*d] 范围内的随机抖动，避免大量实例在同一时刻推送/采集
+// fraction 小于等于0时原样返回 d，大于1时按1处理，返回值至少为1ms
+func Jitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || d <= 0 {
+		return d
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	delta := time.Duration((rand.Float64()*2 - 1) * fraction * float64(d))
+	if jittered := d + delta; jittered > time.Millisecond {
+		return jittered
+	}
+	return time.Millisecond
+}
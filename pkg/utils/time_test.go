@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitter(t *testing.T) {
+	period := 10 * time.Second
+	if got := Jitter(period, 0); got != period {
+		t.Errorf("Jitter(%s, 0) = %s; want %s", period, got, period)
+	}
+	for i := 0; i < 1000; i++ {
+		got := Jitter(period, 0.2)
+		if got < 8*time.Second || got > 12*time.Second {
+			t.Fatalf("Jitter(%s, 0.2) = %s; want within [8s, 12s]", period, got)
+		}
+	}
+	if got := Jitter(period, 5); got < time.Millisecond || got > 20*time.Second {
+		t.Errorf("Jitter(%s, 5) = %s; want within [1ms, 20s]", period, got)
+	}
+}
@@ -0,0 +1,224 @@
+// Package validate checks instrumentation against the naming, tagging and cardinality rules of the SDK.
+// It backs the validate meter provider (config.MeterProviderTypeValidate), which accepts every call without exporting
+// anything and reports the violations, so that CI integration tests can fail on bad instrumentation.
+package validate
+
+import (
+	"fmt"
+	"github.com/liangweijiang/go-metric/pkg/semconv"
+	"go.opentelemetry.io/otel/attribute"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// DefaultCardinalityLimit is the number of distinct tag sets of a metric beyond which a violation is reported.
+const DefaultCardinalityLimit = 1000
+
+// Rules reported in the violations.
+const (
+	RuleMetricName        = "metric_name"
+	RuleReservedSuffix    = "reserved_suffix"
+	RuleUnit              = "unit"
+	RuleKindConflict      = "kind_conflict"
+	RuleBuckets           = "buckets"
+	RuleTagKey            = "tag_key"
+	RuleNearDuplicateKey  = "near_duplicate_tag_key"
+	RuleTagValue          = "tag_value"
+	RuleInconsistentTags  = "inconsistent_tag_keys"
+	RuleCardinality       = "cardinality"
+	RuleValue             = "value"
+	RuleNegativeIncrement = "negative_increment"
+)
+
+var (
+	// metricNamePattern is the pattern of valid Prometheus metric names.
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	// tagKeyPattern is the pattern of valid tag keys.
+	tagKeyPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// reservedSuffixes are the suffixes appended by the exposition, a metric named with them is exported ambiguously.
+	reservedSuffixes = []string{"_total", "_bucket", "_count", "_sum", "_created"}
+)
+
+// Violation is a breach of a rule by a metric.
+type Violation struct {
+	Metric  string
+	Rule    string
+	Message string
+}
+
+// String formats the violation for logs and test failures.
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s: %s", v.Metric, v.Rule, v.Message)
+}
+
+// metricState is what the validator remembers of a metric.
+type metricState struct {
+	kind     string
+	unit     string
+	recorded bool
+	keys     string
+	tagSets  map[attribute.Distinct]struct{}
+	exceeded bool
+}
+
+// Validator checks the instruments and measurements it is given and accumulates the violations, each reported once.
+// It is safe for concurrent use.
+type Validator struct {
+	limit      int
+	onReport   func(v Violation)
+	keyChecker *semconv.KeyChecker
+	mu         sync.Mutex
+	metrics    map[string]*metricState
+	seen       map[Violation]struct{}
+	violations []Violation
+	pending    []Violation
+}
+
+// NewValidator creates a validator reporting the metrics with more than limit distinct tag sets,
+// DefaultCardinalityLimit if limit is not positive. onReport, which may be nil, is called for every new violation,
+// without holding the lock of the validator: it may record metrics checked by the validator itself.
+func NewValidator(limit int, onReport func(v Violation)) *Validator {
+	if limit <= 0 {
+		limit = DefaultCardinalityLimit
+	}
+	return &Validator{
+		limit:      limit,
+		onReport:   onReport,
+		keyChecker: semconv.NewKeyChecker(),
+		metrics:    make(map[string]*metricState),
+		seen:       make(map[Violation]struct{}),
+	}
+}
+
+// SetLimit changes the number of distinct tag sets beyond which a violation is reported,
+// DefaultCardinalityLimit if limit is not positive. Metrics already reported are not reported again.
+func (v *Validator) SetLimit(limit int) {
+	if limit <= 0 {
+		limit = DefaultCardinalityLimit
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.limit = limit
+}
+
+// Violations returns the violations reported so far, in the order they were found.
+func (v *Validator) Violations() []Violation {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]Violation(nil), v.violations...)
+}
+
+// unlock releases v.mu, then calls onReport with the violations reported while it was held.
+func (v *Validator) unlock() {
+	pending := v.pending
+	v.pending = nil
+	v.mu.Unlock()
+	if v.onReport == nil {
+		return
+	}
+	for _, violation := range pending {
+		v.onReport(violation)
+	}
+}
+
+// report records a violation unless it was already reported, v.mu must be held and released with unlock.
+func (v *Validator) report(metric, rule, format string, args ...any) {
+	violation := Violation{
+		Metric:  metric,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	}
+	if _, seen := v.seen[violation]; seen {
+		return
+	}
+	v.seen[violation] = struct{}{}
+	v.violations = append(v.violations, violation)
+	v.pending = append(v.pending, violation)
+}
+
+// CheckInstrument checks the creation of an instrument of the given kind, e.g. "counter", with its name, unit and
+// histogram boundaries, which may be nil.
+func (v *Validator) CheckInstrument(kind, name, unit string, buckets []float64) {
+	v.mu.Lock()
+	defer v.unlock()
+	if !metricNamePattern.MatchString(name) || strings.HasPrefix(name, "__") {
+		v.report(name, RuleMetricName, "metric name must match %s and not start with __", metricNamePattern)
+	}
+	for _, suffix := range reservedSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			v.report(name, RuleReservedSuffix, "metric name must not end with %s, the suffix is added by the exposition", suffix)
+		}
+	}
+	if strings.ContainsAny(unit, " \t\n") {
+		v.report(name, RuleUnit, "unit %q must not contain spaces", unit)
+	}
+	state, ok := v.metrics[name]
+	if !ok {
+		v.metrics[name] = &metricState{
+			kind:    kind,
+			unit:    unit,
+			tagSets: make(map[attribute.Distinct]struct{}),
+		}
+	} else if state.kind != kind || state.unit != unit {
+		v.report(name, RuleKindConflict, "metric created as %s in %q and as %s in %q", state.kind, state.unit, kind, unit)
+	}
+	for i, boundary := range buckets {
+		if math.IsNaN(boundary) || math.IsInf(boundary, 0) {
+			v.report(name, RuleBuckets, "bucket boundaries must be finite")
+		} else if i > 0 && boundary <= buckets[i-1] {
+			v.report(name, RuleBuckets, "bucket boundaries must be strictly increasing")
+		}
+	}
+}
+
+// CheckRecord checks a measurement of value recorded to the metric with the given tags.
+// Counters must not be decreased: monotonic reports negative values as violations.
+func (v *Validator) CheckRecord(name string, tags []attribute.KeyValue, value float64, monotonic bool) {
+	v.mu.Lock()
+	defer v.unlock()
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		v.report(name, RuleValue, "recorded value must be finite")
+	}
+	if monotonic && value < 0 {
+		v.report(name, RuleNegativeIncrement, "counter must not be decreased, use an up-down counter")
+	}
+	for _, kv := range tags {
+		key := string(kv.Key)
+		if !tagKeyPattern.MatchString(key) || strings.HasPrefix(key, "__") {
+			v.report(name, RuleTagKey, "tag key %q must match %s and not start with __", key, tagKeyPattern)
+		}
+		if first, conflict := v.keyChecker.Check(key); conflict {
+			v.report(name, RuleNearDuplicateKey, "tag key %q nearly duplicates tag key %q", key, first)
+		}
+		if kv.Value.Type() == attribute.STRING && !utf8.ValidString(kv.Value.AsString()) {
+			v.report(name, RuleTagValue, "value of tag %q must be valid UTF-8", key)
+		}
+	}
+	state, ok := v.metrics[name]
+	if !ok {
+		return
+	}
+	set := attribute.NewSet(tags...)
+	keys := make([]string, 0, set.Len())
+	for iter := set.Iter(); iter.Next(); {
+		keys = append(keys, string(iter.Attribute().Key))
+	}
+	signature := strings.Join(keys, ",")
+	if !state.recorded {
+		state.recorded, state.keys = true, signature
+	} else if state.keys != signature {
+		v.report(name, RuleInconsistentTags, "recorded with tag keys [%s] and [%s]", state.keys, signature)
+	}
+	if state.exceeded {
+		return
+	}
+	state.tagSets[set.Equivalent()] = struct{}{}
+	if len(state.tagSets) > v.limit {
+		state.exceeded = true
+		state.tagSets = nil
+		v.report(name, RuleCardinality, "more than %d distinct tag sets", v.limit)
+	}
+}
@@ -0,0 +1,73 @@
+package validate
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func rules(violations []Violation) []string {
+	var out []string
+	for _, v := range violations {
+		out = append(out, v.Rule)
+	}
+	return out
+}
+
+func TestValidatorInstrument(t *testing.T) {
+	v := NewValidator(0, nil)
+
+	v.CheckInstrument("counter", "requests", "", nil)
+	assert.Empty(t, v.Violations())
+
+	v.CheckInstrument("counter", "http-requests", "", nil)
+	v.CheckInstrument("counter", "requests_total", "", nil)
+	v.CheckInstrument("gauge", "requests", "", nil)
+	v.CheckInstrument("histogram", "latency", "s", []float64{0.1, 0.05})
+	assert.Equal(t, []string{RuleMetricName, RuleReservedSuffix, RuleKindConflict, RuleBuckets}, rules(v.Violations()))
+}
+
+func TestValidatorRecord(t *testing.T) {
+	var reported []Violation
+	v := NewValidator(2, func(violation Violation) {
+		reported = append(reported, violation)
+	})
+	v.CheckInstrument("counter", "requests", "", nil)
+
+	v.CheckRecord("requests", []attribute.KeyValue{attribute.String("status_code", "200")}, 1, true)
+	assert.Empty(t, v.Violations())
+
+	v.CheckRecord("requests", []attribute.KeyValue{attribute.String("statusCode", "200")}, -1, true)
+	v.CheckRecord("requests", []attribute.KeyValue{attribute.String("status-code", "200")}, 1, true)
+	for i := 0; i < 3; i++ {
+		v.CheckRecord("requests", []attribute.KeyValue{attribute.String("status_code", strconv.Itoa(i))}, 1, true)
+	}
+	assert.Equal(t, []string{
+		RuleNegativeIncrement, RuleNearDuplicateKey, RuleInconsistentTags,
+		RuleTagKey, RuleNearDuplicateKey, RuleInconsistentTags, RuleCardinality,
+	}, rules(v.Violations()))
+	assert.Equal(t, v.Violations(), reported)
+}
+
+func TestValidatorReportChecks(t *testing.T) {
+	var v *Validator
+	v = NewValidator(0, func(violation Violation) {
+		// recording from the callback, e.g. a counter of the violations, checks it with the same validator.
+		v.CheckInstrument("counter", "violations", "", nil)
+		v.CheckRecord("violations", []attribute.KeyValue{attribute.String("rule", violation.Rule)}, 1, true)
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v.CheckInstrument("counter", "requests_total", "", nil)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the callback recording a metric deadlocked")
+	}
+	assert.Equal(t, []string{RuleReservedSuffix}, rules(v.Violations()))
+}